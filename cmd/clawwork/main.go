@@ -2,26 +2,60 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/spf13/cobra"
 
+	"github.com/clawplaza/clawwork-cli/internal/advisor"
 	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/backup"
+	"github.com/clawplaza/clawwork-cli/internal/callback"
 	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/crash"
 	"github.com/clawplaza/clawwork-cli/internal/daemon"
+	"github.com/clawplaza/clawwork-cli/internal/datasync"
+	"github.com/clawplaza/clawwork-cli/internal/devserver"
+	"github.com/clawplaza/clawwork-cli/internal/diagnose"
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
 	"github.com/clawplaza/clawwork-cli/internal/knowledge"
 	"github.com/clawplaza/clawwork-cli/internal/llm"
+	"github.com/clawplaza/clawwork-cli/internal/metrics"
 	"github.com/clawplaza/clawwork-cli/internal/miner"
+	"github.com/clawplaza/clawwork-cli/internal/mqtt"
+	"github.com/clawplaza/clawwork-cli/internal/notify"
+	"github.com/clawplaza/clawwork-cli/internal/schedule"
+	"github.com/clawplaza/clawwork-cli/internal/store"
+	"github.com/clawplaza/clawwork-cli/internal/support"
+	"github.com/clawplaza/clawwork-cli/internal/tools"
 	"github.com/clawplaza/clawwork-cli/internal/updater"
+	"github.com/clawplaza/clawwork-cli/internal/wallet"
 	"github.com/clawplaza/clawwork-cli/internal/web"
+
+	// Embedded zone database, so display.timezone works on machines
+	// without one (Windows, minimal containers).
+	_ "time/tzdata"
 )
 
 // Set at build time via ldflags.
@@ -40,25 +74,88 @@ func main() {
 		Long:  "ClawWork CLI — Official client for the ClawWork AI Agent labor market.",
 	}
 
-	root.AddCommand(initCmd(), inscCmd(), claimCmd(), statusCmd(), configCmd(), soulCmd(), specCmd(), versionCmd(), updateCmd(),
-		installCmd(), uninstallCmd(), startCmd(), stopCmd(), restartCmd())
+	// Apply privacy settings before any command talks to the platform.
+	root.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		if !api.IsProduction() {
+			printEnvBanner()
+		}
+		api.SetNonceStore(filepath.Join(config.Dir(), "nonces.json"))
+		if cfg, err := config.Load(); err == nil {
+			api.SetMinimalHeaders(cfg.Privacy.MinimalHeaders)
+			api.SetLabel(cfg.Agent.Label)
+			applyTimezone(cfg)
+			tools.SetTrash(trashOf(cfg.Web))
+			openStore(cfg)
+			if err := httpx.Configure(cfg.Network); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+			}
+		}
+	}
+
+	root.PersistentPostRun = func(_ *cobra.Command, _ []string) {
+		_ = miner.Store().Close()
+	}
+
+	root.PersistentFlags().Bool("json", false, "Print machine-readable JSON (status, stats, history, earnings, config show, version)")
+
+	root.AddCommand(initCmd(), inscCmd(), claimCmd(), statusCmd(), statsCmd(), historyCmd(), earningsCmd(), metricsCmd(), goalCmd(), scheduleCmd(), experimentCmd(), notifyCmd(), callbackCmd(), leaderboardCmd(), adviseCmd(), agentCmd(), selftestCmd(), debugCmd(), supportCmd(), backupCmd(), syncCmd(), devserverCmd(), configCmd(), storageCmd(), soulCmd(), specCmd(), versionCmd(), updateCmd(),
+		installCmd(), uninstallCmd(), startCmd(), stopCmd(), restartCmd(), attachCmd(), detachCmd(), ctlCmd(), consoleCmd(), chatCmd(), trashCmd(), remoteCmd())
 
 	if err := root.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+// printEnvBanner warns on stderr that a devenv build is talking to a
+// non-production platform, so nobody mistakes staging results for real ones.
+func printEnvBanner() {
+	line := strings.Repeat("!", 64)
+	fmt.Fprintln(os.Stderr, line)
+	fmt.Fprintln(os.Stderr, "!!  NON-PRODUCTION PLATFORM")
+	fmt.Fprintf(os.Stderr, "!!  API: %s\n", api.BaseURL)
+	fmt.Fprintln(os.Stderr, "!!  Inscriptions, trust and rewards here are not real.")
+	fmt.Fprintln(os.Stderr, line)
+}
+
 // ── init command ──
 
 func initCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize config and register agent",
-		RunE:  runInit,
+		Long: "Initialize config and register agent.\n\n" +
+			"With --non-interactive nothing is read from stdin (for Docker images and CI). " +
+			"Pass --api-key for an existing agent, or --agent-name to register a new one. " +
+			"Every flag falls back to an environment variable: CLAWWORK_API_KEY, CLAWWORK_AGENT_NAME, " +
+			"CLAWWORK_TOKEN_ID, CLAWWORK_LLM_PROVIDER, CLAWWORK_LLM_KEY, CLAWWORK_LLM_MODEL and CLAWWORK_LLM_BASE_URL.\n\n" +
+			"With --web the same steps run in a setup wizard opened in the browser.",
+		RunE:         runInit,
+		SilenceUsage: true,
 	}
+	cmd.Flags().Bool("non-interactive", false, "Configure from flags and environment variables without prompting")
+	cmd.Flags().Bool("web", false, "Set up in the browser instead of the terminal")
+	cmd.Flags().String("api-key", "", "Existing agent API key (clwk_...)")
+	cmd.Flags().String("agent-name", "", "Agent name to register when no API key is given")
+	cmd.Flags().Int("token-id", 0, "Token ID to inscribe (25-1024, default 42)")
+	cmd.Flags().String("llm-provider", "", "LLM provider: "+strings.Join(config.LLMPresetNames(), ", ")+" (default kimi)")
+	cmd.Flags().String("llm-key", "", "LLM provider API key (platform: plat_ key)")
+	cmd.Flags().String("llm-model", "", "Model name (default: the provider's)")
+	cmd.Flags().String("llm-base-url", "", "API base URL (required for custom)")
+	cmd.Flags().Bool("force", false, "Overwrite an existing config (non-interactive and web modes)")
+	return cmd
 }
 
-func runInit(_ *cobra.Command, _ []string) error {
+func runInit(cmd *cobra.Command, _ []string) error {
+	nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+	webSetup, _ := cmd.Flags().GetBool("web")
+	switch {
+	case nonInteractive && webSetup:
+		return fmt.Errorf("--web and --non-interactive cannot be combined")
+	case nonInteractive:
+		return runInitNonInteractive(cmd)
+	case webSetup:
+		return runInitWeb(cmd)
+	}
 	fmt.Printf("Welcome to ClawWork!  (v%s)\n", version)
 
 	// Non-blocking remote version check
@@ -68,7 +165,7 @@ func runInit(_ *cobra.Command, _ []string) error {
 	}
 	versionCh := make(chan versionResult, 1)
 	go func() {
-		info, err := updater.CheckUpdate(version)
+		info, err := updater.CheckUpdate(version, updateChannel(nil))
 		versionCh <- versionResult{info, err}
 	}()
 
@@ -150,11 +247,8 @@ func runInitNew(scanner *bufio.Scanner) error {
 	fmt.Print("\nRegistering agent... ")
 	client := api.New("")
 	resp, err := client.Register(context.Background(), cfg.Agent.Name, cfg.Agent.TokenID)
-	if err != nil {
-		return fmt.Errorf("registration failed: %w", err)
-	}
-
-	if resp.Error == "ALREADY_REGISTERED" || resp.Error == "NAME_TAKEN" {
+	switch {
+	case api.HasCode(err, "ALREADY_REGISTERED") || api.HasCode(err, "NAME_TAKEN"):
 		fmt.Println("agent name already taken.")
 		fmt.Print("Enter your existing API key: ")
 		scanner.Scan()
@@ -162,12 +256,15 @@ func runInitNew(scanner *bufio.Scanner) error {
 		if cfg.Agent.APIKey == "" {
 			return fmt.Errorf("API key is required for existing agents")
 		}
-	} else if resp.APIKey != "" {
+	case err != nil:
+		if apiErr, ok := api.AsAPIError(err); ok {
+			return fmt.Errorf("registration error: %s — %s", apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("registration failed: %w", err)
+	case resp.APIKey != "":
 		cfg.Agent.APIKey = resp.APIKey
 		fmt.Println("done!")
 		fmt.Printf("Agent ID: %s\n", resp.AgentID)
-	} else if resp.Error != "" {
-		return fmt.Errorf("registration error: %s — %s", resp.Error, resp.Message)
 	}
 
 	// Save config
@@ -307,6 +404,156 @@ func runInitExisting(scanner *bufio.Scanner) error {
 	return nil
 }
 
+// runInitNonInteractive writes a config from flags and CLAWWORK_*
+// environment variables, registering the agent when only a name is given.
+// It never reads stdin, never starts mining and never overwrites a config
+// without --force.
+func runInitNonInteractive(cmd *cobra.Command) error {
+	if _, err := os.Stat(config.Path()); err == nil {
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			return fmt.Errorf("config already exists at %s (pass --force to overwrite)", config.Path())
+		}
+	}
+	cfg := config.DefaultConfig()
+
+	if s := flagOrEnv(cmd, "token-id", "CLAWWORK_TOKEN_ID"); s != "" && s != "0" {
+		tid, err := strconv.Atoi(s)
+		if err != nil || tid < 25 || tid > 1024 {
+			return fmt.Errorf("invalid token ID %q: must be 25-1024", s)
+		}
+		cfg.Agent.TokenID = tid
+	}
+
+	// LLM: a preset, then any model/URL overrides.
+	provider := strings.ToLower(flagOrEnv(cmd, "llm-provider", "CLAWWORK_LLM_PROVIDER"))
+	if provider == "" {
+		provider = "kimi"
+	}
+	if !config.ApplyLLMPreset(&cfg.LLM, provider) {
+		return fmt.Errorf("unknown --llm-provider %q (want %s)", provider, strings.Join(config.LLMPresetNames(), ", "))
+	}
+	if m := flagOrEnv(cmd, "llm-model", "CLAWWORK_LLM_MODEL"); m != "" {
+		cfg.LLM.Model = m
+	}
+	if u := flagOrEnv(cmd, "llm-base-url", "CLAWWORK_LLM_BASE_URL"); u != "" {
+		cfg.LLM.BaseURL = u
+	}
+	cfg.LLM.APIKey = flagOrEnv(cmd, "llm-key", "CLAWWORK_LLM_KEY")
+	switch {
+	case provider == "custom" && (cfg.LLM.BaseURL == "" || cfg.LLM.Model == ""):
+		return fmt.Errorf("--llm-provider custom needs --llm-base-url and --llm-model")
+	case cfg.LLM.APIKey == "" && cfg.LLM.Provider != "ollama":
+		return fmt.Errorf("--llm-key (or CLAWWORK_LLM_KEY) is required for provider %s", provider)
+	}
+
+	// Agent: verify an existing key, or register a new agent.
+	apiKey := flagOrEnv(cmd, "api-key", "CLAWWORK_API_KEY")
+	cfg.Agent.Name = flagOrEnv(cmd, "agent-name", "CLAWWORK_AGENT_NAME")
+	miningReady := true
+	switch {
+	case apiKey != "":
+		cfg.Agent.APIKey = apiKey
+		status, err := api.New(apiKey).Status(context.Background())
+		if err != nil {
+			return fmt.Errorf("could not verify API key: %w", err)
+		}
+		if status.Agent.ID == "" {
+			return fmt.Errorf("invalid API key")
+		}
+		if cfg.Agent.Name == "" {
+			cfg.Agent.Name = status.Agent.Name
+		}
+		fmt.Printf("Agent verified: %s\n", status.Agent.ID)
+	case cfg.Agent.Name != "":
+		resp, err := api.New("").Register(context.Background(), cfg.Agent.Name, cfg.Agent.TokenID)
+		switch {
+		case api.HasCode(err, "ALREADY_REGISTERED") || api.HasCode(err, "NAME_TAKEN"):
+			return fmt.Errorf("agent name %q is already taken — pass its --api-key instead", cfg.Agent.Name)
+		case err != nil:
+			if apiErr, ok := api.AsAPIError(err); ok {
+				return fmt.Errorf("registration error: %s — %s", apiErr.Code, apiErr.Message)
+			}
+			return fmt.Errorf("registration failed: %w", err)
+		case resp.APIKey == "":
+			return fmt.Errorf("registration returned no API key")
+		}
+		cfg.Agent.APIKey = resp.APIKey
+		miningReady = resp.MiningReady
+		fmt.Printf("Agent registered: %s\n", resp.AgentID)
+	default:
+		return fmt.Errorf("--api-key (or CLAWWORK_API_KEY) or --agent-name (or CLAWWORK_AGENT_NAME) is required")
+	}
+
+	// Save before validating: a freshly registered agent's key must not
+	// be lost to a problem elsewhere in the config.
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("Config saved to %s\n", config.Path())
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config saved, but it is not ready to mine: %w", err)
+	}
+	if !miningReady {
+		fmt.Println("Next: claim this agent at https://work.clawplaza.ai/my-agent, then run: clawwork claim")
+	}
+	return nil
+}
+
+// runInitWeb serves the setup wizard on localhost and opens it in the
+// browser, then waits for it to finish. Like --non-interactive it never
+// overwrites a config without --force.
+func runInitWeb(cmd *cobra.Command) error {
+	if _, err := os.Stat(config.Path()); err == nil {
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			return fmt.Errorf("config already exists at %s (pass --force to overwrite)", config.Path())
+		}
+	}
+
+	setup := web.NewSetup(version)
+	if err := setup.Start(0); err != nil {
+		return err
+	}
+	fmt.Printf("Welcome to ClawWork!  (v%s)\n\n", version)
+	fmt.Printf("Setup wizard: %s\n", setup.URL())
+	if err := openBrowser(setup.URL()); err != nil {
+		fmt.Println("Could not launch a browser — open the address above yourself.")
+	}
+	fmt.Println("Waiting for setup to finish in the browser (Ctrl+C to cancel)...")
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var start bool
+	select {
+	case start = <-setup.Done():
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_ = setup.Shutdown(shutdownCtx)
+	if ctx.Err() != nil {
+		fmt.Println("\nSetup cancelled.")
+		return nil
+	}
+	stop()
+
+	fmt.Printf("\nConfig saved to %s\n", config.Path())
+	if start {
+		fmt.Println()
+		return runInsc(nil, nil)
+	}
+	fmt.Println("Run 'clawwork insc' to begin when ready.")
+	return nil
+}
+
+// flagOrEnv returns the flag's value if it was set, else the environment
+// variable's.
+func flagOrEnv(cmd *cobra.Command, flag, env string) string {
+	if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+		return strings.TrimSpace(f.Value.String())
+	}
+	return strings.TrimSpace(os.Getenv(env))
+}
+
 // ── claim command ──
 
 func claimCmd() *cobra.Command {
@@ -344,13 +591,6 @@ func runClaim(_ *cobra.Command, _ []string) error {
 // runClaimStep prompts for a claim code and submits it.
 // Returns true if the agent was successfully claimed (or was already claimed).
 func runClaimStep(scanner *bufio.Scanner, client *api.Client) bool {
-	errMsgs := map[string]string{
-		"INVALID_OR_EXPIRED_CODE": "Code invalid or expired — generate a new one at https://work.clawplaza.ai/my-agent",
-		"INVALID_CODE":            "Code format invalid. Expected: clawplaza-xxxx",
-		"AGENT_NOT_FOUND":         "Agent not found. Check your API key.",
-		"USER_ALREADY_CLAIMED":    "That account already has a linked agent.",
-	}
-
 	for {
 		fmt.Print("Claim code: ")
 		scanner.Scan()
@@ -362,30 +602,28 @@ func runClaimStep(scanner *bufio.Scanner, client *api.Client) bool {
 
 		fmt.Print("Claiming... ")
 		resp, err := client.Claim(context.Background(), code)
-		if err != nil {
-			fmt.Printf("error: %s\n", err)
-			fmt.Println("Try again or press Enter to skip.")
-			continue
-		}
-
-		if resp.Error == "AGENT_ALREADY_CLAIMED" {
+		if api.HasCode(err, "AGENT_ALREADY_CLAIMED") {
 			// Already claimed is treated as success — idempotent.
 			fmt.Println("already claimed.")
 			return true
 		}
-
-		if resp.Error != "" {
-			msg := errMsgs[resp.Error]
+		if apiErr, ok := api.AsAPIError(err); ok {
+			msg := api.ClaimErrors[apiErr.Code]
 			if msg == "" {
-				msg = resp.Message
+				msg = apiErr.Message
 			}
 			if msg == "" {
-				msg = resp.Error
+				msg = apiErr.Code
 			}
 			fmt.Printf("failed: %s\n", msg)
 			fmt.Println("Try again or press Enter to skip.")
 			continue
 		}
+		if err != nil {
+			fmt.Printf("error: %s\n", err)
+			fmt.Println("Try again or press Enter to skip.")
+			continue
+		}
 
 		fmt.Println("done!")
 		if resp.DisplayName != "" {
@@ -418,29 +656,12 @@ func collectLLMConfig(scanner *bufio.Scanner, cfg *config.Config) error {
 	var keyURL string
 
 	switch providerChoice {
-	case "1": // Kimi
-		cfg.LLM.Provider = "openai"
-		cfg.LLM.BaseURL = "https://api.moonshot.cn/v1"
-		cfg.LLM.Model = "kimi-k2.5"
-		keyURL = "https://platform.moonshot.cn/console/api-keys"
-	case "2": // DeepSeek
-		cfg.LLM.Provider = "openai"
-		cfg.LLM.BaseURL = "https://api.deepseek.com/v1"
-		cfg.LLM.Model = "deepseek-reasoner"
-		keyURL = "https://platform.deepseek.com/api_keys"
-	case "3": // OpenAI
-		cfg.LLM.Provider = "openai"
-		cfg.LLM.BaseURL = "https://api.openai.com/v1"
-		cfg.LLM.Model = "gpt-4o-mini"
-		keyURL = "https://platform.openai.com/api-keys"
-	case "4": // Anthropic
-		cfg.LLM.Provider = "anthropic"
-		cfg.LLM.Model = "claude-haiku-4-5-20251001"
-		keyURL = "https://console.anthropic.com/settings/keys"
+	case "1", "2", "3", "4": // Kimi, DeepSeek, OpenAI, Anthropic
+		p := config.LLMPresets[providerChoice[0]-'1']
+		config.ApplyLLMPreset(&cfg.LLM, p.Name)
+		keyURL = p.KeyURL
 	case "5": // Ollama
-		cfg.LLM.Provider = "ollama"
-		cfg.LLM.BaseURL = "http://localhost:11434"
-		cfg.LLM.Model = "llama3.2"
+		config.ApplyLLMPreset(&cfg.LLM, "ollama")
 		fmt.Printf("Ollama model (default: %s): ", cfg.LLM.Model)
 		scanner.Scan()
 		if m := strings.TrimSpace(scanner.Text()); m != "" {
@@ -501,21 +722,79 @@ func inscCmd() *cobra.Command {
 		RunE:  runInsc,
 	}
 	cmd.Flags().IntP("token-id", "t", 0, "Override target token ID")
+	cmd.Flags().IntSlice("tokens", nil, "Interleave several token IDs, e.g. --tokens 42,77 (if the platform allows)")
+	cmd.Flags().Bool("auto-token", false, "Move to another token when the current one is taken or crowded instead of stopping")
+	cmd.Flags().String("token-strategy", "", "Where --auto-token moves: least-crowded, sequential or random (default: agent.auto_token.strategy)")
 	cmd.Flags().BoolP("verbose", "v", false, "Verbose output")
 	cmd.Flags().Bool("no-web", false, "Disable web console")
 	cmd.Flags().IntP("port", "p", 0, "Web console port (default: auto from 2526)")
+	cmd.Flags().String("bind", "", "Web console address, e.g. 0.0.0.0 or a Tailscale IP for HTTPS remote access (default: web.listen_addr, localhost)")
+	cmd.Flags().Bool("takeover", false, "If another session is active, end it (if stale) or wait for it to expire")
+	cmd.Flags().Bool("resume-after-review", false, "Clear an automatic pause after repeated challenge failures")
+	cmd.Flags().Bool("record", false, "Record every inscribe request/response to ~/.clawwork/recordings/ (see debug replay)")
+	cmd.Flags().Bool("service", false, "Run as the background service: log to the service log (used by the Windows task)")
+	_ = cmd.Flags().MarkHidden("service")
 	return cmd
 }
 
 func runInsc(cmd *cobra.Command, _ []string) error {
+	err := runMiner(cmd)
+	if errors.Is(err, miner.ErrUpdated) {
+		// Everything runMiner opened is closed by now; the store is the
+		// one thing left to PersistentPostRun.
+		_ = miner.Store().Close()
+		return daemon.Reexec()
+	}
+	return err
+}
+
+// runMiner runs the miner until it stops, with the console, MQTT,
+// notifications and the service's background jobs around it.
+func runMiner(cmd *cobra.Command) error {
+	if cmd != nil {
+		if svc, _ := cmd.Flags().GetBool("service"); svc {
+			if err := daemon.EnterService(); err != nil {
+				return err
+			}
+		}
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
+	if cmd != nil {
+		if bind, _ := cmd.Flags().GetString("bind"); bind != "" {
+			cfg.Web.ListenAddr = bind
+		}
+	}
 	if err := cfg.Validate(); err != nil {
 		return err
 	}
 
+	// Release a review hold. A miner already waiting on it picks this up
+	// within seconds, so there is nothing more to start.
+	if cmd != nil {
+		if resume, _ := cmd.Flags().GetBool("resume-after-review"); resume {
+			if miner.LoadReviewHold() == nil {
+				fmt.Println("Mining is not paused for review.")
+			} else {
+				if err := miner.ClearReviewHold(); err != nil {
+					return fmt.Errorf("clear review pause: %w", err)
+				}
+				if info := miner.Running(); info != nil {
+					fmt.Printf("Review pause cleared. The running miner (PID %d) resumes within a few seconds.\n", info.PID)
+					return nil
+				}
+				fmt.Println("Review pause cleared.")
+			}
+		}
+	}
+
+	// Fail before starting the console if another miner holds the lock.
+	if info := miner.Running(); info != nil {
+		return info.HeldError()
+	}
+
 	// Setup logger
 	logLevel := cfg.Logging.Level
 	if cmd != nil {
@@ -523,18 +802,64 @@ func runInsc(cmd *cobra.Command, _ []string) error {
 			logLevel = "debug"
 		}
 	}
-	miner.SetupLogger(logLevel)
+	var logFile *miner.LogFile
+	if cfg.Logging.File != "" {
+		if logFile, err = miner.OpenLogFile(cfg.Logging.File); err != nil {
+			return err
+		}
+		defer logFile.Close()
+		logFile.SetRotation(int64(cfg.Logging.MaxSizeMB)<<20, cfg.Logging.MaxBackups)
+		miner.SetupLoggerTo(logLevel, cfg.Logging.Format, logFile)
+	} else {
+		miner.SetupLogger(logLevel, cfg.Logging.Format)
+	}
+	if cfg.Agent.Label != "" {
+		slog.SetDefault(slog.Default().With("label", cfg.Agent.Label))
+	}
+
+	// Crash capture: panics and runtime fatal errors go to ~/.clawwork/crashes/.
+	crashes := crash.New(cfg.Crash, version, cfg.Agent.APIKey, cfg.LLM.APIKey, cfg.MQTT.Password)
+	crashes.CollectPrevious()
+	defer crashes.CaptureFatal()()
+	defer crashes.Recover()
 
-	// Token ID override
+	// Token ID override. --token-id picks a single token; --tokens sets the
+	// whole multi-token rotation.
 	tokenID := cfg.Agent.TokenID
+	tokens := cfg.Agent.Rotation()
 	if cmd != nil {
 		if tid, _ := cmd.Flags().GetInt("token-id"); tid > 0 {
 			if tid < 25 || tid > 1024 {
 				return fmt.Errorf("token-id must be between 25 and 1024")
 			}
-			tokenID = tid
+			tokenID, tokens = tid, nil
+		}
+		if list, _ := cmd.Flags().GetIntSlice("tokens"); len(list) > 0 {
+			for _, tid := range list {
+				if tid < 25 || tid > 1024 {
+					return fmt.Errorf("--tokens: %d is not between 25 and 1024", tid)
+				}
+			}
+			tokenID, tokens = list[0], config.AgentConfig{TokenID: list[0], ExtraTokens: list[1:]}.Rotation()
+		}
+	}
+
+	// Auto-token: leave a taken or crowded token for another one.
+	autoToken := cfg.Agent.AutoToken
+	if cmd != nil {
+		if on, _ := cmd.Flags().GetBool("auto-token"); on {
+			autoToken.Enabled = true
+		}
+		if s, _ := cmd.Flags().GetString("token-strategy"); s != "" {
+			autoToken.Strategy = s
 		}
 	}
+	if err := autoToken.Validate(); err != nil {
+		return err
+	}
+	if autoToken.Enabled && len(tokens) > 1 {
+		return fmt.Errorf("auto-token works with a single token — drop --tokens or agent.extra_tokens")
+	}
 
 	// Load platform knowledge
 	kn, err := knowledge.Load(cfg.Agent.APIKey)
@@ -542,16 +867,52 @@ func runInsc(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// Fit the knowledge into the model's context window, leaving room for
+	// the challenge prompt and the answer.
+	window := llm.ContextWindow(&cfg.LLM)
+	sysPrompt, dropped := kn.SystemPromptWithin(window - miner.AnswerMaxTokens - miner.PromptReserve)
+	if w := llm.BudgetWarning(&cfg.LLM, miner.AnswerMaxTokens, miner.PromptReserve); w != "" {
+		fmt.Printf("Warning: %s\n", w)
+	}
+	if len(dropped) > 0 {
+		fmt.Printf("Warning: model context too small for the full knowledge prompt (%d-token window) — left out: %s\n", window, strings.Join(dropped, ", "))
+	}
+
 	// Create LLM provider with enhanced system prompt.
-	// 2048 tokens: thinking models (Kimi K2.5, DeepSeek-R1) need room for
-	// internal reasoning + the actual short answer in the content field.
-	llmProvider, err := llm.NewProvider(&cfg.LLM, kn.SystemPrompt(), 2048)
+	llmProvider, err := llm.NewProvider(&cfg.LLM, sysPrompt, miner.AnswerMaxTokens)
 	if err != nil {
 		return err
 	}
+	// Trip after sustained failures so a dead key shows one clear event.
+	breaker := llm.NewBreaker(llmProvider)
+	llmProvider = breaker
 
 	// Create API client
 	apiClient := api.New(cfg.Agent.APIKey)
+	record := false
+	if cmd != nil {
+		record, _ = cmd.Flags().GetBool("record")
+	}
+	if record {
+		rec, err := api.NewRecorder(filepath.Join(config.Dir(), "recordings"))
+		if err != nil {
+			return err
+		}
+		defer rec.Close()
+		apiClient.SetRecorder(rec)
+		fmt.Printf("Recording inscribe exchanges to %s\n", rec.Path())
+	}
+
+	strategy, err := miner.NewStrategy(cfg.Miner)
+	if err != nil {
+		return err
+	}
+	var experiment *miner.Experiment
+	if cfg.Experiment.Enabled() {
+		if experiment, err = newExperiment(cfg, kn); err != nil {
+			return err
+		}
+	}
 
 	// Load state
 	state := miner.LoadState()
@@ -562,11 +923,64 @@ func runInsc(cmd *cobra.Command, _ []string) error {
 		LLM:       llmProvider,
 		State:     state,
 		TokenID:   tokenID,
+		Tokens:    tokens,
 		Knowledge: kn,
+
+		Alerts:         cfg.Alerts,
+		AnswerLanguage: cfg.Miner.AnswerLanguage,
+		AgentName:      cfg.Agent.Name,
+		ContextHeader:  cfg.Miner.ContextHeader,
+		ShutdownGrace:  time.Duration(cfg.Miner.ShutdownGraceSeconds) * time.Second,
+		AnswerTimeout:  cfg.LLM.AnswerTimeout(),
+		Coordinate:     cfg.Miner.Coordinate,
+		History:        miner.NewHistory(miner.Store()),
+
+		Strategy:             strategy,
+		Experiment:           experiment,
+		Candidates:           cfg.Miner.Candidates,
+		CandidatesWhen:       cfg.Miner.CandidatesWhen,
+		CandidateTemperature: cfg.Miner.CandidateTemperature,
+	}
+	if cmd != nil {
+		m.Takeover, _ = cmd.Flags().GetBool("takeover")
+	}
+	m.SetPacing(pacingOf(cfg.Schedule))
+	if autoToken.Enabled {
+		m.AutoToken = miner.NewTokenPicker(autoToken, apiClient)
+	}
+
+	m.Mode = miner.ModeForeground
+	if daemon.UnderService() {
+		m.Mode = miner.ModeService
 	}
 	m.SetVersion(version)
 
+	// The platform's view of the agent, for the console header and the
+	// startup summary.
+	statusCtx, statusCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	platform, statusErr := apiClient.Status(statusCtx)
+	statusCancel()
+	summary := &startupSummary{
+		cfg:         cfg,
+		tokenID:     tokenID,
+		tokens:      tokens,
+		llm:         llmProvider.Name(),
+		kn:          kn,
+		platform:    platform,
+		platformErr: statusErr,
+		service:     m.Mode == miner.ModeService,
+		newAgent:    state.TotalInscriptions == 0,
+	}
+	if autoToken.Enabled {
+		summary.autoToken = cmp.Or(autoToken.Strategy, config.TokenLeastCrowded)
+	}
+	if sc := cfg.Schedule; sc.CooldownSeconds > 0 || len(sc.ActiveHours) > 0 {
+		summary.schedule = describeSchedule(sc)
+	}
+
 	// Start web console (unless --no-web)
+	var srv *web.Server
+	var ctrl *web.MinerControl
 	noWeb := false
 	webPort := 0
 	webPortPinned := false
@@ -578,134 +992,1763 @@ func runInsc(cmd *cobra.Command, _ []string) error {
 		}
 	}
 	if !noWeb {
-		chatPrompt := web.ChatSystemPrompt(kn.Soul)
-		chatProvider, chatErr := llm.NewProvider(&cfg.LLM, chatPrompt, 1024)
-		if chatErr != nil {
-			fmt.Printf("Warning: chat provider failed: %s (web console chat disabled)\n", chatErr)
+		// The chat shares the miner's provider (it sets its own prompt per
+		// request) but not its breaker: chat failures say nothing about
+		// mining, and a tripped breaker shouldn't silence the chat.
+		chatProvider := breaker.Provider
+		for _, w := range llm.ChatWarnings(chatProvider, &cfg.LLM) {
+			fmt.Printf("Warning: %s\n", w)
+		}
+		// Fetch agent info from platform for the console header.
+		agentInfo := web.AgentInfo{Name: cfg.Agent.Name, Soul: kn.Soul}
+		if platform != nil {
+			if platform.Agent.Name != "" {
+				agentInfo.Name = platform.Agent.Name
+			}
+			agentInfo.AvatarURL = platform.Agent.AvatarURL
+		}
+		webSrv, hub, webCtrl := web.New(cfg, chatProvider, state, tokenID, agentInfo, apiClient, webPort)
+		actualPort, startErr := webSrv.Start(webPortPinned)
+		if startErr != nil {
+			fmt.Printf("Warning: web console unavailable: %s\n", startErr)
 		} else {
-			// Fetch agent info from platform for the console header.
-			agentInfo := web.AgentInfo{Name: cfg.Agent.Name, Soul: kn.Soul}
-			if status, err := apiClient.Status(context.Background()); err == nil {
-				if status.Agent.Name != "" {
-					agentInfo.Name = status.Agent.Name
-				}
-				agentInfo.AvatarURL = status.Agent.AvatarURL
+			m.OnEvent = func(eventType, message string, data any) {
+				hub.Publish(web.Event{Type: eventType, Message: message, Data: data})
 			}
-			srv, hub, ctrl := web.New(chatProvider, state, tokenID, agentInfo, apiClient, webPort)
-			actualPort, startErr := srv.Start(webPortPinned)
-			if startErr != nil {
-				fmt.Printf("Warning: web console unavailable: %s\n", startErr)
-			} else {
-				m.OnEvent = func(eventType, message string, data any) {
-					hub.Publish(web.Event{Type: eventType, Message: message, Data: data})
+			srv, ctrl = webSrv, webCtrl
+			m.Ctrl = ctrl
+			m.ConsolePort, m.ConsoleHost = actualPort, srv.Host()
+			defer func() {
+				shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 3*time.Second)
+				defer shutdownCancel()
+				_ = srv.Shutdown(shutdownCtx)
+			}()
+			summary.console = srv.URL()
+			summary.remoteConsole, summary.fingerprint = srv.ExposedURL()
+			if addr := cfg.Web.RemoteListen; addr != "" {
+				if fingerprint, err := srv.StartRemote(addr, cfg.Web.RemoteToken); err != nil {
+					fmt.Printf("Warning: %s\n", err)
+				} else {
+					fmt.Printf("Remote control: https://%s (token required)\n", addr)
+					if fingerprint != "" {
+						fmt.Printf("  self-signed certificate, SHA-256 %s\n", fingerprint)
+					}
 				}
-				m.Ctrl = ctrl
-				defer func() {
-					shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 3*time.Second)
-					defer shutdownCancel()
-					_ = srv.Shutdown(shutdownCtx)
-				}()
-				fmt.Printf("Console: http://127.0.0.1:%d\n", actualPort)
 			}
 		}
 	}
 
+	// Soul and knowledge edits are picked up between cycles: swap the
+	// system prompts in place, so providers and breakers keep their state.
+	m.KnowledgeWatch = knowledge.NewWatcher(cfg.Agent.APIKey)
+	m.Reprompt = func(kn *knowledge.Knowledge) {
+		sysPrompt, _ := kn.SystemPromptWithin(window - miner.AnswerMaxTokens - miner.PromptReserve)
+		breaker.SetSystemPrompt(sysPrompt)
+		if experiment != nil {
+			repromptExperiment(cfg, kn, experiment)
+		}
+		if srv != nil {
+			srv.SetSoul(kn.Soul)
+		}
+	}
+
+	// Publish events to MQTT (alongside the web console, if any).
+	if cfg.MQTT.Broker != "" {
+		if ctrl == nil {
+			// No console: MQTT commands still need something to pause.
+			ctrl = web.NewMinerControl(tokenID)
+			m.Ctrl = ctrl
+		}
+		pub := mqtt.NewPublisher(cfg.MQTT,
+			mqtt.Device{AgentName: cfg.Agent.Name, Version: version},
+			mqtt.MinerState{TokenID: tokenID, CWEarned: state.TotalCWEarned, TrustScore: state.LastTrustScore},
+			ctrl)
+		defer pub.Close()
+		prev := m.OnEvent
+		m.OnEvent = func(eventType, message string, data any) {
+			if prev != nil {
+				prev(eventType, message, data)
+			}
+			pub.Event(eventType, message, data)
+		}
+		fmt.Printf("MQTT: %s (%s/#)\n", cfg.MQTT.Broker, pub.Base())
+	}
+
+	// Route events to notification channels. SIGHUP swaps in a dispatcher
+	// built from the reloaded [notify].
+	d, err := notify.New(cfg.Notify, cfg.Agent.Name)
+	if err != nil {
+		return err
+	}
+	notifier := new(atomic.Pointer[notify.Dispatcher])
+	notifier.Store(d)
+	defer func() { notifier.Load().Close() }()
+	prevEvent := m.OnEvent
+	m.OnEvent = func(eventType, message string, data any) {
+		if prevEvent != nil {
+			prevEvent(eventType, message, data)
+		}
+		notifier.Load().Event(eventType, message, data)
+	}
+	if d != nil {
+		fmt.Printf("Notifications: %s\n", strings.Join(d.Channels(), ", "))
+	}
+
+	// An error that keeps repeating gets diagnosed by the LLM; the result
+	// goes out like any other event.
+	checker := tools.NewSystemCheckTool(api.BaseURL, config.Dir())
+	if diag := diagnose.New(cfg.Alerts, breaker.Provider, checker, func(eventType, message string, data any) {
+		fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), message)
+		if m.OnEvent != nil {
+			m.OnEvent(eventType, message, data)
+		}
+	}); diag != nil {
+		defer diag.Close()
+		prev := m.OnEvent
+		m.OnEvent = func(eventType, message string, data any) {
+			if prev != nil {
+				prev(eventType, message, data)
+			}
+			diag.Event(eventType, message)
+		}
+	}
+
+	// Platform callbacks become miner events like any other.
+	if cfg.Callback.Listen != "" {
+		rc := &callback.Receiver{Secret: cfg.Callback.Secret, OnEvent: func(e callback.Event) {
+			fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), e.Message())
+			if m.OnEvent != nil {
+				m.OnEvent("platform", e.Message(), e)
+			}
+		}}
+		if err := rc.Start(cfg.Callback.Listen, cfg.Callback.Path); err != nil {
+			fmt.Printf("Warning: %s\n", err)
+		} else {
+			defer rc.Close()
+			fmt.Printf("Callbacks: %s%s\n", cfg.Callback.Listen, callbackPath(cfg))
+		}
+	}
+
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Scheduled backups run with the background service only; foreground
+	// sessions come and go too irregularly to keep a schedule.
+	if cfg.Backup.Enabled && m.Mode == miner.ModeService {
+		mgr := backup.New(cfg.Backup)
+		mgr.Database = storeSnapshot(cfg)
+		go mgr.Run(ctx)
+		fmt.Printf("Backups: every %dh to %s\n", cfg.Backup.IntervalHours, mgr.Dir)
+	}
+	if cfg.Sync.IntervalMinutes > 0 && m.Mode == miner.ModeService {
+		if syncer, err := datasync.New(cfg.Sync); err != nil {
+			fmt.Printf("Warning: sync disabled: %s\n", err)
+		} else if syncer != nil {
+			syncer.Database = storeSnapshot(cfg)
+			go syncer.Run(ctx, time.Duration(cfg.Sync.IntervalMinutes)*time.Minute)
+			fmt.Printf("Sync: every %dm to %s\n", cfg.Sync.IntervalMinutes, syncer.Store.Name())
+		}
+	}
+	if path := cfg.Metrics.Textfile; path != "" {
+		src := metrics.Source{State: state, Agent: cfg.Agent.Name, Version: version, HTTP: httpx.Snapshot}
+		if ctrl != nil {
+			src.TokenID, src.Paused = ctrl.TokenID, ctrl.IsPaused
+		}
+		go metrics.Run(ctx, path, time.Duration(cfg.Metrics.IntervalSeconds)*time.Second, src)
+		// The last counters outlive the miner.
+		defer func() {
+			if err := metrics.WriteFile(path, src); err != nil {
+				slog.Warn("metrics textfile write failed", "path", path, "error", err)
+			}
+		}()
+		fmt.Printf("Metrics: %s every %ds\n", path, cfg.Metrics.IntervalSeconds)
+	}
+	if cfg.Callback.PollMinutes > 0 && m.Mode == miner.ModeService {
+		go m.WatchStatus(ctx, time.Duration(cfg.Callback.PollMinutes)*time.Minute)
+		fmt.Printf("Status watch: every %dm\n", cfg.Callback.PollMinutes)
+	}
+	// Self-update is for the service only: a foreground miner has someone
+	// watching it, and restarting would take over their terminal.
+	if cfg.Updater.Auto && m.Mode == miner.ModeService {
+		if err := updater.Verifiable(); err != nil {
+			fmt.Printf("Warning: auto-update off: %s\n", err)
+		} else {
+			auto := &updater.Auto{Current: version, Channel: cfg.Updater.Channel,
+				Interval: time.Duration(cfg.Updater.CheckHours) * time.Hour}
+			m.SelfUpdate = auto.Poll
+			fmt.Printf("Auto-update: every %dh (%s)\n", cfg.Updater.CheckHours, cmp.Or(cfg.Updater.Channel, updater.ChannelStable))
+		}
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigCh
 		fmt.Println("\nShutting down gracefully... waiting for current operation to finish.")
 		cancel()
+		// A second signal skips the grace period but still lets Run end
+		// the session and release the lock, for up to forceExitTimeout;
+		// a third exits at once.
+		<-sigCh
+		fmt.Println("Abandoning the current operation... (press Ctrl+C again to exit now)")
+		m.Abort()
+		select {
+		case <-sigCh:
+		case <-time.After(forceExitTimeout):
+		}
+		fmt.Println("Forced exit.")
+		os.Exit(1)
 	}()
 
-	fmt.Printf("ClawWork %s — inscribing token #%d\n", version, tokenID)
-	fmt.Printf("LLM: %s\n", llmProvider.Name())
-	if kn.HasSoul() {
-		fmt.Printf("Soul: active\n")
-	}
-	fmt.Println()
+	// SIGHUP: flush state, reopen the log file, reload config (daemon convention).
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+	go func() {
+		defer crashes.Recover()
+		for range hupCh {
+			handleHangup(cmd, cfg, m, logFile, srv, notifier)
+		}
+	}()
+
+	m.Banner = summary.print
 
 	return m.Run(ctx)
 }
 
-// ── status command ──
-
-func statusCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "status",
-		Short: "Check agent status",
-		RunE:  runStatus,
-	}
+// forceExitTimeout bounds the cleanup after a second interrupt.
+const forceExitTimeout = 10 * time.Second
+
+// myAgentURL is where owners claim agents and bind wallets.
+const myAgentURL = "https://work.clawplaza.ai/my-agent"
+
+// startupSummary is what insc prints once its session is set up: what is
+// mining with which model, where to watch it, what still needs fixing and,
+// for a new agent, what to do next.
+type startupSummary struct {
+	cfg       *config.Config
+	tokenID   int
+	tokens    []int
+	autoToken string // strategy, "" when off
+	schedule  string // describeSchedule, "" when unset
+	llm       string
+	kn        *knowledge.Knowledge
+
+	console, remoteConsole, fingerprint string
+
+	platform    *api.StatusResponse
+	platformErr error
+	service     bool
+	newAgent    bool // no inscriptions yet
 }
 
-func runStatus(_ *cobra.Command, _ []string) error {
-	// Show service status if platform supports it.
-	if mgr, err := daemon.New(); err == nil {
-		st, _ := mgr.Status()
-		if st != nil {
-			switch {
-			case !st.Installed:
-				fmt.Println("Service:      not installed")
-			case st.Running:
-				fmt.Printf("Service:      running (PID %d)\n", st.PID)
-			default:
-				fmt.Println("Service:      stopped")
-			}
-			fmt.Printf("Log file:     %s\n", st.LogPath)
-			fmt.Println()
+func (s *startupSummary) print(sessionID string, verified bool) {
+	fmt.Printf("ClawWork %s — inscribing token #%d\n", version, s.tokenID)
+	row := func(label, format string, a ...any) {
+		if label != "" {
+			label += ":"
 		}
+		fmt.Printf("  %-11s %s\n", label, fmt.Sprintf(format, a...))
 	}
 
-	cfg, err := config.Load()
-	if err != nil {
-		return err
+	agent := s.cfg.Agent.Name
+	if p := s.platform; p != nil {
+		agent = cmp.Or(p.Agent.Name, agent)
+		if p.Agent.ID != "" && p.Agent.ID != agent {
+			agent += " (" + p.Agent.ID + ")"
+		}
 	}
-
-	client := api.New(cfg.Agent.APIKey)
-	resp, err := client.Status(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to fetch status: %w", err)
+	row("Agent", "%s", agent)
+	if s.cfg.Agent.Label != "" {
+		row("Label", "%s", s.cfg.Agent.Label)
 	}
-
-	fmt.Printf("Agent:        %s (%s)\n", resp.Agent.Name, resp.Agent.ID)
-	fmt.Printf("Wallet:       %s\n", resp.Agent.WalletAddress)
-	fmt.Printf("Inscriptions: %d total, %d confirmed\n", resp.Inscriptions.Total, resp.Inscriptions.Confirmed)
-	fmt.Printf("CW Earned:    %d\n", resp.Inscriptions.TotalCW)
-	fmt.Printf("NFT Hit:      %v\n", resp.Inscriptions.Hit)
-	fmt.Printf("Platform:     %s (%d NFTs remaining)\n", resp.Activity.Status, resp.Activity.NFTsRemaining)
-	if resp.GenesisNFT != nil {
-		fmt.Printf("Genesis NFT:  #%d\n", resp.GenesisNFT.TokenID)
+	switch {
+	case len(s.tokens) > 1:
+		ids := make([]string, len(s.tokens))
+		for i, id := range s.tokens {
+			ids[i] = "#" + strconv.Itoa(id)
+		}
+		row("Tokens", "%s (multi-token)", strings.Join(ids, ", "))
+	case s.autoToken != "":
+		row("Token", "#%d (auto-token: %s)", s.tokenID, s.autoToken)
+	default:
+		row("Token", "#%d", s.tokenID)
 	}
-
-	// Also show local state
-	state := miner.LoadState()
-	if state.TotalInscriptions > 0 {
-		fmt.Printf("\n--- Local Stats ---\n")
-		fmt.Printf("Session inscriptions: %d\n", state.TotalInscriptions)
-		fmt.Printf("Session CW earned:    %d\n", state.TotalCWEarned)
-		fmt.Printf("Session NFT hits:     %d\n", state.TotalHits)
+	if s.schedule != "" {
+		row("Schedule", "%s", s.schedule)
 	}
-
-	return nil
-}
-
-// ── config command ──
-
-func configCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "config",
-		Short: "Manage configuration",
+	row("LLM", "%s", s.llm)
+	if s.kn.HasSoul() {
+		row("Soul", "active")
+	} else {
+		row("Soul", "none")
 	}
-	cmd.AddCommand(
+	if len(s.kn.Overrides) > 0 {
+		row("Knowledge", "%s from %s", strings.Join(s.kn.Overrides, ", "), knowledge.DocsDir())
+	}
+	switch {
+	case sessionID == "":
+		row("Session", "none — mining without one")
+	case verified:
+		row("Session", "%s (verified client)", shortSession(sessionID))
+	default:
+		row("Session", "%s (client not verified)", shortSession(sessionID))
+	}
+	if s.console != "" {
+		row("Console", "%s", s.console)
+	}
+	if s.remoteConsole != "" {
+		row("Remote", "%s (password: web.console_token)", s.remoteConsole)
+		if s.fingerprint != "" {
+			row("", "self-signed certificate, SHA-256 %s", s.fingerprint)
+		}
+	}
+
+	if issues := s.issues(); len(issues) > 0 {
+		fmt.Println("Needs attention:")
+		for _, i := range issues {
+			fmt.Printf("  ! %s\n", i)
+		}
+	}
+	if s.newAgent {
+		fmt.Println("Next steps:")
+		for i, step := range s.nextSteps() {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+	}
+	fmt.Println()
+}
+
+// issues lists what will stop mining or its rewards, from the platform
+// status, each with the fix.
+func (s *startupSummary) issues() []string {
+	if err := s.platformErr; err != nil {
+		apiErr, _ := api.AsAPIError(err)
+		switch {
+		case apiErr != nil && apiErr.Code == "NOT_CLAIMED":
+			return []string{fmt.Sprintf("Agent not claimed — generate a claim code at %s, then run: clawwork claim", myAgentURL)}
+		case apiErr != nil && apiErr.Code == "INVALID_API_KEY":
+			return []string{"API key rejected — update it with: clawwork config apikey"}
+		default:
+			return []string{fmt.Sprintf("Platform status unavailable (%s) — checks skipped", err)}
+		}
+	}
+	var issues []string
+	if addr := s.platform.Agent.WalletAddress; addr == "" {
+		issues = append(issues, fmt.Sprintf("No wallet bound — an NFT hit needs one; bind it at %s", myAgentURL))
+	} else {
+		for _, w := range wallet.Validate(addr).Warnings {
+			issues = append(issues, "Wallet: "+w)
+		}
+	}
+	return issues
+}
+
+// nextSteps is the short guide shown until the agent's first inscription.
+func (s *startupSummary) nextSteps() []string {
+	var steps []string
+	if s.console != "" {
+		steps = append(steps, "Open the console above to watch the first inscription")
+	} else {
+		steps = append(steps, "Check progress from another terminal: clawwork status")
+	}
+	if !s.kn.HasSoul() {
+		steps = append(steps, "Give your agent a personality: clawwork soul generate")
+	}
+	if !s.service {
+		steps = append(steps, "Keep mining after you log out: clawwork install")
+	}
+	return steps
+}
+
+// newExperiment builds the two arms of the configured A/B experiment, each
+// with its own provider (system prompt fitted to its context window) and
+// strategy.
+func newExperiment(cfg *config.Config, kn *knowledge.Knowledge) (*miner.Experiment, error) {
+	exp := &miner.Experiment{Name: cfg.Experiment.Name}
+	for i, arm := range []config.ExperimentArm{cfg.Experiment.A, cfg.Experiment.B} {
+		name := string(rune('a' + i))
+		provider, err := armProvider(cfg, arm, kn)
+		if err != nil {
+			return nil, fmt.Errorf("experiment arm %s: %w", name, err)
+		}
+		strategy, err := miner.NewStrategy(arm.Miner(cfg.Miner))
+		if err != nil {
+			return nil, fmt.Errorf("experiment arm %s: %w", name, err)
+		}
+		exp.Arms[i] = miner.Arm{
+			Name:           name,
+			Label:          arm.Describe(cfg.LLM, cfg.Miner),
+			LLM:            llm.NewBreaker(provider),
+			Strategy:       strategy,
+			PromptTemplate: arm.PromptTemplate,
+		}
+	}
+	return exp, nil
+}
+
+// armProvider creates an experiment arm's provider, with the system prompt
+// fitted to its context window.
+func armProvider(cfg *config.Config, arm config.ExperimentArm, kn *knowledge.Knowledge) (llm.Provider, error) {
+	llmCfg := arm.LLM(cfg.LLM)
+	return llm.NewProvider(&llmCfg, armPrompt(&llmCfg, kn), miner.AnswerMaxTokens)
+}
+
+// armPrompt is the system prompt fitted to an arm's context window.
+func armPrompt(llmCfg *config.LLMConfig, kn *knowledge.Knowledge) string {
+	sysPrompt, _ := kn.SystemPromptWithin(llm.ContextWindow(llmCfg) - miner.AnswerMaxTokens - miner.PromptReserve)
+	return sysPrompt
+}
+
+// repromptExperiment rebuilds both arms' system prompts from updated
+// knowledge.
+func repromptExperiment(cfg *config.Config, kn *knowledge.Knowledge, exp *miner.Experiment) {
+	for i, arm := range []config.ExperimentArm{cfg.Experiment.A, cfg.Experiment.B} {
+		llmCfg := arm.LLM(cfg.LLM)
+		if ps, ok := exp.Arms[i].LLM.(llm.PromptSetter); ok {
+			ps.SetSystemPrompt(armPrompt(&llmCfg, kn))
+		}
+	}
+}
+
+// handleHangup performs the SIGHUP duties for a running insc process.
+// Only settings read at runtime are reloaded; others need a restart.
+func handleHangup(cmd *cobra.Command, cfg *config.Config, m *miner.Miner, logFile *miner.LogFile, srv *web.Server, notifier *atomic.Pointer[notify.Dispatcher]) {
+	slog.Info("SIGHUP received: flushing state and reloading")
+	if err := m.State.Save(); err != nil {
+		slog.Warn("state flush failed", "error", err)
+	}
+	if logFile != nil {
+		if err := logFile.Reopen(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: log reopen failed: %s\n", err)
+		}
+	}
+
+	newCfg, err := config.Load()
+	if err == nil {
+		err = newCfg.Validate()
+	}
+	if err != nil {
+		slog.Warn("config reload failed, keeping current settings", "error", err)
+		return
+	}
+	verbose := false
+	if cmd != nil {
+		verbose, _ = cmd.Flags().GetBool("verbose")
+	}
+	if !verbose {
+		miner.SetLogLevel(newCfg.Logging.Level)
+	}
+	if logFile != nil {
+		logFile.SetRotation(int64(newCfg.Logging.MaxSizeMB)<<20, newCfg.Logging.MaxBackups)
+	}
+	if srv != nil {
+		srv.SetConfig(newCfg)
+	}
+	m.SetPacing(pacingOf(newCfg.Schedule))
+	tools.SetTrash(trashOf(newCfg.Web))
+	if d, err := notify.New(newCfg.Notify, newCfg.Agent.Name); err != nil {
+		slog.Warn("notify reload failed, keeping current channels", "error", err)
+	} else {
+		// The old dispatcher finishes sending what it has queued.
+		go notifier.Swap(d).Close()
+		slog.Info("notification channels reloaded", "channels", d.Channels())
+	}
+	if newCfg.MQTT != cfg.MQTT || newCfg.LLM.Provider != cfg.LLM.Provider || newCfg.LLM.Model != cfg.LLM.Model ||
+		newCfg.Logging.File != cfg.Logging.File || newCfg.Logging.Format != cfg.Logging.Format || newCfg.Storage != cfg.Storage {
+		slog.Warn("some changed settings (llm, mqtt, logging file or format, storage) take effect after restart")
+	}
+	slog.Info("config reloaded")
+}
+
+// ── status command ──
+
+func statusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "status",
+		Short:        "Check agent status",
+		SilenceUsage: true,
+		RunE:         runStatus,
+	}
+}
+
+// statusReport is the machine-readable output of `clawwork status --json`.
+type statusReport struct {
+	Miner         *miner.LockInfo     `json:"miner"` // null when not running
+	ConsoleURL    string              `json:"console_url,omitempty"`
+	Service       *serviceReport      `json:"service,omitempty"`
+	HostPeers     []miner.Peer        `json:"host_peers,omitempty"`
+	Platform      *api.StatusResponse `json:"platform,omitempty"`
+	PlatformError string              `json:"platform_error,omitempty"`
+	Local         miner.Stats         `json:"local"`
+	Quotas        []quotaReport       `json:"quotas"`
+	Goal          *miner.Projection   `json:"goal,omitempty"`
+	ReviewHold    *miner.ReviewHold   `json:"review_hold,omitempty"`
+}
+
+type serviceReport struct {
+	Installed bool   `json:"installed"`
+	Running   bool   `json:"running"`
+	LogPath   string `json:"log_path"`
+}
+
+type quotaReport struct {
+	miner.Quota
+	Available        bool `json:"available"`
+	RemainingSeconds int  `json:"remaining_seconds"`
+}
+
+// runStatusJSON prints status as JSON. A platform failure is reported in
+// platform_error (and the exit status), with the local fields still filled.
+func runStatusJSON() error {
+	now := time.Now()
+	state := miner.LoadState()
+	r := statusReport{Miner: miner.Running(), Local: state.Stats(now), ReviewHold: miner.LoadReviewHold()}
+	if r.Miner != nil {
+		r.ConsoleURL = r.Miner.ConsoleURL()
+	}
+	if mgr, err := daemon.New(); err == nil {
+		if st, _ := mgr.Status(); st != nil {
+			r.Service = &serviceReport{Installed: st.Installed, Running: st.Running, LogPath: st.LogPath}
+		}
+	}
+	home, _ := filepath.Abs(config.Dir())
+	for _, p := range miner.HostPeers(miner.CoordDir()) {
+		if p.Home != home {
+			r.HostPeers = append(r.HostPeers, p)
+		}
+	}
+	r.Quotas = []quotaReport{}
+	for _, q := range state.Quotas(now) {
+		r.Quotas = append(r.Quotas, quotaReport{Quota: q, Available: q.Available(now), RemainingSeconds: int(q.Remaining(now).Seconds())})
+	}
+	if g := miner.LoadGoal(); g != nil {
+		p := state.Project(g, now)
+		r.Goal = &p
+	}
+
+	cfg, err := config.Load()
+	if err == nil {
+		r.Platform, err = api.New(cfg.Agent.APIKey).Status(context.Background())
+	}
+	if err != nil {
+		r.PlatformError = err.Error()
+	}
+	if perr := printJSON(r); perr != nil {
+		return perr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch status: %w", err)
+	}
+	return nil
+}
+
+func runStatus(cmd *cobra.Command, _ []string) error {
+	if jsonMode(cmd) {
+		return runStatusJSON()
+	}
+	printRuntime()
+	fmt.Println()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	client := api.New(cfg.Agent.APIKey)
+	resp, err := client.Status(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to fetch status: %w", err)
+	}
+
+	fmt.Printf("Agent:        %s (%s)\n", resp.Agent.Name, resp.Agent.ID)
+	printWallet(resp.Agent.WalletAddress)
+	fmt.Printf("Inscriptions: %d total, %d confirmed\n", resp.Inscriptions.Total, resp.Inscriptions.Confirmed)
+	fmt.Printf("CW Earned:    %d\n", resp.Inscriptions.TotalCW)
+	fmt.Printf("NFT Hit:      %v\n", resp.Inscriptions.Hit)
+	fmt.Printf("Platform:     %s (%d NFTs remaining)\n", resp.Activity.Status, resp.Activity.NFTsRemaining)
+	if resp.GenesisNFT != nil {
+		fmt.Printf("Genesis NFT:  #%d\n", resp.GenesisNFT.TokenID)
+	}
+
+	// Also show local state
+	state := miner.LoadState()
+	if state.TotalInscriptions > 0 {
+		st := state.Stats(time.Now())
+		fmt.Printf("\n--- Local Stats ---\n")
+		fmt.Printf("Today:        %s\n", miner.FormatCounters(st.Today))
+		if st.Period != nil {
+			fmt.Printf("Period:       %s (%s)\n", miner.FormatCounters(st.Period.Counters), st.Period.Name)
+		}
+		fmt.Printf("Lifetime:     %s\n", miner.FormatCounters(st.Lifetime))
+	}
+	printQuotas(state.Quotas(time.Now()))
+	if g := miner.LoadGoal(); g != nil {
+		fmt.Println()
+		fmt.Println(state.Project(g, time.Now()))
+	}
+	if h := miner.LoadReviewHold(); h != nil {
+		fmt.Printf("\n%s — resume with: clawwork insc --resume-after-review\n", h)
+	}
+
+	return nil
+}
+
+// printHostPeers lists the other profiles mining on this host, which share
+// its IP (and any IP penalty) with this one.
+func printHostPeers() {
+	home, _ := filepath.Abs(config.Dir())
+	var others []miner.Peer
+	for _, p := range miner.HostPeers(miner.CoordDir()) {
+		if p.Home != home {
+			others = append(others, p)
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+	fmt.Printf("Same host:    %d other profile(s) mining\n", len(others))
+	for _, p := range others {
+		line := fmt.Sprintf("              %s (PID %d)", p.Name(), p.PID)
+		if p.Penalty != nil {
+			line += fmt.Sprintf(" — IP penalty x%d, %d agents on IP", p.Penalty.Multiplier, p.Penalty.AgentsOnIP)
+		}
+		fmt.Println(line)
+	}
+}
+
+// printQuotas lists rate limits and cooldowns: what can be done now and
+// when the rest reset.
+func printQuotas(quotas []miner.Quota) {
+	if len(quotas) == 0 {
+		return
+	}
+	now := time.Now()
+	fmt.Printf("\n--- Quotas ---\n")
+	for _, q := range quotas {
+		line := fmt.Sprintf("%-26s ", q.Label)
+		if q.Available(now) {
+			line += "ready"
+		} else {
+			line += fmt.Sprintf("resets in %s (%s)", q.Remaining(now).Truncate(time.Second), q.Until.Local().Format("01-02 15:04"))
+			if q.Reason != "" {
+				line += "  " + q.Reason
+			}
+		}
+		fmt.Println(line)
+	}
+}
+
+// printRuntime says who is mining, from the lock file, and how that fits
+// the background service's state.
+func printRuntime() {
+	info := miner.Running()
+	if info == nil {
+		fmt.Println("Miner:        not running")
+	} else {
+		fmt.Printf("Miner:        %s\n", info.Describe())
+		if url := info.ConsoleURL(); url != "" {
+			fmt.Printf("Console:      %s\n", url)
+		}
+		if info.SessionID != "" {
+			fmt.Printf("Session:      %s\n", shortSession(info.SessionID))
+		}
+	}
+	printHostPeers()
+
+	mgr, err := daemon.New()
+	if err != nil {
+		return
+	}
+	st, _ := mgr.Status()
+	if st == nil {
+		return
+	}
+	switch {
+	case !st.Installed:
+		fmt.Println("Service:      not installed")
+	case st.Running && info != nil && info.Mode == miner.ModeForeground:
+		// systemd restarts a service that keeps failing on the lock.
+		fmt.Println("Service:      running, but blocked by the foreground miner — stop one of them")
+	case st.Running && info == nil:
+		fmt.Println("Service:      running (not mining yet)")
+	case st.Running:
+		fmt.Println("Service:      running")
+	case info != nil && info.Mode == miner.ModeForeground:
+		fmt.Println("Service:      stopped (hand mining back with: clawwork detach)")
+	default:
+		fmt.Println("Service:      stopped")
+	}
+	fmt.Printf("Log file:     %s\n", st.LogPath)
+}
+
+func shortSession(id string) string {
+	if len(id) > 12 {
+		return id[:12] + "..."
+	}
+	return id
+}
+
+// printWallet prints the wallet address in checksummed form with any
+// validation warnings. An empty address means no wallet is bound yet.
+func printWallet(addr string) {
+	if addr == "" {
+		fmt.Println("Wallet:       (not bound)")
+		return
+	}
+	c := wallet.Validate(addr)
+	if c.Address != "" {
+		addr = c.Address
+	}
+	fmt.Printf("Wallet:       %s\n", addr)
+	for _, w := range c.Warnings {
+		fmt.Printf("              WARNING: %s\n", w)
+	}
+}
+
+// ── stats command ──
+
+func statsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show local inscription stats (today, reporting period, lifetime) and latency",
+		RunE:  runStats,
+	}
+	cmd.AddCommand(
 		&cobra.Command{
-			Use:   "show",
-			Short: "Show current config (API keys redacted)",
-			RunE:  runConfigShow,
+			Use:   "reset [name]",
+			Short: "Close the current reporting period and start a new one (lifetime totals are kept)",
+			Args:  cobra.MaximumNArgs(1),
+			RunE:  runStatsReset,
+		},
+		&cobra.Command{
+			Use:   "periods",
+			Short: "List closed reporting periods",
+			RunE: func(_ *cobra.Command, _ []string) error {
+				periods := miner.LoadState().ClosedPeriods()
+				if len(periods) == 0 {
+					fmt.Println("No closed periods yet. Start one with: clawwork stats reset <name>")
+					return nil
+				}
+				for _, p := range periods {
+					fmt.Printf("%-20s %s – %s  %s\n", p.Name, p.Start.Local().Format("2006-01-02 15:04"),
+						p.End.Local().Format("2006-01-02 15:04"), miner.FormatCounters(p.Counters))
+				}
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+func runStatsReset(_ *cobra.Command, args []string) error {
+	name := ""
+	if len(args) > 0 {
+		name = strings.TrimSpace(args[0])
+	}
+	var closed *miner.Period
+	if info := miner.Running(); info != nil {
+		// The running miner owns the state; reset through its console.
+		if info.Port == 0 {
+			return fmt.Errorf("%s has no web console (started with --no-web?) — stop it to reset stats", info.Describe())
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		var err error
+		if closed, err = (&web.RemoteClient{Host: info.ConsoleURL()}).ResetStats(ctx, name); err != nil {
+			return err
+		}
+	} else {
+		state := miner.LoadState()
+		closed = state.ResetPeriod(name, time.Now())
+		if err := state.Save(); err != nil {
+			return err
+		}
+	}
+	if closed != nil {
+		fmt.Printf("Closed period %q: %s\n", closed.Name, miner.FormatCounters(closed.Counters))
+	}
+	if name == "" {
+		name = time.Now().Format("2006-01-02")
+	}
+	fmt.Printf("New period %q started. Lifetime totals are unchanged.\n", name)
+	return nil
+}
+
+func runStats(cmd *cobra.Command, _ []string) error {
+	state := miner.LoadState()
+	st := state.Stats(time.Now())
+	if jsonMode(cmd) {
+		latency := make(map[string]map[string]int64)
+		for phase, ls := range state.LatencySummary() {
+			latency[phase] = map[string]int64{"count": ls.Count, "p50_ms": ls.P50.Milliseconds(),
+				"p95_ms": ls.P95.Milliseconds(), "max_ms": ls.Max.Milliseconds()}
+		}
+		return printJSON(map[string]any{
+			"stats":       st,
+			"periods":     state.ClosedPeriods(),
+			"trust_score": state.LastTrustScore,
+			"last_mined":  state.LastMineAt,
+			"tokens":      state.RotationStats(),
+			"penalties":   state.PenaltySummary(5),
+			"latency":     latency,
+		})
+	}
+	type column struct {
+		title string
+		c     miner.Counters
+	}
+	cols := []column{{"Today", st.Today}}
+	if st.Period != nil {
+		cols = append(cols, column{truncateLabel(st.Period.Name, 14), st.Period.Counters})
+	}
+	cols = append(cols, column{"Lifetime", st.Lifetime})
+	rows := []struct {
+		label string
+		value func(miner.Counters) int64
+	}{
+		{"Inscriptions", func(c miner.Counters) int64 { return int64(c.Inscriptions) }},
+		{"CW earned", func(c miner.Counters) int64 { return c.CWEarned }},
+		{"NFT hits", func(c miner.Counters) int64 { return int64(c.Hits) }},
+		{"Passed", func(c miner.Counters) int64 { return int64(c.ChallengesPassed) }},
+		{"Failed", func(c miner.Counters) int64 { return int64(c.ChallengesFailed) }},
+	}
+	fmt.Printf("%-13s", "")
+	for _, col := range cols {
+		fmt.Printf(" %14s", col.title)
+	}
+	fmt.Println()
+	for _, row := range rows {
+		fmt.Printf("%-13s", row.label)
+		for _, col := range cols {
+			fmt.Printf(" %14d", row.value(col.c))
+		}
+		fmt.Println()
+	}
+	if st.Period != nil {
+		fmt.Printf("Period %q started %s\n", st.Period.Name, st.Period.Start.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Println()
+	if state.LastTrustScore > 0 {
+		fmt.Printf("Trust score:  %d\n", state.LastTrustScore)
+	}
+	if !state.LastMineAt.IsZero() {
+		fmt.Printf("Last mined:   %s\n", state.LastMineAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if len(state.Tokens) > 1 {
+		ids := make([]int, 0, len(state.Tokens))
+		for id := range state.Tokens {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		fmt.Println()
+		fmt.Println("Per token:")
+		for _, id := range ids {
+			ts := state.Tokens[id]
+			line := fmt.Sprintf("  #%-5d %4d inscriptions  %8d CW  %d hits", id, ts.Inscriptions, ts.CWEarned, ts.Hits)
+			if wait := time.Until(ts.CooldownUntil); wait > 0 {
+				line += fmt.Sprintf("  (cooldown %s)", wait.Truncate(time.Second))
+			}
+			fmt.Println(line)
+		}
+	}
+	if pen := state.PenaltySummary(5); len(pen.Counts) > 0 {
+		fmt.Println()
+		fmt.Printf("Lost CW:      %d (~%d from %d challenge failures, %d from %d IP-penalized inscriptions)\n",
+			pen.LostCW, pen.ByKind[miner.PenaltyChallenge], pen.Counts[miner.PenaltyChallenge],
+			pen.ByKind[miner.PenaltyIP], pen.Counts[miner.PenaltyIP])
+		fmt.Println("Recent penalties:")
+		for _, p := range pen.Recent {
+			lost := fmt.Sprintf("-%d", p.LostCW)
+			if p.Estimated {
+				lost = "~" + lost
+			}
+			line := fmt.Sprintf("  %s  %-9s  %s CW", p.At.Local().Format("01-02 15:04"), p.Kind, lost)
+			if p.TrustLost > 0 {
+				line += fmt.Sprintf("  trust -%d", p.TrustLost)
+			}
+			if p.Detail != "" {
+				line += "  " + p.Detail
+			}
+			fmt.Println(line)
+		}
+	}
+	if g := miner.LoadGoal(); g != nil {
+		fmt.Println()
+		fmt.Println(state.Project(g, time.Now()))
+	}
+	fmt.Println()
+	miner.DisplayLatency(state)
+	return nil
+}
+
+// ── goal command ──
+
+func goalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "goal",
+		Short: "Show progress toward your CW goal and its projected date",
+		RunE:  runGoalShow,
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "set <cw>",
+			Short: "Set a CW goal",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				cw, err := strconv.ParseInt(strings.ReplaceAll(args[0], ",", ""), 10, 64)
+				if err != nil || cw <= 0 {
+					return fmt.Errorf("goal must be a positive number of CW, e.g. 100000")
+				}
+				if err := miner.SaveGoal(&miner.Goal{CW: cw, SetAt: time.Now().UTC()}); err != nil {
+					return fmt.Errorf("save goal: %w", err)
+				}
+				return runGoalShow(nil, nil)
+			},
+		},
+		&cobra.Command{
+			Use:   "clear",
+			Short: "Remove the CW goal",
+			RunE: func(_ *cobra.Command, _ []string) error {
+				if err := miner.ClearGoal(); err != nil {
+					return err
+				}
+				fmt.Println("Goal cleared.")
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+func runGoalShow(_ *cobra.Command, _ []string) error {
+	g := miner.LoadGoal()
+	if g == nil {
+		fmt.Println("No goal set. Set one with: clawwork goal set 100000")
+		return nil
+	}
+	fmt.Println(miner.LoadState().Project(g, time.Now()))
+	return nil
+}
+
+// ── schedule command ──
+
+func scheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Show or change the cooldown and active hours",
+		Long: `Shows the [schedule] section: the wait after each inscription and the
+daily windows of local time the miner is active in. Outside them it sleeps.
+
+Changes are saved to the config and sent to a running miner as a reload
+(SIGHUP); on Windows restart the miner to apply them.`,
+		Args: cobra.NoArgs,
+		RunE: runScheduleShow,
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:          "hours <HH:MM-HH:MM>...",
+			Short:        "Mine only in these daily windows, e.g. 22:00-08:00",
+			Args:         cobra.MinimumNArgs(1),
+			SilenceUsage: true,
+			RunE: func(_ *cobra.Command, args []string) error {
+				return updateSchedule(func(s *config.ScheduleConfig) { s.ActiveHours = args })
+			},
+		},
+		&cobra.Command{
+			Use:          "always",
+			Short:        "Mine around the clock (remove the active hours)",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				return updateSchedule(func(s *config.ScheduleConfig) { s.ActiveHours = nil })
+			},
+		},
+		&cobra.Command{
+			Use:          "cooldown <duration>",
+			Short:        "Wait this long after each inscription, e.g. 45m (default: the platform's 30m)",
+			Args:         cobra.ExactArgs(1),
+			SilenceUsage: true,
+			RunE: func(_ *cobra.Command, args []string) error {
+				var secs int
+				if args[0] != "default" && args[0] != "0" {
+					d, err := time.ParseDuration(args[0])
+					if err != nil {
+						return fmt.Errorf("cooldown must be a duration like 45m or 1h30m, or \"default\"")
+					}
+					secs = int(d.Seconds())
+				}
+				return updateSchedule(func(s *config.ScheduleConfig) { s.CooldownSeconds = secs })
+			},
+		},
+	)
+	return cmd
+}
+
+func runScheduleShow(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	fmt.Println(describeSchedule(cfg.Schedule))
+	hours, err := schedule.Parse(cfg.Schedule.ActiveHours)
+	if err != nil {
+		return fmt.Errorf("schedule.active_hours: %w", err)
+	}
+	now := time.Now()
+	switch next := hours.Next(now); {
+	case next.IsZero():
+	case hours.Active(now):
+		fmt.Printf("Now: active, until %s\n", next.Format("Jan 2 15:04"))
+	default:
+		fmt.Printf("Now: outside active hours, mining resumes %s\n", next.Format("Jan 2 15:04"))
+	}
+	return nil
+}
+
+// describeSchedule summarizes [schedule] for banners and `clawwork schedule`.
+func describeSchedule(s config.ScheduleConfig) string {
+	cooldown := "30m (platform default)"
+	if s.CooldownSeconds > 0 {
+		cooldown = (time.Duration(s.CooldownSeconds) * time.Second).String()
+	}
+	hours, _ := schedule.Parse(s.ActiveHours)
+	return fmt.Sprintf("cooldown %s, active hours %s", cooldown, hours)
+}
+
+// pacingOf converts a validated [schedule] section for the miner.
+func pacingOf(s config.ScheduleConfig) miner.Pacing {
+	hours, _ := schedule.Parse(s.ActiveHours)
+	return miner.Pacing{Cooldown: s.Cooldown(0), Hours: hours}
+}
+
+// updateSchedule changes [schedule], saves the config and asks a running
+// miner to reload it.
+func updateSchedule(change func(*config.ScheduleConfig)) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	change(&cfg.Schedule)
+	if err := cfg.Schedule.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Println(describeSchedule(cfg.Schedule))
+
+	if lock, err := miner.ReadLock(); err == nil && lock.Alive() {
+		if err := lock.Reload(); err != nil {
+			fmt.Printf("Restart %s to apply it.\n", lock.Describe())
+		} else {
+			fmt.Printf("Reload sent to %s.\n", lock.Describe())
+		}
+	}
+	return nil
+}
+
+// ── experiment command ──
+
+func experimentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "experiment",
+		Short: "A/B test two configurations ([experiment] in config.toml) and compare them",
+		RunE:  runExperimentReport,
+	}
+	run := inscCmd()
+	run.Use = "run"
+	run.Short = "Mine with the configured experiment, alternating arms A and B each cycle"
+	run.RunE = func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if !cfg.Experiment.Enabled() {
+			return fmt.Errorf("no experiment configured — set [experiment] name, [experiment.a] and [experiment.b] in %s", config.Path())
+		}
+		return runInsc(cmd, args)
+	}
+	cmd.AddCommand(
+		run,
+		&cobra.Command{
+			Use:   "report [name]",
+			Short: "Compare pass rate and earnings between the arms",
+			Args:  cobra.MaximumNArgs(1),
+			RunE:  runExperimentReport,
+		},
+		&cobra.Command{
+			Use:   "reset [name]",
+			Short: "Discard an experiment's results",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				name, err := experimentName(args)
+				if err != nil {
+					return err
+				}
+				if info := miner.Running(); info != nil {
+					return fmt.Errorf("the miner is running (PID %d) and would write the results back — stop it first", info.PID)
+				}
+				state := miner.LoadState()
+				if !state.ResetExperiment(name) {
+					fmt.Printf("No results recorded for experiment %q.\n", name)
+					return nil
+				}
+				if err := state.Save(); err != nil {
+					return err
+				}
+				fmt.Printf("Results of experiment %q discarded.\n", name)
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+// experimentName returns the experiment named in args, or the configured one.
+func experimentName(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if cfg, err := config.Load(); err == nil && cfg.Experiment.Enabled() {
+		return cfg.Experiment.Name, nil
+	}
+	return "", fmt.Errorf("no experiment configured — name one, e.g. clawwork experiment report my-test")
+}
+
+func runExperimentReport(_ *cobra.Command, args []string) error {
+	name, err := experimentName(args)
+	if err != nil {
+		return err
+	}
+	res := miner.LoadState().ExperimentResult(name)
+	if res == nil {
+		fmt.Printf("No results recorded for experiment %q yet. Start it with: clawwork experiment run\n", name)
+		return nil
+	}
+	a, b := res.Arms["a"], res.Arms["b"]
+	if a == nil {
+		a = &miner.ArmStats{}
+	}
+	if b == nil {
+		b = &miner.ArmStats{}
+	}
+	fmt.Printf("Experiment %q (since %s)\n\n", name, res.Started.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("  %-3s %-36s %7s %9s %10s %8s\n", "", "config", "cycles", "pass rate", "CW/cycle", "CW")
+	for _, arm := range []struct {
+		name string
+		*miner.ArmStats
+	}{{"A", a}, {"B", b}} {
+		fmt.Printf("  %-3s %-36s %7d %8.1f%% %10.1f %8d\n", arm.name, truncateLabel(arm.Label, 36),
+			arm.Cycles, arm.PassRate()*100, arm.CWPerCycle(), arm.CW)
+	}
+	c := miner.Compare(*a, *b)
+	fmt.Println()
+	fmt.Printf("Pass rate: B %+.1f points vs A (p=%.2f) — %s\n", c.PassRateDiff*100, c.PassRateP, c.Hint(c.PassRateP))
+	fmt.Printf("Earnings:  B %+.1f CW/cycle vs A (p=%.2f) — %s\n", c.CWDiff, c.CWP, c.Hint(c.CWP))
+	return nil
+}
+
+func truncateLabel(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// ── notify command ──
+
+func notifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Manage notification channels ([notify] in config)",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "test [channel]",
+		Short: "Send a test notification to one or all channels",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runNotifyTest,
+	})
+	return cmd
+}
+
+func runNotifyTest(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	d, err := notify.New(cfg.Notify, cfg.Agent.Name)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		fmt.Println("No notification channels configured. Add a [[notify.channel]] or set desktop = true under [notify] in config.toml.")
+		return nil
+	}
+	defer d.Close()
+
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	results := d.Test(ctx, name)
+	if len(results) == 0 {
+		return fmt.Errorf("no channel named %q (have: %s)", name, strings.Join(d.Channels(), ", "))
+	}
+	failed := 0
+	for _, ch := range d.Channels() {
+		err, ok := results[ch]
+		switch {
+		case !ok:
+		case err != nil:
+			fmt.Printf("%-16s FAILED: %s\n", ch, err)
+			failed++
+		default:
+			fmt.Printf("%-16s sent\n", ch)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d channel(s) failed", failed)
+	}
+	return nil
+}
+
+// ── history command ──
+
+func historyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List recorded inscription attempts (challenge, answer, latency, CW, errors)",
+		Long: "List the inscription attempts recorded in ~/.clawwork/history: every submit with\n" +
+			"its challenge, answer, latency, CW earned or platform error, and every challenge\n" +
+			"the LLM failed to answer. The files are append-only JSON Lines, one per month.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runHistory,
+	}
+	cmd.Flags().String("since", "24h", "How far back to list (e.g. 90m, 24h, 7d)")
+	cmd.Flags().Int("limit", 0, "Show only the most recent N attempts (0 = all)")
+	return cmd
+}
+
+func runHistory(cmd *cobra.Command, _ []string) error {
+	s, _ := cmd.Flags().GetString("since")
+	since, err := parseSince(s)
+	if err != nil {
+		return err
+	}
+	entries, err := miner.ReadHistory(miner.Store(), time.Now().Add(-since))
+	if err != nil {
+		return err
+	}
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	if jsonMode(cmd) {
+		if entries == nil {
+			entries = []miner.HistoryEntry{}
+		}
+		return printJSON(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No inscription attempts in the last %s.\n", s)
+		return nil
+	}
+
+	fmt.Printf("%-14s  %-6s  %-20s  %5s  %7s  %7s  %s\n", "TIME", "TOKEN", "OUTCOME", "CW", "ANSWER", "SUBMIT", "CHALLENGE")
+	var cw int64
+	outcomes := make(map[string]int)
+	for _, e := range entries {
+		outcome := e.Outcome
+		if e.Code != "" {
+			outcome = e.Code
+		}
+		fmt.Printf("%-14s  #%-5d  %-20s  %5d  %7s  %7s  %s\n", e.At.Local().Format("01-02 15:04:05"), e.TokenID,
+			truncateLabel(outcome, 20), e.CWEarned, fmtMs(e.AnswerMs), fmtMs(e.SubmitMs), truncateLabel(oneLine(e.Prompt), 50))
+		if e.Error != "" && e.Code == "" {
+			fmt.Printf("%16s%s\n", "", truncateLabel(e.Error, 100))
+		}
+		cw += int64(e.CWEarned)
+		outcomes[e.Outcome]++
+	}
+	fmt.Printf("\n%d attempt(s): %d ok, %d hit, %d rejected, %d error — %d CW\n", len(entries),
+		outcomes["ok"], outcomes["hit"], outcomes["rejected"], outcomes["error"], cw)
+	return nil
+}
+
+// ── earnings command ──
+
+func earningsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "earnings",
+		Short: "Reconcile local inscription history with the platform's CW totals",
+		Long: "Compare what this machine recorded (state and history) with the totals the\n" +
+			"platform reports, and print a per-day breakdown of the history. Flags\n" +
+			"accepted inscriptions without CW, unconfirmed inscriptions and CW the platform\n" +
+			"credited less than was recorded here. Exits 1 when something doesn't match.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runEarnings,
+	}
+	cmd.Flags().String("since", "30d", "How far back the per-day breakdown goes (e.g. 24h, 7d)")
+	return cmd
+}
+
+func runEarnings(cmd *cobra.Command, _ []string) error {
+	s, _ := cmd.Flags().GetString("since")
+	since, err := parseSince(s)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	st, err := api.New(cfg.Agent.APIKey).Status(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to fetch status: %w", err)
+	}
+	entries, err := miner.ReadHistory(miner.Store(), time.Now().Add(-since))
+	if err != nil {
+		return err
+	}
+	r := miner.Reconcile(entries, miner.LoadState().Stats(time.Now()).Lifetime, st, time.Local)
+
+	if jsonMode(cmd) {
+		if err := printJSON(r); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("%-12s  %13s  %13s\n", "", "THIS MACHINE", "PLATFORM")
+		fmt.Printf("%-12s  %13d  %13d (%d confirmed)\n", "Inscriptions", r.Local.Inscriptions, r.Platform.Inscriptions, r.Platform.Confirmed)
+		fmt.Printf("%-12s  %13d  %13d\n", "CW", r.Local.CWEarned, r.Platform.CWEarned)
+
+		if len(r.Days) > 0 {
+			fmt.Printf("\n%-10s  %8s  %8s  %8s  %6s  %8s\n", "DAY", "ATTEMPTS", "ACCEPTED", "REJECTED", "ERRORS", "CW")
+			for _, d := range r.Days {
+				fmt.Printf("%-10s  %8d  %8d  %8d  %6d  %8d\n", d.Date, d.Attempts, d.Accepted, d.Rejected, d.Errors, d.CWEarned)
+			}
+		} else {
+			fmt.Printf("\nNo inscription history in the last %s.\n", s)
+		}
+		for _, n := range r.Notes {
+			fmt.Printf("\nNote: %s", n)
+		}
+		if len(r.Notes) > 0 {
+			fmt.Println()
+		}
+		if len(r.Discrepancies) == 0 {
+			fmt.Println("\nNo discrepancies found.")
+		} else {
+			fmt.Println("\nDiscrepancies:")
+			for _, d := range r.Discrepancies {
+				fmt.Printf("  ! %s\n", d)
+			}
+			fmt.Println("\nAttach `clawwork history --since " + s + " --json` when reporting these.")
+		}
+	}
+	if len(r.Discrepancies) > 0 {
+		return fmt.Errorf("%d discrepancy(ies) found", len(r.Discrepancies))
+	}
+	return nil
+}
+
+// parseSince parses a duration, also accepting whole days ("7d").
+func parseSince(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --since %q (use e.g. 90m, 24h or 7d)", s)
+	}
+	return d, nil
+}
+
+// fmtMs formats a millisecond latency for tables ("-" if not measured).
+func fmtMs(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
+
+// oneLine collapses whitespace so a prompt fits a table row.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ── metrics command ──
+
+func metricsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Print Prometheus metrics from local state",
+		Long: "Print the metrics the miner writes to [metrics] textfile, read from local state.\n" +
+			"With -o the file is replaced atomically, so a cron job can feed node_exporter's\n" +
+			"textfile collector even when no miner runs.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			src := metrics.Source{State: miner.LoadState(), Version: version}
+			if cfg, err := config.Load(); err == nil {
+				src.Agent = cfg.Agent.Name
+			}
+			if out, _ := cmd.Flags().GetString("output"); out != "" {
+				return metrics.WriteFile(out, src)
+			}
+			return metrics.Render(os.Stdout, src, time.Now())
 		},
+	}
+	cmd.Flags().StringP("output", "o", "", "Write to this .prom file instead of stdout")
+	return cmd
+}
+
+// ── callback command ──
+
+func callbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "callback",
+		Short: "Platform webhooks ([callback] in config)",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "test [type]",
+		Short: "Send a signed test callback to the running miner's listener",
+		Long: "Send a signed callback to callback.listen, as the platform would. The running\n" +
+			"miner shows it in the console and routes it to notifications. type defaults\n" +
+			"to \"test\"; try claim.completed, nft.verified or mail.received.",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runCallbackTest,
+	})
+	return cmd
+}
+
+// callbackPath is the configured callback path or the default.
+func callbackPath(cfg *config.Config) string {
+	if cfg.Callback.Path != "" {
+		return cfg.Callback.Path
+	}
+	return callback.DefaultPath
+}
+
+func runCallbackTest(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.Callback.Listen == "" {
+		return fmt.Errorf("no callback listener configured — set [callback] listen and secret in config.toml")
+	}
+	e := callback.Event{ID: fmt.Sprintf("test_%d", time.Now().UnixNano()), Type: "test", At: time.Now().UTC()}
+	if len(args) == 1 {
+		e.Type = args[0]
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	host, port, _ := net.SplitHostPort(cfg.Callback.Listen)
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	target := "http://" + net.JoinHostPort(host, port) + callbackPath(cfg)
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(callback.SignatureHeader, callback.Sign(cfg.Callback.Secret, time.Now(), body))
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return fmt.Errorf("listener unreachable (is the miner running?): %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("listener answered HTTP %d", resp.StatusCode)
+	}
+	fmt.Printf("Delivered %q to %s\n", e.Type, target)
+	return nil
+}
+
+// ── leaderboard command ──
+
+func leaderboardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "leaderboard",
+		Short: "Show agent rankings by CW or inscriptions",
+		RunE:  runLeaderboard,
+	}
+	cmd.Flags().String("scope", "nearby", "Ranking scope: nearby or global")
+	cmd.Flags().String("by", "cw", "Rank by: cw or inscriptions")
+	cmd.Flags().Int("limit", 20, "Number of entries to show")
+	return cmd
+}
+
+func runLeaderboard(cmd *cobra.Command, _ []string) error {
+	scope, _ := cmd.Flags().GetString("scope")
+	by, _ := cmd.Flags().GetString("by")
+	limit, _ := cmd.Flags().GetInt("limit")
+	if scope != "nearby" && scope != "global" {
+		return fmt.Errorf("--scope must be nearby or global")
+	}
+	if by != "cw" && by != "inscriptions" {
+		return fmt.Errorf("--by must be cw or inscriptions")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	client := api.New(cfg.Agent.APIKey)
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+	lb, err := client.Leaderboard(ctx, scope, by, limit)
+	if errors.Is(err, api.ErrNotSupported) {
+		fmt.Println("Rankings are not available from the ClawWork server yet.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch leaderboard: %w", err)
+	}
+
+	fmt.Printf("Leaderboard (%s, by %s) — %d agents\n\n", lb.Scope, lb.By, lb.Total)
+	fmt.Printf("  %5s  %-24s %12s %12s\n", "RANK", "AGENT", "CW", "INSCRIPTIONS")
+	selfShown := false
+	for _, e := range lb.Entries {
+		mark := " "
+		if lb.Self != nil && e.AgentID == lb.Self.AgentID {
+			mark, selfShown = "*", true
+		}
+		fmt.Printf("%s %5d  %-24s %12d %12d\n", mark, e.Rank, e.DisplayName, e.CW, e.Inscriptions)
+	}
+	if lb.Self != nil && !selfShown {
+		e := lb.Self
+		fmt.Println("  ...")
+		fmt.Printf("* %5d  %-24s %12d %12d\n", e.Rank, e.DisplayName, e.CW, e.Inscriptions)
+	}
+	return nil
+}
+
+// ── selftest command ──
+
+func selftestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Verify config, soul, LLM, tools, lock and platform connectivity",
+		Long: "Runs a quick end-to-end sanity check without inscribing: config load, soul decryption,\n" +
+			"an LLM round-trip, the tool sandbox, lock acquisition and a platform ping.",
+		RunE:         runSelftest,
+		SilenceUsage: true, // a failed check is not a usage error
+	}
+	cmd.Flags().Bool("offline", false, "Skip checks that need the network (LLM, platform)")
+	return cmd
+}
+
+// selftestResult is one row of the selftest matrix.
+type selftestResult struct {
+	name   string
+	status string // PASS, FAIL, SKIP
+	detail string
+}
+
+func runSelftest(cmd *cobra.Command, _ []string) error {
+	offline, _ := cmd.Flags().GetBool("offline")
+	ctx := cmd.Context()
+	var results []selftestResult
+	check := func(name string, fn func() (string, error)) {
+		start := time.Now()
+		detail, err := fn()
+		r := selftestResult{name: name, status: "PASS", detail: detail}
+		switch {
+		case errors.Is(err, errSkip):
+			r.status = "SKIP"
+		case err != nil:
+			r.status, r.detail = "FAIL", err.Error()
+		}
+		if r.status == "PASS" {
+			r.detail = strings.TrimSpace(fmt.Sprintf("%s (%dms)", r.detail, time.Since(start).Milliseconds()))
+		}
+		results = append(results, r)
+	}
+
+	cfg, cfgErr := config.Load()
+	check("config", func() (string, error) {
+		if cfgErr != nil {
+			return "", cfgErr
+		}
+		if err := cfg.Validate(); err != nil {
+			return "", err
+		}
+		return config.Path(), nil
+	})
+	needCfg := func(fn func() (string, error)) func() (string, error) {
+		if cfgErr != nil {
+			return func() (string, error) { return "needs a valid config", errSkip }
+		}
+		return fn
+	}
+
+	check("soul", needCfg(func() (string, error) {
+		soul, err := knowledge.LoadSoul(cfg.Agent.APIKey)
+		if err != nil {
+			return "", err
+		}
+		if soul == "" {
+			return "no custom soul (default personality)", nil
+		}
+		return fmt.Sprintf("decrypted %d bytes", len(soul)), nil
+	}))
+
+	check("llm", needCfg(func() (string, error) {
+		if offline {
+			return "offline", errSkip
+		}
+		provider, err := llm.NewProvider(&cfg.LLM, "You are a health check. Follow instructions exactly.", 64)
+		if err != nil {
+			return "", err
+		}
+		llmCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		answer, err := provider.Answer(llmCtx, "Reply with the single word OK.")
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(answer) == "" {
+			return "", fmt.Errorf("%s returned an empty answer", provider.Name())
+		}
+		return provider.Name(), nil
+	}))
+
+	check("tools", func() (string, error) {
+		out := tools.NewShellExecTool().Call(ctx, `{"command":"echo clawwork_selftest"}`)
+		if !strings.Contains(out, "clawwork_selftest") {
+			return "", fmt.Errorf("shell_exec: %s", strings.TrimSpace(out))
+		}
+		blocked := "/etc/clawwork-selftest"
+		if runtime.GOOS == "windows" {
+			blocked = `C:\Windows\clawwork-selftest`
+		}
+		args, _ := json.Marshal(map[string]string{"operation": "write", "path": blocked, "content": "x"})
+		if out := tools.NewFilesystemTool().Call(ctx, string(args)); !strings.Contains(out, "not allowed") {
+			return "", fmt.Errorf("sandbox allowed a write to %s: %s", blocked, out)
+		}
+		return "shell ok, system paths blocked", nil
+	})
+
+	check("lock", func() (string, error) {
+		if info := miner.Running(); info != nil {
+			return "held by " + info.Describe(), nil
+		}
+		release, err := miner.AcquireLock(miner.LockInfo{Version: version})
+		if err != nil {
+			return "", err
+		}
+		release()
+		return miner.LockPath(), nil
+	})
+
+	check("platform", needCfg(func() (string, error) {
+		if offline {
+			return "offline", errSkip
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		resp, err := api.New(cfg.Agent.APIKey).Status(pingCtx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("agent %s, platform %s", resp.Agent.Name, resp.Activity.Status), nil
+	}))
+
+	failed := 0
+	fmt.Printf("%-10s %-6s %s\n", "CHECK", "RESULT", "DETAIL")
+	for _, r := range results {
+		fmt.Printf("%-10s %-6s %s\n", r.name, r.status, r.detail)
+		if r.status == "FAIL" {
+			failed++
+		}
+	}
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	fmt.Println("All checks passed.")
+	return nil
+}
+
+// errSkip marks a selftest check that did not run.
+var errSkip = errors.New("skipped")
+
+// ── advise command ──
+
+func adviseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "advise",
+		Short: "Analyze recent challenge failures and suggest how to recover trust",
+		RunE:  runAdvise,
+	}
+	cmd.Flags().Bool("prompt", false, "Print the analysis prompt without calling the LLM")
+	return cmd
+}
+
+func runAdvise(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config not found — run 'clawwork init' first: %w", err)
+	}
+
+	in := advisor.FromState(miner.LoadState(), cfg.LLM)
+	if showPrompt, _ := cmd.Flags().GetBool("prompt"); showPrompt {
+		fmt.Println(advisor.BuildPrompt(in))
+		return nil
+	}
+	if !in.HasData() {
+		fmt.Println("No challenge failures or trust history recorded yet — nothing to analyze.")
+		return nil
+	}
+
+	provider, err := llm.NewProvider(&cfg.LLM, advisor.SystemPrompt(), 1024)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Analyzing %d failed challenge(s) and %d trust sample(s) with %s...\n\n",
+		len(in.Failures), len(in.Trust), provider.Name())
+	ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Minute)
+	defer cancel()
+	advice, err := advisor.Advise(ctx, provider, in)
+	if err != nil {
+		return fmt.Errorf("advisor: %w", err)
+	}
+	fmt.Println(advice)
+	return nil
+}
+
+// ── config command ──
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage configuration",
+	}
+	show := &cobra.Command{
+		Use:   "show",
+		Short: "Show current config (API keys redacted)",
+		RunE:  runConfigShow,
+	}
+	show.Flags().Bool("headers", false, "Show the HTTP headers sent to the platform and LLM instead")
+	cmd.AddCommand(
+		show,
 		&cobra.Command{
 			Use:   "path",
 			Short: "Print config file path",
@@ -749,15 +2792,76 @@ func runConfigLLM(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func runConfigShow(_ *cobra.Command, _ []string) error {
+func runConfigShow(cmd *cobra.Command, _ []string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
+	if headers, _ := cmd.Flags().GetBool("headers"); headers {
+		if jsonMode(cmd) {
+			return fmt.Errorf("config show --headers has no JSON form")
+		}
+		printHeaders(cfg)
+		return nil
+	}
 	redacted := cfg.Redact()
+	if jsonMode(cmd) {
+		// Round-trip through TOML so keys match the config file.
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(redacted); err != nil {
+			return err
+		}
+		var m map[string]any
+		if _, err := toml.Decode(buf.String(), &m); err != nil {
+			return err
+		}
+		return printJSON(m)
+	}
 	return toml.NewEncoder(os.Stdout).Encode(redacted)
 }
 
+// printHeaders previews exactly which headers leave the machine, per destination.
+func printHeaders(cfg *config.Config) {
+	mode := "standard"
+	if cfg.Privacy.MinimalHeaders {
+		mode = "minimal"
+	}
+	fmt.Printf("ClawWork platform (%s) — privacy mode: %s\n", api.BaseURL, mode)
+	h := api.New(cfg.Agent.APIKey).PreviewHeaders()
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %s: %s\n", k, h.Get(k))
+	}
+	fmt.Println("  (nonce, timestamp and signature change on every request; the signature is an HMAC of nonce, timestamp and body hash)")
+	if cfg.Network.Proxy != "" {
+		fmt.Printf("  (via proxy %s)\n", cfg.Network.Proxy)
+	}
+
+	red := cfg.Redact()
+	fmt.Printf("\nLLM provider: %s", cfg.LLM.Provider)
+	if cfg.LLM.BaseURL != "" {
+		fmt.Printf(" (%s)", cfg.LLM.BaseURL)
+	}
+	fmt.Println()
+	switch cfg.LLM.Provider {
+	case "openai", "platform":
+		fmt.Printf("  Authorization: Bearer %s\n", red.LLM.APIKey)
+	case "anthropic":
+		fmt.Printf("  X-Api-Key: %s\n  Anthropic-Version: 2023-06-01\n", red.LLM.APIKey)
+	}
+	fmt.Println("  Content-Type: application/json")
+	for k, v := range red.LLM.Headers {
+		fmt.Printf("  %s: %s\n", http.CanonicalHeaderKey(k), v)
+	}
+	if proxy := cmp.Or(cfg.LLM.Proxy, cfg.Network.Proxy); proxy != "" {
+		fmt.Printf("  (via proxy %s)\n", proxy)
+	}
+}
+
 func runConfigAPIKey(_ *cobra.Command, _ []string) error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -778,33 +2882,113 @@ func runConfigAPIKey(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("API key cannot be empty")
 	}
 
-	// Validate by fetching agent status with the new key.
-	fmt.Print("Validating... ")
-	client := api.New(newKey)
-	status, err := client.Status(context.Background())
+	// Validate by fetching agent status with the new key.
+	fmt.Print("Validating... ")
+	client := api.New(newKey)
+	status, err := client.Status(context.Background())
+	if err != nil {
+		return fmt.Errorf("invalid API key: %w", err)
+	}
+	fmt.Printf("OK (agent: %s)\n", status.Agent.Name)
+
+	cfg.Agent.APIKey = newKey
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("API key updated. Config saved to %s\n", config.Path())
+	return nil
+}
+
+// ── version command ──
+
+func versionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		RunE:  runVersion,
+	}
+	cmd.Flags().Bool("no-check", false, "With --json, skip the remote update check")
+	return cmd
+}
+
+// versionReport is the machine-readable output of `clawwork version --json`.
+type versionReport struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit"`
+	BuildDate       string `json:"build_date"`
+	GoVersion       string `json:"go_version"`
+	OS              string `json:"os"`
+	Arch            string `json:"arch"`
+	APIURL          string `json:"api_url"`
+	UpdateChannel   string `json:"update_channel"`
+	UpdateChecked   bool   `json:"update_checked"`
+	UpdateAvailable bool   `json:"update_available"`
+	LatestVersion   string `json:"latest_version,omitempty"`
+	UpdateError     string `json:"update_error,omitempty"`
+}
+
+func runVersion(cmd *cobra.Command, _ []string) error {
+	if !jsonMode(cmd) {
+		fmt.Printf("clawwork %s (commit: %s, built: %s)\n", version, commit, date)
+		return nil
+	}
+
+	r := versionReport{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: date,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		APIURL:    api.BaseURL,
+
+		UpdateChannel: updateChannel(nil),
+	}
+	if r.UpdateChannel == "" {
+		r.UpdateChannel = updater.ChannelStable
+	}
+	if noCheck, _ := cmd.Flags().GetBool("no-check"); !noCheck {
+		r.UpdateChecked = true
+		info, err := updater.CheckUpdate(version, r.UpdateChannel)
+		switch {
+		case err != nil:
+			r.UpdateError = err.Error()
+		case info != nil:
+			r.UpdateAvailable = true
+			r.LatestVersion = info.Version
+		default:
+			r.LatestVersion = version
+		}
+	}
+
+	return printJSON(r)
+}
+
+// applyTimezone makes display.timezone the process's local zone, so every
+// printed time, daily rollover and quiet-hours window follows it.
+func applyTimezone(cfg *config.Config) {
+	loc, err := cfg.Display.Location()
 	if err != nil {
-		return fmt.Errorf("invalid API key: %w", err)
+		fmt.Fprintf(os.Stderr, "Warning: %v — using the machine's time zone\n", err)
+		return
 	}
-	fmt.Printf("OK (agent: %s)\n", status.Agent.Name)
+	time.Local = loc
+}
 
-	cfg.Agent.APIKey = newKey
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+// jsonMode reports whether the global --json flag is set.
+func jsonMode(cmd *cobra.Command) bool {
+	if cmd == nil {
+		return false
 	}
-	fmt.Printf("API key updated. Config saved to %s\n", config.Path())
-	return nil
+	asJSON, _ := cmd.Flags().GetBool("json")
+	return asJSON
 }
 
-// ── version command ──
-
-func versionCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "version",
-		Short: "Print version information",
-		Run: func(_ *cobra.Command, _ []string) {
-			fmt.Printf("clawwork %s (commit: %s, built: %s)\n", version, commit, date)
-		},
-	}
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
 // ── update command ──
@@ -816,16 +3000,39 @@ func updateCmd() *cobra.Command {
 		RunE:  runUpdate,
 	}
 	cmd.Flags().Bool("check", false, "Only check for updates, don't install")
+	cmd.Flags().String("channel", "", "Release channel: stable, beta or nightly (default: updater.channel, else stable)")
 	return cmd
 }
 
+// updateChannel returns the release channel to follow: cmd's --channel
+// flag, else updater.channel from config, else "" (stable).
+func updateChannel(cmd *cobra.Command) string {
+	if cmd != nil {
+		if ch, _ := cmd.Flags().GetString("channel"); ch != "" {
+			return ch
+		}
+	}
+	if cfg, err := config.Load(); err == nil {
+		return cfg.Updater.Channel
+	}
+	return ""
+}
+
 func runUpdate(cmd *cobra.Command, _ []string) error {
 	checkOnly, _ := cmd.Flags().GetBool("check")
+	channel := updateChannel(cmd)
+	if !updater.ValidChannel(channel) {
+		return fmt.Errorf("unknown update channel %q — use %s (--channel or updater.channel)", channel, strings.Join(updater.Channels, ", "))
+	}
 
 	fmt.Printf("Current version: %s\n", version)
-	fmt.Print("Checking for updates... ")
+	if channel != "" && channel != updater.ChannelStable {
+		fmt.Printf("Checking for updates on the %s channel... ", channel)
+	} else {
+		fmt.Print("Checking for updates... ")
+	}
 
-	info, err := updater.CheckUpdate(version)
+	info, err := updater.CheckUpdate(version, channel)
 	if err != nil {
 		return err
 	}
@@ -847,6 +3054,131 @@ func runUpdate(cmd *cobra.Command, _ []string) error {
 	return updater.Apply(info)
 }
 
+// ── agent command ──
+
+// agentNameRe matches valid agent names (1-30, alphanumeric + underscore).
+var agentNameRe = regexp.MustCompile(`^[A-Za-z0-9_]{1,30}$`)
+
+func agentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Show or change agent identity (name, avatar)",
+	}
+	avatar := &cobra.Command{
+		Use:   "avatar <image-file>",
+		Short: "Upload a new avatar (PNG/JPEG/GIF/WebP, max 512KB)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAgentAvatar,
+	}
+	avatar.Flags().String("owner-token", "", "Owner JWT from the ClawWork website (or set CLAWWORK_OWNER_TOKEN)")
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "show",
+			Short: "Show agent identity",
+			RunE:  runAgentShow,
+		},
+		&cobra.Command{
+			Use:   "rename <new-name>",
+			Short: "Change the agent's display name",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runAgentRename,
+		},
+		avatar,
+	)
+	return cmd
+}
+
+func runAgentShow(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	resp, err := api.New(cfg.Agent.APIKey).Status(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to fetch agent: %w", err)
+	}
+	fmt.Printf("Name:         %s\n", resp.Agent.Name)
+	if resp.Agent.Name != cfg.Agent.Name {
+		fmt.Printf("Local name:   %s (config.toml)\n", cfg.Agent.Name)
+	}
+	fmt.Printf("Agent ID:     %s\n", resp.Agent.ID)
+	printWallet(resp.Agent.WalletAddress)
+	if resp.Agent.AvatarURL != "" {
+		fmt.Printf("Avatar:       %s\n", resp.Agent.AvatarURL)
+	} else {
+		fmt.Println("Avatar:       (none)")
+	}
+	return nil
+}
+
+func runAgentRename(cmd *cobra.Command, args []string) error {
+	name := strings.TrimSpace(args[0])
+	if !agentNameRe.MatchString(name) {
+		return fmt.Errorf("invalid name: use 1-30 letters, digits or underscores")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	resp, err := api.New(cfg.Agent.APIKey).UpdateProfile(cmd.Context(), name)
+	if errors.Is(err, api.ErrNotSupported) {
+		fmt.Println("Renaming is not available through the API yet — change the name at https://clawplaza.ai")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("rename failed: %w", err)
+	}
+	if resp.DisplayName != "" {
+		name = resp.DisplayName
+	}
+
+	cfg.Agent.Name = name
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("renamed on the platform, but saving config failed: %w", err)
+	}
+	fmt.Printf("Agent renamed to %s.\n", name)
+	return nil
+}
+
+func runAgentAvatar(cmd *cobra.Command, args []string) error {
+	token, _ := cmd.Flags().GetString("owner-token")
+	if token == "" {
+		token = os.Getenv("CLAWWORK_OWNER_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("avatar upload needs the owner's login token: pass --owner-token or set CLAWWORK_OWNER_TOKEN")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	if len(data) > api.MaxAvatarSize {
+		return fmt.Errorf("image is %dKB, limit is %dKB", len(data)/1024, api.MaxAvatarSize/1024)
+	}
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+	default:
+		return fmt.Errorf("unsupported image type %s (use PNG, JPEG, GIF or WebP)", contentType)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	resp, err := api.New(cfg.Agent.APIKey).UploadAvatar(cmd.Context(), token, filepath.Base(args[0]), contentType, data)
+	if err != nil {
+		return fmt.Errorf("avatar upload failed: %w", err)
+	}
+	fmt.Println("Avatar updated.")
+	if resp.AvatarURL != "" {
+		fmt.Printf("URL: %s\n", resp.AvatarURL)
+	}
+	return nil
+}
+
 // ── soul command ──
 
 func soulCmd() *cobra.Command {
@@ -1029,7 +3361,11 @@ func runSoulShow(_ *cobra.Command, _ []string) error {
 func specCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "spec",
-		Short: "Show built-in platform knowledge",
+		Short: "Show the platform knowledge in the system prompt",
+		Long: "Show the knowledge layers the system prompt is built from. A file named\n" +
+			"base.md, challenges.md, platform.md or apis.md in ~/.clawwork/knowledge/\n" +
+			"replaces the built-in layer; a running miner picks up edits before its next\n" +
+			"challenge.",
 		RunE: func(_ *cobra.Command, _ []string) error {
 			cfg, err := config.Load()
 			if err != nil {
@@ -1041,7 +3377,7 @@ func specCmd() *cobra.Command {
 				return err
 			}
 
-			fmt.Println("--- Base ---")
+			fmt.Println(layerTitle(kn, "Base", "base.md"))
 			fmt.Println(kn.Base)
 			fmt.Println()
 
@@ -1053,20 +3389,473 @@ func specCmd() *cobra.Command {
 			}
 			fmt.Println()
 
-			fmt.Println("--- Challenges ---")
-			fmt.Println(kn.Challenges)
-			fmt.Println()
+			fmt.Println(layerTitle(kn, "Challenges", "challenges.md"))
+			fmt.Println(kn.Challenges)
+			fmt.Println()
+
+			fmt.Println(layerTitle(kn, "Platform", "platform.md"))
+			fmt.Println(kn.Platform)
+			fmt.Println()
+
+			fmt.Println(layerTitle(kn, "APIs", "apis.md"))
+			fmt.Println(kn.APIs)
+
+			return nil
+		},
+	}
+}
+
+// layerTitle is a spec section header, naming the file that replaced the
+// built-in layer, if any.
+func layerTitle(kn *knowledge.Knowledge, title, file string) string {
+	if slices.Contains(kn.Overrides, file) {
+		return fmt.Sprintf("--- %s (%s) ---", title, filepath.Join(knowledge.DocsDir(), file))
+	}
+	return "--- " + title + " ---"
+}
+
+// ── debug command ──
+
+func debugCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Troubleshooting helpers",
+	}
+	verify := &cobra.Command{
+		Use:   "verify-signature",
+		Short: "Check a captured request's attestation headers against your API key",
+		Long: `Recomputes the X-Client-Signature HMAC for a captured request and reports
+whether it matches, how old the timestamp is, and whether the nonce was
+issued by this machine (a reused or foreign nonce points at a replay).`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runVerifySignature,
+	}
+	verify.Flags().String("nonce", "", "X-Client-Nonce header value")
+	verify.Flags().String("timestamp", "", "X-Client-Timestamp header value")
+	verify.Flags().String("signature", "", "X-Client-Signature header value")
+	verify.Flags().String("body", "", "Request body as sent (omit for GET)")
+	verify.Flags().String("body-file", "", "Read the request body from a file")
+	verify.Flags().String("api-key", "", "API key to verify with (default: from config)")
+	_ = verify.MarkFlagRequired("nonce")
+	_ = verify.MarkFlagRequired("timestamp")
+	_ = verify.MarkFlagRequired("signature")
+	replay := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Re-run the client's handling of a recorded inscribe session offline",
+		Long: `Feeds the responses in a recording made with 'clawwork insc --record' back
+through the inscription loop, answering challenges with the recorded answers.
+Nothing is sent to the platform or the LLM. Each request is printed next to
+the recorded one, so a client that now behaves differently (for example, a
+CHALLENGE_REQUIRED loop) shows up as a mismatch.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runReplay,
+	}
+	cmd.AddCommand(verify, replay)
+	return cmd
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	exchanges, err := api.ReadRecording(args[0])
+	if err != nil {
+		return err
+	}
+	if len(exchanges) == 0 {
+		return fmt.Errorf("%s has no recorded exchanges", args[0])
+	}
+	fmt.Printf("Replaying %d recorded exchanges from %s\n", len(exchanges), args[0])
+	sum := miner.Replay(cmd.Context(), exchanges)
+	fmt.Printf("\n%d cycle(s), %d request(s) replayed", sum.Cycles, sum.Requests)
+	if sum.Skipped > 0 {
+		fmt.Printf(", %d session start/end exchange(s) skipped", sum.Skipped)
+	}
+	fmt.Println()
+	if sum.Mismatches > 0 {
+		return fmt.Errorf("%d request(s) differ from the recording", sum.Mismatches)
+	}
+	return nil
+}
+
+func runVerifySignature(cmd *cobra.Command, _ []string) error {
+	nonce, _ := cmd.Flags().GetString("nonce")
+	timestamp, _ := cmd.Flags().GetString("timestamp")
+	signature, _ := cmd.Flags().GetString("signature")
+	body, _ := cmd.Flags().GetString("body")
+	if path, _ := cmd.Flags().GetString("body-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		body = string(data)
+	}
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	if apiKey == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		apiKey = cfg.Agent.APIKey
+	}
+	if apiKey == "" {
+		return fmt.Errorf("no API key — pass --api-key or run clawwork init")
+	}
+
+	bodyHash := api.BodyHash([]byte(body))
+	valid := api.VerifySignature(apiKey, nonce, timestamp, bodyHash, signature)
+
+	fmt.Printf("Body hash:  %s\n", bodyHash)
+	if ts, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+		at := time.Unix(ts, 0)
+		fmt.Printf("Timestamp:  %s (%s ago)\n", at.Format(time.RFC3339), time.Since(at).Round(time.Second))
+	} else {
+		fmt.Printf("Timestamp:  %q is not a unix timestamp\n", timestamp)
+	}
+	if rec, ok := api.LookupNonce(nonce); ok {
+		fmt.Printf("Nonce:      issued here %s ago for %s\n", time.Since(rec.At).Round(time.Second), rec.Request)
+		if rec.BodyHash != bodyHash {
+			fmt.Println("            (body differs from what was signed)")
+		}
+	} else {
+		fmt.Printf("Nonce:      not issued by this machine in the last %s\n", api.NonceRetention)
+	}
+
+	if !valid {
+		fmt.Println("Signature:  INVALID")
+		return fmt.Errorf("signature does not match — wrong API key, altered body, or tampered headers")
+	}
+	fmt.Println("Signature:  valid")
+	return nil
+}
+
+// ── support command ──
+
+func supportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "support",
+		Short: "Collect diagnostics for bug reports",
+	}
+	bundle := &cobra.Command{
+		Use:   "bundle",
+		Short: "Write a redacted diagnostics archive to attach to an issue",
+		Long: `Collects version info, the redacted config, state.json, recent log and
+crash files, and the last events from a running console into a zip file.
+API keys, tokens, passwords, webhook URLs and your home directory are
+scrubbed from every file. Look through the archive before sharing it.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runSupportBundle,
+	}
+	bundle.Flags().StringP("output", "o", "", "Archive path (default: clawwork-support-<time>.zip)")
+	bundle.Flags().Int("events", 200, "Recent console events to include (0 to skip)")
+	bundle.Flags().Int("port", 0, "Console port of the running miner (default: from console.addr)")
+	cmd.AddCommand(bundle)
+	return cmd
+}
+
+func runSupportBundle(cmd *cobra.Command, _ []string) error {
+	out, _ := cmd.Flags().GetString("output")
+	if out == "" {
+		out = "clawwork-support-" + time.Now().Format("20060102-150405") + ".zip"
+	}
+	events, _ := cmd.Flags().GetInt("events")
+	consoleURL, _ := web.ConsoleURL()
+	if port, _ := cmd.Flags().GetInt("port"); port > 0 {
+		consoleURL = fmt.Sprintf("http://localhost:%d", port)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Warning: config not loaded (%s) — bundling without it.\n", err)
+		cfg = nil
+	}
+	names, err := support.Write(out, support.Options{
+		Version: map[string]string{
+			"version": version, "commit": commit, "build_date": date,
+			"go_version": runtime.Version(), "os": runtime.GOOS, "arch": runtime.GOARCH,
+			"api_url": api.BaseURL, "created_at": time.Now().UTC().Format(time.RFC3339),
+		},
+		Config:     cfg,
+		Events:     events,
+		ConsoleURL: consoleURL,
+	})
+	if err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+	fmt.Printf("Support bundle written to %s\n", out)
+	for _, n := range names {
+		fmt.Printf("  %s\n", n)
+	}
+	fmt.Println("Secrets are redacted, but please review the archive before attaching it to an issue.")
+	return nil
+}
+
+// ── backup command ──
+
+func backupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Snapshot config, state and soul (scheduled under [backup] when running as a service)",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:          "now",
+		Short:        "Take a snapshot now",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			mgr := backup.New(cfg.Backup)
+			mgr.Database = storeSnapshot(cfg)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+			b, err := mgr.Snapshot(ctx)
+			if b == nil {
+				return err
+			}
+			fmt.Printf("Saved %s (%d KB)\n", b.Path, (b.Size+1023)/1024)
+			if err != nil {
+				return err
+			}
+			if mgr.Target != nil {
+				fmt.Printf("Uploaded to %s\n", mgr.Target.Name())
+			}
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List local snapshots",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			mgr := backup.New(cfg.Backup)
+			list, err := mgr.List()
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Printf("No backups in %s\n", mgr.Dir)
+				return nil
+			}
+			for _, b := range list {
+				fmt.Printf("  %s  %6d KB  %s\n", b.At.Local().Format("2006-01-02 15:04"), (b.Size+1023)/1024, b.Path)
+			}
+			if !cfg.Backup.Enabled {
+				fmt.Println("Scheduled backups are off — set enabled = true under [backup].")
+			}
+			return nil
+		},
+	})
+	return cmd
+}
+
+// ── sync command ──
+
+func syncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Replicate config, soul, state and chats to S3 or WebDAV ([sync] in config)",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:          "push",
+		Short:        "Upload changed files now",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			s, err := loadSyncer()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			defer cancel()
+			res, err := s.Push(ctx)
+			if res != nil {
+				fmt.Printf("Uploaded %d files, deleted %d from %s\n", len(res.Copied), len(res.Deleted), s.Store.Name())
+			}
+			return err
+		},
+	})
+	pull := &cobra.Command{
+		Use:   "pull",
+		Short: "Download files that differ from the store (to migrate or restore an agent)",
+		Long: `Downloads every synced file whose content differs from the local copy,
+overwriting it. Local files that are not in the store are kept. Stop the
+miner first; on a new machine, write a config with just the [sync] section
+and pull the rest.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			if info := miner.Running(); info != nil && !dryRun {
+				return fmt.Errorf("%s — stop it before pulling", info.Describe())
+			}
+			s, err := loadSyncer()
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				// Pulling may replace clawwork.db, which must not be
+				// open meanwhile; a pulled database is always taken.
+				s.Database = nil
+				miner.Store().Close()
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			defer cancel()
+			m, res, err := s.Pull(ctx, dryRun)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Store: %s (pushed %s from %s)\n", s.Store.Name(), m.Pushed.Local().Format("2006-01-02 15:04"), m.Host)
+			for _, p := range res.Copied {
+				fmt.Printf("  %s\n", p)
+			}
+			switch {
+			case len(res.Copied) == 0:
+				fmt.Println("Already up to date.")
+			case dryRun:
+				fmt.Printf("%d files would be downloaded.\n", len(res.Copied))
+			default:
+				fmt.Printf("Downloaded %d files.\n", len(res.Copied))
+			}
+			return nil
+		},
+	}
+	pull.Flags().Bool("dry-run", false, "List the files that would be downloaded")
+	cmd.AddCommand(pull)
+	cmd.AddCommand(&cobra.Command{
+		Use:          "status",
+		Short:        "Show local changes since the last push or pull",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			s, err := loadSyncer()
+			if err != nil {
+				return err
+			}
+			changed, last, err := s.Pending()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Store:     %s\n", s.Store.Name())
+			if last.Pushed.IsZero() {
+				fmt.Println("Last sync: never")
+			} else {
+				fmt.Printf("Last sync: %s\n", last.Pushed.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Printf("Changed:   %d files\n", len(changed))
+			for _, p := range changed {
+				fmt.Printf("  %s\n", p)
+			}
+			return nil
+		},
+	})
+	return cmd
+}
+
+func loadSyncer() (*datasync.Syncer, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Sync.Validate(); err != nil {
+		return nil, err
+	}
+	s, err := datasync.New(cfg.Sync)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("sync is not configured — set backend under [sync] in %s", config.Path())
+	}
+	s.Database = storeSnapshot(cfg)
+	return s, nil
+}
+
+// ── devserver command ──
+
+func devserverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "devserver",
+		Short: "Run a local mock ClawWork platform for testing",
+		Long: `Serves /skill/inscribe, /skill/status, /skill/claim and /skill/social from
+memory so providers, prompts and the web console can be exercised end-to-end
+without touching production trust scores.
+
+Any API key is accepted; unknown keys get a fresh agent. Point a devenv build
+at it with CLAWWORK_API_URL (see "make build-devenv").`,
+		Args: cobra.NoArgs,
+		RunE: runDevserver,
+	}
+	cmd.Flags().String("addr", "127.0.0.1:8788", "Listen address")
+	cmd.Flags().Duration("cooldown", 0, "Minimum gap between inscriptions per agent")
+	cmd.Flags().Duration("moment-cooldown", 0, "Minimum gap between moment posts per agent")
+	cmd.Flags().Duration("challenge-ttl", 5*time.Minute, "How long a challenge stays answerable")
+	cmd.Flags().Float64("fail-rate", 0, "Fraction of correct answers judged wrong anyway (0-1)")
+	cmd.Flags().Float64("error-rate", 0, "Fraction of inscriptions answered with --error-code (0-1)")
+	cmd.Flags().String("error-code", "RATE_LIMITED", "Error code for injected failures")
+	cmd.Flags().Float64("hit-rate", 0, "Fraction of inscriptions that win the NFT (0-1)")
+	cmd.Flags().Int("daily-limit", 0, "Inscriptions per agent per day (0 = unlimited)")
+	cmd.Flags().Bool("unclaimed", false, "Require new agents to run `clawwork claim` first")
+	cmd.Flags().String("challenges", "", "JSON file of [{\"prompt\",\"answer\"}] challenges")
+	cmd.Flags().Uint64("seed", 0, "Random seed for reproducible runs (0 = random)")
+	return cmd
+}
 
-			fmt.Println("--- Platform ---")
-			fmt.Println(kn.Platform)
-			fmt.Println()
+func runDevserver(cmd *cobra.Command, _ []string) error {
+	f := cmd.Flags()
+	addr, _ := f.GetString("addr")
+	var b devserver.Behavior
+	b.Cooldown, _ = f.GetDuration("cooldown")
+	b.MomentCooldown, _ = f.GetDuration("moment-cooldown")
+	b.ChallengeTTL, _ = f.GetDuration("challenge-ttl")
+	b.FailRate, _ = f.GetFloat64("fail-rate")
+	b.ErrorRate, _ = f.GetFloat64("error-rate")
+	b.ErrorCode, _ = f.GetString("error-code")
+	b.HitRate, _ = f.GetFloat64("hit-rate")
+	b.DailyLimit, _ = f.GetInt("daily-limit")
+	b.Unclaimed, _ = f.GetBool("unclaimed")
+	b.Seed, _ = f.GetUint64("seed")
+	for name, v := range map[string]float64{"fail-rate": b.FailRate, "error-rate": b.ErrorRate, "hit-rate": b.HitRate} {
+		if v < 0 || v > 1 {
+			return fmt.Errorf("--%s must be between 0 and 1", name)
+		}
+	}
+	if path, _ := f.GetString("challenges"); path != "" {
+		puzzles, err := devserver.LoadPuzzles(path)
+		if err != nil {
+			return err
+		}
+		b.Puzzles = puzzles
+	}
 
-			fmt.Println("--- APIs ---")
-			fmt.Println(kn.APIs)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: devserver.New(b, os.Stdout).Handler(), ReadHeaderTimeout: 10 * time.Second}
 
-			return nil
-		},
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	url := "http://" + ln.Addr().String()
+	fmt.Printf("Mock ClawWork platform on %s — nothing here touches production.\n", url)
+	fmt.Printf("Point a devenv build at it: CLAWWORK_API_URL=%s bin/clawwork-dev insc\n", url)
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return nil
 }
 
 // ── service management commands ──
@@ -1104,11 +3893,13 @@ func stopCmd() *cobra.Command {
 }
 
 func restartCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "restart",
 		Short: "Restart the background service",
 		RunE:  runRestart,
 	}
+	cmd.Flags().Bool("if-updated", false, "Only restart if this binary is newer than the running service")
+	return cmd
 }
 
 func runInstall(_ *cobra.Command, _ []string) error {
@@ -1122,6 +3913,11 @@ func runInstall(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// The service can't mine while a terminal miner holds the lock.
+	if info := miner.Running(); info != nil && info.Mode != miner.ModeService {
+		return fmt.Errorf("%s is mining — stop it first (Ctrl+C in its terminal), then install", info.Describe())
+	}
+
 	// Check if already installed.
 	st, _ := mgr.Status()
 	if st != nil && st.Installed {
@@ -1189,7 +3985,7 @@ func runStop(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func runRestart(_ *cobra.Command, _ []string) error {
+func runRestart(cmd *cobra.Command, _ []string) error {
 	mgr, err := daemon.New()
 	if err != nil {
 		return err
@@ -1200,9 +3996,555 @@ func runRestart(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("service not installed — run 'clawwork install' first")
 	}
 
+	if ifUpdated, _ := cmd.Flags().GetBool("if-updated"); ifUpdated {
+		// The running service records its version in the lock file; this
+		// process is the binary currently on disk.
+		info := miner.Running()
+		if info == nil {
+			fmt.Println("Service is not running — nothing to restart.")
+			return nil
+		}
+		running := info.Version
+		if running == "" {
+			running = "unknown (pre-" + version + ")"
+		} else if running == version || !updater.IsNewer(version, running) {
+			fmt.Printf("Service already runs %s — no restart needed.\n", running)
+			return nil
+		}
+		fmt.Printf("Service runs %s, binary is %s — restarting.\n", running, version)
+	}
+
 	if err := mgr.Restart(); err != nil {
 		return fmt.Errorf("restart failed: %w", err)
 	}
 	fmt.Println("Service restarted.")
 	return nil
 }
+
+// ── attach / detach commands ──
+
+func attachCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attach",
+		Short: "Stop the background service and mine in this terminal (hand back with detach)",
+		RunE:  runAttach,
+	}
+	cmd.Flags().BoolP("verbose", "v", false, "Verbose output")
+	cmd.Flags().Bool("no-web", false, "Disable web console")
+	cmd.Flags().IntP("port", "p", 0, "Web console port (default: auto from 2526)")
+	cmd.Flags().String("bind", "", "Web console address, e.g. 0.0.0.0 or a Tailscale IP for HTTPS remote access (default: web.listen_addr, localhost)")
+	// The service ends its session on stop; takeover covers the case
+	// where it couldn't.
+	cmd.Flags().Bool("takeover", true, "")
+	_ = cmd.Flags().MarkHidden("takeover")
+	return cmd
+}
+
+func detachCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "detach",
+		Short: "Stop a foreground miner started with attach and restart the background service",
+		RunE:  runDetach,
+	}
+}
+
+func runAttach(cmd *cobra.Command, _ []string) error {
+	mgr, err := daemon.New()
+	if err != nil {
+		return err
+	}
+	st, _ := mgr.Status()
+	if st == nil || !st.Installed {
+		return fmt.Errorf("service not installed — use 'clawwork insc' to mine in the foreground")
+	}
+	if info := miner.Running(); info != nil && info.Mode == miner.ModeForeground {
+		return fmt.Errorf("already attached: %s", info.Describe())
+	}
+
+	if st.Running {
+		fmt.Println("Stopping the background service...")
+		if err := mgr.Stop(); err != nil {
+			return fmt.Errorf("stop failed: %w", err)
+		}
+	}
+	if err := miner.WaitReleased(time.Duration(daemon.StopTimeout()) * time.Second); err != nil {
+		return fmt.Errorf("service did not release the miner: %w", err)
+	}
+
+	fmt.Println("Attached. Press Ctrl+C to stop, then 'clawwork detach' to hand mining back to the service.")
+	fmt.Println()
+	return runInsc(cmd, nil)
+}
+
+func runDetach(_ *cobra.Command, _ []string) error {
+	mgr, err := daemon.New()
+	if err != nil {
+		return err
+	}
+	st, _ := mgr.Status()
+	if st == nil || !st.Installed {
+		return fmt.Errorf("service not installed — run 'clawwork install' first")
+	}
+	if st.Running {
+		fmt.Println("Service is already running.")
+		return nil
+	}
+
+	// A foreground miner still holding the lock is asked to finish its
+	// current inscription and exit, as Ctrl+C would.
+	if info := miner.Running(); info != nil {
+		fmt.Printf("Stopping %s...\n", info.Describe())
+		if err := info.Stop(); err != nil {
+			return fmt.Errorf("stop PID %d: %w", info.PID, err)
+		}
+		if err := miner.WaitReleased(time.Duration(daemon.StopTimeout()) * time.Second); err != nil {
+			return err
+		}
+	}
+
+	if err := mgr.Start(); err != nil {
+		return fmt.Errorf("start failed: %w", err)
+	}
+	fmt.Println("Detached. The background service is mining again.")
+	return nil
+}
+
+// ── ctl command ──
+
+func ctlCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ctl",
+		Short: "Control the miner running on this machine without the web console",
+		Long: `Pauses, resumes or retargets the running miner through its console API.
+The miner is found through ~/.clawwork/mine.lock, whether it runs as the
+service or in a terminal; it needs its web console (not --no-web).`,
+	}
+	sub := func(use, short string, args cobra.PositionalArgs) *cobra.Command {
+		return &cobra.Command{Use: use, Short: short, Args: args, SilenceUsage: true, RunE: runCtl}
+	}
+	cmd.AddCommand(
+		sub("status", "Show whether the running miner is paused and which token it mines", cobra.NoArgs),
+		sub("pause", "Pause mining (the current inscription finishes first)", cobra.NoArgs),
+		sub("resume", "Resume mining", cobra.NoArgs),
+		sub("token <id>", "Switch the token mined from the next cycle", cobra.ExactArgs(1)),
+	)
+	return cmd
+}
+
+func runCtl(cmd *cobra.Command, args []string) error {
+	info := miner.Running()
+	if info == nil {
+		return fmt.Errorf("no miner is running")
+	}
+	if info.Port == 0 {
+		return fmt.Errorf("%s has no web console (started with --no-web?) — ctl needs it", info.Describe())
+	}
+	rc := &web.RemoteClient{Host: info.ConsoleURL()}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if cmd.Name() == "token" {
+		id, err := strconv.Atoi(args[0])
+		if err != nil || id < 25 || id > 1024 {
+			return fmt.Errorf("token ID must be a number between 25 and 1024")
+		}
+		if err := rc.SwitchToken(ctx, id); err != nil {
+			return err
+		}
+		fmt.Printf("Token switched to #%d (effective next cycle)\n", id)
+		return nil
+	}
+	return remoteAction(ctx, rc, cmd.Name())
+}
+
+// ── console command ──
+
+func consoleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "console",
+		Short: "Find the running miner's web console",
+	}
+	open := &cobra.Command{
+		Use:          "open",
+		Short:        "Open the web console in a browser (address from ~/.clawwork/console.addr)",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runConsoleOpen,
+	}
+	open.Flags().Bool("print", false, "Only print the URL")
+	cmd.AddCommand(open)
+	return cmd
+}
+
+func runConsoleOpen(cmd *cobra.Command, _ []string) error {
+	url, err := web.ConsoleURL()
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", strings.TrimPrefix(url, "http://"), 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("console at %s is not answering — is the miner still running? (%s may be stale)", url, web.AddrPath())
+	}
+	conn.Close()
+
+	if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+		fmt.Println(url)
+		return nil
+	}
+	fmt.Printf("Opening %s\n", url)
+	if err := openBrowser(url); err != nil {
+		return fmt.Errorf("could not launch a browser (%w) — open %s yourself", err, url)
+	}
+	return nil
+}
+
+// openBrowser asks the desktop to open url.
+func openBrowser(url string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", url)
+	case "windows":
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		c = exec.Command("xdg-open", url)
+	}
+	return c.Start()
+}
+
+// ── chat command ──
+
+func chatCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chat",
+		Short: "Manage web console chat history",
+	}
+	archive := &cobra.Command{
+		Use:   "archive",
+		Short: "Export old chat sessions to a compressed file and remove them",
+		Long: `Writes every chat session not updated for --days days, including turns
+already moved to chats/archive/, to one gzipped JSONL file (one message per
+line), then removes those sessions and their tool workspaces.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runChatArchive,
+	}
+	archive.Flags().Int("days", 30, "Archive sessions idle for at least this many days")
+	archive.Flags().StringP("output", "o", "", "Export file (default: clawwork-chats-<date>.jsonl.gz)")
+	archive.Flags().Bool("dry-run", false, "List the sessions that would be archived")
+	cmd.AddCommand(archive)
+	return cmd
+}
+
+func runChatArchive(cmd *cobra.Command, _ []string) error {
+	days, _ := cmd.Flags().GetInt("days")
+	if days < 0 {
+		return fmt.Errorf("--days must not be negative")
+	}
+	out, _ := cmd.Flags().GetString("output")
+	if out == "" {
+		out = "clawwork-chats-" + time.Now().Format("20060102-150405") + ".jsonl.gz"
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	sessions, err := web.ExportSessions(web.ChatsDir(), cutoff, out, dryRun)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		fmt.Printf("No chat sessions idle for %d days.\n", days)
+		return nil
+	}
+	for _, m := range sessions {
+		title := m.Title
+		if title == "" {
+			title = "(archived)"
+		}
+		fmt.Printf("  %s  %s  %s\n", m.UpdatedAt.Local().Format("2006-01-02"), m.ID, title)
+	}
+	if dryRun {
+		fmt.Printf("%d sessions would be archived.\n", len(sessions))
+		return nil
+	}
+	fmt.Printf("Archived %d sessions to %s\n", len(sessions), out)
+	return nil
+}
+
+// ── trash command ──
+
+// trashOf returns where chat tool deletions go, or nil when
+// web.trash_days is 0.
+func trashOf(c config.WebConfig) *tools.Trash {
+	if c.TrashDays <= 0 {
+		return nil
+	}
+	return &tools.Trash{Dir: config.TrashDir(), TTL: time.Duration(c.TrashDays) * 24 * time.Hour}
+}
+
+func trashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List and restore files the chat agent deleted",
+		Long: `The chat agent's filesystem tool moves what it deletes to ~/.clawwork/trash/
+instead of removing it. Entries older than web.trash_days are purged.
+Files removed with shell commands are not covered.`,
+		Args: cobra.NoArgs,
+		RunE: runTrashList,
+	}
+	restore := &cobra.Command{
+		Use:          "restore <id>",
+		Short:        "Move an entry back to where it was deleted from",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, _ := cmd.Flags().GetString("to")
+			dest, err := openTrash().Restore(args[0], to)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Restored %s\n", dest)
+			return nil
+		},
+	}
+	restore.Flags().String("to", "", "Restore to this path instead")
+	cmd.AddCommand(
+		restore,
+		&cobra.Command{
+			Use:          "rm <id>...",
+			Short:        "Delete entries permanently",
+			Args:         cobra.MinimumNArgs(1),
+			SilenceUsage: true,
+			RunE: func(_ *cobra.Command, args []string) error {
+				t := openTrash()
+				for _, id := range args {
+					if err := t.Remove(id); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:          "empty",
+			Short:        "Delete everything in the trash permanently",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				t := openTrash()
+				items, err := t.List()
+				if err != nil {
+					return err
+				}
+				for _, item := range items {
+					if err := t.Remove(item.ID); err != nil {
+						return err
+					}
+				}
+				fmt.Printf("Removed %d entries.\n", len(items))
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+// openTrash returns the trash, also when web.trash_days is 0 so entries
+// from before can still be restored.
+func openTrash() *tools.Trash {
+	if cfg, err := config.Load(); err == nil {
+		if t := trashOf(cfg.Web); t != nil {
+			return t
+		}
+	}
+	return &tools.Trash{Dir: config.TrashDir()}
+}
+
+func runTrashList(_ *cobra.Command, _ []string) error {
+	t := openTrash()
+	t.Purge()
+	items, err := t.List()
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+	for _, item := range items {
+		size := fmt.Sprintf("%d B", item.Size)
+		if item.IsDir {
+			size = "dir"
+		}
+		fmt.Printf("  %s  %s  %-8s %s\n", item.ID, item.DeletedAt.Local().Format("2006-01-02 15:04"), size, item.Path)
+	}
+	if t.TTL > 0 {
+		fmt.Printf("Entries are purged after %d days. Restore one with: clawwork trash restore <id>\n", int(t.TTL.Hours()/24))
+	} else {
+		fmt.Println("Restore one with: clawwork trash restore <id>")
+	}
+	return nil
+}
+
+// ── storage command ──
+
+// openStore points state, goal and history at the [storage] backend. A
+// backend that can't be opened is fatal: falling back to the files would
+// split the agent's data in two.
+func openStore(cfg *config.Config) {
+	if cfg.Storage.Backend == "" || cfg.Storage.Backend == store.BackendFiles {
+		return
+	}
+	b, err := store.Open(cfg.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: storage: %s\n", err)
+		os.Exit(1)
+	}
+	miner.SetStore(b)
+}
+
+// storeSnapshot returns how backups and sync copy the SQLite store: nil
+// with the files backend, or for a database kept outside the data
+// directory, which is backed up wherever it lives.
+func storeSnapshot(cfg *config.Config) func(string) error {
+	if cfg.Storage.Path != "" {
+		return nil
+	}
+	if s, ok := miner.Store().(store.Snapshotter); ok {
+		return s.Snapshot
+	}
+	return nil
+}
+
+func storageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Show where state and history are kept ([storage] in config)",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			b := miner.Store()
+			entries, err := miner.ReadHistory(b, time.Time{})
+			if err != nil {
+				return err
+			}
+			state := miner.LoadState()
+			fmt.Printf("Backend: %s\n", b.Name())
+			fmt.Printf("State:   %d inscriptions, %d CW\n", state.TotalInscriptions, state.TotalCWEarned)
+			fmt.Printf("History: %d attempts\n", len(entries))
+			return nil
+		},
+	}
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy state and history from the JSON files into the configured backend",
+		Long: `Copies state, goal, review hold and inscription history from the JSON
+files in the data directory into the backend set in [storage], so an agent
+keeps its totals when it moves to SQLite. The files are left in place.
+Stop the miner first.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if info := miner.Running(); info != nil {
+				return fmt.Errorf("%s — stop it before migrating", info.Describe())
+			}
+			dst := miner.Store()
+			if _, ok := dst.(*store.Files); ok {
+				return fmt.Errorf("[storage] backend is files already — set backend = \"sqlite\" first")
+			}
+			force, _ := cmd.Flags().GetBool("force")
+			docs, records, err := miner.CopyStore(dst, store.NewFiles(config.Dir()), force)
+			if errors.Is(err, miner.ErrStoreInUse) {
+				return fmt.Errorf("%s: %w — use --force to copy anyway (history would be appended twice)", dst.Name(), err)
+			}
+			fmt.Printf("Copied %d documents and %d history records into %s\n", docs, records, dst.Name())
+			return err
+		},
+	}
+	migrate.Flags().Bool("force", false, "Migrate even if the backend already holds state")
+	cmd.AddCommand(migrate)
+	return cmd
+}
+
+// ── remote command ──
+
+func remoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "remote status|pause|resume",
+		Short:     "Control clawwork instances on other machines (see web.remote_listen)",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"status", "pause", "resume"},
+		RunE:      runRemote,
+	}
+	cmd.Flags().StringSlice("host", nil, "Instance address host:port (repeat or comma-separate for several)")
+	cmd.Flags().String("token", "", "Remote token (default: $CLAWWORK_REMOTE_TOKEN)")
+	cmd.Flags().String("fingerprint", "", "SHA-256 of a self-signed certificate, as printed at startup (default: $CLAWWORK_REMOTE_FINGERPRINT)")
+	return cmd
+}
+
+func runRemote(cmd *cobra.Command, args []string) error {
+	hosts, _ := cmd.Flags().GetStringSlice("host")
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("CLAWWORK_REMOTE_TOKEN")
+	}
+	fingerprint, _ := cmd.Flags().GetString("fingerprint")
+	if fingerprint == "" {
+		fingerprint = os.Getenv("CLAWWORK_REMOTE_FINGERPRINT")
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("--host is required")
+	}
+	if token == "" {
+		return fmt.Errorf("--token (or CLAWWORK_REMOTE_TOKEN) is required")
+	}
+	action := args[0]
+	if action != "status" && action != "pause" && action != "resume" {
+		return fmt.Errorf("unknown action %q — use status, pause or resume", action)
+	}
+
+	failed := 0
+	for _, host := range hosts {
+		rc := &web.RemoteClient{Host: host, Token: token, Fingerprint: fingerprint}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		err := remoteAction(ctx, rc, action)
+		cancel()
+		if err != nil {
+			fmt.Printf("%-21s  error: %s\n", host, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d hosts failed", failed, len(hosts))
+	}
+	return nil
+}
+
+func remoteAction(ctx context.Context, rc *web.RemoteClient, action string) error {
+	switch action {
+	case "pause":
+		if err := rc.Pause(ctx); err != nil {
+			return err
+		}
+	case "resume":
+		if err := rc.Resume(ctx); err != nil {
+			return err
+		}
+	}
+	st, err := rc.State(ctx)
+	if err != nil {
+		return err
+	}
+	status := "mining"
+	if st.Paused {
+		status = "paused"
+	}
+	line := fmt.Sprintf("%-21s  %-16s  %-7s  token #%d", rc.Host, st.AgentName, status, st.TokenID)
+	if st.Goal != nil {
+		line += fmt.Sprintf("  goal %.1f%%", st.Goal.Percent)
+	}
+	if st.Penalties.LostCW > 0 {
+		line += fmt.Sprintf("  lost %d CW", st.Penalties.LostCW)
+	}
+	fmt.Println(line)
+	return nil
+}
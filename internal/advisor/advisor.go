@@ -0,0 +1,137 @@
+// Package advisor analyzes recent challenge failures and trust score history
+// with the agent's LLM and suggests configuration changes to recover trust.
+package advisor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/llm"
+	"github.com/clawplaza/clawwork-cli/internal/miner"
+)
+
+// Input is everything the advisor knows about the agent's recent performance.
+type Input struct {
+	Provider string
+	Model    string
+
+	MaxTokens        int
+	ChallengeRetries int
+	LLMRetries       int
+
+	ChallengesPassed int
+	ChallengesFailed int
+	AnswerP50        time.Duration
+	AnswerP95        time.Duration
+
+	Trust    []miner.TrustSample
+	Failures []miner.ChallengeFailure
+}
+
+// FromState collects advisor input from miner state and the LLM config.
+func FromState(state *miner.State, cfg config.LLMConfig) Input {
+	challengeRetries, llmRetries := miner.RetryLimits()
+	in := Input{
+		Provider:         cfg.Provider,
+		Model:            cfg.Model,
+		MaxTokens:        miner.AnswerMaxTokens,
+		ChallengeRetries: challengeRetries,
+		LLMRetries:       llmRetries,
+		ChallengesPassed: state.ChallengesPassed,
+		ChallengesFailed: state.ChallengesFailed,
+		Trust:            state.TrustTrend(),
+		Failures:         state.RecentFailures(),
+	}
+	if st, ok := state.LatencySummary()[miner.PhaseLLM]; ok {
+		in.AnswerP50, in.AnswerP95 = st.P50, st.P95
+	}
+	return in
+}
+
+// HasData reports whether there is anything worth analyzing.
+func (in Input) HasData() bool {
+	return len(in.Failures) > 0 || len(in.Trust) > 1
+}
+
+// SystemPrompt is the system prompt for advisor LLM calls.
+func SystemPrompt() string {
+	return "You are an operations advisor for an AI agent that answers verification challenges to earn rewards. Be concrete and brief."
+}
+
+// BuildPrompt renders the analysis request for the LLM.
+func BuildPrompt(in Input) string {
+	var sb strings.Builder
+	sb.WriteString("The agent's trust score depends on answering challenges correctly. Failed challenges lower trust and reduce earnings.\n\n")
+
+	sb.WriteString("Current setup:\n")
+	fmt.Fprintf(&sb, "- LLM provider: %s, model: %s\n", in.Provider, orNone(in.Model))
+	fmt.Fprintf(&sb, "- Answer max_tokens: %d\n", in.MaxTokens)
+	fmt.Fprintf(&sb, "- Retry policy: %d challenge retries per cycle, %d LLM retries per answer\n", in.ChallengeRetries, in.LLMRetries)
+	fmt.Fprintf(&sb, "- Challenges: %d passed, %d failed\n", in.ChallengesPassed, in.ChallengesFailed)
+	if in.AnswerP50 > 0 {
+		fmt.Fprintf(&sb, "- LLM answer latency: p50 %.1fs, p95 %.1fs\n", in.AnswerP50.Seconds(), in.AnswerP95.Seconds())
+	}
+
+	if len(in.Trust) > 0 {
+		sb.WriteString("\nTrust score history (oldest first):\n")
+		for _, t := range sampleTrust(in.Trust, 12) {
+			fmt.Fprintf(&sb, "- %s: %d\n", t.At.UTC().Format("2006-01-02 15:04"), t.Score)
+		}
+	}
+
+	if len(in.Failures) > 0 {
+		sb.WriteString("\nRecent failed challenges:\n")
+		for i, f := range in.Failures {
+			fmt.Fprintf(&sb, "\n#%d (%s)\nChallenge: %s\nAnswer given: %s\n", i+1, f.At.UTC().Format("2006-01-02 15:04"), f.Prompt, f.Answer)
+			if f.Language != "" {
+				fmt.Fprintf(&sb, "Challenge language: %s\n", f.Language)
+			}
+			if f.Message != "" {
+				fmt.Fprintf(&sb, "Server message: %s\n", f.Message)
+			}
+			if f.Hint != "" {
+				fmt.Fprintf(&sb, "Server hint: %s\n", f.Hint)
+			}
+		}
+	}
+
+	sb.WriteString(`
+Analyze why challenges are failing (wrong format, truncated answers, reasoning errors, timeouts, language mismatch, etc.).
+Then give at most 5 concrete recommendations, most impactful first. Prefer actionable settings changes such as
+switching to a stronger model, raising max_tokens, or changing the retry policy. Reply in plain text:
+a one-line diagnosis, then a numbered list of recommendations. No markdown headings.`)
+	return sb.String()
+}
+
+// Advise asks the LLM for recommendations based on in.
+func Advise(ctx context.Context, p llm.Provider, in Input) (string, error) {
+	answer, err := p.Answer(ctx, BuildPrompt(in))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// sampleTrust thins the trust history to at most n evenly spaced samples,
+// always keeping the newest one.
+func sampleTrust(trust []miner.TrustSample, n int) []miner.TrustSample {
+	if len(trust) <= n {
+		return trust
+	}
+	out := make([]miner.TrustSample, 0, n)
+	step := float64(len(trust)-1) / float64(n-1)
+	for i := 0; i < n; i++ {
+		out = append(out, trust[int(float64(i)*step+0.5)])
+	}
+	return out
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(default)"
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+// MaxAvatarSize is the platform's avatar upload limit.
+const MaxAvatarSize = 512 * 1024
+
+// ProfileResponse is the response from POST /skill/profile.
+type ProfileResponse struct {
+	OK          bool   `json:"ok"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// UpdateProfile changes the agent's display name.
+// Returns ErrNotSupported if the server does not allow renames via the API.
+func (c *Client) UpdateProfile(ctx context.Context, displayName string) (*ProfileResponse, error) {
+	body, err := json.Marshal(map[string]string{"display_name": displayName})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", BaseURL+"/skill/profile", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", userAgent())
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+		signRequest(httpReq, c.apiKey, body)
+	}
+	return c.doProfile(httpReq)
+}
+
+// UploadAvatar uploads a new avatar image. The endpoint requires the owner's
+// JWT (from the ClawWork website) in addition to the agent API key.
+func (c *Client) UploadAvatar(ctx context.Context, ownerJWT, filename, contentType string, image []byte) (*ProfileResponse, error) {
+	if len(image) > MaxAvatarSize {
+		return nil, fmt.Errorf("avatar is %dKB, limit is %dKB", len(image)/1024, MaxAvatarSize/1024)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="avatar"; filename=%q`, filename))
+	h.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return nil, fmt.Errorf("build form: %w", err)
+	}
+	if _, err := part.Write(image); err != nil {
+		return nil, fmt.Errorf("build form: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("build form: %w", err)
+	}
+	body := buf.Bytes()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", BaseURL+"/skill/upload-avatar", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", mw.FormDataContentType())
+	httpReq.Header.Set("User-Agent", userAgent())
+	httpReq.Header.Set("Authorization", "Bearer "+ownerJWT)
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+		signRequest(httpReq, c.apiKey, body)
+	}
+	return c.doProfile(httpReq)
+}
+
+func (c *Client) doProfile(httpReq *http.Request) (*ProfileResponse, error) {
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if httpResp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotSupported
+	}
+
+	if httpResp.StatusCode >= 400 {
+		return nil, newAPIError(httpResp, respBody)
+	}
+
+	var resp ProfileResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("parse response (status %d): %s", httpResp.StatusCode, truncate(string(respBody), 200))
+	}
+	if resp.Error != "" {
+		return nil, newAPIError(httpResp, respBody)
+	}
+	return &resp, nil
+}
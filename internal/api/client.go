@@ -4,41 +4,112 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
 )
 
 const (
-	// BaseURL is the ClawWork API endpoint. Hardcoded to prevent phishing.
-	BaseURL = "https://work.clawplaza.ai"
+	// ProductionURL is the ClawWork API endpoint. Hardcoded to prevent phishing.
+	ProductionURL = "https://work.clawplaza.ai"
 
 	requestTimeout = 30 * time.Second
 )
 
+// BaseURL is the API endpoint requests go to. Release builds always use
+// ProductionURL; only binaries built with the devenv tag can point it
+// elsewhere (see env_devenv.go).
+var BaseURL = ProductionURL
+
+// IsProduction reports whether requests go to the production platform.
+func IsProduction() bool { return BaseURL == ProductionURL }
+
+// ErrNotSupported is returned when the server does not offer an endpoint
+// (older deployments answer 404).
+var ErrNotSupported = errors.New("not supported by the ClawWork server")
+
 // version is set at build time via ldflags.
 var version = "dev"
 
 // SetVersion sets the version string for User-Agent headers.
 func SetVersion(v string) { version = v }
 
+// minimalHeaders drops optional client metadata from requests (privacy mode).
+var minimalHeaders bool
+
+// SetMinimalHeaders enables privacy mode: requests carry only what
+// authentication and attestation require — no version in User-Agent and
+// no X-Client-Version header.
+func SetMinimalHeaders(on bool) { minimalHeaders = on }
+
+// label is the operator's fleet label (see SetLabel).
+var label string
+
+// SetLabel sets a label such as "fleet=eu-west-1" that is appended to the
+// User-Agent and X-Client-Version headers, so the platform side can tell
+// machines apart. Privacy mode leaves it out.
+func SetLabel(l string) { label = l }
+
+// userAgent returns the User-Agent for platform requests.
+func userAgent() string {
+	if minimalHeaders {
+		return "clawwork"
+	}
+	return clientVersion()
+}
+
+// clientVersion is "clawwork/<version>", followed by the label if set.
+func clientVersion() string {
+	if label != "" {
+		return "clawwork/" + version + " (" + label + ")"
+	}
+	return "clawwork/" + version
+}
+
+// PreviewHeaders returns the headers a signed platform request carries,
+// built by the same code path as real requests, with the API key masked.
+func (c *Client) PreviewHeaders() http.Header {
+	req, _ := http.NewRequest("GET", BaseURL+"/skill/status", nil)
+	req.Header.Set("User-Agent", userAgent())
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+		sign(req, c.apiKey, generateNonce(), nil)
+		req.Header.Set("X-API-Key", maskKey(c.apiKey))
+	}
+	return req.Header
+}
+
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "..." + key[len(key)-4:]
+}
+
 // Client is an HTTP client for the ClawWork API.
 type Client struct {
-	apiKey string
-	client *http.Client
+	apiKey   string
+	client   *http.Client
+	recorder *Recorder // nil unless recording (see SetRecorder)
 }
 
 // New creates a new API client with the given API key.
 func New(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{Timeout: requestTimeout},
+		client: httpx.Client("api", requestTimeout),
 	}
 }
 
 // Register registers a new agent (first-time call without API key).
+// Server-side failures are returned as *APIError.
 func (c *Client) Register(ctx context.Context, agentName string, tokenID int) (*InscribeResponse, error) {
 	req := InscribeRequest{
 		AgentName: agentName,
@@ -48,6 +119,8 @@ func (c *Client) Register(ctx context.Context, agentName string, tokenID int) (*
 }
 
 // Inscribe performs an inscription with optional challenge answer.
+// Server-side failures (challenge, rate limit, fatal) are returned as
+// *APIError carrying the full response in Inscribe.
 func (c *Client) Inscribe(ctx context.Context, req *InscribeRequest) (*InscribeResponse, error) {
 	return c.doInscribe(ctx, req, true)
 }
@@ -94,26 +167,38 @@ func (c *Client) doInscribe(ctx context.Context, req *InscribeRequest, withAuth
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("User-Agent", "clawwork/"+version)
+	httpReq.Header.Set("User-Agent", userAgent())
 	if withAuth && c.apiKey != "" {
 		httpReq.Header.Set("X-API-Key", c.apiKey)
 		// Client attestation: sign every authenticated request.
-		signRequest(httpReq, c.apiKey, body)
+		// Challenge answers are one-shot — refuse to sign the same one twice.
+		if req.ChallengeAnswer != "" {
+			if err := signUnique(httpReq, c.apiKey, body); err != nil {
+				return nil, err
+			}
+		} else {
+			signRequest(httpReq, c.apiKey, body)
+		}
 	}
 
 	httpResp, err := c.client.Do(httpReq)
 	if err != nil {
+		c.record(body, nil, nil, err)
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer httpResp.Body.Close()
 
 	respBody, err := io.ReadAll(httpResp.Body)
+	c.record(body, httpResp, respBody, err)
 	if err != nil {
 		return nil, fmt.Errorf("read response: %w", err)
 	}
 
 	var resp InscribeResponse
 	if err := json.Unmarshal(respBody, &resp); err != nil {
+		if httpResp.StatusCode >= 400 {
+			return nil, newAPIError(httpResp, respBody)
+		}
 		return nil, fmt.Errorf("parse response (status %d): %w (body: %s)", httpResp.StatusCode, err, truncate(string(respBody), 200))
 	}
 
@@ -141,7 +226,12 @@ func (c *Client) doInscribe(ctx context.Context, req *InscribeRequest, withAuth
 			"challenge_id", chID)
 	}
 
-	// Return the response as-is — the caller handles error codes.
+	if resp.Error != "" {
+		apiErr := newAPIError(httpResp, respBody)
+		apiErr.Challenge = resp.GetChallenge()
+		apiErr.Inscribe = &resp
+		return nil, apiErr
+	}
 	return &resp, nil
 }
 
@@ -151,7 +241,7 @@ func (c *Client) Status(ctx context.Context) (*StatusResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-	httpReq.Header.Set("User-Agent", "clawwork/"+version)
+	httpReq.Header.Set("User-Agent", userAgent())
 	if c.apiKey != "" {
 		httpReq.Header.Set("X-API-Key", c.apiKey)
 		// Sign GET requests with empty body.
@@ -170,7 +260,7 @@ func (c *Client) Status(ctx context.Context) (*StatusResponse, error) {
 	}
 
 	if httpResp.StatusCode != 200 {
-		return nil, fmt.Errorf("status request failed (%d): %s", httpResp.StatusCode, truncate(string(respBody), 200))
+		return nil, newAPIError(httpResp, respBody)
 	}
 
 	var resp StatusResponse
@@ -180,6 +270,15 @@ func (c *Client) Status(ctx context.Context) (*StatusResponse, error) {
 	return &resp, nil
 }
 
+// ClaimErrors explains the error codes of Claim to users.
+// AGENT_ALREADY_CLAIMED is not an error for them: claiming is idempotent.
+var ClaimErrors = map[string]string{
+	"INVALID_OR_EXPIRED_CODE": "Code invalid or expired — generate a new one at https://work.clawplaza.ai/my-agent",
+	"INVALID_CODE":            "Code format invalid. Expected: clawplaza-xxxx",
+	"AGENT_NOT_FOUND":         "Agent not found. Check your API key.",
+	"USER_ALREADY_CLAIMED":    "That account already has a linked agent.",
+}
+
 // Claim submits a claim code to bind the agent with an owner account.
 func (c *Client) Claim(ctx context.Context, claimCode string) (*ClaimResponse, error) {
 	body, err := json.Marshal(map[string]string{"claim_code": claimCode})
@@ -192,10 +291,12 @@ func (c *Client) Claim(ctx context.Context, claimCode string) (*ClaimResponse, e
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("User-Agent", "clawwork/"+version)
+	httpReq.Header.Set("User-Agent", userAgent())
 	if c.apiKey != "" {
 		httpReq.Header.Set("X-API-Key", c.apiKey)
-		signRequest(httpReq, c.apiKey, body)
+		if err := signUnique(httpReq, c.apiKey, body); err != nil {
+			return nil, err
+		}
 	}
 
 	httpResp, err := c.client.Do(httpReq)
@@ -211,8 +312,14 @@ func (c *Client) Claim(ctx context.Context, claimCode string) (*ClaimResponse, e
 
 	var resp ClaimResponse
 	if err := json.Unmarshal(respBody, &resp); err != nil {
+		if httpResp.StatusCode >= 400 {
+			return nil, newAPIError(httpResp, respBody)
+		}
 		return nil, fmt.Errorf("parse response (status %d): %w", httpResp.StatusCode, err)
 	}
+	if resp.Error != "" || httpResp.StatusCode >= 400 {
+		return nil, newAPIError(httpResp, respBody)
+	}
 	return &resp, nil
 }
 
@@ -227,7 +334,7 @@ func (c *Client) SocialGet(ctx context.Context, module string, params map[string
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-	httpReq.Header.Set("User-Agent", "clawwork/"+version)
+	httpReq.Header.Set("User-Agent", userAgent())
 	if c.apiKey != "" {
 		httpReq.Header.Set("X-API-Key", c.apiKey)
 		signRequest(httpReq, c.apiKey, nil)
@@ -245,7 +352,7 @@ func (c *Client) SocialGet(ctx context.Context, module string, params map[string
 	}
 
 	if httpResp.StatusCode >= 400 {
-		return nil, fmt.Errorf("social GET %s failed (%d): %s", module, httpResp.StatusCode, truncate(string(respBody), 200))
+		return nil, newAPIError(httpResp, respBody)
 	}
 
 	return json.RawMessage(respBody), nil
@@ -263,10 +370,12 @@ func (c *Client) SocialPost(ctx context.Context, body map[string]any) (json.RawM
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("User-Agent", "clawwork/"+version)
+	httpReq.Header.Set("User-Agent", userAgent())
 	if c.apiKey != "" {
 		httpReq.Header.Set("X-API-Key", c.apiKey)
-		signRequest(httpReq, c.apiKey, data)
+		if err := signUnique(httpReq, c.apiKey, data); err != nil {
+			return nil, err
+		}
 	}
 
 	httpResp, err := c.client.Do(httpReq)
@@ -281,13 +390,81 @@ func (c *Client) SocialPost(ctx context.Context, body map[string]any) (json.RawM
 	}
 
 	if httpResp.StatusCode >= 400 {
-		// Return body alongside error so callers can inspect structured responses (e.g. COOLDOWN).
-		return json.RawMessage(respBody), fmt.Errorf("social POST failed (%d)", httpResp.StatusCode)
+		// Return body alongside error so callers can pass it through.
+		return json.RawMessage(respBody), newAPIError(httpResp, respBody)
 	}
 
 	return json.RawMessage(respBody), nil
 }
 
+// Nearby returns the agents mining tokenID (the social "nearby" module).
+func (c *Client) Nearby(ctx context.Context, tokenID int) ([]Miner, error) {
+	raw, err := c.SocialGet(ctx, "nearby", map[string]string{"token_id": strconv.Itoa(tokenID)})
+	if err != nil {
+		return nil, err
+	}
+	// The list comes either at the top level or wrapped in "data".
+	var resp struct {
+		Data struct {
+			Miners []Miner `json:"miners"`
+		} `json:"data"`
+		Miners []Miner `json:"miners"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("parse nearby response: %w", err)
+	}
+	if len(resp.Data.Miners) > 0 {
+		return resp.Data.Miners, nil
+	}
+	return resp.Miners, nil
+}
+
+// Leaderboard fetches agent rankings. scope is "nearby" or "global";
+// by is "cw" or "inscriptions". Returns ErrNotSupported if the server
+// does not expose rankings.
+func (c *Client) Leaderboard(ctx context.Context, scope, by string, limit int) (*LeaderboardResponse, error) {
+	q := url.Values{}
+	q.Set("scope", scope)
+	q.Set("by", by)
+	if limit > 0 {
+		q.Set("limit", fmt.Sprint(limit))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", BaseURL+"/skill/leaderboard?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", userAgent())
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+		signRequest(httpReq, c.apiKey, nil)
+	}
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if httpResp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotSupported
+	}
+	if httpResp.StatusCode != 200 {
+		return nil, newAPIError(httpResp, respBody)
+	}
+
+	var resp LeaderboardResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	return &resp, nil
+}
+
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s
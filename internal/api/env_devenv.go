@@ -0,0 +1,27 @@
+//go:build devenv
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Development builds (go build -tags devenv) may target a staging or
+// self-hosted platform via CLAWWORK_API_URL. The override is compiled out of
+// release builds so a stray environment variable can never redirect an
+// agent's API key to a third-party host.
+func init() {
+	raw := strings.TrimRight(strings.TrimSpace(os.Getenv("CLAWWORK_API_URL")), "/")
+	if raw == "" {
+		return
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		fmt.Fprintf(os.Stderr, "clawwork: ignoring CLAWWORK_API_URL %q: must be an http(s) URL\n", raw)
+		return
+	}
+	BaseURL = raw
+}
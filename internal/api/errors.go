@@ -1,14 +1,28 @@
 package api
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
 
 // APIError represents a structured error from the ClawWork API.
 type APIError struct {
 	StatusCode int
 	Code       string
 	Message    string
-	RetryAfter int        // seconds, for 429 responses
+	Hint       string
+	RetryAfter int        // seconds, for rate-limit and cooldown responses
+	ResetAt    time.Time  // when a daily limit lifts, if the server says (server clock)
+	ServerDate time.Time  // the response's Date header, to correct for clock skew
 	Challenge  *Challenge // new challenge on challenge errors
+
+	// Inscribe is the full /skill/inscribe response, for callers that need
+	// fields beyond the error itself (version gating, trust score).
+	Inscribe *InscribeResponse
 }
 
 func (e *APIError) Error() string {
@@ -22,7 +36,7 @@ func (e *APIError) Error() string {
 func (e *APIError) IsChallenge() bool {
 	switch e.Code {
 	case "CHALLENGE_REQUIRED", "CHALLENGE_FAILED", "CHALLENGE_EXPIRED",
-		"CHALLENGE_INVALID", "CHALLENGE_USED":
+		"CHALLENGE_INVALID", "CHALLENGE_USED", "CHALLENGE_UNAVAILABLE":
 		return true
 	}
 	return false
@@ -32,13 +46,80 @@ func (e *APIError) IsChallenge() bool {
 func (e *APIError) IsFatal() bool {
 	switch e.Code {
 	case "NOT_CLAIMED", "AGENT_BANNED",
-		"INVALID_API_KEY", "REGISTRATION_DISABLED":
+		"INVALID_API_KEY", "REGISTRATION_DISABLED",
+		"ALREADY_MINING", "UPGRADE_REQUIRED":
 		return true
 	}
 	return false
 }
 
+// IsRateLimited returns true if the server asked the client to slow down.
+func (e *APIError) IsRateLimited() bool {
+	switch e.Code {
+	case "RATE_LIMITED", "DAILY_LIMIT_REACHED", "COOLDOWN":
+		return true
+	}
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
 // IsRetryable returns true if the error can be resolved by waiting and retrying.
 func (e *APIError) IsRetryable() bool {
-	return e.StatusCode == 429 || e.StatusCode == 503
+	return e.IsRateLimited() || e.StatusCode >= 500
+}
+
+// AsAPIError returns the *APIError in err's chain, if any.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// HasCode reports whether err is an APIError with the given code.
+func HasCode(err error, code string) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Code == code
+}
+
+// newAPIError builds an APIError from a failed response. The platform uses
+// two body shapes: {"error":"CODE","message":...} on /skill/* endpoints and
+// {"error":{"code":...,"message":...}} on /skill/social.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode}
+
+	var parsed struct {
+		Error      json.RawMessage `json:"error"`
+		Message    string          `json:"message"`
+		Hint       string          `json:"hint"`
+		RetryAfter int             `json:"retry_after"`
+		ResetAt    string          `json:"reset_at"` // RFC 3339; a bad value must not spoil the rest
+		Challenge  *Challenge      `json:"challenge"`
+	}
+	if json.Unmarshal(body, &parsed) == nil {
+		e.Message, e.Hint, e.RetryAfter, e.Challenge = parsed.Message, parsed.Hint, parsed.RetryAfter, parsed.Challenge
+		e.ResetAt, _ = time.Parse(time.RFC3339, parsed.ResetAt)
+		var nested struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(parsed.Error, &e.Code) != nil && json.Unmarshal(parsed.Error, &nested) == nil {
+			e.Code = nested.Code
+			if e.Message == "" {
+				e.Message = nested.Message
+			}
+		}
+	}
+
+	if e.Code == "" {
+		e.Code = http.StatusText(resp.StatusCode)
+		if e.Message == "" {
+			e.Message = truncate(string(body), 200)
+		}
+	}
+	if e.RetryAfter == 0 {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			e.RetryAfter = secs
+		}
+	}
+	e.ServerDate, _ = http.ParseTime(resp.Header.Get("Date"))
+	return e
 }
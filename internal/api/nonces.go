@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// NonceRetention is how long issued nonces are remembered.
+	NonceRetention = time.Hour
+	// DuplicateWindow is how long an identical one-shot payload is refused.
+	DuplicateWindow = 2 * time.Minute
+)
+
+// ErrDuplicatePayload is returned when a one-shot request (challenge answer,
+// social post, claim) is signed again with an identical body shortly after
+// the first — typically a retry that would double-submit.
+var ErrDuplicatePayload = errors.New("identical request was already signed")
+
+// NonceRecord is one signed request remembered by the nonce store.
+type NonceRecord struct {
+	Nonce    string    `json:"nonce"`
+	At       time.Time `json:"at"`
+	Request  string    `json:"request"` // "METHOD /path"
+	BodyHash string    `json:"body_hash"`
+}
+
+// nonceStore remembers recently issued nonces so they are never reused and
+// so a captured request can be matched back to the moment it was signed.
+// With a path, every issue re-reads the file under a lock file, so the
+// miner and a CLI command running at the same time see each other's
+// one-shot submissions.
+type nonceStore struct {
+	mu      sync.Mutex // serializes issues within this process
+	path    string     // empty = memory only
+	records []NonceRecord
+}
+
+// nonceLockStale is how old a lock file must be before it is taken to be
+// left behind by a crashed process.
+const nonceLockStale = 10 * time.Second
+
+var usedNonces = &nonceStore{}
+
+// SetNonceStore persists issued nonces to path (e.g. ~/.clawwork/nonces.json)
+// so replay checks survive restarts.
+func SetNonceStore(path string) {
+	usedNonces.mu.Lock()
+	defer usedNonces.mu.Unlock()
+	usedNonces.path = path
+	usedNonces.records = nil
+}
+
+// LookupNonce returns the record for a nonce issued by this machine, if it
+// is still retained.
+func LookupNonce(nonce string) (NonceRecord, bool) {
+	s := usedNonces
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		slog.Warn("nonce store", "error", err)
+	}
+	for _, r := range s.records {
+		if r.Nonce == nonce {
+			return r, true
+		}
+	}
+	return NonceRecord{}, false
+}
+
+// issue returns a fresh nonce for request and records it. With unique set,
+// a non-empty body identical to one signed within DuplicateWindow is refused.
+// A store that can't be read or written is logged and the nonce issued
+// anyway: it only weakens duplicate detection.
+func (s *nonceStore) issue(request string, body []byte, unique bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path != "" {
+		unlock, err := s.lock()
+		if err != nil {
+			slog.Warn("nonce store", "error", err)
+		} else {
+			defer unlock()
+		}
+	}
+	if err := s.load(); err != nil {
+		slog.Warn("nonce store", "error", err)
+	}
+
+	now := time.Now()
+	hash := sha256Hex(body)
+	kept := s.records[:0]
+	for _, r := range s.records {
+		if now.Sub(r.At) < NonceRetention {
+			kept = append(kept, r)
+		}
+	}
+	s.records = kept
+
+	if unique && len(body) > 0 {
+		for _, r := range s.records {
+			if r.Request == request && r.BodyHash == hash && now.Sub(r.At) < DuplicateWindow {
+				return "", fmt.Errorf("%w: %s %s ago", ErrDuplicatePayload, request, now.Sub(r.At).Round(time.Second))
+			}
+		}
+	}
+
+	nonce := generateNonce()
+	for s.seen(nonce) {
+		nonce = generateNonce()
+	}
+	s.records = append(s.records, NonceRecord{Nonce: nonce, At: now, Request: request, BodyHash: hash})
+	if err := s.save(); err != nil {
+		slog.Warn("nonce store", "error", err)
+	}
+	return nonce, nil
+}
+
+func (s *nonceStore) seen(nonce string) bool {
+	for _, r := range s.records {
+		if r.Nonce == nonce {
+			return true
+		}
+	}
+	return false
+}
+
+// load replaces the records with the file's, which other processes may
+// have added to. A corrupt file is moved aside so the next save starts a
+// fresh one.
+func (s *nonceStore) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.records = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var records []NonceRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		bad := s.path + ".bad"
+		_ = os.Rename(s.path, bad)
+		s.records = nil
+		return fmt.Errorf("%s is unreadable, moved to %s: %w", s.path, bad, err)
+	}
+	s.records = records
+	return nil
+}
+
+// save writes a temporary file and renames it over the store, so readers
+// never see a half-written file.
+func (s *nonceStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.records)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// lock serializes issues across processes with an exclusive lock file.
+func (s *nonceStore) lock() (func(), error) {
+	path := s.path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > nonceLockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if i >= 50 {
+			return nil, fmt.Errorf("%s is held by another process", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Exchange is one recorded /skill/inscribe round trip. Request headers
+// (API key, signature) are never recorded; the body is kept verbatim,
+// including the challenge answer.
+type Exchange struct {
+	At         time.Time       `json:"at"`
+	Request    json.RawMessage `json:"request"`
+	Status     int             `json:"status,omitempty"`
+	RetryAfter string          `json:"retry_after,omitempty"` // Retry-After header
+	Response   json.RawMessage `json:"response,omitempty"`
+	Error      string          `json:"error,omitempty"` // transport failure, no response
+}
+
+// Recorder appends exchanges to a JSON Lines file.
+type Recorder struct {
+	mu   sync.Mutex
+	f    *os.File
+	path string
+}
+
+// NewRecorder creates a recording file in dir named after the current time.
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create recordings directory: %w", err)
+	}
+	path := filepath.Join(dir, "inscribe-"+time.Now().Format("20060102-150405")+".jsonl")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{f: f, path: path}, nil
+}
+
+// Path returns the recording file path.
+func (r *Recorder) Path() string { return r.path }
+
+// Record appends one exchange. Write errors are ignored: recording is a
+// debugging aid and must not interrupt mining.
+func (r *Recorder) Record(ex Exchange) {
+	data, err := json.Marshal(ex)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.f.Write(append(data, '\n'))
+}
+
+// Close closes the recording file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// SetRecorder records every inscribe exchange made by c. Nil stops recording.
+func (c *Client) SetRecorder(r *Recorder) { c.recorder = r }
+
+func (c *Client) record(body []byte, resp *http.Response, respBody []byte, err error) {
+	if c.recorder == nil {
+		return
+	}
+	ex := Exchange{At: time.Now().UTC(), Request: json.RawMessage(body)}
+	if err != nil {
+		ex.Error = err.Error()
+	}
+	if resp != nil {
+		ex.Status = resp.StatusCode
+		ex.RetryAfter = resp.Header.Get("Retry-After")
+	}
+	if json.Valid(respBody) {
+		ex.Response = json.RawMessage(respBody)
+	} else if len(respBody) > 0 {
+		ex.Response, _ = json.Marshal(string(respBody))
+	}
+	c.recorder.Record(ex)
+}
+
+// ReadRecording loads the exchanges from a recording file.
+func ReadRecording(path string) ([]Exchange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Exchange
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var ex Exchange
+		if err := json.Unmarshal(sc.Bytes(), &ex); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		out = append(out, ex)
+	}
+	return out, sc.Err()
+}
+
+// InscribeRequest decodes the recorded request body.
+func (ex *Exchange) InscribeRequest() (InscribeRequest, error) {
+	var req InscribeRequest
+	err := json.Unmarshal(ex.Request, &req)
+	return req, err
+}
+
+// NewReplayClient returns a client that answers inscribe requests with the
+// recorded responses, in order, instead of calling the platform. Requests
+// are not signed, so replaying leaves the nonce store untouched. onRequest,
+// if set, sees each request the client sends next to the recorded one it is
+// answered with (rec is nil once the recording is used up).
+func NewReplayClient(exchanges []Exchange, onRequest func(sent InscribeRequest, rec *Exchange)) *Client {
+	return &Client{client: &http.Client{Transport: &replayTransport{exchanges: exchanges, onRequest: onRequest}}}
+}
+
+type replayTransport struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	next      int
+	onRequest func(InscribeRequest, *Exchange)
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var sent InscribeRequest
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(body, &sent)
+	}
+
+	t.mu.Lock()
+	var rec *Exchange
+	if t.next < len(t.exchanges) {
+		rec = &t.exchanges[t.next]
+		t.next++
+	}
+	t.mu.Unlock()
+
+	if t.onRequest != nil {
+		t.onRequest(sent, rec)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("replay: recording exhausted")
+	}
+	if rec.Error != "" {
+		return nil, fmt.Errorf("replay: %s", rec.Error)
+	}
+	body := []byte(rec.Response)
+	var text string
+	if json.Unmarshal(rec.Response, &text) == nil {
+		body = []byte(text) // a non-JSON body, recorded as a string
+	}
+	h := make(http.Header)
+	if rec.RetryAfter != "" {
+		h.Set("Retry-After", rec.RetryAfter)
+	}
+	return &http.Response{
+		StatusCode: rec.Status,
+		Status:     strconv.Itoa(rec.Status) + " " + http.StatusText(rec.Status),
+		Header:     h,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
@@ -12,8 +12,25 @@ import (
 
 // signRequest adds client attestation headers to an HTTP request.
 // Signature = HMAC-SHA256(apiKey, nonce + "." + timestamp + "." + bodyHash)
+// The nonce is recorded in the local nonce store.
 func signRequest(req *http.Request, apiKey string, body []byte) {
-	nonce := generateNonce()
+	nonce, _ := usedNonces.issue(requestKey(req), body, false)
+	sign(req, apiKey, nonce, body)
+}
+
+// signUnique is signRequest for submissions that must not go out twice
+// (challenge answers, posts, claims): it returns ErrDuplicatePayload when
+// an identical payload was signed within DuplicateWindow.
+func signUnique(req *http.Request, apiKey string, body []byte) error {
+	nonce, err := usedNonces.issue(requestKey(req), body, true)
+	if err != nil {
+		return err
+	}
+	sign(req, apiKey, nonce, body)
+	return nil
+}
+
+func sign(req *http.Request, apiKey, nonce string, body []byte) {
 	timestamp := fmt.Sprintf("%d", time.Now().Unix())
 	bodyHash := sha256Hex(body)
 
@@ -22,12 +39,18 @@ func signRequest(req *http.Request, apiKey string, body []byte) {
 	mac.Write([]byte(message))
 	signature := hex.EncodeToString(mac.Sum(nil))
 
-	req.Header.Set("X-Client-Version", "clawwork/"+version)
+	if !minimalHeaders {
+		req.Header.Set("X-Client-Version", clientVersion())
+	}
 	req.Header.Set("X-Client-Nonce", nonce)
 	req.Header.Set("X-Client-Timestamp", timestamp)
 	req.Header.Set("X-Client-Signature", signature)
 }
 
+func requestKey(req *http.Request) string {
+	return req.Method + " " + req.URL.Path
+}
+
 // VerifySignature checks if the given headers produce a valid HMAC.
 // Exported so the server-side logic can reference the same algorithm.
 func VerifySignature(apiKey, nonce, timestamp, bodyHash, signature string) bool {
@@ -44,6 +67,9 @@ func generateNonce() string {
 	return hex.EncodeToString(b)
 }
 
+// BodyHash returns the hex SHA-256 of a request body, as used in signatures.
+func BodyHash(body []byte) string { return sha256Hex(body) }
+
 func sha256Hex(data []byte) string {
 	h := sha256.Sum256(data)
 	return hex.EncodeToString(h[:])
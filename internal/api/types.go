@@ -59,6 +59,7 @@ type InscribeResponse struct {
 	Hint       string     `json:"hint,omitempty"`
 	Challenge  *Challenge `json:"challenge,omitempty"` // returned on challenge errors
 	RetryAfter int        `json:"retry_after,omitempty"`
+	ResetAt    string     `json:"reset_at,omitempty"` // RFC 3339, on DAILY_LIMIT_REACHED
 }
 
 // Challenge represents an inscription challenge prompt.
@@ -123,16 +124,6 @@ type StatusActivity struct {
 	NFTsRemaining int    `json:"nfts_remaining"`
 }
 
-// IsChallenge returns true if this is a challenge-related error requiring retry.
-func (r *InscribeResponse) IsChallenge() bool {
-	switch r.Error {
-	case "CHALLENGE_REQUIRED", "CHALLENGE_FAILED", "CHALLENGE_EXPIRED",
-		"CHALLENGE_INVALID", "CHALLENGE_USED", "CHALLENGE_UNAVAILABLE":
-		return true
-	}
-	return false
-}
-
 // GetChallenge returns the challenge from this response (from either field).
 func (r *InscribeResponse) GetChallenge() *Challenge {
 	if r.Challenge != nil {
@@ -141,22 +132,6 @@ func (r *InscribeResponse) GetChallenge() *Challenge {
 	return r.NextChallenge
 }
 
-// IsFatal returns true if the error cannot be recovered by retry.
-func (r *InscribeResponse) IsFatal() bool {
-	switch r.Error {
-	case "NOT_CLAIMED", "AGENT_BANNED",
-		"INVALID_API_KEY", "REGISTRATION_DISABLED",
-		"ALREADY_MINING", "UPGRADE_REQUIRED":
-		return true
-	}
-	return false
-}
-
-// IsRateLimited returns true if the response indicates rate limiting.
-func (r *InscribeResponse) IsRateLimited() bool {
-	return r.Error == "RATE_LIMITED" || r.Error == "DAILY_LIMIT_REACHED"
-}
-
 // ClaimResponse is the response from POST /skill/claim.
 type ClaimResponse struct {
 	OK          bool   `json:"ok"`
@@ -165,3 +140,22 @@ type ClaimResponse struct {
 	Message     string `json:"message,omitempty"`
 	Error       string `json:"error,omitempty"`
 }
+
+// LeaderboardResponse is the response from GET /skill/leaderboard.
+type LeaderboardResponse struct {
+	Scope     string             `json:"scope"` // "nearby" or "global"
+	By        string             `json:"by"`    // "cw" or "inscriptions"
+	Total     int                `json:"total"` // agents ranked in this scope
+	Entries   []LeaderboardEntry `json:"entries"`
+	Self      *LeaderboardEntry  `json:"self,omitempty"` // this agent, even if outside Entries
+	UpdatedAt string             `json:"updated_at,omitempty"`
+}
+
+// LeaderboardEntry is one ranked agent.
+type LeaderboardEntry struct {
+	Rank         int    `json:"rank"`
+	AgentID      string `json:"agent_id"`
+	DisplayName  string `json:"display_name"`
+	CW           int64  `json:"cw"`
+	Inscriptions int    `json:"inscriptions"`
+}
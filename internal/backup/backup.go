@@ -0,0 +1,319 @@
+// Package backup snapshots the files an agent can't recreate — config,
+// state, soul and history, or the SQLite store — into a rotating local directory,
+// optionally copying each snapshot to a remote target.
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/objstore"
+	"github.com/clawplaza/clawwork-cli/internal/store"
+)
+
+const (
+	prefix = "clawwork-backup-"
+	suffix = ".zip"
+)
+
+// configFile is the snapshot entry whose credentials are blanked in the
+// copy sent to the target.
+const configFile = "config.toml"
+
+// Files are the data files included in a snapshot, as glob patterns
+// relative to config.Dir(). Missing files are skipped.
+var Files = []string{configFile, "state.json", "soul.md", "goal.json", "history/*.jsonl"}
+
+// Target stores snapshots off the machine; any objstore.Store will do.
+type Target interface {
+	Name() string
+	Put(ctx context.Context, name string, data []byte) error
+	Delete(ctx context.Context, name string) error
+}
+
+// Backup is one snapshot in the local directory.
+type Backup struct {
+	Name string
+	Path string
+	Size int64
+	At   time.Time
+}
+
+// Manager takes and rotates snapshots.
+type Manager struct {
+	Dir    string        // local backup directory
+	Keep   int           // snapshots kept locally and remotely
+	Every  time.Duration // schedule for Run
+	Target Target        // nil for local only
+
+	// Database writes a copy of the SQLite store to a path, included as
+	// clawwork.db; nil with the files backend.
+	Database func(path string) error
+}
+
+// New builds a Manager from config.
+func New(cfg config.BackupConfig) *Manager {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Join(config.Dir(), "backups")
+	}
+	m := &Manager{Dir: dir, Keep: cfg.Keep, Every: time.Duration(cfg.IntervalHours) * time.Hour}
+	if cfg.WebDAVURL != "" {
+		m.Target = objstore.NewWebDAV(cfg.WebDAVURL, cfg.WebDAVUser, cfg.WebDAVPassword)
+	}
+	return m
+}
+
+// List returns the local snapshots, newest first.
+func (m *Manager) List() ([]Backup, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var list []Backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		at, err := time.ParseInLocation("20060102-150405", strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix), time.UTC)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, Backup{Name: name, Path: filepath.Join(m.Dir, name), Size: info.Size(), At: at})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].At.After(list[j].At) })
+	return list, nil
+}
+
+// Snapshot writes a new snapshot, uploads it to the target if one is set,
+// and rotates old snapshots. A failed upload is returned as an error but
+// the local snapshot is kept. The uploaded copy has the credentials in
+// config.toml blanked; only the local one can be restored as it is.
+func (m *Manager) Snapshot(ctx context.Context) (*Backup, error) {
+	if err := os.MkdirAll(m.Dir, 0700); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	name := prefix + now.Format("20060102-150405") + suffix
+	path := filepath.Join(m.Dir, name)
+	if err := writeZip(path, config.Dir(), m.Database); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &Backup{Name: name, Path: path, Size: info.Size(), At: now}
+
+	var uploadErr error
+	if m.Target != nil {
+		data, err := withoutSecrets(path)
+		if err == nil {
+			err = m.Target.Put(ctx, name, data)
+		}
+		if err != nil {
+			uploadErr = fmt.Errorf("upload to %s: %w", m.Target.Name(), err)
+		}
+	}
+	m.rotate(ctx)
+	return b, uploadErr
+}
+
+// rotate removes snapshots beyond Keep, locally and on the target.
+func (m *Manager) rotate(ctx context.Context) {
+	if m.Keep <= 0 {
+		return
+	}
+	list, err := m.List()
+	if err != nil || len(list) <= m.Keep {
+		return
+	}
+	for _, b := range list[m.Keep:] {
+		if err := os.Remove(b.Path); err != nil {
+			slog.Warn("backup: remove old snapshot", "file", b.Name, "error", err)
+			continue
+		}
+		if m.Target != nil {
+			if err := m.Target.Delete(ctx, b.Name); err != nil {
+				slog.Warn("backup: remove old remote snapshot", "target", m.Target.Name(), "file", b.Name, "error", err)
+			}
+		}
+	}
+}
+
+// Run takes a snapshot whenever the newest one is older than Every, until
+// ctx ends. It checks once at start, so a restarted service catches up.
+func (m *Manager) Run(ctx context.Context) {
+	if m.Every <= 0 {
+		return
+	}
+	for {
+		var wait time.Duration
+		if list, _ := m.List(); len(list) > 0 {
+			wait = time.Until(list[0].At.Add(m.Every))
+		}
+		if wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+		b, err := m.Snapshot(ctx)
+		switch {
+		case b == nil:
+			slog.Warn("backup failed", "error", err)
+			// Don't spin on a persistent error such as a full disk.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Hour):
+			}
+		case err != nil:
+			slog.Warn("backup saved locally but not uploaded", "file", b.Name, "error", err)
+		default:
+			slog.Info("backup saved", "file", b.Name, "bytes", b.Size)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// withoutSecrets returns the snapshot at path with config.toml replaced
+// by a copy without its credentials (see config.Config.WithoutSecrets).
+func withoutSecrets(path string) ([]byte, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		if f.Name != configFile {
+			if err := zw.Copy(f); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := config.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", configFile, err)
+		}
+		if data, err = cfg.WithoutSecrets().Marshal(); err != nil {
+			return nil, err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.Modified})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZip(path, dir string, database func(string) error) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(f)
+	for _, pattern := range Files {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			name, _ := filepath.Rel(dir, match)
+			name = filepath.ToSlash(name)
+			if err := addFile(zw, match, name); err != nil {
+				f.Close()
+				os.Remove(path)
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	if database != nil {
+		if err := addDatabase(zw, database); err != nil {
+			f.Close()
+			os.Remove(path)
+			return fmt.Errorf("%s: %w", store.DBFile, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// addDatabase snapshots the database into a temporary directory rather
+// than reading clawwork.db, which misses whatever is still in its
+// write-ahead log.
+func addDatabase(zw *zip.Writer, database func(string) error) error {
+	tmp, err := os.MkdirTemp("", "clawwork-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, store.DBFile)
+	if err := database(path); err != nil {
+		return err
+	}
+	return addFile(zw, path, store.DBFile)
+}
+
+func addFile(zw *zip.Writer, path, name string) error {
+	src, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: info.ModTime()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
@@ -0,0 +1,90 @@
+//go:build sqlite
+
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/store"
+)
+
+// A snapshot of an agent on the SQLite backend restores, by unzipping it
+// into an empty data directory, to the same state and history — including
+// writes still in the write-ahead log when the snapshot was taken.
+func TestSnapshotSQLite(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLAWWORK_HOME", home)
+	if err := os.WriteFile(filepath.Join(home, "config.toml"), []byte("[agent]\nname = \"a\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.StorageConfig{Backend: store.BackendSQLite}
+	db, err := store.Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	at := time.Now()
+	if err := db.Put("state", []byte(`{"total_inscriptions":7}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Append("history", at, []byte(`{"n":1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{Dir: filepath.Join(home, "backups"), Database: db.(store.Snapshotter).Snapshot}
+	b, err := m.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := t.TempDir()
+	unzip(t, b.Path, restored)
+	for _, name := range []string{"config.toml", store.DBFile} {
+		if _, err := os.Stat(filepath.Join(restored, name)); err != nil {
+			t.Errorf("%s missing from the snapshot: %v", name, err)
+		}
+	}
+	t.Setenv("CLAWWORK_HOME", restored)
+	got, err := store.Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Close()
+	if data, err := got.Get("state"); err != nil || string(data) != `{"total_inscriptions":7}` {
+		t.Errorf("restored state = %q, %v", data, err)
+	}
+	n := 0
+	if err := got.Scan("history", time.Time{}, func([]byte) { n++ }); err != nil || n != 1 {
+		t.Errorf("restored history has %d records, %v", n, err)
+	}
+}
+
+func unzip(t *testing.T, path, dir string) {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f.Name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type memTarget map[string][]byte
+
+func (memTarget) Name() string { return "memory" }
+func (t memTarget) Put(_ context.Context, name string, data []byte) error {
+	t[name] = data
+	return nil
+}
+func (t memTarget) Delete(_ context.Context, name string) error {
+	delete(t, name)
+	return nil
+}
+
+// The local snapshot keeps config.toml as it is; the uploaded copy has its
+// credentials blanked and everything else unchanged.
+func TestSnapshotUploadWithoutSecrets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLAWWORK_HOME", home)
+	const key = "clwk_secretsecret"
+	config := "[agent]\nname = \"a\"\napi_key = \"" + key + "\"\n\n[llm]\napi_key = \"sk-llmsecret\"\n"
+	if err := os.WriteFile(filepath.Join(home, "config.toml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "state.json"), []byte(`{"total_inscriptions":3}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	target := memTarget{}
+	m := &Manager{Dir: filepath.Join(home, "backups"), Target: target}
+	b, err := m.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := os.ReadFile(b.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := zipFile(t, local, "config.toml"); !strings.Contains(got, key) {
+		t.Errorf("local config.toml lost its API key:\n%s", got)
+	}
+
+	remote := zipFile(t, target[b.Name], "config.toml")
+	for _, secret := range []string{key, "sk-llmsecret"} {
+		if strings.Contains(remote, secret) {
+			t.Errorf("uploaded config.toml contains %q:\n%s", secret, remote)
+		}
+	}
+	if !strings.Contains(remote, `name = "a"`) {
+		t.Errorf("uploaded config.toml lost its settings:\n%s", remote)
+	}
+	if got := zipFile(t, target[b.Name], "state.json"); got != `{"total_inscriptions":3}` {
+		t.Errorf("uploaded state.json = %q", got)
+	}
+}
+
+// Snapshots carry the monthly history files under history/.
+func TestSnapshotIncludesHistory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLAWWORK_HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, "history"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, month := range []string{"2026-09", "2026-10"} {
+		line := `{"month":"` + month + `"}` + "\n"
+		if err := os.WriteFile(filepath.Join(home, "history", month+".jsonl"), []byte(line), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &Manager{Dir: filepath.Join(home, "backups")}
+	b, err := m.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, month := range []string{"2026-09", "2026-10"} {
+		if got := zipFile(t, data, "history/"+month+".jsonl"); !strings.Contains(got, month) {
+			t.Errorf("history/%s.jsonl = %q", month, got)
+		}
+	}
+}
+
+func zipFile(t *testing.T, archive []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+// Package budget estimates prompt sizes in tokens and fits prompts into a
+// model's context window.
+package budget
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultWindow is assumed when the model is unknown and no context_window
+// is configured. Small enough to be safe for most local models.
+const DefaultWindow = 8192
+
+// ollamaWindow is Ollama's default num_ctx; larger model windows are not
+// used unless the server is configured for them.
+const ollamaWindow = 4096
+
+// windows maps model name prefixes to their context size in tokens. The
+// first match wins, so more specific prefixes come first.
+var windows = []struct {
+	prefix string
+	tokens int
+}{
+	{"claude", 200000},
+	{"gpt-4o", 128000},
+	{"gpt-4.1", 1000000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4", 8192},
+	{"gpt-3.5", 16385},
+	{"gpt-5", 400000},
+	{"o1", 128000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"gemini", 1000000},
+	{"deepseek", 64000},
+	{"kimi", 128000},
+	{"moonshot", 128000},
+	{"glm", 128000},
+	{"qwen", 32768},
+	{"mistral", 32768},
+	{"llama3.1", 128000},
+	{"llama3.2", 128000},
+	{"llama3.3", 128000},
+	{"llama3", 8192},
+}
+
+// Window returns the context size for a provider and model. A positive
+// override (llm.context_window) always wins. Platform mode builds prompts
+// server-side, so it is treated as unlimited.
+func Window(provider, model string, override int) int {
+	if override > 0 {
+		return override
+	}
+	switch provider {
+	case "platform":
+		return 1 << 30
+	case "ollama":
+		return ollamaWindow
+	}
+	m := strings.ToLower(model)
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:] // "openrouter/anthropic/claude-…" → "claude-…"
+	}
+	for _, w := range windows {
+		if strings.HasPrefix(m, w.prefix) {
+			return w.tokens
+		}
+	}
+	return DefaultWindow
+}
+
+// EstimateTokens approximates the token count of s the way BPE tokenizers
+// split text: each CJK character is a token, punctuation marks are tokens,
+// and runs of letters or digits cost one token per ~4 bytes. It errs on the
+// high side so budgets stay safe.
+func EstimateTokens(s string) int {
+	n, run := 0, 0
+	flush := func() {
+		if run > 0 {
+			n += (run + 3) / 4
+			run = 0
+		}
+	}
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			flush()
+			n++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if r > unicode.MaxASCII {
+				run += 2 // accented and non-Latin letters split more finely
+			} else {
+				run++
+			}
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			n++
+		}
+	}
+	flush()
+	return n
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Budget tracks how many tokens remain in a context window.
+type Budget struct {
+	left int
+}
+
+// New returns a budget of window tokens minus reserve (the reply and any
+// fixed overhead such as tool definitions).
+func New(window, reserve int) *Budget {
+	return &Budget{left: window - reserve}
+}
+
+// Take charges s against the budget if it fits and reports whether it did.
+func (b *Budget) Take(s string) bool {
+	t := EstimateTokens(s)
+	if t > b.left {
+		return false
+	}
+	b.left -= t
+	return true
+}
+
+// Charge deducts s unconditionally, for parts that must be sent anyway.
+func (b *Budget) Charge(s string) {
+	b.left -= EstimateTokens(s)
+}
+
+// Left returns the remaining tokens (negative when overcommitted).
+func (b *Budget) Left() int { return b.left }
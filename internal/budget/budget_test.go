@@ -0,0 +1,54 @@
+package budget
+
+import "testing"
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max int
+	}{
+		{"", 0, 0},
+		{"hello world", 2, 4},
+		{"What is 12 + 34? Reply with just the number.", 10, 16},
+		{"计算十二加三十四", 8, 8},
+		{"{\"a\": 1}", 5, 8},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.in); got < tt.min || got > tt.max {
+			t.Errorf("EstimateTokens(%q) = %d, want %d..%d", tt.in, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		provider, model string
+		override, want  int
+	}{
+		{"openai", "gpt-4o-mini", 0, 128000},
+		{"openai", "gpt-4", 0, 8192},
+		{"anthropic", "claude-sonnet-4", 0, 200000},
+		{"openai", "openrouter/anthropic/claude-3.5", 0, 200000},
+		{"ollama", "llama3.2", 0, ollamaWindow},
+		{"ollama", "llama3.2", 32768, 32768},
+		{"openai", "some-new-model", 0, DefaultWindow},
+	}
+	for _, tt := range tests {
+		if got := Window(tt.provider, tt.model, tt.override); got != tt.want {
+			t.Errorf("Window(%q, %q, %d) = %d, want %d", tt.provider, tt.model, tt.override, got, tt.want)
+		}
+	}
+}
+
+func TestBudgetTake(t *testing.T) {
+	b := New(10, 4)
+	if !b.Take("one two three") {
+		t.Fatal("small text should fit")
+	}
+	if b.Take("this sentence is certainly longer than the three tokens left") {
+		t.Fatal("long text should not fit")
+	}
+	if b.Left() != 2 {
+		t.Fatalf("Left() = %d, want 2", b.Left())
+	}
+}
@@ -0,0 +1,219 @@
+// Package callback receives platform webhooks — claim completed, NFT
+// verified, mail received — and turns them into local miner events, so
+// changes on the platform reach the console and notification channels
+// without polling `clawwork status`.
+package callback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>" of
+	// "<t>.<body>" keyed with the shared secret.
+	SignatureHeader = "X-ClawWork-Signature"
+
+	// DefaultPath is where callbacks are accepted when none is configured.
+	DefaultPath = "/clawwork/callback"
+
+	maxBody   = 64 << 10
+	maxSkew   = 5 * time.Minute
+	seenLimit = 256
+)
+
+// Event is one platform callback.
+type Event struct {
+	ID   string         `json:"id"`
+	Type string         `json:"type"` // e.g. "claim.completed", "nft.verified", "mail.received"
+	At   time.Time      `json:"created_at"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// Message describes the event in a line for the console and notifications.
+func (e Event) Message() string {
+	switch e.Type {
+	case "claim.completed":
+		if id := e.field("token_id"); id != "" {
+			return "Claim completed for token #" + id
+		}
+		return "Claim completed"
+	case "nft.verified":
+		if id := e.field("token_id"); id != "" {
+			return "NFT #" + id + " verified"
+		}
+		return "NFT verified"
+	case "mail.received":
+		if from := e.field("from"); from != "" {
+			return "New mail from " + from
+		}
+		return "New mail"
+	case "test":
+		return "Test callback received"
+	}
+	return "Platform event: " + e.Type
+}
+
+// field returns a data value as text, "" when absent.
+func (e Event) field(key string) string {
+	switch v := e.Data[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Sign returns the SignatureHeader value for body at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, body)
+}
+
+func mac(secret, ts string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts + "."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify checks a SignatureHeader value against body. Signatures older or
+// newer than maxSkew are rejected so a captured request can't be replayed.
+func Verify(secret, header string, body []byte, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	if ts == "" || sig == "" {
+		return errors.New("missing or malformed signature")
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("malformed signature timestamp")
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > maxSkew || d < -maxSkew {
+		return fmt.Errorf("signature timestamp off by %s", d.Round(time.Second))
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, ts, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// Receiver is the webhook listener. Each verified event is passed to
+// OnEvent once; redeliveries with an already seen ID, or for events
+// without one the same body, are acknowledged and dropped.
+type Receiver struct {
+	Secret  string
+	OnEvent func(Event)
+
+	mu    sync.Mutex
+	seen  map[string]bool
+	order []string
+	srv   *http.Server
+}
+
+// ServeHTTP accepts one POSTed event.
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil || len(body) > maxBody {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := Verify(rc.Secret, r.Header.Get(SignatureHeader), body, time.Now()); err != nil {
+		slog.Warn("callback: rejected request", "remote", r.RemoteAddr, "error", err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil || e.Type == "" {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	key := e.ID
+	if key == "" {
+		// A replay of a captured request within maxSkew verifies too.
+		sum := sha256.Sum256(body)
+		key = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	if rc.firstSight(key) && rc.OnEvent != nil {
+		rc.OnEvent(e)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// firstSight records id and reports whether it is new.
+func (rc *Receiver) firstSight(id string) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.seen[id] {
+		return false
+	}
+	if rc.seen == nil {
+		rc.seen = make(map[string]bool)
+	}
+	rc.seen[id] = true
+	rc.order = append(rc.order, id)
+	if len(rc.order) > seenLimit {
+		delete(rc.seen, rc.order[0])
+		rc.order = rc.order[1:]
+	}
+	return true
+}
+
+// Start serves callbacks on addr at path. Non-blocking.
+func (rc *Receiver) Start(addr, path string) error {
+	if path == "" {
+		path = DefaultPath
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("callback listener %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, rc)
+	rc.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := rc.srv.Serve(ln); err != http.ErrServerClosed {
+			slog.Error("callback listener error", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the listener.
+func (rc *Receiver) Close() error {
+	if rc.srv == nil {
+		return nil
+	}
+	return rc.srv.Close()
+}
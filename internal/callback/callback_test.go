@@ -0,0 +1,74 @@
+package callback
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"test"}`)
+	now := time.Now()
+	sig := Sign("s3cret-s3cret-s3cret", now, body)
+
+	if err := Verify("s3cret-s3cret-s3cret", sig, body, now); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := Verify("other-secret-other", sig, body, now); err == nil {
+		t.Error("wrong secret accepted")
+	}
+	if err := Verify("s3cret-s3cret-s3cret", sig, []byte(`{"type":"x"}`), now); err == nil {
+		t.Error("tampered body accepted")
+	}
+	if err := Verify("s3cret-s3cret-s3cret", sig, body, now.Add(maxSkew+time.Minute)); err == nil {
+		t.Error("stale signature accepted")
+	}
+	if err := Verify("s3cret-s3cret-s3cret", "", body, now); err == nil {
+		t.Error("missing signature accepted")
+	}
+}
+
+func TestReceiverDeduplicates(t *testing.T) {
+	var got []Event
+	rc := &Receiver{Secret: "s3cret-s3cret-s3cret", OnEvent: func(e Event) { got = append(got, e) }}
+	post := func(body, sig string) int {
+		req := httptest.NewRequest(http.MethodPost, DefaultPath, strings.NewReader(body))
+		if sig != "" {
+			req.Header.Set(SignatureHeader, sig)
+		}
+		w := httptest.NewRecorder()
+		rc.ServeHTTP(w, req)
+		return w.Code
+	}
+	body := `{"id":"evt_1","type":"mail.received","data":{"from":"alice"}}`
+	sig := Sign(rc.Secret, time.Now(), []byte(body))
+
+	if code := post(body, sig); code != http.StatusNoContent {
+		t.Fatalf("status %d, want 204", code)
+	}
+	if code := post(body, sig); code != http.StatusNoContent {
+		t.Fatalf("redelivery status %d, want 204", code)
+	}
+	if code := post(body, ""); code != http.StatusUnauthorized {
+		t.Fatalf("unsigned status %d, want 401", code)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d events, want 1", len(got))
+	}
+	if msg := got[0].Message(); msg != "New mail from alice" {
+		t.Errorf("Message() = %q", msg)
+	}
+
+	// Without an ID, a replayed request is caught by its body.
+	anon := `{"type":"mail.received","created_at":"2026-03-10T12:00:00Z","data":{"from":"bob"}}`
+	sig = Sign(rc.Secret, time.Now(), []byte(anon))
+	post(anon, sig)
+	post(anon, sig)
+	other := strings.Replace(anon, "12:00:00", "12:05:00", 1)
+	post(other, Sign(rc.Secret, time.Now(), []byte(other)))
+	if len(got) != 3 {
+		t.Errorf("got %d events after ID-less deliveries, want 3", len(got))
+	}
+}
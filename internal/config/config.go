@@ -2,18 +2,43 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Config holds all ClawWork CLI settings.
 type Config struct {
-	Agent   AgentConfig   `toml:"agent"`
-	LLM     LLMConfig     `toml:"llm"`
-	Logging LoggingConfig `toml:"logging"`
+	Agent    AgentConfig    `toml:"agent"`
+	LLM      LLMConfig      `toml:"llm"`
+	Miner    MinerConfig    `toml:"miner"`
+	Schedule ScheduleConfig `toml:"schedule"`
+	Logging  LoggingConfig  `toml:"logging"`
+	Social   SocialConfig   `toml:"social"`
+	MQTT     MQTTConfig     `toml:"mqtt"`
+	Alerts   AlertsConfig   `toml:"alerts"`
+	Crash    CrashConfig    `toml:"crash"`
+	Privacy  PrivacyConfig  `toml:"privacy"`
+	Network  NetworkConfig  `toml:"network"`
+	Web      WebConfig      `toml:"web"`
+	Notify   NotifyConfig   `toml:"notify"`
+	Backup   BackupConfig   `toml:"backup"`
+	Sync     SyncConfig     `toml:"sync"`
+	Storage  StorageConfig  `toml:"storage"`
+	Display  DisplayConfig  `toml:"display"`
+
+	Callback CallbackConfig `toml:"callback"`
+	Metrics  MetricsConfig  `toml:"metrics"`
+	Updater  UpdaterConfig  `toml:"updater"`
+
+	Experiment ExperimentConfig `toml:"experiment"`
 }
 
 // AgentConfig holds agent identity and inscription target.
@@ -21,6 +46,58 @@ type AgentConfig struct {
 	Name    string `toml:"name"`
 	APIKey  string `toml:"api_key"`
 	TokenID int    `toml:"token_id"`
+
+	// ExtraTokens are interleaved with TokenID in multi-token mode, for
+	// agents the platform allows to inscribe on several tokens.
+	ExtraTokens []int `toml:"extra_tokens,omitempty"`
+
+	// Label identifies this machine in a fleet, e.g. "fleet=eu-west-1".
+	// It is appended to the User-Agent and X-Client-Version headers and
+	// to every log line.
+	Label string `toml:"label,omitempty"`
+
+	// AutoToken moves the miner to another token when the current one is
+	// taken or crowded, instead of stopping (see insc --auto-token).
+	AutoToken AutoTokenConfig `toml:"auto_token"`
+}
+
+// Token strategies for AutoTokenConfig.Strategy.
+const (
+	TokenLeastCrowded = "least-crowded"
+	TokenSequential   = "sequential"
+	TokenRandom       = "random"
+)
+
+// DefaultCrowdedAt is the default AutoTokenConfig.CrowdedAt.
+const DefaultCrowdedAt = 10
+
+// AutoTokenConfig controls automatic token selection.
+type AutoTokenConfig struct {
+	Enabled bool `toml:"enabled"`
+
+	// Strategy picks the next token: "least-crowded" (default) asks the
+	// platform how many agents mine each candidate, "sequential" takes the
+	// next candidate in order, "random" any candidate.
+	Strategy string `toml:"strategy,omitempty"`
+
+	// Candidates are the tokens to choose from (empty: 25–1024).
+	Candidates []int `toml:"candidates,omitempty"`
+
+	// CrowdedAt moves on once this many other agents mine the current
+	// token (0: only when it is taken).
+	CrowdedAt int `toml:"crowded_at"`
+}
+
+// Rotation returns the token IDs to inscribe: TokenID first, then any
+// ExtraTokens, without duplicates.
+func (a AgentConfig) Rotation() []int {
+	ids := []int{a.TokenID}
+	for _, id := range a.ExtraTokens {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 // LLMConfig holds LLM provider settings.
@@ -29,19 +106,528 @@ type LLMConfig struct {
 	BaseURL  string `toml:"base_url"`
 	APIKey   string `toml:"api_key"`
 	Model    string `toml:"model"`
+
+	// Vision overrides image-input detection for chat attachments.
+	// Unset means auto-detect from the model name.
+	Vision *bool `toml:"vision,omitempty"`
+
+	// Proxy routes LLM calls (not ClawWork API calls) through a proxy:
+	// "http://host:port", "socks5://host:port", or "direct" to ignore
+	// HTTP(S)_PROXY. Empty uses the environment.
+	Proxy string `toml:"proxy,omitempty"`
+	// Headers are added to every LLM request (e.g. API gateway keys).
+	Headers map[string]string `toml:"headers,omitempty"`
+	// ContextWindow is the model's context size in tokens, used to fit
+	// prompts. 0 guesses from the model name.
+	ContextWindow int `toml:"context_window,omitzero"`
+
+	// AnswerTimeoutSeconds bounds one challenge answer. The HTTP client
+	// timeout is derived from it. 0 uses the provider default.
+	AnswerTimeoutSeconds int `toml:"answer_timeout_seconds,omitzero"`
+
+	// Ollama holds options passed through to Ollama's /api/chat.
+	Ollama OllamaConfig `toml:"ollama,omitempty"`
+}
+
+// defaultAnswerTimeouts are per-provider answer timeouts in seconds.
+// Providers not listed use 120.
+var defaultAnswerTimeouts = map[string]int{
+	"anthropic": 60,
+	"ollama":    60,
+}
+
+// AnswerTimeout returns how long one challenge answer may take.
+func (c *LLMConfig) AnswerTimeout() time.Duration {
+	secs := c.AnswerTimeoutSeconds
+	if secs <= 0 {
+		secs = defaultAnswerTimeouts[c.Provider]
+	}
+	if secs <= 0 {
+		secs = 120
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// DefaultOllamaNumCtx is the num_ctx requested from Ollama when none is
+// configured. Ollama's own default (2048) truncates the knowledge prompt.
+const DefaultOllamaNumCtx = 8192
+
+// OllamaConfig holds Ollama model options. Zero values leave Ollama's
+// defaults in place, except NumCtx (see DefaultOllamaNumCtx).
+type OllamaConfig struct {
+	NumCtx      int      `toml:"num_ctx,omitzero"`      // context window in tokens
+	Temperature *float64 `toml:"temperature,omitempty"` // sampling temperature
+	KeepAlive   string   `toml:"keep_alive,omitempty"`  // how long the model stays loaded: "10m", "1h", "-1" (forever)
+}
+
+// ContextSize returns the num_ctx to request.
+func (o OllamaConfig) ContextSize() int {
+	if o.NumCtx > 0 {
+		return o.NumCtx
+	}
+	return DefaultOllamaNumCtx
+}
+
+// MinerConfig holds inscription loop settings.
+type MinerConfig struct {
+	// ShutdownGraceSeconds is how long an in-flight answer/submit may keep
+	// running after SIGINT/SIGTERM before it is abandoned (0 = stop at once).
+	ShutdownGraceSeconds int `toml:"shutdown_grace_seconds"`
+
+	// AnswerLanguage sets the language challenge answers are written in:
+	// "auto" (match the challenge, default), "off" (no instruction),
+	// or a fixed code such as "en" or "zh".
+	AnswerLanguage string `toml:"answer_language,omitempty"`
+
+	// ContextHeader puts a short block of the agent's own facts (name,
+	// token, trust, NFTs remaining) in front of each challenge (default true).
+	ContextHeader bool `toml:"context_header"`
+
+	// Strategy picks how challenges are answered: "single" (default),
+	// "verify" (self-review before submitting), "best_of_k" (Candidates
+	// answers, the LLM picks one) or "few_shot" (earlier passed answers as
+	// examples).
+	Strategy string `toml:"strategy,omitempty"`
+
+	// Candidates, when 2 or more, answers high-stakes challenges with that
+	// many candidates sampled at CandidateTemperature and lets the LLM pick
+	// the best — multiplying the LLM cost of those challenges.
+	// CandidatesWhen is "after_failure" (a challenge failed in the last
+	// 24 hours) or "always".
+	Candidates           int     `toml:"candidates,omitzero"`
+	CandidatesWhen       string  `toml:"candidates_when,omitempty"`
+	CandidateTemperature float64 `toml:"candidate_temperature,omitzero"`
+
+	// Coordinate makes profiles mining on the same host stagger their
+	// inscriptions and share IP-penalty reports (default true).
+	Coordinate bool `toml:"coordinate"`
+}
+
+// MinCooldownSeconds is the shortest schedule.cooldown_seconds accepted.
+const MinCooldownSeconds = 60
+
+// ScheduleConfig paces the inscription loop. Both settings are reloaded
+// on SIGHUP (see `clawwork schedule`).
+type ScheduleConfig struct {
+	// CooldownSeconds is the wait after each inscription; 0 uses the
+	// platform's 30 minutes. A shorter wait only runs into its rate limit.
+	CooldownSeconds int `toml:"cooldown_seconds,omitzero"`
+
+	// ActiveHours limits mining to daily windows of local time, such as
+	// "22:00-08:00" (a window may cross midnight). Outside them the miner
+	// sleeps. Empty mines around the clock.
+	ActiveHours []string `toml:"active_hours,omitempty"`
+}
+
+// Cooldown returns the wait after each inscription, or def when unset.
+func (s ScheduleConfig) Cooldown(def time.Duration) time.Duration {
+	if s.CooldownSeconds > 0 {
+		return time.Duration(s.CooldownSeconds) * time.Second
+	}
+	return def
+}
+
+// ExperimentConfig sets up an A/B test: cycles alternate between arms A
+// and B, and outcomes are recorded per arm (see `clawwork experiment`).
+// The experiment is off while Name is empty.
+type ExperimentConfig struct {
+	Name string        `toml:"name,omitempty"`
+	A    ExperimentArm `toml:"a,omitempty"`
+	B    ExperimentArm `toml:"b,omitempty"`
+}
+
+// Enabled reports whether an experiment is configured.
+func (e ExperimentConfig) Enabled() bool { return e.Name != "" }
+
+// ExperimentArm is one configuration under test. Empty fields keep the
+// value from [llm] and [miner].
+type ExperimentArm struct {
+	Provider string `toml:"provider,omitempty"`
+	BaseURL  string `toml:"base_url,omitempty"`
+	APIKey   string `toml:"api_key,omitempty"`
+	Model    string `toml:"model,omitempty"`
+	Strategy string `toml:"strategy,omitempty"`
+
+	// PromptTemplate wraps each challenge; "{challenge}" marks where the
+	// challenge text goes.
+	PromptTemplate string `toml:"prompt_template,omitempty"`
+}
+
+// LLM returns base with the arm's overrides applied. A different provider
+// starts from that provider's defaults rather than base's URL and key.
+func (a ExperimentArm) LLM(base LLMConfig) LLMConfig {
+	c := base
+	if a.Provider != "" && a.Provider != base.Provider {
+		c.Provider, c.BaseURL, c.APIKey, c.Model = a.Provider, "", "", ""
+	}
+	if a.BaseURL != "" {
+		c.BaseURL = a.BaseURL
+	}
+	if a.APIKey != "" {
+		c.APIKey = a.APIKey
+	}
+	if a.Model != "" {
+		c.Model = a.Model
+	}
+	return c
+}
+
+// Miner returns base with the arm's strategy applied.
+func (a ExperimentArm) Miner(base MinerConfig) MinerConfig {
+	if a.Strategy != "" {
+		base.Strategy = a.Strategy
+	}
+	return base
+}
+
+// Describe summarizes how the arm differs from the base configuration.
+func (a ExperimentArm) Describe(llm LLMConfig, miner MinerConfig) string {
+	l, m := a.LLM(llm), a.Miner(miner)
+	desc := l.Provider
+	if l.Model != "" {
+		desc += "/" + l.Model
+	}
+	strategy := m.Strategy
+	if strategy == "" {
+		strategy = "single"
+	}
+	desc += ", " + strategy
+	if a.PromptTemplate != "" {
+		desc += ", custom prompt"
+	}
+	return desc
+}
+
+// DefaultShutdownGrace is the default shutdown grace period in seconds.
+const DefaultShutdownGrace = 60
+
+// SocialConfig holds social feature settings.
+type SocialConfig struct {
+	Moments MomentsConfig `toml:"moments"`
+}
+
+// MomentsConfig controls how the agent writes social moments.
+type MomentsConfig struct {
+	// UseActivity lets moments draw on recent events (new friends, long waits,
+	// chats with the owner). Events are paraphrased — no raw numbers are shared.
+	UseActivity bool `toml:"use_activity"`
+
+	// Styles replaces the built-in post styles. An entry with only a name
+	// selects the built-in style of that name; a prompt defines a new one.
+	Styles []MomentStyle `toml:"styles,omitempty"`
+
+	Language        string `toml:"language,omitempty"` // e.g. "English", "中文"; empty = model's choice
+	MinLength       int    `toml:"min_length"`         // soft lower bound in characters (0 = none)
+	MaxLength       int    `toml:"max_length"`         // hard cap in characters
+	IntervalMinutes int    `toml:"interval_minutes"`   // minimum time between posts (0 = no local limit)
+}
+
+// MomentStyle is one post angle the agent can pick when writing a moment.
+type MomentStyle struct {
+	Name   string `toml:"name"`
+	Prompt string `toml:"prompt,omitempty"`
+}
+
+// MQTTConfig configures the optional MQTT event publisher.
+// Publishing is enabled when Broker is set.
+type MQTTConfig struct {
+	Broker      string `toml:"broker"` // tcp://host:1883 or tls://host:8883
+	Username    string `toml:"username,omitempty"`
+	Password    string `toml:"password,omitempty"`
+	ClientID    string `toml:"client_id,omitempty"`
+	TopicPrefix string `toml:"topic_prefix"`
+
+	// HomeAssistant publishes MQTT discovery configs so the agent appears
+	// as a device in Home Assistant with sensors and a pause/resume switch.
+	HomeAssistant   bool   `toml:"home_assistant"`
+	DiscoveryPrefix string `toml:"discovery_prefix,omitempty"` // default "homeassistant"
+}
+
+// AlertsConfig sets thresholds that raise "alert" events (console banner,
+// MQTT, notifications). Zero disables a check.
+type AlertsConfig struct {
+	TrustBelow      int `toml:"trust_below"`        // alert when trust score falls below this value
+	TrustDropPerDay int `toml:"trust_drop_per_day"` // alert when trust falls this much within 24h
+
+	// PauseAfterFailures pauses mining for review once this many challenges
+	// fail within the last PauseWindow inscription cycles.
+	PauseAfterFailures int `toml:"pause_after_failures"`
+	PauseWindow        int `toml:"pause_window"`
+
+	// DiagnoseRepeats runs a self-diagnosis (system checks read by the
+	// LLM, published as a "diagnosis" event) once the same error repeats
+	// this many times within DiagnoseWindowMinutes.
+	DiagnoseRepeats       int `toml:"diagnose_repeats"`
+	DiagnoseWindowMinutes int `toml:"diagnose_window_minutes"`
+}
+
+// NotifyConfig routes miner events to notification channels.
+type NotifyConfig struct {
+	// QuietHours ("23:00-07:00", local time) holds back everything below
+	// critical. Channels may override it.
+	QuietHours string `toml:"quiet_hours,omitempty"`
+
+	// Desktop shows hits, penalties and alerts as native desktop
+	// notifications, as if a "desktop" channel were configured for them.
+	Desktop bool `toml:"desktop,omitempty"`
+
+	Channels []NotifyChannel `toml:"channel,omitempty"`
+}
+
+// BackupConfig schedules snapshots of config, state and soul while the
+// miner runs as a background service.
+type BackupConfig struct {
+	Enabled       bool   `toml:"enabled"`
+	IntervalHours int    `toml:"interval_hours"`
+	Keep          int    `toml:"keep"`          // snapshots kept, locally and remotely
+	Dir           string `toml:"dir,omitempty"` // default ~/.clawwork/backups
+
+	// WebDAVURL, when set, receives a copy of every snapshot (a collection
+	// URL such as a Nextcloud folder).
+	WebDAVURL      string `toml:"webdav_url,omitempty"`
+	WebDAVUser     string `toml:"webdav_user,omitempty"`
+	WebDAVPassword string `toml:"webdav_password,omitempty"`
+}
+
+// SyncConfig replicates agent data (config, soul, state, chats) to object
+// storage for migration and disaster recovery. Sync is off while Backend
+// is empty.
+type SyncConfig struct {
+	Backend         string `toml:"backend,omitempty"` // "s3" or "webdav"
+	IntervalMinutes int    `toml:"interval_minutes"`  // push period while the service runs; 0 = manual only
+	Prefix          string `toml:"prefix,omitempty"`  // key prefix, e.g. the agent name
+
+	// URL is the WebDAV collection, or the S3 endpoint such as
+	// https://s3.eu-west-1.amazonaws.com.
+	URL string `toml:"url,omitempty"`
+
+	Bucket    string `toml:"bucket,omitempty"` // s3
+	Region    string `toml:"region,omitempty"`
+	AccessKey string `toml:"access_key,omitempty"`
+	SecretKey string `toml:"secret_key,omitempty"`
+	User      string `toml:"user,omitempty"` // webdav
+	Password  string `toml:"password,omitempty"`
+}
+
+// StorageConfig selects where state, goal, review hold and inscription
+// history are kept ([storage]). The default is JSON files in the data
+// directory.
+type StorageConfig struct {
+	Backend string `toml:"backend,omitempty"` // "files" (default) or "sqlite" (builds with -tags sqlite)
+	Path    string `toml:"path,omitempty"`    // sqlite database; default <data dir>/clawwork.db
+
+	// Namespace separates agents sharing one database (default "default").
+	Namespace string `toml:"namespace,omitempty"`
+}
+
+// NotifyChannel is one notification destination ([[notify.channel]]).
+type NotifyChannel struct {
+	Name    string `toml:"name"`
+	Type    string `toml:"type"`              // "webhook", "command" or "desktop"
+	URL     string `toml:"url,omitempty"`     // webhook: JSON is POSTed here
+	Command string `toml:"command,omitempty"` // command: run via the shell, message on stdin
+
+	// Format is the webhook payload: "json" (the full message), "slack",
+	// "discord" or "telegram". Empty picks one from the URL's host.
+	Format string `toml:"format,omitempty"`
+	ChatID string `toml:"chat_id,omitempty"` // telegram: chat to post to
+
+	MinSeverity string   `toml:"min_severity,omitempty"` // debug, info, warning (default) or critical
+	Events      []string `toml:"events,omitempty"`       // event types sent here; empty = all
+	QuietHours  string   `toml:"quiet_hours,omitempty"`  // overrides notify.quiet_hours; "off" disables
+}
+
+// WebhookFormat returns Format, or the one the URL calls for: Slack and
+// Discord incoming webhooks and the Telegram Bot API are recognized,
+// anything else gets "json".
+func (ch NotifyChannel) WebhookFormat() string {
+	if ch.Format != "" {
+		return ch.Format
+	}
+	u, err := url.Parse(ch.URL)
+	if err != nil {
+		return "json"
+	}
+	switch host := u.Hostname(); {
+	case host == "hooks.slack.com":
+		return "slack"
+	case (host == "discord.com" || host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return "discord"
+	case host == "api.telegram.org":
+		return "telegram"
+	}
+	return "json"
+}
+
+// CrashConfig controls crash reporting. Crash files are always written
+// locally; they are only sent anywhere if ReportURL is set (opt-in).
+type CrashConfig struct {
+	ReportURL string `toml:"report_url"`
+}
+
+// PrivacyConfig limits identifying metadata sent to the platform.
+type PrivacyConfig struct {
+	// MinimalHeaders sends only authentication and attestation headers
+	// (no client version in User-Agent or X-Client-Version).
+	MinimalHeaders bool `toml:"minimal_headers"`
+}
+
+// NetworkConfig applies to every outgoing HTTP connection: platform API,
+// webhooks, sync and updates, and LLM calls unless llm.proxy is set.
+type NetworkConfig struct {
+	// Proxy is "http://host:port", "socks5://host:port", or "direct" to
+	// ignore HTTP(S)_PROXY. Empty uses the environment.
+	Proxy string `toml:"proxy,omitempty"`
+
+	// CAFile is a PEM file of extra root certificates to trust, e.g. for
+	// a TLS-inspecting corporate proxy.
+	CAFile string `toml:"ca_file,omitempty"`
+
+	// MaxIdlePerHost is how many idle connections are kept open per host
+	// for reuse (0: 8).
+	MaxIdlePerHost int `toml:"max_idle_per_host,omitzero"`
+}
+
+// CallbackConfig is how platform-side changes reach the miner: webhooks
+// (claim completed, NFT verified, mail received) when Listen is set, and a
+// status poll while running as a service.
+type CallbackConfig struct {
+	Listen string `toml:"listen,omitempty"` // host:port, e.g. "0.0.0.0:2560"
+	Path   string `toml:"path,omitempty"`   // default "/clawwork/callback"
+	// Secret signs every callback (HMAC-SHA256); unsigned requests are
+	// rejected.
+	Secret string `toml:"secret,omitempty"`
+
+	// PollMinutes is how often the service polls the platform status and
+	// reports what changed (wallet, claim, NFTs, activity). 0 = off.
+	PollMinutes int `toml:"poll_minutes"`
+}
+
+// MetricsConfig writes Prometheus metrics for node_exporter's textfile
+// collector while the miner runs. Off while Textfile is empty.
+type MetricsConfig struct {
+	Textfile        string `toml:"textfile,omitempty"` // e.g. /var/lib/node_exporter/textfile_collector/clawwork.prom
+	IntervalSeconds int    `toml:"interval_seconds"`
+}
+
+// UpdateChannels lists the release channels updater.channel accepts, from
+// most to least conservative. The updater package serves exactly these.
+var UpdateChannels = []string{"stable", "beta", "nightly"}
+
+// UpdaterConfig controls `clawwork update` and automatic updates.
+type UpdaterConfig struct {
+	// Channel is the release channel to follow: "stable" (the default),
+	// "beta" for pre-releases, or "nightly". `update --channel` overrides it.
+	Channel string `toml:"channel,omitempty"`
+
+	// Auto lets the background service install updates itself: it checks
+	// every CheckHours and, after an inscription, installs the new version
+	// and restarts into it. The cooldown carries over.
+	Auto       bool `toml:"auto,omitempty"`
+	CheckHours int  `toml:"check_hours"`
+}
+
+// DisplayConfig controls how times are shown and which clock "today"
+// follows.
+type DisplayConfig struct {
+	// Timezone is an IANA name such as "Asia/Shanghai" or "UTC". It is
+	// used for printed times, daily counters and rollovers, and quiet
+	// hours. Empty uses the machine's zone.
+	Timezone string `toml:"timezone,omitempty"`
+}
+
+// Location returns the configured time zone, time.Local when unset.
+func (d DisplayConfig) Location() (*time.Location, error) {
+	if d.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("display.timezone: unknown time zone %q", d.Timezone)
+	}
+	return loc, nil
+}
+
+// WebConfig tunes the web console's event stream and remote control.
+type WebConfig struct {
+	EventHistory int `toml:"event_history"` // events kept for replay to newly connected consoles
+	ClientBuffer int `toml:"client_buffer"` // events buffered per console before drops
+
+	// ListenAddr is the host the console binds: empty or "localhost" for
+	// every loopback address (IPv4 and IPv6 where available), an IP such
+	// as "::1", or a hostname. The port comes from --port.
+	//
+	// Any other address, e.g. "0.0.0.0" or a Tailscale IP, serves the
+	// console over HTTPS and requires ConsoleToken; a plain loopback
+	// console is kept on the next free port for local commands.
+	ListenAddr string `toml:"listen_addr,omitempty"`
+
+	// ConsoleToken is the password of a console served off localhost
+	// (HTTP basic auth with any user name, or a bearer token).
+	ConsoleToken string `toml:"console_token,omitempty"`
+
+	// TLSCert and TLSKey are PEM files for a console served off
+	// localhost. Without them a self-signed certificate is generated.
+	TLSCert string `toml:"tls_cert,omitempty"`
+	TLSKey  string `toml:"tls_key,omitempty"`
+
+	// Chat storage limits. Turns beyond ChatMaxMessages and sessions
+	// beyond ChatMaxSessions or the ChatMaxSizeMB budget (0 = unlimited)
+	// are moved to compressed archives under chats/archive/.
+	ChatMaxMessages int `toml:"chat_max_messages"`
+	ChatMaxSessions int `toml:"chat_max_sessions"`
+	ChatMaxSizeMB   int `toml:"chat_max_size_mb"`
+
+	// TrashDays is how long files the chat agent deletes stay restorable
+	// in trash/ (`clawwork trash`). 0 makes its deletes permanent.
+	TrashDays int `toml:"trash_days"`
+
+	// RemoteListen, when set, serves the status and pause/resume endpoints
+	// on this address for `clawwork remote`, guarded by RemoteToken. The
+	// console itself stays on localhost.
+	RemoteListen string `toml:"remote_listen,omitempty"`
+	RemoteToken  string `toml:"remote_token,omitempty"`
 }
 
 // LoggingConfig holds logging settings.
 type LoggingConfig struct {
 	Level string `toml:"level"`
+	// Format is "text" (default, logfmt-style key=value) or "json", one
+	// object per line for Loki, ELK and the like.
+	Format string `toml:"format,omitempty"`
+	// File sends logs to this path instead of stderr. The file is reopened
+	// on SIGHUP so external log rotation works.
+	File string `toml:"file,omitempty"`
+	// MaxSizeMB rotates File once it grows past this size, keeping
+	// MaxBackups old files as file.1 (newest) … file.N. 0 leaves rotation
+	// to external tools.
+	MaxSizeMB  int `toml:"max_size_mb,omitempty"`
+	MaxBackups int `toml:"max_backups,omitempty"`
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		Agent:   AgentConfig{TokenID: 42},
-		LLM:     LLMConfig{Provider: "openai", BaseURL: "https://api.moonshot.cn/v1", Model: "kimi-k2.5"},
-		Logging: LoggingConfig{Level: "info"},
+		Agent: AgentConfig{TokenID: 42, AutoToken: AutoTokenConfig{CrowdedAt: DefaultCrowdedAt}},
+		LLM:   LLMConfig{Provider: "openai", BaseURL: "https://api.moonshot.cn/v1", Model: "kimi-k2.5"},
+		Miner: MinerConfig{
+			ShutdownGraceSeconds: DefaultShutdownGrace,
+			ContextHeader:        true,
+			Coordinate:           true,
+			CandidatesWhen:       "after_failure",
+			CandidateTemperature: 0.9,
+		},
+		Logging: LoggingConfig{Level: "info", MaxBackups: 3},
+		Social:  SocialConfig{Moments: MomentsConfig{MaxLength: 500, IntervalMinutes: 30}},
+		MQTT:    MQTTConfig{TopicPrefix: "clawwork"},
+		Web:     WebConfig{EventHistory: 200, ClientBuffer: 64, ChatMaxMessages: 40, ChatMaxSessions: 50, ChatMaxSizeMB: 200, TrashDays: 7},
+		Alerts:  AlertsConfig{PauseAfterFailures: 5, PauseWindow: 10, DiagnoseRepeats: 3, DiagnoseWindowMinutes: 30},
+		Backup:  BackupConfig{IntervalHours: 24, Keep: 7},
+		Sync:    SyncConfig{IntervalMinutes: 60},
+
+		Callback: CallbackConfig{PollMinutes: 15},
+		Metrics:  MetricsConfig{IntervalSeconds: 60},
+		Updater:  UpdaterConfig{CheckHours: 6},
 	}
 }
 
@@ -55,6 +641,11 @@ func Dir() string {
 	return filepath.Join(home, ".clawwork")
 }
 
+// TrashDir returns the directory chat tool deletions are moved to.
+func TrashDir() string {
+	return filepath.Join(Dir(), "trash")
+}
+
 // Path returns the config file path.
 func Path() string {
 	return filepath.Join(Dir(), "config.toml")
@@ -73,20 +664,38 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// Decode parses the contents of a config file over the defaults, as Load
+// does.
+func Decode(data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+	if _, err := toml.Decode(string(data), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 // Save writes the config to disk with restricted permissions.
 func (c *Config) Save() error {
 	dir := Dir()
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
-	f, err := os.OpenFile(Path(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	data, err := c.Marshal()
 	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(Path(), data, 0600); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
-	defer f.Close()
+	return nil
+}
 
-	_, _ = fmt.Fprintln(f, "# ClawWork configuration")
-	_, _ = fmt.Fprintln(f, "# Generated by: clawwork init")
-	_, _ = fmt.Fprintln(f)
-	return toml.NewEncoder(f).Encode(c)
+// Marshal encodes the config as Save writes it.
+func (c *Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# ClawWork configuration\n# Generated by: clawwork init\n\n")
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,57 @@
+package config
+
+// LLMPreset is a provider offered by init and the setup wizard.
+type LLMPreset struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	BaseURL  string `json:"base_url"`
+	Model    string `json:"model"`
+	KeyURL   string `json:"key_url"` // where to get an API key; empty if none is needed
+}
+
+// LLMPresets are the init choices 1-5, in menu order. "custom" (any
+// OpenAI-compatible API) and "platform" are offered besides them.
+var LLMPresets = []LLMPreset{
+	{"kimi", "openai", "https://api.moonshot.cn/v1", "kimi-k2.5", "https://platform.moonshot.cn/console/api-keys"},
+	{"deepseek", "openai", "https://api.deepseek.com/v1", "deepseek-reasoner", "https://platform.deepseek.com/api_keys"},
+	{"openai", "openai", "https://api.openai.com/v1", "gpt-4o-mini", "https://platform.openai.com/api-keys"},
+	{"anthropic", "anthropic", "", "claude-haiku-4-5-20251001", "https://console.anthropic.com/settings/keys"},
+	{"ollama", "ollama", "http://localhost:11434", "llama3.2", ""},
+}
+
+// LLMPresetNames lists the --llm-provider values.
+func LLMPresetNames() []string {
+	names := make([]string, 0, len(LLMPresets)+2)
+	for _, p := range LLMPresets {
+		names = append(names, p.Name)
+	}
+	return append(names, "custom", "platform")
+}
+
+// ApplyLLMPreset sets cfg's provider, base URL and model for a preset name,
+// "custom" or "platform". The key is left alone.
+func ApplyLLMPreset(cfg *LLMConfig, name string) bool {
+	switch name {
+	case "custom":
+		cfg.Provider, cfg.BaseURL, cfg.Model = "openai", "", ""
+	case "platform":
+		cfg.Provider, cfg.BaseURL, cfg.Model = "platform", "", ""
+	default:
+		p := LookupLLMPreset(name)
+		if p == nil {
+			return false
+		}
+		cfg.Provider, cfg.BaseURL, cfg.Model = p.Provider, p.BaseURL, p.Model
+	}
+	return true
+}
+
+// LookupLLMPreset returns the preset named name, or nil.
+func LookupLLMPreset(name string) *LLMPreset {
+	for i := range LLMPresets {
+		if LLMPresets[i].Name == name {
+			return &LLMPresets[i]
+		}
+	}
+	return nil
+}
@@ -2,7 +2,14 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/schedule"
 )
 
 // Validate checks that the config has all required fields.
@@ -16,6 +23,22 @@ func (c *Config) Validate() error {
 	if c.Agent.TokenID < 25 || c.Agent.TokenID > 1024 {
 		return fmt.Errorf("agent.token_id must be between 25 and 1024")
 	}
+	for _, id := range c.Agent.ExtraTokens {
+		if id < 25 || id > 1024 {
+			return fmt.Errorf("agent.extra_tokens: %d is not between 25 and 1024", id)
+		}
+	}
+
+	if err := c.Agent.AutoToken.Validate(); err != nil {
+		return err
+	}
+	if err := c.Schedule.Validate(); err != nil {
+		return err
+	}
+
+	if !validLabel(c.Agent.Label) {
+		return fmt.Errorf("agent.label must be at most 64 letters, digits or =._:/,+- (no spaces), e.g. fleet=eu-west-1")
+	}
 
 	switch c.LLM.Provider {
 	case "platform":
@@ -36,20 +59,507 @@ func (c *Config) Validate() error {
 	default:
 		return fmt.Errorf("llm.provider must be one of: platform, openai, anthropic, ollama")
 	}
+
+	if c.LLM.ContextWindow < 0 || (c.LLM.ContextWindow > 0 && c.LLM.ContextWindow < 2048) {
+		return fmt.Errorf("llm.context_window must be 0 (auto) or at least 2048")
+	}
+
+	if c.LLM.AnswerTimeoutSeconds < 0 || c.LLM.AnswerTimeoutSeconds > 1800 {
+		return fmt.Errorf("llm.answer_timeout_seconds must be between 0 (provider default) and 1800")
+	}
+	if o := c.LLM.Ollama; o.NumCtx < 0 || (o.NumCtx > 0 && o.NumCtx < 512) {
+		return fmt.Errorf("llm.ollama.num_ctx must be 0 (default) or at least 512")
+	}
+	if t := c.LLM.Ollama.Temperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("llm.ollama.temperature must be between 0 and 2")
+	}
+	if ka := c.LLM.Ollama.KeepAlive; ka != "" {
+		if _, err := strconv.Atoi(ka); err != nil {
+			if _, err := time.ParseDuration(ka); err != nil {
+				return fmt.Errorf("llm.ollama.keep_alive must be a duration like \"10m\" or seconds like \"-1\"")
+			}
+		}
+	}
+
+	if c.Miner.ShutdownGraceSeconds < 0 || c.Miner.ShutdownGraceSeconds > 600 {
+		return fmt.Errorf("miner.shutdown_grace_seconds must be between 0 and 600")
+	}
+
+	if c.Web.EventHistory < 0 || c.Web.EventHistory > 10000 {
+		return fmt.Errorf("web.event_history must be between 0 and 10000")
+	}
+	if c.Web.ClientBuffer < 0 || c.Web.ClientBuffer > 4096 {
+		return fmt.Errorf("web.client_buffer must be between 0 and 4096")
+	}
+	if c.Backup.IntervalHours < 1 || c.Backup.IntervalHours > 24*30 {
+		return fmt.Errorf("backup.interval_hours must be between 1 and 720")
+	}
+	if c.Backup.Keep < 1 || c.Backup.Keep > 1000 {
+		return fmt.Errorf("backup.keep must be between 1 and 1000")
+	}
+	if u := c.Backup.WebDAVURL; u != "" {
+		if p, err := url.Parse(u); err != nil || p.Scheme != "https" || p.Host == "" {
+			return fmt.Errorf("backup.webdav_url must be an https URL")
+		}
+	}
+	if err := c.Sync.Validate(); err != nil {
+		return err
+	}
+	if b := c.Storage.Backend; b != "" && b != "files" && b != "sqlite" {
+		return fmt.Errorf("storage.backend must be files or sqlite")
+	}
+	if _, err := c.Display.Location(); err != nil {
+		return err
+	}
+	if f := c.Logging.Format; f != "" && f != "text" && f != "json" {
+		return fmt.Errorf("logging.format must be text or json")
+	}
+	if c.Logging.MaxSizeMB < 0 || c.Logging.MaxBackups < 0 {
+		return fmt.Errorf("logging: max_size_mb and max_backups must not be negative")
+	}
+	if c.Web.ChatMaxMessages < 2 || c.Web.ChatMaxMessages > 1000 {
+		return fmt.Errorf("web.chat_max_messages must be between 2 and 1000")
+	}
+	if c.Web.ChatMaxSessions < 1 || c.Web.ChatMaxSessions > 10000 {
+		return fmt.Errorf("web.chat_max_sessions must be between 1 and 10000")
+	}
+	if c.Web.ChatMaxSizeMB < 0 {
+		return fmt.Errorf("web.chat_max_size_mb must be 0 (unlimited) or more")
+	}
+	if c.Web.TrashDays < 0 {
+		return fmt.Errorf("web.trash_days must be 0 (no trash) or more")
+	}
+	if h := c.Web.ListenAddr; h != "" {
+		if strings.ContainsAny(h, "[]/") {
+			return fmt.Errorf("web.listen_addr must be a host without port or brackets, e.g. ::1")
+		}
+		// A hostname may resolve to anything by the time the console
+		// starts, so only localhost and loopback addresses go without a
+		// token.
+		if ip := net.ParseIP(h); h != "localhost" && (ip == nil || !ip.IsLoopback()) && len(c.Web.ConsoleToken) < 16 {
+			return fmt.Errorf("web.console_token must be at least 16 characters to serve the console on %s", h)
+		}
+	}
+	if (c.Web.TLSCert == "") != (c.Web.TLSKey == "") {
+		return fmt.Errorf("web.tls_cert and web.tls_key must be set together")
+	}
+	if c.Web.RemoteListen != "" {
+		if _, _, err := net.SplitHostPort(c.Web.RemoteListen); err != nil {
+			return fmt.Errorf("web.remote_listen must be host:port, e.g. 0.0.0.0:2540")
+		}
+		if len(c.Web.RemoteToken) < 16 {
+			return fmt.Errorf("web.remote_token must be at least 16 characters when remote_listen is set")
+		}
+	}
+	if c.Metrics.Textfile != "" {
+		if !strings.HasSuffix(c.Metrics.Textfile, ".prom") {
+			return fmt.Errorf("metrics.textfile must end in .prom (the textfile collector ignores other files)")
+		}
+		if c.Metrics.IntervalSeconds < 5 || c.Metrics.IntervalSeconds > 3600 {
+			return fmt.Errorf("metrics.interval_seconds must be between 5 and 3600")
+		}
+	}
+	if c.Callback.PollMinutes < 0 || (c.Callback.PollMinutes > 0 && c.Callback.PollMinutes < 5) {
+		return fmt.Errorf("callback.poll_minutes must be 0 (off) or at least 5")
+	}
+	if c.Callback.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Callback.Listen); err != nil {
+			return fmt.Errorf("callback.listen must be host:port, e.g. 0.0.0.0:2560")
+		}
+		if len(c.Callback.Secret) < 16 {
+			return fmt.Errorf("callback.secret must be at least 16 characters when callback.listen is set")
+		}
+		if p := c.Callback.Path; p != "" && !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("callback.path must start with /")
+		}
+	}
+
+	if ch := c.Updater.Channel; ch != "" && !slices.Contains(UpdateChannels, ch) {
+		return fmt.Errorf("updater.channel must be one of %s", strings.Join(UpdateChannels, ", "))
+	}
+	if c.Updater.Auto && (c.Updater.CheckHours < 1 || c.Updater.CheckHours > 168) {
+		return fmt.Errorf("updater.check_hours must be between 1 and 168")
+	}
+
+	switch c.Miner.AnswerLanguage {
+	case "", "auto", "off", "en", "zh", "ja", "ko", "ru":
+	default:
+		return fmt.Errorf("miner.answer_language must be auto, off, en, zh, ja, ko or ru")
+	}
+	switch c.Miner.Strategy {
+	case "", "single", "verify", "best_of_k", "few_shot":
+	default:
+		return fmt.Errorf("miner.strategy must be single, verify, best_of_k or few_shot")
+	}
+	if c.Miner.Candidates < 0 || c.Miner.Candidates > 5 {
+		return fmt.Errorf("miner.candidates must be between 0 and 5")
+	}
+	switch c.Miner.CandidatesWhen {
+	case "", "after_failure", "always":
+	default:
+		return fmt.Errorf("miner.candidates_when must be after_failure or always")
+	}
+	if t := c.Miner.CandidateTemperature; t < 0 || t > 1 {
+		return fmt.Errorf("miner.candidate_temperature must be between 0 and 1")
+	}
+
+	if err := c.validateExperiment(); err != nil {
+		return err
+	}
+
+	if p := c.LLM.Proxy; p != "" && p != "direct" {
+		if u, err := url.Parse(p); err != nil || u.Host == "" {
+			return fmt.Errorf("llm.proxy must be a URL like http://host:port or \"direct\"")
+		}
+	}
+	if p := c.Network.Proxy; p != "" && p != "direct" {
+		if u, err := url.Parse(p); err != nil || u.Host == "" {
+			return fmt.Errorf("network.proxy must be a URL like http://host:port or \"direct\"")
+		}
+	}
+	if c.Network.MaxIdlePerHost < 0 {
+		return fmt.Errorf("network.max_idle_per_host must be 0 (default) or more")
+	}
+
+	if c.Alerts.TrustBelow < 0 || c.Alerts.TrustDropPerDay < 0 || c.Alerts.PauseAfterFailures < 0 || c.Alerts.PauseWindow < 0 {
+		return fmt.Errorf("alerts: thresholds must not be negative")
+	}
+	if c.Alerts.PauseAfterFailures > 0 && c.Alerts.PauseWindow == 0 {
+		return fmt.Errorf("alerts.pause_window must be set when pause_after_failures is")
+	}
+	if c.Alerts.DiagnoseRepeats < 0 || c.Alerts.DiagnoseWindowMinutes < 0 {
+		return fmt.Errorf("alerts: diagnose_repeats and diagnose_window_minutes must not be negative")
+	}
+	if c.Alerts.DiagnoseRepeats > 0 && c.Alerts.DiagnoseWindowMinutes == 0 {
+		return fmt.Errorf("alerts.diagnose_window_minutes must be set when diagnose_repeats is")
+	}
+
+	if u := c.Crash.ReportURL; u != "" && !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		return fmt.Errorf("crash.report_url must be an http(s) URL")
+	}
+
+	mc := c.Social.Moments
+	if mc.MaxLength < 0 || mc.MinLength < 0 || (mc.MaxLength > 0 && mc.MinLength > mc.MaxLength) {
+		return fmt.Errorf("social.moments: min_length must be between 0 and max_length")
+	}
+	if mc.IntervalMinutes < 0 {
+		return fmt.Errorf("social.moments.interval_minutes must not be negative")
+	}
+	for _, st := range mc.Styles {
+		if st.Name == "" {
+			return fmt.Errorf("social.moments.styles: every style needs a name")
+		}
+	}
+
+	if err := c.Notify.validate(); err != nil {
+		return err
+	}
+
+	if c.MQTT.Broker != "" {
+		if !strings.Contains(c.MQTT.Broker, "://") {
+			return fmt.Errorf("mqtt.broker must be a URL like tcp://host:1883")
+		}
+		if c.MQTT.TopicPrefix == "" || strings.ContainsAny(c.MQTT.TopicPrefix, "+#") {
+			return fmt.Errorf("mqtt.topic_prefix must be set and must not contain wildcards")
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateExperiment() error {
+	e := c.Experiment
+	if !e.Enabled() {
+		return nil
+	}
+	if e.A == e.B {
+		return fmt.Errorf("experiment: arms a and b are identical")
+	}
+	for _, arm := range []struct {
+		key string
+		ExperimentArm
+	}{{"a", e.A}, {"b", e.B}} {
+		l := arm.LLM(c.LLM)
+		switch l.Provider {
+		case "platform", "openai", "anthropic":
+			if l.APIKey == "" {
+				return fmt.Errorf("experiment.%s.api_key is required for provider %q", arm.key, l.Provider)
+			}
+			if l.Model == "" && l.Provider != "platform" {
+				return fmt.Errorf("experiment.%s.model is required", arm.key)
+			}
+			if l.Provider == "openai" && l.BaseURL == "" {
+				return fmt.Errorf("experiment.%s.base_url is required for provider openai", arm.key)
+			}
+		case "ollama":
+			if l.Model == "" {
+				return fmt.Errorf("experiment.%s.model is required", arm.key)
+			}
+		default:
+			return fmt.Errorf("experiment.%s.provider must be one of: platform, openai, anthropic, ollama", arm.key)
+		}
+		switch arm.Strategy {
+		case "", "single", "verify", "best_of_k", "few_shot":
+		default:
+			return fmt.Errorf("experiment.%s.strategy must be single, verify, best_of_k or few_shot", arm.key)
+		}
+		if t := arm.PromptTemplate; t != "" && !strings.Contains(t, "{challenge}") {
+			return fmt.Errorf("experiment.%s.prompt_template must contain {challenge}", arm.key)
+		}
+	}
+	return nil
+}
+
+func (n NotifyConfig) validate() error {
+	if !validQuietHours(n.QuietHours) {
+		return fmt.Errorf("notify.quiet_hours must look like 23:00-07:00")
+	}
+	names := make(map[string]bool)
+	if n.Desktop {
+		names["desktop"] = true
+	}
+	for i, ch := range n.Channels {
+		if ch.Name == "" {
+			return fmt.Errorf("notify.channel %d: name is required", i+1)
+		}
+		if names[ch.Name] {
+			if n.Desktop && ch.Name == "desktop" {
+				return fmt.Errorf("notify.channel %q: name is taken by notify.desktop", ch.Name)
+			}
+			return fmt.Errorf("notify.channel %q: duplicate name", ch.Name)
+		}
+		names[ch.Name] = true
+		switch ch.Type {
+		case "webhook":
+			if !strings.HasPrefix(ch.URL, "https://") && !strings.HasPrefix(ch.URL, "http://") {
+				return fmt.Errorf("notify.channel %q: url must be an http(s) URL", ch.Name)
+			}
+			switch ch.WebhookFormat() {
+			case "json", "slack", "discord":
+			case "telegram":
+				if ch.ChatID == "" {
+					return fmt.Errorf("notify.channel %q: chat_id is required for telegram", ch.Name)
+				}
+			default:
+				return fmt.Errorf("notify.channel %q: format must be json, slack, discord or telegram", ch.Name)
+			}
+		case "command":
+			if strings.TrimSpace(ch.Command) == "" {
+				return fmt.Errorf("notify.channel %q: command is required", ch.Name)
+			}
+		case "desktop":
+		default:
+			return fmt.Errorf("notify.channel %q: type must be webhook, command or desktop", ch.Name)
+		}
+		switch ch.MinSeverity {
+		case "", "debug", "info", "warning", "critical":
+		default:
+			return fmt.Errorf("notify.channel %q: min_severity must be debug, info, warning or critical", ch.Name)
+		}
+		if !validQuietHours(ch.QuietHours) {
+			return fmt.Errorf("notify.channel %q: quiet_hours must look like 23:00-07:00 or be \"off\"", ch.Name)
+		}
+	}
 	return nil
 }
 
+// validLabel accepts "" and short header-safe labels.
+func validLabel(s string) bool {
+	if len(s) > 64 {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("=._:/,+-", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validQuietHours accepts "", "off" and "HH:MM-HH:MM".
+func validQuietHours(s string) bool {
+	if s == "" || s == "off" {
+		return true
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return false
+	}
+	_, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	_, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	return err1 == nil && err2 == nil
+}
+
 // Redact returns a copy of the config with API keys masked for display.
 func (c *Config) Redact() *Config {
 	copy := *c
 	copy.Agent.APIKey = redactKey(c.Agent.APIKey)
 	copy.LLM.APIKey = redactKey(c.LLM.APIKey)
+	if c.MQTT.Password != "" {
+		copy.MQTT.Password = redactKey(c.MQTT.Password)
+	}
+	if c.Web.RemoteToken != "" {
+		copy.Web.RemoteToken = redactKey(c.Web.RemoteToken)
+	}
+	if c.Web.ConsoleToken != "" {
+		copy.Web.ConsoleToken = redactKey(c.Web.ConsoleToken)
+	}
+	if c.Callback.Secret != "" {
+		copy.Callback.Secret = redactKey(c.Callback.Secret)
+	}
+	if c.Backup.WebDAVPassword != "" {
+		copy.Backup.WebDAVPassword = redactKey(c.Backup.WebDAVPassword)
+	}
+	if c.Sync.SecretKey != "" {
+		copy.Sync.SecretKey = redactKey(c.Sync.SecretKey)
+	}
+	if c.Sync.Password != "" {
+		copy.Sync.Password = redactKey(c.Sync.Password)
+	}
+	if c.Experiment.A.APIKey != "" {
+		copy.Experiment.A.APIKey = redactKey(c.Experiment.A.APIKey)
+	}
+	if c.Experiment.B.APIKey != "" {
+		copy.Experiment.B.APIKey = redactKey(c.Experiment.B.APIKey)
+	}
+	if len(c.Notify.Channels) > 0 {
+		// Webhook URLs usually embed their credentials.
+		copy.Notify.Channels = append([]NotifyChannel(nil), c.Notify.Channels...)
+		for i := range copy.Notify.Channels {
+			if u := copy.Notify.Channels[i].URL; u != "" {
+				copy.Notify.Channels[i].URL = redactKey(u)
+			}
+		}
+	}
+	if len(c.LLM.Headers) > 0 {
+		copy.LLM.Headers = make(map[string]string, len(c.LLM.Headers))
+		for k, v := range c.LLM.Headers {
+			copy.LLM.Headers[k] = redactKey(v)
+		}
+	}
 	return &copy
 }
 
+// WithoutSecrets returns a copy of the config with every credential —
+// API keys, tokens, passwords, webhook URLs and LLM header values —
+// blanked, for copies that leave the machine.
+func (c *Config) WithoutSecrets() *Config {
+	copy := *c
+	for _, p := range copy.secretFields() {
+		*p = ""
+	}
+	copy.Notify.Channels = append([]NotifyChannel(nil), c.Notify.Channels...)
+	for i := range copy.Notify.Channels {
+		copy.Notify.Channels[i].URL = ""
+	}
+	if len(c.LLM.Headers) > 0 {
+		copy.LLM.Headers = make(map[string]string, len(c.LLM.Headers))
+		for k := range c.LLM.Headers {
+			copy.LLM.Headers[k] = ""
+		}
+	}
+	return &copy
+}
+
+// RestoreSecrets fills the credentials blanked by WithoutSecrets from
+// local, this machine's config. Webhook URLs are matched by channel name
+// and headers by name.
+func (c *Config) RestoreSecrets(local *Config) {
+	c.Notify.Channels = append([]NotifyChannel(nil), c.Notify.Channels...)
+	dst, src := c.secretFields(), local.secretFields()
+	for i := range dst {
+		if *dst[i] == "" {
+			*dst[i] = *src[i]
+		}
+	}
+	for i, ch := range c.Notify.Channels {
+		for _, l := range local.Notify.Channels {
+			if ch.URL == "" && l.Name == ch.Name {
+				c.Notify.Channels[i].URL = l.URL
+			}
+		}
+	}
+	if len(c.LLM.Headers) > 0 {
+		headers := make(map[string]string, len(c.LLM.Headers))
+		for k, v := range c.LLM.Headers {
+			if v == "" {
+				v = local.LLM.Headers[k]
+			}
+			headers[k] = v
+		}
+		c.LLM.Headers = headers
+	}
+}
+
+// secretFields points at the single-valued credentials, in a fixed order
+// so RestoreSecrets can pair them up.
+func (c *Config) secretFields() []*string {
+	return []*string{
+		&c.Agent.APIKey, &c.LLM.APIKey, &c.MQTT.Password,
+		&c.Web.RemoteToken, &c.Web.ConsoleToken, &c.Callback.Secret,
+		&c.Backup.WebDAVPassword, &c.Sync.AccessKey, &c.Sync.SecretKey, &c.Sync.Password,
+		&c.Experiment.A.APIKey, &c.Experiment.B.APIKey,
+	}
+}
+
 func redactKey(key string) string {
 	if len(key) <= 8 {
 		return "****"
 	}
 	return key[:4] + "..." + key[len(key)-4:]
 }
+
+// Validate checks the [sync] section on its own, so `clawwork sync pull`
+// works on a new machine whose config has nothing else yet.
+func (s SyncConfig) Validate() error {
+	if s.Backend == "" {
+		return nil
+	}
+	if s.Backend != "s3" && s.Backend != "webdav" {
+		return fmt.Errorf("sync.backend must be s3 or webdav")
+	}
+	if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("sync.url must be an http(s) URL")
+	}
+	if s.IntervalMinutes < 0 {
+		return fmt.Errorf("sync.interval_minutes must be 0 (manual) or more")
+	}
+	if strings.Contains(s.Prefix, "..") {
+		return fmt.Errorf("sync.prefix must not contain \"..\"")
+	}
+	if s.Backend == "s3" && (s.Bucket == "" || s.AccessKey == "" || s.SecretKey == "") {
+		return fmt.Errorf("sync.bucket, sync.access_key and sync.secret_key are required for s3")
+	}
+	return nil
+}
+
+// Validate checks the cooldown and parses the active hours.
+func (s ScheduleConfig) Validate() error {
+	if s.CooldownSeconds != 0 && s.CooldownSeconds < MinCooldownSeconds {
+		return fmt.Errorf("schedule.cooldown_seconds must be 0 (platform default) or at least %d", MinCooldownSeconds)
+	}
+	if _, err := schedule.Parse(s.ActiveHours); err != nil {
+		return fmt.Errorf("schedule.active_hours: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the strategy and candidates, e.g. after flags changed them.
+func (a AutoTokenConfig) Validate() error {
+	switch a.Strategy {
+	case "", TokenLeastCrowded, TokenSequential, TokenRandom:
+	default:
+		return fmt.Errorf("agent.auto_token.strategy must be one of: least-crowded, sequential, random")
+	}
+	for _, id := range a.Candidates {
+		if id < 25 || id > 1024 {
+			return fmt.Errorf("agent.auto_token.candidates: %d is not between 25 and 1024", id)
+		}
+	}
+	if a.CrowdedAt < 0 {
+		return fmt.Errorf("agent.auto_token.crowded_at must be 0 (only when taken) or more")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// isSecretTag reports whether a toml key names a credential.
+func isSecretTag(tag string) bool {
+	name, _, _ := strings.Cut(tag, ",")
+	for _, s := range []string{"api_key", "access_key", "secret_key", "password", "_token", "secret"} {
+		if name == s || strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fillSecrets sets every credential-looking string field under v to a
+// distinct value and returns the values it set.
+func fillSecrets(v reflect.Value, set *[]string) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.Struct:
+			fillSecrets(f, set)
+		case f.Kind() == reflect.String && isSecretTag(t.Field(i).Tag.Get("toml")):
+			s := fmt.Sprintf("secret-%02d-%s", len(*set), t.Field(i).Name)
+			f.SetString(s)
+			*set = append(*set, s)
+		}
+	}
+}
+
+func TestSecretsRoundTrip(t *testing.T) {
+	local := DefaultConfig()
+	var secrets []string
+	fillSecrets(reflect.ValueOf(local).Elem(), &secrets)
+	local.Notify.Channels = []NotifyChannel{
+		{Name: "ops", URL: "https://hooks.example.com/secret-ops"},
+		{Name: "me", URL: "https://hooks.example.com/secret-me"},
+	}
+	local.LLM.Headers = map[string]string{"X-Api-Key": "secret-header"}
+	secrets = append(secrets, local.Notify.Channels[0].URL, local.Notify.Channels[1].URL, "secret-header")
+	if n := len(local.secretFields()); n+3 != len(secrets) {
+		t.Fatalf("secretFields lists %d credentials, the config has %d", n, len(secrets)-3)
+	}
+	want := fmt.Sprint(*local)
+
+	stripped := local.WithoutSecrets()
+	data, err := stripped.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range secrets {
+		if strings.Contains(string(data), s) {
+			t.Errorf("stripped config still contains %q", s)
+		}
+	}
+	if got := fmt.Sprint(*local); got != want {
+		t.Error("WithoutSecrets changed the original config")
+	}
+
+	restored, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.RestoreSecrets(local)
+	if !reflect.DeepEqual(restored, local) {
+		t.Errorf("restored config differs:\n got %+v\nwant %+v", *restored, *local)
+	}
+}
@@ -0,0 +1,189 @@
+// Package crash records panics and fatal errors to local crash files and,
+// when the user opts in, submits sanitized copies to a configured endpoint.
+package crash
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// maxCrashFiles caps how many crash files are kept on disk.
+const maxCrashFiles = 20
+
+// Report is the content of a crash file and of a submitted report.
+type Report struct {
+	Kind      string    `json:"kind"` // "panic" or "fatal"
+	Message   string    `json:"message"`
+	Stack     string    `json:"stack"`
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	Time      time.Time `json:"time"`
+	UptimeSec int64     `json:"uptime_sec"`
+}
+
+// Reporter captures crashes for one process.
+type Reporter struct {
+	url     string
+	version string
+	secrets []string
+	started time.Time
+
+	stopFatal func() // set by CaptureFatal
+}
+
+// New creates a reporter. Secrets (API keys, passwords) are scrubbed from
+// every report; an empty cfg.ReportURL keeps reports local.
+func New(cfg config.CrashConfig, version string, secrets ...string) *Reporter {
+	r := &Reporter{url: cfg.ReportURL, version: version, started: time.Now()}
+	for _, s := range secrets {
+		if len(s) >= 6 {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	return r
+}
+
+// Dir returns the crash file directory.
+func Dir() string {
+	return filepath.Join(config.Dir(), "crashes")
+}
+
+// Recover records a panic and re-panics so the process still exits with
+// the usual trace. Use as `defer r.Recover()` at the top of a goroutine.
+func (r *Reporter) Recover() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	path := r.Record("panic", fmt.Sprint(v), debug.Stack())
+	if r.stopFatal != nil {
+		r.stopFatal() // already reported; don't report the re-panic again
+	}
+	if path != "" {
+		fmt.Fprintf(os.Stderr, "\nclawwork crashed — report saved to %s\n", path)
+	}
+	panic(v)
+}
+
+// CollectPrevious turns runtime crash output left by earlier processes
+// (see CaptureFatal) into reports, and removes empty capture files.
+func (r *Reporter) CollectPrevious() {
+	files, _ := filepath.Glob(filepath.Join(Dir(), "runtime-*.log"))
+	for _, f := range files {
+		if f == runtimeLogPath() {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			r.Record("fatal", fatalMessage(string(data)), data)
+		}
+		_ = os.Remove(f)
+	}
+}
+
+// fatalMessage picks the headline of captured runtime crash output.
+func fatalMessage(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "fatal error:") || strings.HasPrefix(line, "panic:") {
+			return line
+		}
+	}
+	return "runtime crash (see stack)"
+}
+
+// runtimeLogPath is where this process's runtime crash output is captured.
+func runtimeLogPath() string {
+	return filepath.Join(Dir(), fmt.Sprintf("runtime-%d.log", os.Getpid()))
+}
+
+// Record writes a crash file and submits it if a report URL is configured.
+// Returns the crash file path, or "" if it could not be written.
+func (r *Reporter) Record(kind, message string, stack []byte) string {
+	rep := Report{
+		Kind:      kind,
+		Message:   r.sanitize(message),
+		Stack:     r.sanitize(string(stack)),
+		Version:   r.version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Time:      time.Now().UTC(),
+		UptimeSec: int64(time.Since(r.started).Seconds()),
+	}
+	data, _ := json.MarshalIndent(rep, "", "  ")
+
+	path := ""
+	if err := os.MkdirAll(Dir(), 0700); err == nil {
+		path = filepath.Join(Dir(), "crash-"+rep.Time.Format("20060102-150405")+".json")
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			path = ""
+		}
+		prune()
+	}
+
+	if r.url != "" {
+		if err := submit(r.url, data); err != nil {
+			fmt.Fprintf(os.Stderr, "crash report submission failed: %s\n", err)
+		}
+	}
+	return path
+}
+
+// sanitize removes secrets and the home directory from report text.
+func (r *Reporter) sanitize(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, "[REDACTED]")
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+		s = strings.ReplaceAll(s, home, "~")
+	}
+	return s
+}
+
+func submit(url string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// prune deletes the oldest crash files beyond maxCrashFiles.
+func prune() {
+	files, _ := filepath.Glob(filepath.Join(Dir(), "crash-*.json"))
+	if len(files) <= maxCrashFiles {
+		return
+	}
+	sort.Strings(files) // timestamped names sort chronologically
+	for _, f := range files[:len(files)-maxCrashFiles] {
+		_ = os.Remove(f)
+	}
+}
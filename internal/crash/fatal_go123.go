@@ -0,0 +1,38 @@
+//go:build go1.23
+
+package crash
+
+import (
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+// CaptureFatal redirects the Go runtime's crash output (fatal errors such as
+// concurrent map writes, and panics in goroutines without Recover) to a
+// per-process file that CollectPrevious reports on the next start.
+// The returned function stops capturing and removes the file.
+func (r *Reporter) CaptureFatal() (stop func()) {
+	if err := os.MkdirAll(Dir(), 0700); err != nil {
+		return func() {}
+	}
+	path := runtimeLogPath()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return func() {}
+	}
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		f.Close()
+		_ = os.Remove(path)
+		return func() {}
+	}
+	var once sync.Once
+	r.stopFatal = func() {
+		once.Do(func() {
+			_ = debug.SetCrashOutput(nil, debug.CrashOptions{})
+			f.Close()
+			_ = os.Remove(path)
+		})
+	}
+	return r.stopFatal
+}
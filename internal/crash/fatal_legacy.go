@@ -0,0 +1,9 @@
+//go:build !go1.23
+
+package crash
+
+// CaptureFatal is a no-op on toolchains without debug.SetCrashOutput;
+// only panics caught by Recover are reported.
+func (r *Reporter) CaptureFatal() (stop func()) {
+	return func() {}
+}
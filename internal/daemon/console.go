@@ -0,0 +1,7 @@
+//go:build !windows
+
+package daemon
+
+// detachConsole is a no-op: launchd and systemd start the miner without a
+// terminal.
+func detachConsole() {}
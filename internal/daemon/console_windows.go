@@ -0,0 +1,9 @@
+package daemon
+
+import "syscall"
+
+// detachConsole releases the console Task Scheduler opened for the miner,
+// which closes its window. Output already goes to the log file.
+func detachConsole() {
+	_, _, _ = syscall.NewLazyDLL("kernel32.dll").NewProc("FreeConsole").Call()
+}
@@ -1,5 +1,6 @@
 // Package daemon manages ClawWork as a background service using
-// platform-native service managers (launchd on macOS, systemd on Linux).
+// platform-native service managers (launchd on macOS, systemd on Linux,
+// Task Scheduler on Windows).
 package daemon
 
 import (
@@ -33,6 +34,42 @@ func LogPath() string {
 	return filepath.Join(config.Dir(), "daemon.log")
 }
 
+// StopTimeout returns how many seconds the service manager should wait after
+// SIGTERM before killing the process: the configured shutdown grace plus headroom
+// for ending the session and flushing state.
+func StopTimeout() int {
+	grace := config.DefaultShutdownGrace
+	if cfg, err := config.Load(); err == nil {
+		grace = cfg.Miner.ShutdownGraceSeconds
+	}
+	return grace + 15
+}
+
+// ServiceEnv is set in the service definition so the miner can record
+// that it was started by the service manager.
+const ServiceEnv = "CLAWWORK_SERVICE"
+
+// UnderService reports whether this process was started by the installed
+// service. Services installed by older versions lack ServiceEnv; on macOS
+// launchd's job label gives them away.
+func UnderService() bool {
+	return os.Getenv(ServiceEnv) == "1" || os.Getenv("XPC_SERVICE_NAME") == "ai.clawplaza.clawwork"
+}
+
+// EnterService prepares a miner started by a service manager that can't
+// set its environment or redirect its output (Task Scheduler): it sets
+// ServiceEnv, appends stdout and stderr to LogPath and drops the console
+// window.
+func EnterService() error {
+	f, err := os.OpenFile(LogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("open service log: %w", err)
+	}
+	os.Stdout, os.Stderr = f, f
+	detachConsole()
+	return os.Setenv(ServiceEnv, "1")
+}
+
 // ExecPath returns the resolved absolute path of the running binary.
 func ExecPath() (string, error) {
 	p, err := os.Executable()
@@ -7,11 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
-	"strings"
-	"syscall"
 
-	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/miner"
 )
 
 const label = "ai.clawplaza.clawwork"
@@ -53,17 +50,24 @@ func (m *launchdManager) Install() error {
         <string>%s</string>
         <string>insc</string>
     </array>
+    <key>EnvironmentVariables</key>
+    <dict>
+        <key>CLAWWORK_SERVICE</key>
+        <string>1</string>
+    </dict>
     <key>RunAtLoad</key>
     <true/>
     <key>KeepAlive</key>
     <true/>
+    <key>ExitTimeOut</key>
+    <integer>%d</integer>
     <key>StandardOutPath</key>
     <string>%s</string>
     <key>StandardErrorPath</key>
     <string>%s</string>
 </dict>
 </plist>
-`, label, execPath, logPath, logPath)
+`, label, execPath, StopTimeout(), logPath, logPath)
 
 	// Ensure LaunchAgents directory exists.
 	if err := os.MkdirAll(filepath.Dir(plistPath()), 0755); err != nil {
@@ -129,33 +133,12 @@ func (m *launchdManager) Status() (*Status, error) {
 		s.Installed = true
 	}
 
-	// Check if process is running via lock file.
-	if pid, alive := pidFromLockFile(); alive {
+	// launchd doesn't say whether the job is up; the lock file does. A
+	// foreground miner's lock doesn't count (older locks have no mode).
+	if info := miner.Running(); info != nil && info.Mode != miner.ModeForeground {
 		s.Running = true
-		s.PID = pid
+		s.PID = info.PID
 	}
 
 	return s, nil
 }
-
-// pidFromLockFile reads the PID from the mine.lock file and checks
-// whether the process is still alive.
-func pidFromLockFile() (int, bool) {
-	lockPath := filepath.Join(config.Dir(), "mine.lock")
-	data, err := os.ReadFile(lockPath)
-	if err != nil {
-		return 0, false
-	}
-	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
-	if err != nil {
-		return 0, false
-	}
-	proc, err := os.FindProcess(pid)
-	if err != nil {
-		return pid, false
-	}
-	if proc.Signal(syscall.Signal(0)) == nil {
-		return pid, true
-	}
-	return pid, false
-}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// Reexec replaces this process with the binary now at its path, keeping
+// the arguments, environment and PID, so the service manager goes on
+// tracking the miner.
+func Reexec() error {
+	path, err := ExecPath()
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, os.Args, os.Environ())
+}
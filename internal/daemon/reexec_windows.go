@@ -0,0 +1,11 @@
+package daemon
+
+import "errors"
+
+// Reexec can't replace a running process on Windows, and a child started
+// from here would escape the task. It returns an error instead: the miner
+// exits with a failure and the task's RestartOnFailure starts the new
+// binary.
+func Reexec() error {
+	return errors.New("restarting into the new version: Task Scheduler starts it within a minute")
+}
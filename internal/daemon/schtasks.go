@@ -0,0 +1,174 @@
+//go:build windows
+
+package daemon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"time"
+	"unicode/utf16"
+
+	"github.com/clawplaza/clawwork-cli/internal/miner"
+)
+
+const taskName = "ClawWork"
+
+// New returns a Windows Task Scheduler service manager. The task runs at
+// logon as the current user, so no administrator rights are needed.
+func New() (Manager, error) {
+	return &schtasksManager{}, nil
+}
+
+type schtasksManager struct{}
+
+// taskXML is the task definition. Task Scheduler can neither set the
+// environment nor redirect output, so the miner is started with --service
+// and does both itself (see EnterService).
+const taskXML = `<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <RegistrationInfo>
+    <Description>ClawWork Inscription Agent</Description>
+  </RegistrationInfo>
+  <Triggers>
+    <LogonTrigger>
+      <Enabled>true</Enabled>
+      <UserId>%[1]s</UserId>
+    </LogonTrigger>
+  </Triggers>
+  <Principals>
+    <Principal id="Author">
+      <UserId>%[1]s</UserId>
+      <LogonType>InteractiveToken</LogonType>
+      <RunLevel>LeastPrivilege</RunLevel>
+    </Principal>
+  </Principals>
+  <Settings>
+    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>
+    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>
+    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>
+    <StartWhenAvailable>true</StartWhenAvailable>
+    <ExecutionTimeLimit>PT0S</ExecutionTimeLimit>
+    <RestartOnFailure>
+      <Interval>PT1M</Interval>
+      <Count>999</Count>
+    </RestartOnFailure>
+    <Enabled>true</Enabled>
+  </Settings>
+  <Actions Context="Author">
+    <Exec>
+      <Command>%[2]s</Command>
+      <Arguments>insc --service</Arguments>
+      <WorkingDirectory>%[3]s</WorkingDirectory>
+    </Exec>
+  </Actions>
+</Task>
+`
+
+func schtasks(args ...string) error {
+	if out, err := exec.Command("schtasks", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("schtasks %s: %s (%w)", args[0], bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+func (m *schtasksManager) Install() error {
+	execPath, err := ExecPath()
+	if err != nil {
+		return err
+	}
+	u, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("current user: %w", err)
+	}
+
+	logPath := LogPath()
+
+	// Ensure log directory exists.
+	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+
+	def := fmt.Sprintf(taskXML, escape(u.Username), escape(execPath), escape(filepath.Dir(execPath)))
+
+	// schtasks only reads task XML as UTF-16.
+	f, err := os.CreateTemp("", "clawwork-task-*.xml")
+	if err != nil {
+		return fmt.Errorf("write task definition: %w", err)
+	}
+	defer os.Remove(f.Name())
+	err = binary.Write(f, binary.LittleEndian, append([]uint16{0xFEFF}, utf16.Encode([]rune(def))...))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("write task definition: %w", err)
+	}
+
+	if err := schtasks("/Create", "/TN", taskName, "/XML", f.Name(), "/F"); err != nil {
+		return err
+	}
+	return m.Start()
+}
+
+func escape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (m *schtasksManager) Uninstall() error {
+	if !m.installed() {
+		return fmt.Errorf("service not installed")
+	}
+	_ = m.Stop()
+	if err := schtasks("/Delete", "/TN", taskName, "/F"); err != nil {
+		return err
+	}
+
+	// Clean up log file.
+	_ = os.Remove(LogPath())
+
+	return nil
+}
+
+func (m *schtasksManager) Start() error {
+	return schtasks("/Run", "/TN", taskName)
+}
+
+// Stop ends the task. Windows has no SIGTERM for a console-less process, so
+// the miner is terminated at once: an answer in flight is abandoned and
+// its platform session expires on its own.
+func (m *schtasksManager) Stop() error {
+	if err := schtasks("/End", "/TN", taskName); err != nil {
+		return err
+	}
+	return miner.WaitReleased(10 * time.Second)
+}
+
+func (m *schtasksManager) Restart() error {
+	_ = m.Stop()
+	return m.Start()
+}
+
+func (m *schtasksManager) installed() bool {
+	return exec.Command("schtasks", "/Query", "/TN", taskName).Run() == nil
+}
+
+func (m *schtasksManager) Status() (*Status, error) {
+	s := &Status{LogPath: LogPath(), Installed: m.installed()}
+
+	// Task Scheduler's status text is localized; the lock file is not. A
+	// foreground miner's lock doesn't count.
+	if info := miner.Running(); info != nil && info.Mode != miner.ModeForeground {
+		s.Running = true
+		s.PID = info.PID
+	}
+
+	return s, nil
+}
@@ -46,14 +46,16 @@ Wants=network-online.target
 [Service]
 Type=simple
 ExecStart=%s insc
+Environment=CLAWWORK_SERVICE=1
 Restart=on-failure
 RestartSec=30
+TimeoutStopSec=%d
 StandardOutput=append:%s
 StandardError=append:%s
 
 [Install]
 WantedBy=default.target
-`, execPath, logPath, logPath)
+`, execPath, StopTimeout(), logPath, logPath)
 
 	// Ensure systemd user directory exists.
 	if err := os.MkdirAll(filepath.Dir(unitPath()), 0755); err != nil {
@@ -0,0 +1,415 @@
+// Package datasync replicates agent data — config, soul, state, chat
+// sessions and the SQLite store — to object storage, so an agent can be moved to another
+// machine or rebuilt after a disk failure with `clawwork sync pull`.
+//
+// The bucket holds one object per file plus manifest.json listing them
+// with their hashes. config.toml is uploaded without its credentials,
+// which never leave the machine. Pushes upload only files whose hash changed and
+// delete only files this machine pushed earlier and has since removed.
+package datasync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/objstore"
+	"github.com/clawplaza/clawwork-cli/internal/store"
+)
+
+const (
+	manifestKey = "manifest.json"
+	configFile  = "config.toml"
+)
+
+// patterns select the synced files, relative to the data directory. Tool
+// workspaces, logs, locks and caches stay local. clawwork.db is never
+// read from disk: it comes from Syncer.Database.
+var patterns = []string{
+	"config.toml", "soul.md", "state.json", "goal.json", "prefs.json", "moments.json",
+	"chats/*.json", "chats/archive/*.jsonl.gz", "history/*.jsonl", "knowledge/*.md",
+	store.DBFile,
+}
+
+// Entry describes one synced file.
+type Entry struct {
+	SHA256 string    `json:"sha256"`
+	Size   int64     `json:"size"`
+	Mod    time.Time `json:"mod"`
+
+	data []byte // what to upload, when not the file as it is on disk
+}
+
+// Manifest lists the files in the bucket, keyed by slash-separated path.
+type Manifest struct {
+	Host   string           `json:"host,omitempty"` // machine that pushed last
+	Pushed time.Time        `json:"pushed"`
+	Files  map[string]Entry `json:"files"`
+}
+
+// Syncer pushes and pulls one data directory.
+type Syncer struct {
+	Store  objstore.Store
+	Dir    string // data directory, normally config.Dir()
+	Prefix string // key prefix in the store
+
+	// Database writes a copy of the SQLite store to a path, synced as
+	// clawwork.db; nil with the files backend.
+	Database func(path string) error
+}
+
+// New builds a Syncer from config. It returns nil, nil when sync is off.
+func New(cfg config.SyncConfig) (*Syncer, error) {
+	store, err := objstore.New(cfg)
+	if err != nil || store == nil {
+		return nil, err
+	}
+	return &Syncer{Store: store, Dir: config.Dir(), Prefix: strings.Trim(cfg.Prefix, "/")}, nil
+}
+
+// Result reports what a push or pull changed.
+type Result struct {
+	Copied  []string // uploaded or downloaded
+	Deleted []string // removed from the store
+}
+
+func (s *Syncer) key(p string) string {
+	if s.Prefix == "" {
+		return p
+	}
+	return s.Prefix + "/" + p
+}
+
+// statePath records what this machine last pushed or pulled.
+func (s *Syncer) statePath() string { return filepath.Join(s.Dir, "sync.json") }
+
+// Push uploads changed files and the manifest.
+func (s *Syncer) Push(ctx context.Context) (*Result, error) {
+	local, err := s.scan()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.remoteManifest(ctx)
+	if errors.Is(err, objstore.ErrNotFound) {
+		remote = &Manifest{Files: map[string]Entry{}}
+	} else if err != nil {
+		return nil, err
+	}
+	last := s.lastSynced()
+
+	res := &Result{}
+	for _, p := range sortedKeys(local) {
+		e := local[p]
+		if remote.Files[p].SHA256 == e.SHA256 {
+			continue
+		}
+		data := e.data
+		if data == nil {
+			if data, err = os.ReadFile(filepath.Join(s.Dir, filepath.FromSlash(p))); err != nil {
+				return res, err
+			}
+		}
+		if err := s.Store.Put(ctx, s.key(p), data); err != nil {
+			return res, fmt.Errorf("upload %s: %w", p, err)
+		}
+		remote.Files[p] = e
+		res.Copied = append(res.Copied, p)
+	}
+	for _, p := range sortedKeys(last.Files) {
+		// Removed here since the last sync, and not changed by another
+		// machine in the meantime.
+		if _, ok := local[p]; ok || remote.Files[p].SHA256 != last.Files[p].SHA256 {
+			continue
+		}
+		if err := s.Store.Delete(ctx, s.key(p)); err != nil {
+			return res, fmt.Errorf("delete %s: %w", p, err)
+		}
+		delete(remote.Files, p)
+		res.Deleted = append(res.Deleted, p)
+	}
+
+	remote.Host, _ = os.Hostname()
+	remote.Pushed = time.Now().UTC()
+	data, err := json.MarshalIndent(remote, "", "  ")
+	if err != nil {
+		return res, err
+	}
+	if err := s.Store.Put(ctx, s.key(manifestKey), data); err != nil {
+		return res, fmt.Errorf("upload manifest: %w", err)
+	}
+	s.saveSynced(&Manifest{Host: remote.Host, Pushed: remote.Pushed, Files: local})
+	return res, nil
+}
+
+// Pull downloads every file that differs from the local copy. Local files
+// missing from the store are left alone. With dryRun nothing is written.
+// The miner must not be running: it would overwrite state.json.
+func (s *Syncer) Pull(ctx context.Context, dryRun bool) (*Manifest, *Result, error) {
+	remote, err := s.remoteManifest(ctx)
+	if errors.Is(err, objstore.ErrNotFound) {
+		return nil, nil, fmt.Errorf("nothing has been pushed to %s yet", s.Store.Name())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	local, err := s.scan()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := &Result{}
+	for _, p := range sortedKeys(remote.Files) {
+		e := remote.Files[p]
+		if local[p].SHA256 == e.SHA256 || !safePath(p) {
+			continue
+		}
+		res.Copied = append(res.Copied, p)
+		if dryRun {
+			continue
+		}
+		data, err := s.Store.Get(ctx, s.key(p))
+		if err != nil {
+			return remote, res, fmt.Errorf("download %s: %w", p, err)
+		}
+		if hashOf(data) != e.SHA256 {
+			return remote, res, fmt.Errorf("download %s: content does not match the manifest", p)
+		}
+		full := filepath.Join(s.Dir, filepath.FromSlash(p))
+		if p == configFile {
+			if data, err = withLocalSecrets(data, full); err != nil {
+				return remote, res, fmt.Errorf("%s: %w", p, err)
+			}
+		}
+		if p == store.DBFile {
+			// A write-ahead log left by the old database would be
+			// replayed into the new one.
+			for _, suffix := range []string{"-wal", "-shm"} {
+				if err := os.Remove(full + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return remote, res, err
+				}
+			}
+		}
+		if err := writeFile(full, data); err != nil {
+			return remote, res, err
+		}
+	}
+	if !dryRun {
+		s.saveSynced(remote)
+	}
+	return remote, res, nil
+}
+
+// Pending lists local files that differ from the last push or pull.
+func (s *Syncer) Pending() ([]string, *Manifest, error) {
+	local, err := s.scan()
+	if err != nil {
+		return nil, nil, err
+	}
+	last := s.lastSynced()
+	var changed []string
+	for _, p := range sortedKeys(local) {
+		if last.Files[p].SHA256 != local[p].SHA256 {
+			changed = append(changed, p)
+		}
+	}
+	for _, p := range sortedKeys(last.Files) {
+		if _, ok := local[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	return changed, last, nil
+}
+
+// Run pushes every interval until ctx ends.
+func (s *Syncer) Run(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		return
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		res, err := s.Push(ctx)
+		switch {
+		case err != nil:
+			slog.Warn("sync push failed", "store", s.Store.Name(), "error", err)
+		case len(res.Copied) > 0 || len(res.Deleted) > 0:
+			slog.Info("sync pushed", "store", s.Store.Name(), "uploaded", len(res.Copied), "deleted", len(res.Deleted))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// scan hashes the local files as they would be uploaded: config.toml
+// without its credentials, and with Database set, a snapshot of the store
+// as clawwork.db.
+func (s *Syncer) scan() (map[string]Entry, error) {
+	files := make(map[string]Entry)
+	for _, pat := range patterns {
+		if pat == store.DBFile {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(s.Dir, filepath.FromSlash(pat)))
+		if err != nil {
+			return nil, err
+		}
+		for _, full := range matches {
+			info, err := os.Stat(full)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			data, err := os.ReadFile(full)
+			if err != nil {
+				return nil, err
+			}
+			rel, _ := filepath.Rel(s.Dir, full)
+			e := Entry{Size: info.Size(), Mod: info.ModTime().UTC()}
+			if rel == configFile {
+				if data, err = withoutSecrets(data); err != nil {
+					return nil, fmt.Errorf("%s: %w", rel, err)
+				}
+				e.Size, e.data = int64(len(data)), data
+			}
+			e.SHA256 = hashOf(data)
+			files[filepath.ToSlash(rel)] = e
+		}
+	}
+	if s.Database != nil {
+		data, err := s.snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", store.DBFile, err)
+		}
+		files[store.DBFile] = Entry{SHA256: hashOf(data), Size: int64(len(data)), Mod: time.Now().UTC(), data: data}
+	}
+	return files, nil
+}
+
+// snapshot returns a consistent copy of the SQLite store.
+func (s *Syncer) snapshot() ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "clawwork-sync-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, store.DBFile)
+	if err := s.Database(path); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// withoutSecrets re-encodes a config file with its credentials blanked.
+func withoutSecrets(data []byte) ([]byte, error) {
+	cfg, err := config.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.WithoutSecrets().Marshal()
+}
+
+// withLocalSecrets fills the credentials missing from a pulled config
+// from the config at path, if there is one.
+func withLocalSecrets(data []byte, path string) ([]byte, error) {
+	cfg, err := config.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if old, err := os.ReadFile(path); err == nil {
+		local, err := config.Decode(old)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RestoreSecrets(local)
+	}
+	return cfg.Marshal()
+}
+
+func (s *Syncer) remoteManifest(ctx context.Context) (*Manifest, error) {
+	data, err := s.Store.Get(ctx, s.key(manifestKey))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s in %s is unreadable: %w", manifestKey, s.Store.Name(), err)
+	}
+	if m.Files == nil {
+		m.Files = map[string]Entry{}
+	}
+	return &m, nil
+}
+
+func (s *Syncer) lastSynced() *Manifest {
+	m := &Manifest{Files: map[string]Entry{}}
+	if data, err := os.ReadFile(s.statePath()); err == nil {
+		_ = json.Unmarshal(data, m)
+	}
+	if m.Files == nil {
+		m.Files = map[string]Entry{}
+	}
+	return m
+}
+
+func (s *Syncer) saveSynced(m *Manifest) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err == nil {
+		err = os.WriteFile(s.statePath(), data, 0600)
+	}
+	if err != nil {
+		slog.Warn("sync: save state", "error", err)
+	}
+}
+
+// safePath rejects manifest paths that would escape the data directory or
+// that no pattern would have produced.
+func safePath(p string) bool {
+	if p != path.Clean(p) || path.IsAbs(p) || strings.HasPrefix(p, "..") {
+		return false
+	}
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFile replaces path atomically with owner-only permissions.
+func writeFile(p string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	tmp := p + ".sync-tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedKeys(m map[string]Entry) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
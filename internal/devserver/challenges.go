@@ -0,0 +1,87 @@
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Puzzle is a challenge prompt with its expected answer.
+type Puzzle struct {
+	Prompt string `json:"prompt"`
+	Answer string `json:"answer"`
+}
+
+var words = []string{"lobster", "harbor", "granite", "lantern", "meadow", "cobalt", "whisper", "orchard"}
+
+// generators produce built-in puzzles. They are simple on purpose: a
+// working model should pass every one, so failures point at the client.
+var generators = []func(r *rand.Rand) Puzzle{
+	func(r *rand.Rand) Puzzle {
+		a, b := r.IntN(90)+10, r.IntN(90)+10
+		return Puzzle{fmt.Sprintf("What is %d + %d? Reply with just the number.", a, b), fmt.Sprint(a + b)}
+	},
+	func(r *rand.Rand) Puzzle {
+		a, b := r.IntN(12)+2, r.IntN(12)+2
+		return Puzzle{fmt.Sprintf("What is %d multiplied by %d? Reply with just the number.", a, b), fmt.Sprint(a * b)}
+	},
+	func(r *rand.Rand) Puzzle {
+		w := words[r.IntN(len(words))]
+		return Puzzle{fmt.Sprintf("Spell the word %q backwards. Reply with the reversed word only.", w), reverse(w)}
+	},
+	func(r *rand.Rand) Puzzle {
+		w := words[r.IntN(len(words))]
+		return Puzzle{fmt.Sprintf("How many letters are in the word %q? Reply with the number only.", w), fmt.Sprint(len(w))}
+	},
+	func(r *rand.Rand) Puzzle {
+		a, b := r.IntN(50)+1, r.IntN(50)+1
+		return Puzzle{fmt.Sprintf("计算 %d 加 %d 等于多少？只回复数字。", a, b), fmt.Sprint(a + b)}
+	},
+}
+
+// LoadPuzzles reads a JSON array of {"prompt","answer"} objects.
+func LoadPuzzles(path string) ([]Puzzle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ps []Puzzle
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for i, p := range ps {
+		if p.Prompt == "" || p.Answer == "" {
+			return nil, fmt.Errorf("%s: entry %d needs both prompt and answer", path, i+1)
+		}
+	}
+	if len(ps) == 0 {
+		return nil, fmt.Errorf("%s: no challenges", path)
+	}
+	return ps, nil
+}
+
+// judge accepts an answer when any word in it matches the expected answer,
+// so "The answer is 42." passes for 42 the way a lenient grader would.
+func judge(answer, expected string) bool {
+	want := strings.ToLower(strings.TrimSpace(expected))
+	fields := strings.FieldsFunc(strings.ToLower(answer), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, f := range fields {
+		if f == want {
+			return true
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(answer)) == want
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
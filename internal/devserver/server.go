@@ -0,0 +1,430 @@
+// Package devserver is a local mock of the ClawWork platform API for
+// integration testing and demos. It implements /skill/inscribe,
+// /skill/status, /skill/claim and /skill/social in memory, with
+// configurable challenge failures, cooldowns and injected errors, so the
+// miner loop, LLM providers and the web console can be exercised end-to-end
+// without touching production trust scores.
+package devserver
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	mrand "math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// Behavior controls how the mock platform responds.
+type Behavior struct {
+	Cooldown       time.Duration // minimum gap between inscriptions; earlier attempts get RATE_LIMITED
+	MomentCooldown time.Duration // minimum gap between moment posts; earlier posts get COOLDOWN
+	ChallengeTTL   time.Duration // how long a challenge stays answerable
+	FailRate       float64       // fraction of correct answers judged wrong anyway
+	ErrorRate      float64       // fraction of inscribe calls answered with ErrorCode
+	ErrorCode      string        // injected error code (default RATE_LIMITED)
+	HitRate        float64       // fraction of inscriptions that win the NFT
+	DailyLimit     int           // inscriptions per agent per day; 0 = unlimited
+	Unclaimed      bool          // new agents must `clawwork claim` before mining
+	Puzzles        []Puzzle      // custom challenges; built-in generators when empty
+	Seed           uint64        // non-zero for reproducible randomness
+}
+
+const (
+	cwPerInscription = 100
+	startingTrust    = 50
+	totalNFTs        = 1024
+)
+
+// Server is the mock platform.
+type Server struct {
+	b   Behavior
+	out io.Writer
+
+	mu         sync.Mutex
+	rng        *mrand.Rand
+	agents     map[string]*agent // by API key
+	challenges map[string]*challenge
+	moments    []moment
+	nftsLeft   int
+}
+
+type agent struct {
+	id, name     string
+	claimed      bool
+	trust        int
+	inscriptions int
+	totalCW      int
+	hit          bool
+	tokenID      int
+	session      string
+	lastInscribe time.Time
+	lastMoment   time.Time
+	day          string
+	today        int
+}
+
+type challenge struct {
+	Puzzle
+	agentID string
+	expires time.Time
+	used    bool
+}
+
+type moment struct {
+	AgentID     string    `json:"agent_id"`
+	DisplayName string    `json:"display_name"`
+	Content     string    `json:"content"`
+	LikesCount  int       `json:"likes_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// New creates a mock platform. Request logs are written to out.
+func New(b Behavior, out io.Writer) *Server {
+	if b.ErrorCode == "" {
+		b.ErrorCode = "RATE_LIMITED"
+	}
+	if b.ChallengeTTL <= 0 {
+		b.ChallengeTTL = 5 * time.Minute
+	}
+	seed := b.Seed
+	if seed == 0 {
+		seed = mrand.Uint64()
+	}
+	return &Server{
+		b:          b,
+		out:        out,
+		rng:        mrand.New(mrand.NewPCG(seed, seed)),
+		agents:     make(map[string]*agent),
+		challenges: make(map[string]*challenge),
+		nftsLeft:   totalNFTs,
+	}
+}
+
+// Handler returns the HTTP handler serving the platform routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /skill/inscribe", s.handleInscribe)
+	mux.HandleFunc("GET /skill/status", s.handleStatus)
+	mux.HandleFunc("POST /skill/claim", s.handleClaim)
+	mux.HandleFunc("GET /skill/social", s.handleSocialGet)
+	mux.HandleFunc("POST /skill/social", s.handleSocialPost)
+	return mux
+}
+
+// ── inscribe ──
+
+func (s *Server) handleInscribe(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req api.InscribeRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, api.InscribeResponse{Error: "INVALID_REQUEST", Message: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		s.register(w, &req)
+		return
+	}
+	a := s.agentFor(key)
+	verified := verify(r, key, body)
+
+	var status int
+	var resp api.InscribeResponse
+	switch {
+	case req.SessionEnd:
+		a.session = ""
+		status, resp = 200, api.InscribeResponse{SessionEnded: true}
+	case req.SessionStart:
+		a.session = "sess_" + randHex(8)
+		a.tokenID = req.TokenID
+		status, resp = 200, api.InscribeResponse{
+			SessionID:      a.session,
+			ClientVerified: verified,
+			NextChallenge:  s.newChallenge(a),
+		}
+	default:
+		status, resp = s.inscribe(a, &req)
+	}
+
+	result := "ok"
+	if resp.Error != "" {
+		result = resp.Error
+	}
+	s.logf("inscribe agent=%s token=%d verified=%t → %s", a.name, req.TokenID, verified, result)
+	writeJSON(w, status, resp)
+}
+
+func (s *Server) register(w http.ResponseWriter, req *api.InscribeRequest) {
+	if req.AgentName == "" {
+		writeJSON(w, http.StatusUnauthorized, api.InscribeResponse{Error: "INVALID_API_KEY", Message: "missing X-API-Key"})
+		return
+	}
+	key := "dev_" + randHex(16)
+	a := s.agentFor(key)
+	a.name = req.AgentName
+	a.tokenID = req.TokenID
+	s.logf("register agent=%s id=%s", a.name, a.id)
+	writeJSON(w, 200, api.InscribeResponse{
+		AgentID:     a.id,
+		APIKey:      key,
+		Registered:  true,
+		MiningReady: a.claimed,
+	})
+}
+
+func (s *Server) inscribe(a *agent, req *api.InscribeRequest) (int, api.InscribeResponse) {
+	now := time.Now()
+	if !a.claimed {
+		return http.StatusForbidden, api.InscribeResponse{Error: "NOT_CLAIMED", Message: "agent not claimed — run clawwork claim with any code"}
+	}
+	if s.b.ErrorRate > 0 && s.rng.Float64() < s.b.ErrorRate {
+		resp := api.InscribeResponse{Error: s.b.ErrorCode, Message: "injected by devserver"}
+		if s.b.ErrorCode == "RATE_LIMITED" || s.b.ErrorCode == "DAILY_LIMIT_REACHED" {
+			resp.RetryAfter = 60
+			return http.StatusTooManyRequests, resp
+		}
+		return http.StatusInternalServerError, resp
+	}
+
+	if day := now.UTC().Format("2006-01-02"); day != a.day {
+		a.day, a.today = day, 0
+	}
+	if s.b.DailyLimit > 0 && a.today >= s.b.DailyLimit {
+		next := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		return http.StatusTooManyRequests, api.InscribeResponse{
+			Error: "DAILY_LIMIT_REACHED", Message: fmt.Sprintf("daily limit of %d reached", s.b.DailyLimit),
+			RetryAfter: int(next.Sub(now).Seconds()) + 1, ResetAt: next.Format(time.RFC3339),
+		}
+	}
+	if wait := s.b.Cooldown - now.Sub(a.lastInscribe); wait > 0 {
+		return http.StatusTooManyRequests, api.InscribeResponse{
+			Error: "RATE_LIMITED", Message: "cooldown active", RetryAfter: int(wait.Seconds()) + 1,
+		}
+	}
+
+	if req.ChallengeID == "" {
+		return http.StatusBadRequest, api.InscribeResponse{Error: "CHALLENGE_REQUIRED", Message: "answer the challenge", Challenge: s.newChallenge(a)}
+	}
+	ch, ok := s.challenges[req.ChallengeID]
+	switch {
+	case !ok || ch.agentID != a.id:
+		return http.StatusBadRequest, api.InscribeResponse{Error: "CHALLENGE_INVALID", Message: "unknown challenge", Challenge: s.newChallenge(a)}
+	case ch.used:
+		return http.StatusBadRequest, api.InscribeResponse{Error: "CHALLENGE_USED", Message: "challenge already answered", Challenge: s.newChallenge(a)}
+	case now.After(ch.expires):
+		delete(s.challenges, req.ChallengeID)
+		return http.StatusBadRequest, api.InscribeResponse{Error: "CHALLENGE_EXPIRED", Message: "challenge expired", Challenge: s.newChallenge(a)}
+	}
+	ch.used = true
+	if !judge(req.ChallengeAnswer, ch.Answer) || (s.b.FailRate > 0 && s.rng.Float64() < s.b.FailRate) {
+		a.trust = max(a.trust-5, 0)
+		return http.StatusBadRequest, api.InscribeResponse{
+			Error: "CHALLENGE_FAILED", Message: "incorrect answer",
+			Hint:       fmt.Sprintf("expected %q — trust -5 (now %d)", ch.Answer, a.trust),
+			TrustScore: a.trust, Challenge: s.newChallenge(a),
+		}
+	}
+
+	a.trust = min(a.trust+1, 100)
+	a.inscriptions++
+	a.today++
+	a.totalCW += cwPerInscription
+	a.lastInscribe = now
+	a.tokenID = req.TokenID
+
+	success := true
+	resp := api.InscribeResponse{
+		Success:          &success,
+		Hash:             "0x" + randHex(32),
+		TokenID:          req.TokenID,
+		IDStatus:         "available",
+		Nonce:            a.inscriptions,
+		CWEarned:         cwPerInscription,
+		CWPerInscription: cwPerInscription,
+		TrustScore:       a.trust,
+		NextChallenge:    s.newChallenge(a),
+		NearbyMiners:     nearbyMiners,
+	}
+	if !a.hit && s.nftsLeft > 0 && s.b.HitRate > 0 && s.rng.Float64() < s.b.HitRate {
+		a.hit = true
+		s.nftsLeft--
+		resp.Hit = true
+		resp.IDStatus = "hit"
+		resp.GenesisNFT = &api.GenesisNFT{TokenID: req.TokenID}
+	}
+	resp.NFTsRemaining = s.nftsLeft
+	return 200, resp
+}
+
+func (s *Server) newChallenge(a *agent) *api.Challenge {
+	var p Puzzle
+	if len(s.b.Puzzles) > 0 {
+		p = s.b.Puzzles[s.rng.IntN(len(s.b.Puzzles))]
+	} else {
+		p = generators[s.rng.IntN(len(generators))](s.rng)
+	}
+	id := "ch_" + randHex(12)
+	s.challenges[id] = &challenge{Puzzle: p, agentID: a.id, expires: time.Now().Add(s.b.ChallengeTTL)}
+	return &api.Challenge{ID: id, Prompt: p.Prompt, ExpiresIn: int(s.b.ChallengeTTL.Seconds())}
+}
+
+// ── status & claim ──
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "INVALID_API_KEY"})
+		return
+	}
+	a := s.agentFor(key)
+	resp := api.StatusResponse{
+		Agent: api.StatusAgent{ID: a.id, Name: a.name},
+		Inscriptions: api.StatusInscriptions{
+			Total: a.inscriptions, Confirmed: a.inscriptions, TotalCW: a.totalCW, Hit: a.hit,
+		},
+		Activity: api.StatusActivity{Status: "active", NFTsRemaining: s.nftsLeft},
+	}
+	if a.hit {
+		resp.GenesisNFT = &api.GenesisNFT{TokenID: a.tokenID}
+	}
+	writeJSON(w, 200, resp)
+}
+
+func (s *Server) handleClaim(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body struct {
+		ClaimCode string `json:"claim_code"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	key := r.Header.Get("X-API-Key")
+	if key == "" || body.ClaimCode == "" {
+		writeJSON(w, http.StatusBadRequest, api.ClaimResponse{Error: "INVALID_OR_EXPIRED_CODE", Message: "claim code required"})
+		return
+	}
+	a := s.agentFor(key)
+	a.claimed = true
+	s.logf("claim agent=%s", a.name)
+	writeJSON(w, 200, api.ClaimResponse{OK: true, AgentID: a.id, DisplayName: a.name})
+}
+
+// ── social ──
+
+var nearbyMiners = []api.Miner{
+	{AgentID: "dev-agent-ada", DisplayName: "Ada"},
+	{AgentID: "dev-agent-bolt", DisplayName: "Bolt"},
+}
+
+func (s *Server) handleSocialGet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	module := r.URL.Query().Get("module")
+	var data any
+	switch module {
+	case "nearby":
+		miners := make([]map[string]any, 0, len(nearbyMiners))
+		for _, m := range nearbyMiners {
+			miners = append(miners, map[string]any{"agent_id": m.AgentID, "display_name": m.DisplayName, "inscription_count": 7})
+		}
+		data = map[string]any{"miners": miners}
+	case "connections":
+		data = map[string]any{"friends": []any{}, "following": []any{}, "followers": []any{}}
+	case "moments":
+		ms := make([]moment, 0, len(s.moments))
+		for i := len(s.moments) - 1; i >= 0; i-- {
+			ms = append(ms, s.moments[i])
+		}
+		data = map[string]any{"moments": ms}
+	case "mail":
+		data = []any{}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]any{"success": false, "error": map[string]string{"code": "UNKNOWN_MODULE"}})
+		return
+	}
+	writeJSON(w, 200, map[string]any{"success": true, "data": data})
+}
+
+func (s *Server) handleSocialPost(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"success": false, "error": map[string]string{"code": "INVALID_REQUEST"}})
+		return
+	}
+	a := s.agentFor(r.Header.Get("X-API-Key"))
+	module, _ := body["module"].(string)
+	switch module {
+	case "moments":
+		now := time.Now()
+		if wait := s.b.MomentCooldown - now.Sub(a.lastMoment); wait > 0 {
+			writeJSON(w, http.StatusTooManyRequests, map[string]any{
+				"success": false, "error": map[string]string{"code": "COOLDOWN"}, "retry_after": int(wait.Seconds()) + 1,
+			})
+			return
+		}
+		content, _ := body["content"].(string)
+		a.lastMoment = now
+		s.moments = append(s.moments, moment{AgentID: a.id, DisplayName: a.name, Content: content, CreatedAt: now})
+		s.logf("moment agent=%s %q", a.name, content)
+	case "follow":
+		s.logf("follow agent=%s target=%v", a.name, body["target_id"])
+	}
+	writeJSON(w, 200, map[string]any{"success": true})
+}
+
+// ── helpers ──
+
+// agentFor returns the agent for an API key, creating one on first sight
+// so an existing config can be pointed at the devserver without re-init.
+func (s *Server) agentFor(key string) *agent {
+	if a, ok := s.agents[key]; ok {
+		return a
+	}
+	id := "dev-" + randHex(4)
+	a := &agent{id: id, name: id, claimed: !s.b.Unclaimed, trust: startingTrust}
+	s.agents[key] = a
+	return a
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.out == nil {
+		return
+	}
+	fmt.Fprintf(s.out, "[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// verify checks the client attestation headers the way the platform does.
+func verify(r *http.Request, key string, body []byte) bool {
+	h := sha256.Sum256(body)
+	return api.VerifySignature(key,
+		r.Header.Get("X-Client-Nonce"),
+		r.Header.Get("X-Client-Timestamp"),
+		hex.EncodeToString(h[:]),
+		r.Header.Get("X-Client-Signature"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,168 @@
+// Package diagnose watches miner events for an error that keeps repeating
+// and has the agent's LLM work out why, with read-only system checks,
+// before anyone reads the logs. The result is published as a "diagnosis"
+// event.
+package diagnose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/llm"
+	"github.com/clawplaza/clawwork-cli/internal/tools"
+)
+
+const (
+	// interval is the least time between two diagnoses of the same error.
+	interval = 6 * time.Hour
+	// timeout bounds one diagnosis, tool rounds included.
+	timeout = 3 * time.Minute
+	// maxTokens is the reply budget: a cause and a few fixes.
+	maxTokens = 800
+)
+
+// SystemPrompt instructs the LLM for a diagnosis.
+const SystemPrompt = `You diagnose a ClawWork miner: a CLI that answers LLM challenges to inscribe tokens on the ClawWork platform. One error keeps repeating.
+Use system_check to test connectivity to the platform, the clock and the disk, as far as the error suggests.
+Then reply in plain text: first line, the most likely cause; then up to three short suggested fixes, one per line starting with "- ". No preamble, no markdown headings.`
+
+// Publish delivers an event, like miner.Miner.OnEvent.
+type Publish func(eventType, message string, data any)
+
+// Watcher counts repeats of each error and runs one diagnosis at a time.
+type Watcher struct {
+	repeats  int
+	window   time.Duration
+	provider llm.Provider
+	check    *tools.SystemCheckTool
+	publish  Publish
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	seen    map[string][]time.Time // signature → recent occurrences
+	last    map[string]time.Time   // signature → last diagnosis
+	running bool
+}
+
+// New returns a Watcher for the [alerts] diagnose settings, or nil when
+// they turn it off.
+func New(cfg config.AlertsConfig, provider llm.Provider, check *tools.SystemCheckTool, publish Publish) *Watcher {
+	if cfg.DiagnoseRepeats <= 0 || provider == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Watcher{
+		repeats:  cfg.DiagnoseRepeats,
+		window:   time.Duration(cfg.DiagnoseWindowMinutes) * time.Minute,
+		provider: provider,
+		check:    check,
+		publish:  publish,
+		ctx:      ctx,
+		cancel:   cancel,
+		seen:     make(map[string][]time.Time),
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Event observes a miner event. Once an error repeats often enough, a
+// diagnosis starts in the background.
+func (w *Watcher) Event(eventType, message string) {
+	if eventType != "error" {
+		return
+	}
+	if n, ok := w.record(signature(message), time.Now()); ok {
+		go w.run(message, n)
+	}
+}
+
+// Close stops a diagnosis in progress.
+func (w *Watcher) Close() { w.cancel() }
+
+// record notes one occurrence of sig and reports whether a diagnosis is due,
+// with the number of repeats seen.
+func (w *Watcher) record(sig string, now time.Time) (int, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	times := append(w.seen[sig], now)
+	for len(times) > 0 && now.Sub(times[0]) > w.window {
+		times = times[1:]
+	}
+	w.seen[sig] = times
+	if len(times) < w.repeats || w.running || now.Sub(w.last[sig]) < interval {
+		return len(times), false
+	}
+	w.running = true
+	w.last[sig] = now
+	delete(w.seen, sig)
+	return len(times), true
+}
+
+func (w *Watcher) run(message string, repeats int) {
+	defer func() {
+		w.mu.Lock()
+		w.running = false
+		w.mu.Unlock()
+	}()
+	slog.Info("diagnosing repeated error", "error", message, "repeats", repeats)
+
+	ctx, cancel := context.WithTimeout(w.ctx, timeout)
+	defer cancel()
+	summary, checks, err := w.diagnose(ctx, message, repeats)
+	if w.ctx.Err() != nil {
+		return
+	}
+	data := map[string]any{"error": message, "repeats": repeats, "checks": checks}
+	if err != nil {
+		// The LLM may be what's failing; the checks still say something.
+		slog.Warn("diagnosis: LLM unavailable", "error", err)
+		data["llm_error"] = err.Error()
+		checkCtx, cancel := context.WithTimeout(w.ctx, time.Minute)
+		defer cancel()
+		summary = "The LLM could not be asked (" + err.Error() + "). System checks:\n" + w.check.RunAll(checkCtx)
+	}
+	data["summary"] = summary
+	w.publish("diagnosis", fmt.Sprintf("Diagnosis of a repeated error (%d×: %s)\n%s", repeats, message, summary), data)
+}
+
+// diagnose asks the LLM, letting it call system_check if it can use
+// tools; otherwise the checks are run up front and included. It returns
+// the LLM's summary and the checks that ran.
+func (w *Watcher) diagnose(ctx context.Context, message string, repeats int) (string, []string, error) {
+	ctx = llm.WithMaxTokens(llm.WithSystemPrompt(ctx, SystemPrompt), maxTokens)
+	prompt := fmt.Sprintf("This error repeated %d times within %s:\n%s", repeats, w.window, message)
+
+	if tp, ok := w.provider.(tools.ChatToolProvider); ok {
+		reply, used, err := tools.RunAgentLoop(ctx, tp, []tools.Message{{Role: "user", Content: prompt}}, []tools.Tool{w.check})
+		if !errors.Is(err, llm.ErrChatUnsupported) {
+			checks := make([]string, len(used))
+			for i, u := range used {
+				checks[i] = u.Summary
+			}
+			return strings.TrimSpace(reply), checks, err
+		}
+	}
+	results := w.check.RunAll(ctx)
+	reply, err := w.provider.Answer(ctx, prompt+"\n\nSystem checks:\n"+results)
+	return strings.TrimSpace(reply), strings.Split(results, "\n"), err
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// signature identifies an error across repeats whose numbers differ, such
+// as durations or IDs.
+func signature(message string) string {
+	sig := digits.ReplaceAllString(strings.ToLower(message), "#")
+	if len(sig) > 200 {
+		sig = sig[:200]
+	}
+	return sig
+}
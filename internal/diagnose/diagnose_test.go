@@ -0,0 +1,76 @@
+package diagnose
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/tools"
+)
+
+// answerer is an LLM without tool calling that records its prompt.
+type answerer struct {
+	mu     sync.Mutex
+	prompt string
+}
+
+func (a *answerer) Answer(_ context.Context, prompt string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prompt = prompt
+	return "Cause: the platform is unreachable.\n- Check the network", nil
+}
+
+func (a *answerer) Name() string { return "test" }
+
+func TestRepeatedErrorIsDiagnosed(t *testing.T) {
+	cfg := config.AlertsConfig{DiagnoseRepeats: 3, DiagnoseWindowMinutes: 30}
+	llm := &answerer{}
+	got := make(chan string, 1)
+	w := New(cfg, llm, tools.NewSystemCheckTool("http://127.0.0.1:1", t.TempDir()), func(eventType, message string, _ any) {
+		if eventType == "diagnosis" {
+			got <- message
+		}
+	})
+	defer w.Close()
+
+	// Numbers differ between repeats; other events don't count.
+	w.Event("error", "request failed after 1.2s")
+	w.Event("inscription", "Inscribed #1")
+	w.Event("error", "request failed after 3.4s")
+	select {
+	case <-got:
+		t.Fatal("diagnosed after two repeats")
+	case <-time.After(50 * time.Millisecond):
+	}
+	w.Event("error", "request failed after 5.6s")
+
+	select {
+	case msg := <-got:
+		if !strings.Contains(msg, "3×") || !strings.Contains(msg, "platform is unreachable") {
+			t.Errorf("diagnosis event = %q", msg)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("no diagnosis")
+	}
+	llm.mu.Lock()
+	prompt := llm.prompt
+	llm.mu.Unlock()
+	if !strings.Contains(prompt, "connectivity:") || !strings.Contains(prompt, "disk:") {
+		t.Errorf("system checks missing from prompt:\n%s", prompt)
+	}
+
+	// The same error isn't diagnosed again right away.
+	if _, due := w.record(signature("request failed after 9s"), time.Now()); due {
+		t.Error("diagnosed again within the interval")
+	}
+	for i := 0; i < 2; i++ {
+		w.record(signature("request failed after 9s"), time.Now())
+	}
+	if _, due := w.record(signature("request failed after 9s"), time.Now()); due {
+		t.Error("diagnosed again within the interval")
+	}
+}
@@ -0,0 +1,155 @@
+// Package event defines the typed data miner events carry, so the console,
+// MQTT, notification channels and webhooks can read what happened from
+// fields instead of parsing the human-readable message.
+//
+// Every payload encodes to a JSON object whose "schema" field holds
+// SchemaVersion. Fields are only ever added within a version; a change
+// that renames, removes or reinterprets one bumps it.
+package event
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// SchemaVersion is the version of the payloads in this package.
+const SchemaVersion = 1
+
+// Inscription is the data of "inscription" and "hit" events.
+type Inscription struct {
+	TokenID       int  `json:"token_id"`
+	CWEarned      int  `json:"cw_earned"`
+	TrustScore    int  `json:"trust_score"`
+	NFTsRemaining int  `json:"nfts_remaining"`
+	Hit           bool `json:"hit"`
+}
+
+// Penalty is the data of "penalty" events.
+type Penalty struct {
+	// Kind is "challenge" (a failed answer), "ip" (a payout under an IP
+	// multiplier) or "ip_peer" (another instance on this host paid one).
+	Kind      string `json:"kind"`
+	TokenID   int    `json:"token_id,omitempty"`
+	LostCW    int    `json:"lost_cw"`
+	Estimated bool   `json:"estimated,omitempty"` // LostCW is a guess (challenge failures)
+	TrustLost int    `json:"trust_lost,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+
+	// ip_peer only.
+	Peer         string `json:"peer,omitempty"`
+	IPMultiplier int    `json:"ip_multiplier,omitempty"`
+	AgentsOnIP   int    `json:"agents_on_ip,omitempty"`
+}
+
+// Cooldown reasons.
+const (
+	CooldownNext       = "next"        // the wait between inscriptions
+	CooldownResume     = "resume"      // a wait carried over from the last run
+	CooldownServer     = "server"      // the platform asked to wait
+	CooldownToken      = "token"       // multi-token: this token waits, the next is tried
+	CooldownAllTokens  = "all_tokens"  // multi-token: every token is waiting
+	CooldownStagger    = "stagger"     // taking turns with other instances on this host
+	CooldownDailyLimit = "daily_limit" // the daily limit is reached
+)
+
+// Cooldown is the data of "cooldown" and "limit" events: mining waits
+// until Until.
+type Cooldown struct {
+	Reason  string    `json:"reason"`
+	Seconds int       `json:"seconds"`
+	Until   time.Time `json:"until"`
+	TokenID int       `json:"token_id,omitempty"`
+	Quota   string    `json:"quota,omitempty"` // daily_limit: the quota in state
+}
+
+// NewCooldown returns a cooldown of secs seconds from now.
+func NewCooldown(reason string, secs int) Cooldown {
+	return Cooldown{Reason: reason, Seconds: secs, Until: time.Now().Add(time.Duration(secs) * time.Second).UTC().Truncate(time.Second)}
+}
+
+// Control actions.
+const (
+	ControlPause        = "pause"
+	ControlResume       = "resume"
+	ControlTokenSwitch  = "token_switch"  // From → To
+	ControlTokenRemoved = "token_removed" // TokenID left the rotation
+	ControlPeriod       = "period"        // a new stats period started
+)
+
+// Control is the data of "control" events.
+type Control struct {
+	Action string `json:"action"`
+
+	// Reason says what asked for it: "console", "chat", "review"
+	// (the automatic pause for review), or for an automatic token switch
+	// "crowded" or "taken".
+	Reason  string `json:"reason,omitempty"`
+	TokenID int    `json:"token_id,omitempty"`
+	From    int    `json:"from,omitempty"`
+	To      int    `json:"to,omitempty"`
+	Agents  *int   `json:"agents,omitempty"` // agents mining To, when known
+	Period  string `json:"period,omitempty"`
+}
+
+// Session actions.
+const (
+	SessionStart          = "start"
+	SessionMultiToken     = "multi_token"
+	SessionPeers          = "peers"           // other instances share this host
+	SessionChallengeRetry = "challenge_retry" // the challenge was rejected without a penalty
+	SessionTakeoverWait   = "takeover_wait"   // another session holds the agent
+	SessionTakeover       = "takeover"
+	SessionExperiment     = "experiment"
+	SessionUpdate         = "update" // a new version was installed; the miner restarts into it
+)
+
+// Session is the data of "session" events.
+type Session struct {
+	Action     string `json:"action"`
+	SessionID  string `json:"session_id,omitempty"`
+	Tokens     []int  `json:"tokens,omitempty"`
+	Peers      int    `json:"peers,omitempty"`
+	Code       string `json:"code,omitempty"`    // challenge_retry: the platform's error code
+	Seconds    int    `json:"seconds,omitempty"` // takeover_wait: until the other session expires
+	Experiment string `json:"experiment,omitempty"`
+	Version    string `json:"version,omitempty"` // update: the version installed
+}
+
+func (e Inscription) MarshalJSON() ([]byte, error) {
+	type plain Inscription
+	return withSchema(plain(e))
+}
+
+func (e Penalty) MarshalJSON() ([]byte, error) {
+	type plain Penalty
+	return withSchema(plain(e))
+}
+
+func (e Cooldown) MarshalJSON() ([]byte, error) {
+	type plain Cooldown
+	return withSchema(plain(e))
+}
+
+func (e Control) MarshalJSON() ([]byte, error) {
+	type plain Control
+	return withSchema(plain(e))
+}
+
+func (e Session) MarshalJSON() ([]byte, error) {
+	type plain Session
+	return withSchema(plain(e))
+}
+
+// withSchema encodes the struct v with "schema" as its first field.
+func withSchema(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	head := `{"schema":` + strconv.Itoa(SchemaVersion)
+	if len(b) > 2 {
+		head += ","
+	}
+	return append([]byte(head), b[1:]...), nil
+}
@@ -0,0 +1,47 @@
+package event
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	until := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		data any
+		want string
+	}{
+		{Cooldown{Reason: CooldownNext, Seconds: 60, Until: until},
+			`{"schema":1,"reason":"next","seconds":60,"until":"2026-03-01T12:00:00Z"}`},
+		{Session{}, `{"schema":1,"action":""}`},
+		{Control{Action: ControlTokenSwitch, From: 25, To: 42, Agents: new(int)},
+			`{"schema":1,"action":"token_switch","from":25,"to":42,"agents":0}`},
+		// Inside another document, as webhooks and MQTT send it.
+		{map[string]any{"type": "hit", "data": Inscription{TokenID: 42, Hit: true}},
+			`{"data":{"schema":1,"token_id":42,"cw_earned":0,"trust_score":0,"nfts_remaining":0,"hit":true},"type":"hit"}`},
+	} {
+		b, err := json.Marshal(tc.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.want {
+			t.Errorf("got  %s\nwant %s", b, tc.want)
+		}
+	}
+
+	// Consumers decode payloads into the same types.
+	b, _ := json.Marshal(Penalty{Kind: "ip", LostCW: 5})
+	var p Penalty
+	if err := json.Unmarshal(b, &p); err != nil || p.Kind != "ip" || p.LostCW != 5 {
+		t.Errorf("round trip = %+v, %v", p, err)
+	}
+}
+
+func TestWithSchemaEmptyObject(t *testing.T) {
+	type empty struct{}
+	b, err := withSchema(empty{})
+	if err != nil || string(b) != `{"schema":1}` {
+		t.Errorf("withSchema(empty) = %s, %v", b, err)
+	}
+}
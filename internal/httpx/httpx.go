@@ -0,0 +1,184 @@
+// Package httpx builds the HTTP clients of every subsystem (platform API,
+// LLM, webhooks, sync, updates) on a few shared, pooled transports, so the
+// [network] proxy and TLS settings apply everywhere and requests are
+// counted per subsystem.
+package httpx
+
+import (
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// defaultMaxIdlePerHost replaces net/http's 2, which makes the miner, the
+// console chat and social calls reopen connections to the same host.
+const defaultMaxIdlePerHost = 8
+
+var (
+	mu      sync.Mutex
+	network config.NetworkConfig
+	roots   *x509.CertPool                 // nil: system roots
+	pools   = map[string]*http.Transport{} // by proxy setting
+	clients = map[string]*counters{}       // by subsystem
+)
+
+// Configure applies the [network] settings to the transports built from
+// now on. Call it before creating clients; the command setup does.
+func Configure(cfg config.NetworkConfig) error {
+	var certs *x509.CertPool
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("network.ca_file: %w", err)
+		}
+		if certs, err = x509.SystemCertPool(); err != nil {
+			certs = x509.NewCertPool()
+		}
+		if !certs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("network.ca_file: no PEM certificates in %s", cfg.CAFile)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	network, roots = cfg, certs
+	for _, t := range pools {
+		t.CloseIdleConnections()
+	}
+	pools = map[string]*http.Transport{}
+	return nil
+}
+
+// pool returns the shared transport for a proxy setting: "" for
+// network.proxy, "direct" for none, or a proxy URL.
+func pool(proxy string) (*http.Transport, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if proxy == "" {
+		proxy = network.Proxy
+	}
+	if t := pools[proxy]; t != nil {
+		return t, nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = defaultMaxIdlePerHost
+	if network.MaxIdlePerHost > 0 {
+		t.MaxIdleConnsPerHost = network.MaxIdlePerHost
+	}
+	switch proxy {
+	case "":
+		// Keep environment proxy settings.
+	case "direct":
+		t.Proxy = nil
+	default:
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q", proxy)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if roots != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+	pools[proxy] = t
+	return t, nil
+}
+
+// Transport returns the round tripper for subsystem name (as it appears
+// in Snapshot) on the shared transport for proxy (see pool).
+func Transport(name, proxy string) (http.RoundTripper, error) {
+	t, err := pool(proxy)
+	if err != nil {
+		return nil, err
+	}
+	return &counted{base: t, c: stat(name)}, nil
+}
+
+// Client returns an HTTP client for subsystem name using network.proxy.
+func Client(name string, timeout time.Duration) *http.Client {
+	rt, err := Transport(name, "")
+	if err != nil {
+		// Configure validated the proxy; only a bad config reaches this.
+		slog.Warn("network proxy ignored", "client", name, "error", err)
+		rt = &counted{base: http.DefaultTransport, c: stat(name)}
+	}
+	return &http.Client{Transport: rt, Timeout: timeout}
+}
+
+// Stats are the request counters of one subsystem since start.
+type Stats struct {
+	Client   string  `json:"client"`
+	Requests int64   `json:"requests"`
+	Errors   int64   `json:"errors"`    // no response: refused, timed out, TLS...
+	NewConns int64   `json:"new_conns"` // requests that could not reuse a connection
+	Seconds  float64 `json:"seconds"`   // total time until the response headers
+}
+
+// Snapshot returns the counters of every subsystem that built a client,
+// by name.
+func Snapshot() []Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]Stats, 0, len(clients))
+	for name, c := range clients {
+		out = append(out, Stats{
+			Client:   name,
+			Requests: c.requests.Load(),
+			Errors:   c.errors.Load(),
+			NewConns: c.newConns.Load(),
+			Seconds:  time.Duration(c.nanos.Load()).Seconds(),
+		})
+	}
+	slices.SortFunc(out, func(a, b Stats) int { return cmp.Compare(a.Client, b.Client) })
+	return out
+}
+
+type counters struct {
+	requests, errors, newConns, nanos atomic.Int64
+}
+
+func stat(name string) *counters {
+	mu.Lock()
+	defer mu.Unlock()
+	c := clients[name]
+	if c == nil {
+		c = &counters{}
+		clients[name] = c
+	}
+	return c
+}
+
+// counted counts the requests of one subsystem.
+type counted struct {
+	base http.RoundTripper
+	c    *counters
+}
+
+func (t *counted) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) {
+		if !info.Reused {
+			t.c.newConns.Add(1)
+		}
+	}}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	t.c.requests.Add(1)
+	t.c.nanos.Add(int64(time.Since(start)))
+	if err != nil {
+		t.c.errors.Add(1)
+	}
+	return resp, err
+}
@@ -0,0 +1,48 @@
+package httpx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+func TestSharedPool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	if err := Configure(config.NetworkConfig{Proxy: "direct"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two subsystems, one pool: the second reuses the first's connection.
+	for _, name := range []string{"test-a", "test-b", "test-b"} {
+		resp, err := Client(name, 0).Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	got := map[string]Stats{}
+	for _, s := range Snapshot() {
+		got[s.Client] = s
+	}
+	if a := got["test-a"]; a.Requests != 1 || a.NewConns != 1 {
+		t.Errorf("test-a = %+v", a)
+	}
+	if b := got["test-b"]; b.Requests != 2 || b.NewConns != 0 || b.Errors != 0 {
+		t.Errorf("test-b = %+v, want 2 requests on the reused connection", b)
+	}
+
+	if _, err := Transport("test", "::bad"); err == nil {
+		t.Error("bad proxy accepted")
+	}
+	if err := Configure(config.NetworkConfig{CAFile: "/nonexistent.pem"}); err == nil {
+		t.Error("missing CA file accepted")
+	}
+}
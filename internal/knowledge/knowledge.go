@@ -2,8 +2,14 @@
 package knowledge
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+
+	"github.com/clawplaza/clawwork-cli/internal/budget"
+	"github.com/clawplaza/clawwork-cli/internal/config"
 )
 
 // Knowledge holds platform knowledge for building enhanced LLM system prompts.
@@ -14,24 +20,61 @@ type Knowledge struct {
 	APIs       string // platform API reference (embedded)
 	Soul       string // agent personality (from ~/.clawwork/soul.md, may be empty)
 
+	// Overrides lists the layers replaced by a file in DocsDir, e.g.
+	// "challenges.md".
+	Overrides []string
+
 	// SpecVersion tracks the last seen server spec version for change detection.
 	SpecVersion string
 	SpecHash    string
 }
 
-// Load returns knowledge loaded from embedded docs and the user's encrypted soul file.
+// DocFiles are the layers that can be replaced by a file of the same name
+// in DocsDir.
+var DocFiles = []string{"base.md", "challenges.md", "platform.md", "apis.md"}
+
+// DocsDir holds the user's replacements for the embedded layers.
+func DocsDir() string {
+	return filepath.Join(config.Dir(), "knowledge")
+}
+
+// Load returns knowledge loaded from embedded docs, the user's replacements
+// in DocsDir and the user's encrypted soul file.
 func Load(apiKey string) (*Knowledge, error) {
 	soul, err := LoadSoul(apiKey)
 	if err != nil {
 		return nil, fmt.Errorf("load soul: %w", err)
 	}
-	return &Knowledge{
-		Base:       strings.TrimSpace(baseDoc),
-		Challenges: strings.TrimSpace(challengesDoc),
-		Platform:   strings.TrimSpace(platformDoc),
-		APIs:       strings.TrimSpace(apisDoc),
-		Soul:       strings.TrimSpace(soul),
-	}, nil
+	k := &Knowledge{Soul: strings.TrimSpace(soul)}
+	if err := k.loadDocs(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// loadDocs sets the four document layers, each from DocsDir if a non-empty
+// file is there, else from the embedded copy.
+func (k *Knowledge) loadDocs() error {
+	var overrides []string
+	docs := make([]string, len(DocFiles))
+	for i, name := range DocFiles {
+		data, err := os.ReadFile(filepath.Join(DocsDir(), name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("read knowledge: %w", err)
+		}
+		if text := strings.TrimSpace(string(data)); text != "" {
+			docs[i] = text
+			overrides = append(overrides, name)
+		}
+	}
+	for i, embedded := range []string{baseDoc, challengesDoc, platformDoc, apisDoc} {
+		if docs[i] == "" {
+			docs[i] = strings.TrimSpace(embedded)
+		}
+	}
+	k.Base, k.Challenges, k.Platform, k.APIs = docs[0], docs[1], docs[2], docs[3]
+	k.Overrides = overrides
+	return nil
 }
 
 // SystemPrompt builds the full system prompt from all knowledge layers.
@@ -45,13 +88,40 @@ func (k *Knowledge) SystemPrompt() string {
 		parts = append(parts, k.Soul)
 	}
 
-	parts = append(parts, k.Challenges)
-	parts = append(parts, k.Platform)
-	parts = append(parts, k.APIs)
+	for _, sec := range []string{k.Challenges, k.Platform, k.APIs} {
+		if sec != "" {
+			parts = append(parts, sec)
+		}
+	}
 
 	return strings.Join(parts, "\n\n")
 }
 
+// SystemPromptWithin builds the system prompt in at most maxTokens
+// (estimated). Optional sections are left out, least important first: the
+// API reference, then platform rules, then challenge rules. Base rules and
+// the soul are always kept. Returns the prompt and the names of any
+// sections that were dropped.
+func (k *Knowledge) SystemPromptWithin(maxTokens int) (string, []string) {
+	kept := *k
+	var dropped []string
+	for _, sec := range []struct {
+		name string
+		text *string
+	}{
+		{"apis", &kept.APIs},
+		{"platform", &kept.Platform},
+		{"challenges", &kept.Challenges},
+	} {
+		if budget.EstimateTokens(kept.SystemPrompt()) <= maxTokens {
+			break
+		}
+		*sec.text = ""
+		dropped = append(dropped, sec.name)
+	}
+	return kept.SystemPrompt(), dropped
+}
+
 // HasSoul returns true if the agent has a personality configured.
 func (k *Knowledge) HasSoul() bool {
 	return k.Soul != ""
@@ -0,0 +1,127 @@
+package knowledge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Watcher notices changes to the soul file and the files in DocsDir while
+// the miner runs, so prompt edits don't need a restart.
+type Watcher struct {
+	apiKey string
+	soul   fileStamp
+	docs   map[string]fileStamp // by name in DocFiles
+}
+
+// fileStamp identifies a version of a file without reading it.
+type fileStamp struct {
+	exists bool
+	size   int64
+	mod    time.Time
+}
+
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), mod: info.ModTime()}
+}
+
+func stampDocs() map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(DocFiles))
+	for _, name := range DocFiles {
+		stamps[name] = stampFile(filepath.Join(DocsDir(), name))
+	}
+	return stamps
+}
+
+// NewWatcher starts watching the files as they are now, which is assumed
+// to be what Load read.
+func NewWatcher(apiKey string) *Watcher {
+	return &Watcher{apiKey: apiKey, soul: stampFile(SoulPath()), docs: stampDocs()}
+}
+
+// Change describes a knowledge reload.
+type Change struct {
+	Layer   string   `json:"layer"`  // "soul" or "docs"
+	Status  string   `json:"status"` // "updated", "removed" or "unreadable"
+	Files   []string `json:"files,omitempty"`
+	Message string   `json:"message"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Poll reloads whatever changed since the last call into k and reports
+// it; an empty result means nothing changed. A removed file falls back to
+// what a restart would load (no soul, or the embedded document). A file
+// that can't be read keeps what is already loaded: a restart would fail
+// on it, a running miner shouldn't.
+func (w *Watcher) Poll(k *Knowledge) []Change {
+	var changes []Change
+	if c := w.pollDocs(k); c != nil {
+		changes = append(changes, *c)
+	}
+	if c := w.pollSoul(k); c != nil {
+		changes = append(changes, *c)
+	}
+	return changes
+}
+
+func (w *Watcher) pollDocs(k *Knowledge) *Change {
+	stamps := stampDocs()
+	var changed []string
+	for _, name := range DocFiles {
+		if stamps[name] != w.docs[name] {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	w.docs = stamps
+
+	fresh := &Knowledge{}
+	if err := fresh.loadDocs(); err != nil {
+		return &Change{Layer: "docs", Status: "unreadable", Files: changed, Error: err.Error(),
+			Message: "Knowledge files unreadable — keeping the ones loaded earlier"}
+	}
+	if fresh.Base == k.Base && fresh.Challenges == k.Challenges && fresh.Platform == k.Platform && fresh.APIs == k.APIs {
+		return nil // touched, same content
+	}
+	k.Base, k.Challenges, k.Platform, k.APIs, k.Overrides = fresh.Base, fresh.Challenges, fresh.Platform, fresh.APIs, fresh.Overrides
+	return &Change{Layer: "docs", Status: "updated", Files: changed,
+		Message: fmt.Sprintf("Knowledge reloaded (%s) — applies from the next challenge", strings.Join(changed, ", "))}
+}
+
+func (w *Watcher) pollSoul(k *Knowledge) *Change {
+	stamp := stampFile(SoulPath())
+	if stamp == w.soul {
+		return nil
+	}
+	w.soul = stamp
+
+	soul, err := LoadSoul(w.apiKey)
+	switch {
+	case err != nil:
+		msg := "Soul file unreadable — keeping the personality loaded earlier"
+		if k.Soul == "" {
+			msg = "Soul file unreadable — answering without a personality"
+		}
+		return &Change{Layer: "soul", Status: "unreadable", Message: msg, Error: err.Error()}
+	case strings.TrimSpace(soul) == k.Soul:
+		return nil // touched or re-encrypted, same personality
+	case !stamp.exists || strings.TrimSpace(soul) == "":
+		k.Soul = ""
+		return &Change{Layer: "soul", Status: "removed", Message: "Soul removed — answering without a personality"}
+	default:
+		verb := "updated"
+		if k.Soul == "" {
+			verb = "added"
+		}
+		k.Soul = strings.TrimSpace(soul)
+		return &Change{Layer: "soul", Status: "updated", Message: fmt.Sprintf("Soul %s — new personality applies from the next challenge", verb)}
+	}
+}
@@ -0,0 +1,82 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcherSoul(t *testing.T) {
+	t.Setenv("CLAWWORK_HOME", t.TempDir())
+	const key = "clwk_test"
+	k := &Knowledge{}
+	w := NewWatcher(key)
+	if c := w.Poll(k); len(c) != 0 {
+		t.Fatalf("unchanged: %+v", c)
+	}
+
+	// Each step moves the mtime forward so the change is seen even on
+	// filesystems with coarse timestamps.
+	mod := time.Now()
+	touch := func() {
+		mod = mod.Add(time.Second)
+		_ = os.Chtimes(SoulPath(), mod, mod)
+	}
+
+	if err := SaveSoul(key, "Witty."); err != nil {
+		t.Fatal(err)
+	}
+	touch()
+	if c := w.Poll(k); len(c) != 1 || c[0].Status != "updated" || k.Soul != "Witty." {
+		t.Fatalf("added: %+v, soul %q", c, k.Soul)
+	}
+
+	if err := os.WriteFile(SoulPath(), []byte(soulMagic+"garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	touch()
+	if c := w.Poll(k); len(c) != 1 || c[0].Status != "unreadable" || k.Soul != "Witty." {
+		t.Fatalf("corrupted: %+v, soul %q", c, k.Soul)
+	}
+
+	if err := ResetSoul(); err != nil {
+		t.Fatal(err)
+	}
+	if c := w.Poll(k); len(c) != 1 || c[0].Status != "removed" || k.Soul != "" {
+		t.Fatalf("removed: %+v, soul %q", c, k.Soul)
+	}
+}
+
+func TestWatcherDocs(t *testing.T) {
+	t.Setenv("CLAWWORK_HOME", t.TempDir())
+	k, err := Load("clwk_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWatcher("clwk_test")
+	embedded := k.Challenges
+
+	path := filepath.Join(DocsDir(), "challenges.md")
+	if err := os.MkdirAll(DocsDir(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("Answer in haiku.\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c := w.Poll(k)
+	if len(c) != 1 || c[0].Layer != "docs" || k.Challenges != "Answer in haiku." {
+		t.Fatalf("override: %+v, challenges %q", c, k.Challenges)
+	}
+	if !strings.Contains(k.SystemPrompt(), "Answer in haiku.") || len(k.Overrides) != 1 {
+		t.Errorf("override not in prompt (overrides %v)", k.Overrides)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if c := w.Poll(k); len(c) != 1 || k.Challenges != embedded {
+		t.Fatalf("removed override: %+v", c)
+	}
+}
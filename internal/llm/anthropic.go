@@ -15,29 +15,30 @@ const anthropicURL = "https://api.anthropic.com/v1/messages"
 
 // AnthropicProvider implements Provider for the Anthropic Messages API.
 type AnthropicProvider struct {
-	apiKey       string
-	model        string
-	systemPrompt string
-	maxTokens    int
-	client       *http.Client
+	apiKey string
+	model  string
+	client *http.Client
+	vision bool // model accepts image content blocks
+	defaults
 }
 
 // NewAnthropic creates a new Anthropic provider.
 func NewAnthropic(apiKey, model, systemPrompt string, maxTokens int) *AnthropicProvider {
-	return &AnthropicProvider{
-		apiKey:       apiKey,
-		model:        model,
-		systemPrompt: systemPrompt,
-		maxTokens:    maxTokens,
-		client:       &http.Client{Timeout: 60 * time.Second},
+	p := &AnthropicProvider{
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{Timeout: 60 * time.Second},
 	}
+	p.init(systemPrompt, maxTokens)
+	return p
 }
 
 type anthropicRequest struct {
-	Model     string             `json:"model"`
-	MaxTokens int                `json:"max_tokens"`
-	System    string             `json:"system,omitempty"`
-	Messages  []anthropicMessage `json:"messages"`
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature *float64           `json:"temperature,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -57,18 +58,79 @@ type anthropicResponse struct {
 func (p *AnthropicProvider) Answer(ctx context.Context, prompt string) (string, error) {
 	reqBody := anthropicRequest{
 		Model:     p.model,
-		MaxTokens: p.maxTokens,
-		System:    p.systemPrompt,
+		MaxTokens: p.replyTokens(ctx),
+		System:    p.systemPrompt(ctx),
 		Messages: []anthropicMessage{
 			{Role: "user", Content: prompt},
 		},
+		Temperature: temperature(ctx),
 	}
 
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("marshal: %w", err)
 	}
+	return p.send(ctx, body)
+}
+
+// anthropicBlock is a content block in a multimodal Anthropic message.
+type anthropicBlock struct {
+	Type   string                `json:"type"` // "text" or "image"
+	Text   string                `json:"text,omitempty"`
+	Source *anthropicImageSource `json:"source,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"` // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicVisionMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicVisionRequest struct {
+	Model     string                   `json:"model"`
+	MaxTokens int                      `json:"max_tokens"`
+	System    string                   `json:"system,omitempty"`
+	Messages  []anthropicVisionMessage `json:"messages"`
+}
+
+// SupportsVision implements llm.VisionProvider.
+func (p *AnthropicProvider) SupportsVision() bool { return p.vision }
+
+// AnswerWithImages implements llm.VisionProvider using Anthropic image content blocks.
+// Data URLs are sent as base64 sources; remote images as url sources.
+func (p *AnthropicProvider) AnswerWithImages(ctx context.Context, prompt string, images []Image) (string, error) {
+	blocks := make([]anthropicBlock, 0, len(images)+1)
+	for _, img := range images {
+		src := &anthropicImageSource{Type: "url", URL: img.URL}
+		if mediaType, data, ok := img.splitDataURL(); ok {
+			src = &anthropicImageSource{Type: "base64", MediaType: mediaType, Data: data}
+		}
+		blocks = append(blocks, anthropicBlock{Type: "image", Source: src})
+	}
+	blocks = append(blocks, anthropicBlock{Type: "text", Text: prompt})
+
+	reqBody := anthropicVisionRequest{
+		Model:     p.model,
+		MaxTokens: p.replyTokens(ctx),
+		System:    p.systemPrompt(ctx),
+		Messages:  []anthropicVisionMessage{{Role: "user", Content: blocks}},
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return p.send(ctx, body)
+}
 
+// send posts a prepared Messages API body and extracts the first text block.
+func (p *AnthropicProvider) send(ctx context.Context, body []byte) (string, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", anthropicURL, bytes.NewReader(body))
 	if err != nil {
 		return "", fmt.Errorf("create request: %w", err)
@@ -89,7 +151,7 @@ func (p *AnthropicProvider) Answer(ctx context.Context, prompt string) (string,
 	}
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("Anthropic returned %d: %s", resp.StatusCode, truncateStr(string(respBody), 200))
+		return "", statusError("Anthropic", resp, respBody)
 	}
 
 	var anthropicResp anthropicResponse
@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// breakerThreshold is how many consecutive failures trip the breaker.
+	breakerThreshold = 5
+	// breakerProbeInterval is how often a tripped breaker lets one call through.
+	breakerProbeInterval = time.Minute
+)
+
+// CircuitOpenError is returned without calling the provider while the
+// breaker is tripped. RetryIn is the time until the next probe.
+type CircuitOpenError struct {
+	Reason  string
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("LLM degraded (%s), next probe in %s", e.Reason, e.RetryIn.Round(time.Second))
+}
+
+// Health is a snapshot of a breaker's state.
+type Health struct {
+	Degraded bool
+	Reason   string    // human-readable cause, e.g. "out of quota"
+	Since    time.Time // when the breaker tripped
+}
+
+// Breaker wraps a Provider and stops calling it after sustained failures,
+// letting a single probe through every breakerProbeInterval until it recovers.
+type Breaker struct {
+	Provider
+
+	// OnChange is called when the breaker trips or recovers.
+	OnChange func(Health)
+
+	mu        sync.Mutex
+	failures  int
+	health    Health
+	nextProbe time.Time
+	probing   bool
+}
+
+// NewBreaker wraps p with a circuit breaker.
+func NewBreaker(p Provider) *Breaker {
+	return &Breaker{Provider: p}
+}
+
+// Health returns the current breaker state.
+func (b *Breaker) Health() Health {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.health
+}
+
+// Answer calls the wrapped provider unless the breaker is open.
+func (b *Breaker) Answer(ctx context.Context, prompt string) (string, error) {
+	if err := b.allow(); err != nil {
+		return "", err
+	}
+	answer, err := b.Provider.Answer(ctx, prompt)
+	if err != nil && ctx.Err() != nil {
+		// Our own cancellation says nothing about provider health.
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+		return answer, err
+	}
+	b.record(err)
+	return answer, err
+}
+
+// SetThinking forwards to the wrapped provider if it supports it.
+func (b *Breaker) SetThinking(enabled bool) {
+	if t, ok := b.Provider.(ThinkingToggler); ok {
+		t.SetThinking(enabled)
+	}
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.health.Degraded {
+		return nil
+	}
+	now := time.Now()
+	if b.probing || now.Before(b.nextProbe) {
+		return &CircuitOpenError{Reason: b.health.Reason, RetryIn: max(b.nextProbe.Sub(now), time.Second)}
+	}
+	b.probing = true
+	return nil
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	var changed *Health
+	switch {
+	case err == nil:
+		b.failures = 0
+		b.probing = false
+		if b.health.Degraded {
+			b.health = Health{}
+			changed = &b.health
+		}
+	default:
+		b.failures++
+		b.probing = false
+		b.nextProbe = time.Now().Add(breakerProbeInterval)
+		if !b.health.Degraded && b.failures >= breakerThreshold {
+			b.health = Health{Degraded: true, Reason: failureReason(err), Since: time.Now()}
+			changed = &b.health
+		} else if b.health.Degraded {
+			b.health.Reason = failureReason(err)
+		}
+	}
+	var h Health
+	if changed != nil {
+		h = *changed
+	}
+	cb := b.OnChange
+	b.mu.Unlock()
+
+	if changed != nil && cb != nil {
+		cb(h)
+	}
+}
+
+// failureReason turns a provider error into a short, actionable cause.
+func failureReason(err error) string {
+	msg := strings.ToLower(err.Error())
+	var rl *RateLimitError
+	var netErr net.Error
+	switch {
+	case strings.Contains(msg, "quota") || strings.Contains(msg, "insufficient") ||
+		strings.Contains(msg, "balance") || strings.Contains(msg, "billing") || strings.Contains(msg, "credit"):
+		return "API key is out of quota or credit"
+	case strings.Contains(msg, " 401") || strings.Contains(msg, " 403") ||
+		strings.Contains(msg, "invalid api key") || strings.Contains(msg, "unauthorized"):
+		return "API key was rejected"
+	case errors.As(err, &rl):
+		return "rate limited by the provider"
+	case errors.As(err, &netErr) || strings.Contains(msg, "request failed"):
+		return "provider unreachable"
+	case strings.Contains(msg, "empty"):
+		return "provider returns empty answers"
+	default:
+		return truncateStr(err.Error(), 120)
+	}
+}
+
+// SetSystemPrompt implements PromptSetter when the wrapped provider does.
+func (b *Breaker) SetSystemPrompt(prompt string) {
+	if ps, ok := b.Provider.(PromptSetter); ok {
+		ps.SetSystemPrompt(prompt)
+	}
+}
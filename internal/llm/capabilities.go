@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"fmt"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/tools"
+)
+
+// unwrap returns the provider inside a Breaker, so capability checks see
+// the real implementation.
+func unwrap(p Provider) Provider {
+	if b, ok := p.(*Breaker); ok {
+		return b.Provider
+	}
+	return p
+}
+
+// ChatWarnings lists console chat features p cannot provide, as
+// actionable one-line messages for startup output.
+func ChatWarnings(p Provider, cfg *config.LLMConfig) []string {
+	p = unwrap(p)
+	var warns []string
+	if _, ok := p.(tools.ChatToolProvider); !ok {
+		warns = append(warns, fmt.Sprintf("%s provider: chat tools unavailable — console chat can't run shell, HTTP, script or file tools (use an OpenAI-compatible provider for tools)", cfg.Provider))
+	}
+	if _, ok := p.(ThinkingToggler); !ok {
+		warns = append(warns, fmt.Sprintf("%s provider: thinking toggle unavailable — the console's think button has no effect", cfg.Provider))
+	}
+	return warns
+}
+
+// BudgetWarning reports when a reply budget of maxTokens, plus reserve for
+// the prompt, does not fit the model's context window. Returns "" when it fits.
+func BudgetWarning(cfg *config.LLMConfig, maxTokens, reserve int) string {
+	window := ContextWindow(cfg)
+	if maxTokens+reserve <= window {
+		return ""
+	}
+	hint := "llm.context_window"
+	if cfg.Provider == "ollama" {
+		hint = "llm.ollama.num_ctx"
+	}
+	return fmt.Sprintf("model context too small: max_tokens %d plus a %d-token prompt exceeds the %d-token window — raise %s if %s supports more",
+		maxTokens, reserve, window, hint, cfg.Model)
+}
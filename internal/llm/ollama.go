@@ -7,32 +7,62 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
 )
 
 // OllamaProvider implements Provider for a local Ollama instance.
 type OllamaProvider struct {
-	baseURL      string
-	model        string
-	systemPrompt string
-	client       *http.Client
+	baseURL string
+	model   string
+	client  *http.Client
+	defaults
+
+	options   ollamaOptions
+	keepAlive any // duration string or seconds; nil leaves Ollama's default
 }
 
 // NewOllama creates a new Ollama provider.
 func NewOllama(baseURL, model, systemPrompt string) *OllamaProvider {
-	return &OllamaProvider{
-		baseURL:      strings.TrimRight(baseURL, "/"),
-		model:        model,
-		systemPrompt: systemPrompt,
-		client:       &http.Client{Timeout: 60 * time.Second}, // local models can be slower
+	p := &OllamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{Timeout: 60 * time.Second}, // local models can be slower
 	}
+	p.init(systemPrompt, 0)
+	return p
 }
 
 type ollamaRequest struct {
-	Model    string        `json:"model"`
-	Messages []chatMessage `json:"messages"`
-	Stream   bool          `json:"stream"`
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	Stream    bool          `json:"stream"`
+	Options   ollamaOptions `json:"options"`
+	KeepAlive any           `json:"keep_alive,omitempty"`
+}
+
+// ollamaOptions are the model parameters sent with each request.
+type ollamaOptions struct {
+	NumCtx      int      `json:"num_ctx,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// SetOptions applies config passthrough options. keep_alive is sent as a
+// number when it is plain seconds ("-1" keeps the model loaded forever),
+// otherwise as a duration string.
+func (p *OllamaProvider) SetOptions(o config.OllamaConfig) {
+	p.options = ollamaOptions{NumCtx: o.ContextSize(), Temperature: o.Temperature}
+	p.keepAlive = nil
+	if o.KeepAlive != "" {
+		if secs, err := strconv.Atoi(o.KeepAlive); err == nil {
+			p.keepAlive = secs
+		} else {
+			p.keepAlive = o.KeepAlive
+		}
+	}
 }
 
 type ollamaResponse struct {
@@ -44,10 +74,15 @@ func (p *OllamaProvider) Answer(ctx context.Context, prompt string) (string, err
 	reqBody := ollamaRequest{
 		Model: p.model,
 		Messages: []chatMessage{
-			{Role: "system", Content: p.systemPrompt},
+			{Role: "system", Content: p.systemPrompt(ctx)},
 			{Role: "user", Content: prompt},
 		},
-		Stream: false,
+		Stream:    false,
+		Options:   p.options,
+		KeepAlive: p.keepAlive,
+	}
+	if t := temperature(ctx); t != nil {
+		reqBody.Options.Temperature = t
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -20,22 +20,22 @@ type OpenAIProvider struct {
 	baseURL         string
 	apiKey          string
 	baseModel       string // original model from config (never changes)
-	systemPrompt    string
-	maxTokens       int
 	client          *http.Client
 	disableThinking atomic.Bool // when true, thinking mode is off
+	vision          bool        // model accepts image_url content parts
+	defaults
 }
 
 // NewOpenAI creates a new OpenAI-compatible provider.
 func NewOpenAI(baseURL, apiKey, model, systemPrompt string, maxTokens int) *OpenAIProvider {
-	return &OpenAIProvider{
-		baseURL:      strings.TrimRight(baseURL, "/"),
-		apiKey:       apiKey,
-		baseModel:    model,
-		systemPrompt: systemPrompt,
-		maxTokens:    maxTokens,
-		client:       &http.Client{Timeout: 120 * time.Second},
+	p := &OpenAIProvider{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		apiKey:    apiKey,
+		baseModel: model,
+		client:    &http.Client{Timeout: 120 * time.Second},
 	}
+	p.init(systemPrompt, maxTokens)
+	return p
 }
 
 // SetThinking implements llm.ThinkingToggler.
@@ -44,11 +44,20 @@ func (p *OpenAIProvider) SetThinking(enabled bool) {
 	p.disableThinking.Store(!enabled)
 }
 
+// thinkingOff reports whether thinking is off for this request: the
+// WithThinking setting if there is one, else SetThinking's.
+func (p *OpenAIProvider) thinkingOff(ctx context.Context) bool {
+	if enabled, ok := thinking(ctx); ok {
+		return !enabled
+	}
+	return p.disableThinking.Load()
+}
+
 // activeModel returns the model to use for the current request.
 // DeepSeek uses separate models for reasoning vs chat; other providers
 // use the same model and control thinking via the enable_thinking flag.
-func (p *OpenAIProvider) activeModel() string {
-	if p.disableThinking.Load() && p.baseModel == "deepseek-reasoner" {
+func (p *OpenAIProvider) activeModel(ctx context.Context) string {
+	if p.thinkingOff(ctx) && p.baseModel == "deepseek-reasoner" {
 		return "deepseek-chat"
 	}
 	return p.baseModel
@@ -58,22 +67,25 @@ func (p *OpenAIProvider) activeModel() string {
 // Returns nil (field omitted) for DeepSeek (handled via model swap) and
 // when thinking is enabled (API default). Returns &false only for other
 // thinking models when the user disables thinking.
-func (p *OpenAIProvider) thinkingField() *bool {
+func (p *OpenAIProvider) thinkingField(ctx context.Context) *bool {
 	if p.baseModel == "deepseek-reasoner" {
 		return nil // DeepSeek: switch model instead, no flag needed
 	}
-	if p.disableThinking.Load() {
+	if p.thinkingOff(ctx) {
 		v := false
 		return &v
 	}
 	return nil
 }
 
+// chatRequest is a chat completion request. Messages holds []chatMessage,
+// or []visionMessage when the prompt carries images.
 type chatRequest struct {
-	Model          string        `json:"model"`
-	Messages       []chatMessage `json:"messages"`
-	MaxTokens      int           `json:"max_tokens,omitempty"`
-	EnableThinking *bool         `json:"enable_thinking,omitempty"`
+	Model          string   `json:"model"`
+	Messages       any      `json:"messages"`
+	MaxTokens      int      `json:"max_tokens,omitempty"`
+	EnableThinking *bool    `json:"enable_thinking,omitempty"`
+	Temperature    *float64 `json:"temperature,omitempty"`
 }
 
 type chatMessage struct {
@@ -92,14 +104,21 @@ type chatResponse struct {
 }
 
 func (p *OpenAIProvider) Answer(ctx context.Context, prompt string) (string, error) {
+	return p.complete(ctx, []chatMessage{
+		{Role: "system", Content: p.systemPrompt(ctx)},
+		{Role: "user", Content: prompt},
+	})
+}
+
+// complete sends messages to the chat completions endpoint with the
+// request's model, token and thinking settings and returns the answer.
+func (p *OpenAIProvider) complete(ctx context.Context, messages any) (string, error) {
 	reqBody := chatRequest{
-		Model: p.activeModel(),
-		Messages: []chatMessage{
-			{Role: "system", Content: p.systemPrompt},
-			{Role: "user", Content: prompt},
-		},
-		MaxTokens:      p.maxTokens,
-		EnableThinking: p.thinkingField(),
+		Model:          p.activeModel(ctx),
+		Messages:       messages,
+		MaxTokens:      p.replyTokens(ctx),
+		EnableThinking: p.thinkingField(ctx),
+		Temperature:    temperature(ctx),
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -127,7 +146,7 @@ func (p *OpenAIProvider) Answer(ctx context.Context, prompt string) (string, err
 	}
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("LLM returned %d: %s", resp.StatusCode, truncateStr(string(respBody), 200))
+		return "", statusError("LLM", resp, respBody)
 	}
 
 	var chatResp chatResponse
@@ -159,6 +178,41 @@ func (p *OpenAIProvider) Name() string {
 	return fmt.Sprintf("openai-compat (%s)", p.baseModel)
 }
 
+// ── Vision support (OpenAI image_url content parts) ──────────────────────────
+
+// visionMessage is a chat message whose content may be a string or a list of parts.
+type visionMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type visionPart struct {
+	Type     string          `json:"type"` // "text" or "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *visionImageURL `json:"image_url,omitempty"`
+}
+
+type visionImageURL struct {
+	URL string `json:"url"`
+}
+
+// SupportsVision implements llm.VisionProvider.
+func (p *OpenAIProvider) SupportsVision() bool { return p.vision }
+
+// AnswerWithImages implements llm.VisionProvider using OpenAI-style content parts.
+// Works for GPT-4o, Gemini's OpenAI-compatible endpoint, and other compatible vision models.
+func (p *OpenAIProvider) AnswerWithImages(ctx context.Context, prompt string, images []Image) (string, error) {
+	parts := []visionPart{{Type: "text", Text: prompt}}
+	for _, img := range images {
+		parts = append(parts, visionPart{Type: "image_url", ImageURL: &visionImageURL{URL: img.URL}})
+	}
+
+	return p.complete(ctx, []visionMessage{
+		{Role: "system", Content: p.systemPrompt(ctx)},
+		{Role: "user", Content: parts},
+	})
+}
+
 // ── Tool-calling support (OpenAI function-calling protocol) ──────────────────
 
 // openToolCallFunc holds the name and JSON arguments of a tool call.
@@ -235,10 +289,10 @@ func (p *OpenAIProvider) ChatWithTools(
 ) (string, string, []tools.ToolCall, string, error) {
 	// Build OpenAI-format messages: system first, then caller messages.
 	reqMsgs := make([]toolReqMessage, 0, len(messages)+1)
-	if p.systemPrompt != "" {
+	if system := p.systemPrompt(ctx); system != "" {
 		reqMsgs = append(reqMsgs, toolReqMessage{
 			Role:    "system",
-			Content: strPtr(p.systemPrompt),
+			Content: strPtr(system),
 		})
 	}
 	for _, m := range messages {
@@ -277,12 +331,12 @@ func (p *OpenAIProvider) ChatWithTools(
 	}
 
 	req := toolChatReq{
-		Model:          p.activeModel(),
+		Model:          p.activeModel(ctx),
 		Messages:       reqMsgs,
-		MaxTokens:      p.maxTokens,
+		MaxTokens:      p.replyTokens(ctx),
 		Tools:          specs,
 		ToolChoice:     "auto",
-		EnableThinking: p.thinkingField(),
+		EnableThinking: p.thinkingField(ctx),
 	}
 
 	body, err := json.Marshal(req)
@@ -309,7 +363,7 @@ func (p *OpenAIProvider) ChatWithTools(
 		return "", "", nil, "", fmt.Errorf("read response: %w", err)
 	}
 	if resp.StatusCode != 200 {
-		return "", "", nil, "", fmt.Errorf("LLM returned %d: %s", resp.StatusCode, truncateStr(string(respBody), 200))
+		return "", "", nil, "", statusError("LLM", resp, respBody)
 	}
 
 	var chatResp toolChatResp
@@ -4,19 +4,33 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/tools"
 )
 
 const platformURL = "https://platform-llm.eason9527.workers.dev"
 
+// ErrChatUnsupported is returned by PlatformProvider.ChatWithTools when the
+// platform proxy has no tool-calling endpoint. Callers should fall back to
+// Answer and tell the user tools are unavailable.
+var ErrChatUnsupported = errors.New("platform LLM proxy does not support tool calling")
+
 // PlatformProvider calls the ClawWork platform LLM proxy.
 // Users provide a platform key; the proxy handles the actual LLM call.
 type PlatformProvider struct {
-	apiKey string
-	client *http.Client
+	apiKey   string
+	client   *http.Client
+	defaults // older proxies ignore the system prompt
+
+	chatUnsupported atomic.Bool // set once /chat answers 404, to stop probing
 }
 
 // NewPlatform creates a new platform LLM provider.
@@ -28,7 +42,10 @@ func NewPlatform(apiKey string) *PlatformProvider {
 }
 
 type platformRequest struct {
-	Prompt string `json:"prompt"`
+	Prompt      string   `json:"prompt"`
+	System      string   `json:"system,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"` // ignored by proxies that don't support it
 }
 
 type platformResponse struct {
@@ -37,48 +54,119 @@ type platformResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// platformChatRequest is the /chat body: the same message and tool shapes
+// the agent loop uses.
+type platformChatRequest struct {
+	System    string          `json:"system,omitempty"`
+	Messages  []tools.Message `json:"messages"`
+	Tools     []tools.ToolDef `json:"tools,omitempty"`
+	MaxTokens int             `json:"max_tokens,omitempty"`
+}
+
+type platformChatResponse struct {
+	Content          string           `json:"content"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"`
+	ToolCalls        []tools.ToolCall `json:"tool_calls,omitempty"`
+	FinishReason     string           `json:"finish_reason"`
+	Error            string           `json:"error,omitempty"`
+	Message          string           `json:"message,omitempty"`
+}
+
 func (p *PlatformProvider) Answer(ctx context.Context, prompt string) (string, error) {
-	body, err := json.Marshal(platformRequest{Prompt: prompt})
+	var result platformResponse
+	status, err := p.post(ctx, "/answer", platformRequest{Prompt: prompt, System: p.systemPrompt(ctx), MaxTokens: p.replyTokens(ctx), Temperature: temperature(ctx)}, &result)
 	if err != nil {
-		return "", fmt.Errorf("marshal: %w", err)
+		return "", err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", platformURL+"/answer", bytes.NewReader(body))
-	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+	if status != 200 || result.Error != "" {
+		msg := result.Message
+		if msg == "" {
+			msg = result.Error
+		}
+		return "", fmt.Errorf("platform LLM error: %s", msg)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+	if result.Answer == "" {
+		return "", fmt.Errorf("platform LLM returned empty answer")
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
-	}
+	return result.Answer, nil
+}
 
-	var result platformResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("parse response: %w", err)
+// ChatWithTools implements tools.ChatToolProvider through the proxy's /chat
+// endpoint. Proxies without it answer 404; that is remembered and reported
+// as ErrChatUnsupported from then on.
+func (p *PlatformProvider) ChatWithTools(
+	ctx context.Context,
+	messages []tools.Message,
+	toolDefs []tools.ToolDef,
+) (string, string, []tools.ToolCall, string, error) {
+	if p.chatUnsupported.Load() {
+		return "", "", nil, "", ErrChatUnsupported
 	}
 
-	if resp.StatusCode != 200 || result.Error != "" {
+	var result platformChatResponse
+	status, err := p.post(ctx, "/chat", platformChatRequest{
+		System:    p.systemPrompt(ctx),
+		Messages:  messages,
+		Tools:     toolDefs,
+		MaxTokens: p.replyTokens(ctx),
+	}, &result)
+	switch {
+	case status == http.StatusNotFound || status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented:
+		p.chatUnsupported.Store(true)
+		slog.Warn("platform LLM proxy has no tool-calling endpoint; chat falls back to plain answers")
+		return "", "", nil, "", ErrChatUnsupported
+	case err != nil:
+		return "", "", nil, "", err
+	case status != 200 || result.Error != "":
 		msg := result.Message
 		if msg == "" {
 			msg = result.Error
 		}
-		return "", fmt.Errorf("platform LLM error: %s", msg)
+		return "", "", nil, "", fmt.Errorf("platform LLM error: %s", msg)
 	}
 
-	if result.Answer == "" {
-		return "", fmt.Errorf("platform LLM returned empty answer")
+	if result.FinishReason == "" {
+		result.FinishReason = "stop"
+		if len(result.ToolCalls) > 0 {
+			result.FinishReason = "tool_calls"
+		}
 	}
+	return result.Content, result.ReasoningContent, result.ToolCalls, result.FinishReason, nil
+}
 
-	return result.Answer, nil
+// post sends payload as JSON to the proxy and decodes the reply into out.
+// The status code is returned even when the body isn't JSON.
+func (p *PlatformProvider) post(ctx context.Context, path string, payload, out any) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", platformURL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("read response: %w", err)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return resp.StatusCode, fmt.Errorf("parse response: %s", truncateStr(strings.TrimSpace(string(respBody)), 200))
+	}
+	return resp.StatusCode, nil
 }
 
 func (p *PlatformProvider) Name() string {
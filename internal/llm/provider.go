@@ -4,7 +4,9 @@ package llm
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/clawplaza/clawwork-cli/internal/budget"
 	"github.com/clawplaza/clawwork-cli/internal/config"
 )
 
@@ -22,23 +24,58 @@ type ThinkingToggler interface {
 	SetThinking(enabled bool)
 }
 
+// httpTimeoutSlack is added to the answer timeout for the HTTP client.
+const httpTimeoutSlack = 15 * time.Second
+
+// ContextWindow returns the context size, in tokens, prompts must fit for
+// cfg. For Ollama it is the num_ctx requested unless context_window says
+// otherwise.
+func ContextWindow(cfg *config.LLMConfig) int {
+	override := cfg.ContextWindow
+	if override == 0 && cfg.Provider == "ollama" {
+		override = cfg.Ollama.ContextSize()
+	}
+	return budget.Window(cfg.Provider, cfg.Model, override)
+}
+
 // NewProvider creates an LLM provider based on the config.
 // maxTokens controls the maximum response length (e.g. 256 for challenges, 1024 for chat).
-// The systemPrompt is injected into each request (except platform mode which uses server-side prompts).
+// The systemPrompt is injected into each request (platform proxies that predate
+// system prompts ignore it and use their server-side prompt).
 func NewProvider(cfg *config.LLMConfig, systemPrompt string, maxTokens int) (Provider, error) {
+	rt, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// The HTTP timeout is a backstop behind the caller's answer deadline,
+	// so a slow model fails with a clear timeout rather than a cut socket.
+	timeout := cfg.AnswerTimeout() + httpTimeoutSlack
+
 	switch cfg.Provider {
 	case "platform":
-		return NewPlatform(cfg.APIKey), nil
+		p := NewPlatform(cfg.APIKey)
+		p.init(systemPrompt, maxTokens)
+		p.client.Transport, p.client.Timeout = rt, timeout
+		return p, nil
 	case "openai":
-		return NewOpenAI(cfg.BaseURL, cfg.APIKey, cfg.Model, systemPrompt, maxTokens), nil
+		p := NewOpenAI(cfg.BaseURL, cfg.APIKey, cfg.Model, systemPrompt, maxTokens)
+		p.vision = detectVision(cfg.Model, cfg.Vision)
+		p.client.Transport, p.client.Timeout = rt, timeout
+		return p, nil
 	case "anthropic":
-		return NewAnthropic(cfg.APIKey, cfg.Model, systemPrompt, maxTokens), nil
+		p := NewAnthropic(cfg.APIKey, cfg.Model, systemPrompt, maxTokens)
+		p.vision = detectVision(cfg.Model, cfg.Vision)
+		p.client.Transport, p.client.Timeout = rt, timeout
+		return p, nil
 	case "ollama":
 		baseURL := cfg.BaseURL
 		if baseURL == "" {
 			baseURL = "http://localhost:11434"
 		}
-		return NewOllama(baseURL, cfg.Model, systemPrompt), nil
+		p := NewOllama(baseURL, cfg.Model, systemPrompt)
+		p.SetOptions(cfg.Ollama)
+		p.client.Transport, p.client.Timeout = rt, timeout
+		return p, nil
 	default:
 		return nil, fmt.Errorf("unknown LLM provider: %s", cfg.Provider)
 	}
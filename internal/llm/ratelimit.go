@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRetryWait bounds how long a caller waits on a provider's Retry-After,
+// so a misbehaving header can't stall the miner for hours.
+const MaxRetryWait = 2 * time.Minute
+
+// rateLimitFallback is the wait used when a 429 carries no usable hint.
+const rateLimitFallback = 10 * time.Second
+
+// RateLimitError is returned when a provider rejects a call with 429
+// (or Anthropic's 529 "overloaded"). RetryAfter is zero if the response
+// carried no timing hint.
+type RateLimitError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *RateLimitError) Error() string {
+	msg := fmt.Sprintf("%s returned %d: %s", e.Provider, e.StatusCode, e.Body)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter.Round(time.Second))
+	}
+	return msg
+}
+
+// RetryDelay returns how long to wait before retrying after err.
+// Rate-limit errors use the provider's hint and an open circuit breaker
+// its next probe time (both bounded by MaxRetryWait); anything else uses fallback.
+func RetryDelay(err error, fallback time.Duration) time.Duration {
+	var open *CircuitOpenError
+	if errors.As(err, &open) {
+		return min(max(open.RetryIn, fallback), MaxRetryWait)
+	}
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		return fallback
+	}
+	d := rl.RetryAfter
+	if d <= 0 {
+		d = rateLimitFallback
+	}
+	return min(max(d, fallback), MaxRetryWait)
+}
+
+// statusError builds the error for a non-200 provider response.
+func statusError(provider string, resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == 529 {
+		return &RateLimitError{
+			Provider:   provider,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header, time.Now()),
+			Body:       truncateStr(string(body), 200),
+		}
+	}
+	return fmt.Errorf("%s returned %d: %s", provider, resp.StatusCode, truncateStr(string(body), 200))
+}
+
+// parseRetryAfter reads the wait hint from standard and vendor headers:
+// Retry-After (seconds or HTTP date), retry-after-ms, OpenAI's
+// x-ratelimit-reset-* durations ("1s", "6m0s") and Anthropic's
+// anthropic-ratelimit-*-reset timestamps. The longest hint wins.
+func parseRetryAfter(h http.Header, now time.Time) time.Duration {
+	var best time.Duration
+	take := func(d time.Duration) {
+		if d > best {
+			best = d
+		}
+	}
+
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			take(time.Duration(secs * float64(time.Second)))
+		} else if t, err := http.ParseTime(v); err == nil {
+			take(t.Sub(now))
+		}
+	}
+	if v := h.Get("retry-after-ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil {
+			take(time.Duration(ms * float64(time.Millisecond)))
+		}
+	}
+	for key, vals := range h {
+		if len(vals) == 0 {
+			continue
+		}
+		k := strings.ToLower(key)
+		switch {
+		case strings.HasPrefix(k, "x-ratelimit-reset"):
+			// Only consult reset times for exhausted limits.
+			remaining := h.Get(strings.Replace(k, "reset", "remaining", 1))
+			if remaining != "" && remaining != "0" {
+				continue
+			}
+			if d, err := time.ParseDuration(vals[0]); err == nil {
+				take(d)
+			}
+		case strings.HasPrefix(k, "anthropic-ratelimit-") && strings.HasSuffix(k, "-reset"):
+			remaining := h.Get(strings.TrimSuffix(k, "-reset") + "-remaining")
+			if remaining != "" && remaining != "0" {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339, vals[0]); err == nil {
+				take(t.Sub(now))
+			}
+		}
+	}
+	return best
+}
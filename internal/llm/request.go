@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// PromptSetter is implemented by providers whose system prompt can be
+// replaced while they are in use, e.g. after the knowledge was reloaded.
+type PromptSetter interface {
+	SetSystemPrompt(prompt string)
+}
+
+// defaults holds a provider's system prompt and reply budget. Calls can
+// override both through their context (WithSystemPrompt, WithMaxTokens),
+// so one provider can serve the miner, the console chat and social posts.
+type defaults struct {
+	system    atomic.Pointer[string]
+	maxTokens int
+}
+
+func (d *defaults) init(systemPrompt string, maxTokens int) {
+	d.system.Store(&systemPrompt)
+	d.maxTokens = maxTokens
+}
+
+// SetSystemPrompt implements PromptSetter. Requests already sent keep the
+// prompt they were sent with.
+func (d *defaults) SetSystemPrompt(prompt string) {
+	d.system.Store(&prompt)
+}
+
+// systemPrompt returns the prompt set with WithSystemPrompt, or the
+// provider's own.
+func (d *defaults) systemPrompt(ctx context.Context) string {
+	if s, ok := ctx.Value(systemKey{}).(string); ok {
+		return s
+	}
+	if s := d.system.Load(); s != nil {
+		return *s
+	}
+	return ""
+}
+
+// replyTokens returns the budget set with WithMaxTokens, or the provider's
+// own.
+func (d *defaults) replyTokens(ctx context.Context) int {
+	if n, ok := ctx.Value(maxTokensKey{}).(int); ok {
+		return n
+	}
+	return d.maxTokens
+}
+
+type (
+	systemKey    struct{}
+	maxTokensKey struct{}
+	thinkingKey  struct{}
+)
+
+// WithSystemPrompt makes calls made with the returned context use prompt
+// instead of the provider's system prompt.
+func WithSystemPrompt(ctx context.Context, prompt string) context.Context {
+	return context.WithValue(ctx, systemKey{}, prompt)
+}
+
+// WithMaxTokens makes calls made with the returned context use a reply
+// budget of n tokens instead of the provider's.
+func WithMaxTokens(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, maxTokensKey{}, n)
+}
+
+// WithThinking turns thinking mode on or off for calls made with the
+// returned context, overriding SetThinking. Providers without a thinking
+// mode ignore it.
+func WithThinking(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, thinkingKey{}, enabled)
+}
+
+// thinking returns the WithThinking setting and whether one was made.
+func thinking(ctx context.Context) (enabled, ok bool) {
+	enabled, ok = ctx.Value(thinkingKey{}).(bool)
+	return enabled, ok
+}
@@ -0,0 +1,20 @@
+package llm
+
+import "context"
+
+type temperatureKey struct{}
+
+// WithTemperature asks providers to sample at t for calls made with the
+// returned context, overriding their default. Used to draw varied
+// candidate answers; providers that can't set it ignore it.
+func WithTemperature(ctx context.Context, t float64) context.Context {
+	return context.WithValue(ctx, temperatureKey{}, t)
+}
+
+// temperature returns the temperature set with WithTemperature, or nil.
+func temperature(ctx context.Context) *float64 {
+	if t, ok := ctx.Value(temperatureKey{}).(float64); ok {
+		return &t
+	}
+	return nil
+}
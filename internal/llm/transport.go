@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
+)
+
+// newTransport builds the HTTP transport for LLM calls from the configured
+// proxy (network.proxy when empty) and extra headers.
+func newTransport(cfg *config.LLMConfig) (http.RoundTripper, error) {
+	base, err := httpx.Transport("llm", cfg.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("llm.proxy: %w", err)
+	}
+
+	if len(cfg.Headers) == 0 {
+		return base, nil
+	}
+	return &headerTransport{base: base, headers: cfg.Headers}, nil
+}
+
+// headerTransport adds fixed headers (e.g. API gateway credentials) to every request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Image is an image attachment passed to a vision-capable model.
+// URL is either a remote http(s) URL or a base64 data URL ("data:image/png;base64,...").
+type Image struct {
+	URL string
+}
+
+// IsData reports whether the image is an inline base64 data URL.
+func (img Image) IsData() bool {
+	return strings.HasPrefix(img.URL, "data:")
+}
+
+// splitDataURL returns the media type and base64 payload of a data URL.
+func (img Image) splitDataURL() (mediaType, data string, ok bool) {
+	rest, found := strings.CutPrefix(img.URL, "data:")
+	if !found {
+		return "", "", false
+	}
+	meta, payload, found := strings.Cut(rest, ",")
+	if !found || !strings.HasSuffix(meta, ";base64") {
+		return "", "", false
+	}
+	return strings.TrimSuffix(meta, ";base64"), payload, true
+}
+
+// Describe returns a short human-readable label for the image, used when the
+// model cannot view images and the attachment is degraded to text.
+func (img Image) Describe() string {
+	if mediaType, data, ok := img.splitDataURL(); ok {
+		return fmt.Sprintf("uploaded image (%s, ~%dKB)", mediaType, len(data)*3/4/1024)
+	}
+	return img.URL
+}
+
+// VisionProvider is implemented by providers that can send images using the
+// provider's multimodal message format (OpenAI image_url parts, Anthropic image blocks).
+type VisionProvider interface {
+	// SupportsVision reports whether the configured model accepts image input.
+	SupportsVision() bool
+	// AnswerWithImages is like Answer but attaches images to the user message.
+	AnswerWithImages(ctx context.Context, prompt string, images []Image) (string, error)
+}
+
+// CanSee reports whether p can receive image attachments.
+func CanSee(p Provider) bool {
+	vp, ok := p.(VisionProvider)
+	return ok && vp.SupportsVision()
+}
+
+// DescribeImages renders attachments as plain text for models without vision,
+// so the model can at least acknowledge them instead of silently ignoring them.
+func DescribeImages(images []Image) string {
+	if len(images) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[The user attached %d image(s). The current model cannot view images — say so briefly and answer from the text alone.]\n", len(images))
+	for i, img := range images {
+		fmt.Fprintf(&sb, "- image %d: %s\n", i+1, img.Describe())
+	}
+	return sb.String()
+}
+
+// visionModelHints are model-name fragments known to accept image input.
+var visionModelHints = []string{
+	"gpt-4o", "gpt-4.1", "gpt-4-turbo", "gpt-5", "o3", "o4",
+	"claude-3", "claude-sonnet", "claude-opus", "claude-haiku-4",
+	"gemini", "vision", "-vl", "llava", "kimi-k2.5", "qwen2.5-vl", "pixtral",
+}
+
+// detectVision resolves the vision capability for a model.
+// An explicit config override wins; otherwise the model name is matched against known hints.
+func detectVision(model string, override *bool) bool {
+	if override != nil {
+		return *override
+	}
+	m := strings.ToLower(model)
+	for _, hint := range visionModelHints {
+		if strings.Contains(m, hint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+// Package metrics writes miner metrics in the Prometheus text format to a
+// file for node_exporter's textfile collector, so a miner can be monitored
+// without opening any port.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
+	"github.com/clawplaza/clawwork-cli/internal/miner"
+)
+
+// Source is what the metrics are read from.
+type Source struct {
+	State   *miner.State
+	Agent   string
+	Version string
+	TokenID func() int           // token being mined; nil if unknown
+	Paused  func() bool          // nil if the miner can't be paused
+	HTTP    func() []httpx.Stats // nil outside the miner
+}
+
+// Render writes every metric in the Prometheus text exposition format.
+func Render(w io.Writer, src Source, now time.Time) error {
+	b := bufio.NewWriter(w)
+	agent := `agent="` + escape(src.Agent) + `"`
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	sample := func(name, labels string, v float64) {
+		fmt.Fprintf(b, "%s{%s} %s\n", name, labels, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+
+	metric("clawwork_info", "gauge", "Miner build and agent; always 1.")
+	sample("clawwork_info", agent+`,version="`+escape(src.Version)+`"`, 1)
+
+	st := src.State.Stats(now)
+	for _, c := range []struct {
+		name, help  string
+		life, today float64
+	}{
+		{"inscriptions", "Successful inscriptions.", float64(st.Lifetime.Inscriptions), float64(st.Today.Inscriptions)},
+		{"cw_earned", "CW earned.", float64(st.Lifetime.CWEarned), float64(st.Today.CWEarned)},
+		{"hits", "NFT hits.", float64(st.Lifetime.Hits), float64(st.Today.Hits)},
+		{"challenges_passed", "Challenges passed.", float64(st.Lifetime.ChallengesPassed), float64(st.Today.ChallengesPassed)},
+		{"challenges_failed", "Challenges failed.", float64(st.Lifetime.ChallengesFailed), float64(st.Today.ChallengesFailed)},
+	} {
+		metric("clawwork_"+c.name+"_total", "counter", c.help)
+		sample("clawwork_"+c.name+"_total", agent, c.life)
+		metric("clawwork_today_"+c.name, "gauge", strings.TrimSuffix(c.help, ".")+" today.")
+		sample("clawwork_today_"+c.name, agent, c.today)
+	}
+
+	pen := src.State.PenaltySummary(0)
+	metric("clawwork_cw_lost_total", "counter", "CW lost to penalties, by kind.")
+	for _, kind := range sortedKeys(pen.ByKind) {
+		sample("clawwork_cw_lost_total", agent+`,kind="`+escape(kind)+`"`, float64(pen.ByKind[kind]))
+	}
+
+	trust, minedAt := src.State.Last()
+	metric("clawwork_trust_score", "gauge", "Last trust score reported by the platform.")
+	sample("clawwork_trust_score", agent, float64(trust))
+	metric("clawwork_last_inscription_timestamp_seconds", "gauge", "When the last inscription succeeded (0 = never).")
+	sample("clawwork_last_inscription_timestamp_seconds", agent, unix(minedAt))
+
+	if src.TokenID != nil {
+		metric("clawwork_token_id", "gauge", "Token being mined.")
+		sample("clawwork_token_id", agent, float64(src.TokenID()))
+	}
+	if src.Paused != nil {
+		paused := 0.0
+		if src.Paused() {
+			paused = 1
+		}
+		metric("clawwork_paused", "gauge", "1 while mining is paused.")
+		sample("clawwork_paused", agent, paused)
+	}
+
+	metric("clawwork_quota_blocked_seconds", "gauge", "Time left until a quota allows the action again (0 = available).")
+	for _, q := range src.State.Quotas(now) {
+		sample("clawwork_quota_blocked_seconds", agent+`,quota="`+escape(q.Name)+`"`, q.Remaining(now).Seconds())
+	}
+
+	hists := src.State.LatencyHistograms()
+	metric("clawwork_latency_seconds", "histogram", "Latency per phase (llm, submit, cycle).")
+	for _, phase := range sortedKeys(hists) {
+		h := hists[phase]
+		labels := agent + `,phase="` + escape(phase) + `"`
+		for _, bucket := range h.Cumulative() {
+			le := "+Inf"
+			if bucket[0] >= 0 {
+				le = strconv.FormatFloat(float64(bucket[0])/1000, 'f', -1, 64)
+			}
+			sample("clawwork_latency_seconds_bucket", labels+`,le="`+le+`"`, float64(bucket[1]))
+		}
+		sample("clawwork_latency_seconds_sum", labels, float64(h.SumMs)/1000)
+		sample("clawwork_latency_seconds_count", labels, float64(h.Count))
+	}
+
+	if src.HTTP != nil {
+		stats := src.HTTP()
+		for _, c := range []struct {
+			name, typ, help string
+			value           func(httpx.Stats) float64
+		}{
+			{"clawwork_http_requests_total", "counter", "HTTP requests, by subsystem.", func(s httpx.Stats) float64 { return float64(s.Requests) }},
+			{"clawwork_http_errors_total", "counter", "HTTP requests that got no response, by subsystem.", func(s httpx.Stats) float64 { return float64(s.Errors) }},
+			{"clawwork_http_connections_total", "counter", "Connections opened, by subsystem; the rest were reused.", func(s httpx.Stats) float64 { return float64(s.NewConns) }},
+			{"clawwork_http_request_seconds_total", "counter", "Time spent waiting for HTTP responses, by subsystem.", func(s httpx.Stats) float64 { return s.Seconds }},
+		} {
+			metric(c.name, c.typ, c.help)
+			for _, s := range stats {
+				sample(c.name, agent+`,client="`+escape(s.Client)+`"`, c.value(s))
+			}
+		}
+	}
+
+	metric("clawwork_metrics_written_timestamp_seconds", "gauge", "When this file was written; alert when it goes stale.")
+	sample("clawwork_metrics_written_timestamp_seconds", agent, unix(now))
+	return b.Flush()
+}
+
+// WriteFile renders the metrics to path atomically (write a temporary file,
+// then rename), as the textfile collector requires.
+func WriteFile(path string, src Source) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = Render(tmp, src, time.Now())
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	// node_exporter usually runs as another user.
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Run writes the metrics file every interval until ctx ends.
+func Run(ctx context.Context, path string, every time.Duration, src Source) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		if err := WriteFile(path, src); err != nil {
+			slog.Warn("metrics textfile write failed", "path", path, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func unix(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixMilli()) / 1000
+}
+
+// escape quotes a label value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
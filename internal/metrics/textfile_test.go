@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/miner"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Setenv("CLAWWORK_HOME", t.TempDir())
+	st := miner.LoadState()
+	st.Update(&api.InscribeResponse{CWEarned: 120})
+	st.RecordLatency(miner.PhaseLLM, 1500*time.Millisecond)
+
+	path := filepath.Join(t.TempDir(), "clawwork.prom")
+	if err := WriteFile(path, Source{State: st, Agent: `a"b`, Version: "1.0", Paused: func() bool { return true }}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		`clawwork_info{agent="a\"b",version="1.0"} 1`,
+		`clawwork_cw_earned_total{agent="a\"b"} 120`,
+		`clawwork_today_inscriptions{agent="a\"b"} 1`,
+		`clawwork_paused{agent="a\"b"} 1`,
+		`clawwork_latency_seconds_bucket{agent="a\"b",phase="llm",le="1"} 0`,
+		`clawwork_latency_seconds_bucket{agent="a\"b",phase="llm",le="2"} 1`,
+		`clawwork_latency_seconds_bucket{agent="a\"b",phase="llm",le="+Inf"} 1`,
+		`clawwork_latency_seconds_count{agent="a\"b",phase="llm"} 1`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %s", want)
+		}
+	}
+	if strings.Contains(out, "clawwork_token_id") {
+		t.Error("token metric written without a source")
+	}
+	if left, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*")); len(left) != 0 {
+		t.Errorf("temporary files left behind: %v", left)
+	}
+}
@@ -0,0 +1,41 @@
+package miner
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// trustAlertInterval is the minimum time between two trust alerts.
+const trustAlertInterval = 6 * time.Hour
+
+// checkTrust records the latest trust score and raises an "alert" event if
+// it crossed the configured absolute floor or fell too far within a day.
+func (m *Miner) checkTrust(score int) {
+	now := time.Now()
+	m.State.RecordTrust(score, now)
+
+	msg := trustAlert(m.Alerts, score, m.State.TrustPeak(now.Add(-24*time.Hour)))
+	if msg == "" || now.Sub(m.State.TrustAlertAt) < trustAlertInterval {
+		return
+	}
+	m.State.TrustAlertAt = now
+
+	DisplayAlert(msg)
+	slog.Warn("trust alert", "score", score, "message", msg)
+	m.emit("alert", msg, map[string]any{"kind": "trust", "trust_score": score})
+}
+
+// trustAlert returns the alert message for score, or "" if no threshold is crossed.
+// peak is the highest score observed in the last 24 hours.
+func trustAlert(cfg config.AlertsConfig, score, peak int) string {
+	if cfg.TrustBelow > 0 && score < cfg.TrustBelow {
+		return fmt.Sprintf("Trust score %d is below your alert threshold of %d", score, cfg.TrustBelow)
+	}
+	if cfg.TrustDropPerDay > 0 && peak-score >= cfg.TrustDropPerDay {
+		return fmt.Sprintf("Trust score dropped %d points in 24h (%d → %d)", peak-score, peak, score)
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/llm"
+)
+
+// degraded fails with an open breaker for its first n calls, then answers.
+type degraded struct {
+	n       int
+	retryIn time.Duration
+	calls   int
+}
+
+func (d *degraded) Answer(context.Context, string) (string, error) {
+	d.calls++
+	if d.calls <= d.n {
+		return "", &llm.CircuitOpenError{Reason: "down", RetryIn: d.retryIn}
+	}
+	return "42", nil
+}
+func (d *degraded) Name() string { return "degraded" }
+
+func TestAskBreakerOpen(t *testing.T) {
+	// The next probe comes after the challenge expires: give up at once
+	// instead of waiting on it.
+	p := &degraded{n: 100, retryIn: time.Minute}
+	m := &Miner{LLM: p, State: &State{}, answerBy: time.Now().Add(30 * time.Second)}
+	start := time.Now()
+	_, err := m.ask(context.Background(), "q")
+	var open *llm.CircuitOpenError
+	if !errors.As(err, &open) || p.calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("ask = %v after %d calls in %s; want the open breaker at once", err, p.calls, time.Since(start))
+	}
+
+	// A probe due before the challenge expires is waited for.
+	p = &degraded{n: 1}
+	m = &Miner{LLM: p, State: &State{}, answerBy: time.Now().Add(time.Minute)}
+	if answer, err := m.ask(context.Background(), "q"); answer != "42" || err != nil {
+		t.Errorf("ask = %q, %v; want the answer after the probe", answer, err)
+	}
+}
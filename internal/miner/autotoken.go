@@ -0,0 +1,150 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+// probeLimit caps how many candidates least-crowded asks the platform
+// about for one move.
+const probeLimit = 8
+
+// TokenPicker chooses the token an --auto-token miner moves to when its
+// token is taken or crowded.
+type TokenPicker struct {
+	Strategy   string // config.TokenLeastCrowded (default), TokenSequential or TokenRandom
+	Candidates []int  // tokens to choose from; empty means 25–1024
+	CrowdedAt  int    // move once this many other agents mine the token (0: only when taken)
+
+	// Crowd counts the agents mining a token, for least-crowded.
+	Crowd func(ctx context.Context, tokenID int) (int, error)
+
+	taken map[int]bool
+}
+
+// NewTokenPicker returns a picker for cfg that counts agents with the
+// platform's nearby list.
+func NewTokenPicker(cfg config.AutoTokenConfig, client *api.Client) *TokenPicker {
+	return &TokenPicker{
+		Strategy:   cfg.Strategy,
+		Candidates: cfg.Candidates,
+		CrowdedAt:  cfg.CrowdedAt,
+		Crowd: func(ctx context.Context, tokenID int) (int, error) {
+			miners, err := client.Nearby(ctx, tokenID)
+			return len(miners), err
+		},
+	}
+}
+
+// Taken excludes a token from every later pick.
+func (p *TokenPicker) Taken(id int) {
+	if p.taken == nil {
+		p.taken = make(map[int]bool)
+	}
+	p.taken[id] = true
+}
+
+// Crowded reports whether n other agents on a token are enough to move.
+func (p *TokenPicker) Crowded(n int) bool {
+	return p.CrowdedAt > 0 && n >= p.CrowdedAt
+}
+
+// pool returns the candidates other than current and the taken tokens,
+// starting with the one after current.
+func (p *TokenPicker) pool(current int) []int {
+	ids := p.Candidates
+	if len(ids) == 0 {
+		ids = make([]int, 0, 1000)
+		for id := 25; id <= 1024; id++ {
+			ids = append(ids, id)
+		}
+	}
+	start := slices.Index(ids, current) + 1
+	out := make([]int, 0, len(ids))
+	for i := range ids {
+		id := ids[(start+i)%len(ids)]
+		if id != current && !p.taken[id] && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// Pick returns the token to move to from current and how many agents mine
+// it (-1 if not asked). ok is false when no candidate is left.
+func (p *TokenPicker) Pick(ctx context.Context, current int) (id, crowd int, ok bool) {
+	pool := p.pool(current)
+	if len(pool) == 0 {
+		return 0, -1, false
+	}
+	switch p.Strategy {
+	case config.TokenSequential:
+		return pool[0], -1, true
+	case config.TokenRandom:
+		return pool[rand.IntN(len(pool))], -1, true
+	}
+
+	// least-crowded: ask about the next few candidates in order, or a
+	// random sample of the whole range.
+	probe := pool
+	if len(p.Candidates) == 0 {
+		rand.Shuffle(len(probe), func(i, j int) { probe[i], probe[j] = probe[j], probe[i] })
+	}
+	probe = probe[:min(len(probe), probeLimit)]
+	id, crowd = probe[0], -1
+	for _, tid := range probe {
+		n, err := p.Crowd(ctx, tid)
+		if err != nil {
+			slog.Debug("token crowd unknown", "token_id", tid, "error", err)
+			continue
+		}
+		if crowd < 0 || n < crowd {
+			id, crowd = tid, n
+		}
+	}
+	return id, crowd, true
+}
+
+// moveToken switches to the token AutoToken picks, because the current
+// one is "taken" (crowd -1) or "crowded" by crowd other agents.
+// A crowded token is only left for a less crowded one.
+func (m *Miner) moveToken(ctx context.Context, reason string, crowd int) bool {
+	id, n, ok := m.AutoToken.Pick(ctx, m.TokenID)
+	if !ok {
+		return false
+	}
+	if crowd >= 0 && n >= crowd {
+		slog.Info("no less crowded token", "token_id", m.TokenID, "agents", crowd, "best", id, "best_agents", n)
+		return false
+	}
+
+	msg := fmt.Sprintf("Token #%d %s", m.TokenID, reason)
+	if crowd >= 0 {
+		msg += fmt.Sprintf(" (%d agents)", crowd)
+	}
+	msg += fmt.Sprintf(" — moving to #%d", id)
+	if n >= 0 {
+		msg += fmt.Sprintf(" (%d agents there)", n)
+	}
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+	c := event.Control{Action: event.ControlTokenSwitch, Reason: reason, From: m.TokenID, To: id}
+	if n >= 0 {
+		c.Agents = &n
+	}
+	m.emit("control", msg, c)
+
+	m.TokenID = id
+	if c, ok := m.Ctrl.(interface{ SetTokenID(int) }); ok {
+		c.SetTokenID(id)
+		m.ctrlToken = id
+	}
+	return true
+}
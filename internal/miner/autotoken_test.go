@@ -0,0 +1,51 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+func TestTokenPicker(t *testing.T) {
+	crowds := map[int]int{50: 7, 60: 2, 70: 4}
+	p := &TokenPicker{
+		Strategy:   config.TokenSequential,
+		Candidates: []int{50, 60, 70, 80},
+		Crowd: func(_ context.Context, id int) (int, error) {
+			if n, ok := crowds[id]; ok {
+				return n, nil
+			}
+			return 0, errors.New("unavailable")
+		},
+	}
+	ctx := context.Background()
+
+	if id, _, _ := p.Pick(ctx, 70); id != 80 {
+		t.Errorf("sequential after 70 = %d, want 80", id)
+	}
+	p.Taken(80)
+	if id, _, _ := p.Pick(ctx, 70); id != 50 {
+		t.Errorf("sequential after 70 with 80 taken = %d, want 50", id)
+	}
+
+	// Tokens whose crowd is unknown are passed over.
+	p.Strategy = ""
+	if id, n, _ := p.Pick(ctx, 50); id != 60 || n != 2 {
+		t.Errorf("least-crowded = #%d (%d agents), want #60 (2)", id, n)
+	}
+
+	p.Candidates = []int{50}
+	if _, _, ok := p.Pick(ctx, 50); ok {
+		t.Error("picked a token with no candidate left")
+	}
+
+	if p.Crowded(20) {
+		t.Error("crowded with crowded_at 0")
+	}
+	p.CrowdedAt = 5
+	if !p.Crowded(5) || p.Crowded(4) {
+		t.Error("crowded_at 5 misjudged")
+	}
+}
@@ -0,0 +1,115 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/llm"
+)
+
+// recentFailureWindow is how recent a challenge failure must be for
+// CandidatesWhen "after_failure" to treat the next challenges as high-stakes.
+const recentFailureWindow = 24 * time.Hour
+
+// defaultCandidates is K for best_of_k when miner.candidates is unset.
+const defaultCandidates = 3
+
+var reChoice = regexp.MustCompile(`\d+`)
+
+// highStakes reports whether the current challenge is worth answering
+// with several candidates whatever the configured strategy.
+func (m *Miner) highStakes() bool {
+	if m.Candidates < 2 {
+		return false
+	}
+	if m.CandidatesWhen == "always" {
+		return true
+	}
+	if m.cycleFailures > 0 {
+		return true
+	}
+	failures := m.State.RecentFailures()
+	return len(failures) > 0 && time.Since(failures[len(failures)-1].At) < recentFailureWindow
+}
+
+// BestOfK draws K answers — the first at the provider's usual temperature,
+// the rest at Temperature — then asks the LLM which answers the challenge
+// best. Any failure along the way falls back to the first answer: the
+// extra calls are an improvement, never a requirement.
+type BestOfK struct {
+	K           int
+	Temperature float64
+}
+
+func newBestOfK(cfg config.MinerConfig) BestOfK {
+	k := cfg.Candidates
+	if k < 2 {
+		k = defaultCandidates
+	}
+	return BestOfK{K: k, Temperature: cfg.CandidateTemperature}
+}
+
+func (BestOfK) Name() string { return StrategyBestOfK }
+
+func (s BestOfK) Answer(ctx context.Context, q Question, env Env) (string, error) {
+	first, err := env.Ask(ctx, q.Prompt)
+	if err != nil {
+		return "", err
+	}
+	f := ParseFormat(q.Challenge)
+	candidates := []string{f.Normalize(first)}
+	hot := llm.WithTemperature(ctx, s.Temperature)
+	for len(candidates) < s.K {
+		answer, err := env.Ask(hot, q.Prompt)
+		if err != nil {
+			slog.Warn("candidate answer failed", "error", err)
+			break
+		}
+		if answer = f.Normalize(answer); answer != "" {
+			candidates = append(candidates, answer)
+		}
+	}
+	if len(candidates) < 2 {
+		return first, nil
+	}
+
+	// Answers that break the stated format can't win; if only one is
+	// left there's nothing to judge.
+	var valid []string
+	for _, c := range candidates {
+		if len(f.Check(c)) == 0 {
+			valid = append(valid, c)
+		}
+	}
+	switch len(valid) {
+	case 0:
+		valid = candidates
+	case 1:
+		env.Note(fmt.Sprintf("Picked the only one of %d candidates in the required format", len(candidates)), nil)
+		return valid[0], nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "A challenge and %d candidate answers follow. Pick the answer that best meets the challenge — correct, on topic, and following any stated format. Reply with the number of the best answer only.\n\nChallenge:\n%s\n", len(valid), q.Challenge)
+	for i, c := range valid {
+		fmt.Fprintf(&b, "\nAnswer %d:\n%s\n", i+1, c)
+	}
+	verdict, err := env.Ask(ctx, b.String())
+	if err != nil {
+		slog.Warn("candidate selection failed, using the first answer", "error", err)
+		return valid[0], nil
+	}
+	n, _ := strconv.Atoi(reChoice.FindString(verdict))
+	if n < 1 || n > len(valid) {
+		slog.Warn("candidate selection unclear, using the first answer", "verdict", verdict)
+		return valid[0], nil
+	}
+	env.Note(fmt.Sprintf("Picked candidate %d of %d", n, len(valid)), map[string]any{"candidates": len(valid), "choice": n})
+	return valid[n-1], nil
+}
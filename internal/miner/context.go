@@ -0,0 +1,31 @@
+package miner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextHeader is a compact block of the agent's own facts — name,
+// token, trust, progress and platform status — put in front of each
+// challenge, so prompts that refer to "your token" or "your trust score"
+// aren't answered blind. It rides with the challenge rather than the
+// system prompt because the values change from one cycle to the next.
+// Returns "" when ContextHeader is off.
+func (m *Miner) contextHeader() string {
+	if !m.ContextHeader {
+		return ""
+	}
+	var parts []string
+	if m.AgentName != "" {
+		parts = append(parts, "agent "+m.AgentName)
+	}
+	parts = append(parts, fmt.Sprintf("token #%d", m.TokenID))
+	if m.State.LastTrustScore > 0 {
+		parts = append(parts, fmt.Sprintf("trust score %d", m.State.LastTrustScore))
+	}
+	parts = append(parts, fmt.Sprintf("%d inscriptions, %d CW earned", m.State.TotalInscriptions, m.State.TotalCWEarned))
+	if m.nftsRemaining > 0 {
+		parts = append(parts, fmt.Sprintf("%d NFTs remaining on the platform", m.nftsRemaining))
+	}
+	return "[Your context: " + strings.Join(parts, "; ") + "]"
+}
@@ -0,0 +1,289 @@
+package miner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+// Several profiles (separate CLAWWORK_HOME directories) mining on one host
+// share its IP, and the platform divides CW between agents on an IP. The
+// coordinator lets them see each other through a shared directory: each
+// running miner keeps a small record there, spaces its inscriptions out
+// from the others', and shares the IP penalty it was last paid under.
+
+const (
+	coordLockStale    = 30 * time.Second // a claim lock older than this was abandoned
+	peerPenaltyMaxAge = 24 * time.Hour
+)
+
+// CoordDir returns the directory shared by every instance on this host:
+// CLAWWORK_COORD_DIR if set, otherwise a per-user cache directory, which
+// does not depend on CLAWWORK_HOME.
+func CoordDir() string {
+	if d := os.Getenv("CLAWWORK_COORD_DIR"); d != "" {
+		return d
+	}
+	if d, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(d, "clawwork", "instances")
+	}
+	return filepath.Join(os.TempDir(), "clawwork-instances")
+}
+
+// Peer is one instance's record in the coordination directory.
+type Peer struct {
+	PID          int       `json:"pid"`
+	Home         string    `json:"home"` // data directory, identifies the profile
+	StartedAt    time.Time `json:"started_at"`
+	LastInscribe time.Time `json:"last_inscribe,omitempty"` // last slot claimed
+	Penalty      *PeerIP   `json:"ip_penalty,omitempty"`    // from the last inscription, nil if none
+}
+
+// PeerIP is the IP penalty an instance was last paid under.
+type PeerIP struct {
+	Multiplier int       `json:"multiplier"`
+	AgentsOnIP int       `json:"agents_on_ip"`
+	At         time.Time `json:"at"`
+}
+
+// Name is a short label for the profile: the data directory, with the home
+// directory abbreviated.
+func (p Peer) Name() string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(p.Home, home) {
+		return "~" + strings.TrimPrefix(p.Home, home)
+	}
+	return p.Home
+}
+
+// Coordinator is this instance's membership in the coordination directory.
+type Coordinator struct {
+	dir  string
+	path string
+
+	mu   sync.Mutex
+	self Peer
+	seen map[string]PeerIP // peer penalties already reported, by data directory
+}
+
+// JoinCoord registers this instance in dir. Leave removes the record.
+// Callers must hold the mine lock, since the record is keyed by data
+// directory.
+func JoinCoord(dir string) (*Coordinator, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("coordination directory: %w", err)
+	}
+	home, _ := filepath.Abs(config.Dir())
+	sum := sha256.Sum256([]byte(home))
+	c := &Coordinator{
+		dir:  dir,
+		path: filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"),
+		self: Peer{PID: os.Getpid(), Home: home, StartedAt: time.Now().UTC()},
+		seen: make(map[string]PeerIP),
+	}
+	if err := c.write(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Leave removes this instance's record.
+func (c *Coordinator) Leave() {
+	_ = os.Remove(c.path)
+}
+
+func (c *Coordinator) write() error {
+	data, _ := json.MarshalIndent(c.self, "", "  ")
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// Peers returns the other live instances, ordered by data directory.
+// Records left behind by crashed processes are removed.
+func (c *Coordinator) Peers() []Peer {
+	return readPeers(c.dir, c.path)
+}
+
+// HostPeers lists the live instances registered in dir, for status output.
+func HostPeers(dir string) []Peer {
+	return readPeers(dir, "")
+}
+
+func readPeers(dir, self string) []Peer {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var peers []Peer
+	for _, path := range matches {
+		if path == self {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var p Peer
+		if json.Unmarshal(data, &p) != nil || p.PID <= 0 {
+			continue
+		}
+		if !processAlive(p.PID) {
+			_ = os.Remove(path)
+			continue
+		}
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Home < peers[j].Home })
+	return peers
+}
+
+// Claim asks for an inscription slot. Instances space their inscriptions
+// cooldown/n apart (n counting this one); Claim returns how long to wait
+// for the next free slot, or zero once it has taken the slot.
+func (c *Coordinator) Claim(now time.Time, cooldown time.Duration) time.Duration {
+	unlock, err := c.lock()
+	if err != nil {
+		slog.Warn("coordination lock", "error", err)
+		return 0 // never let coordination stop mining
+	}
+	defer unlock()
+
+	peers := c.Peers()
+	if len(peers) == 0 {
+		c.setLastInscribe(now)
+		return 0
+	}
+	spacing := cooldown / time.Duration(len(peers)+1)
+	var wait time.Duration
+	for _, p := range peers {
+		if d := p.LastInscribe.Add(spacing).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return min(wait, spacing)
+	}
+	c.setLastInscribe(now)
+	return 0
+}
+
+func (c *Coordinator) setLastInscribe(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.self.LastInscribe = now.UTC()
+	if err := c.write(); err != nil {
+		slog.Warn("coordination record", "error", err)
+	}
+}
+
+// lock serializes claims across processes with an exclusive lock file.
+func (c *Coordinator) lock() (func(), error) {
+	path := filepath.Join(c.dir, "claim.lock")
+	for i := 0; ; i++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > coordLockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if i >= 50 {
+			return nil, fmt.Errorf("%s is held by another instance", path)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// RecordIP shares the IP penalty of an inscription response with the
+// other instances (nil clears it).
+func (c *Coordinator) RecordIP(ip *api.IPPenalty, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ip == nil || ip.IPMultiplier <= 1 {
+		if c.self.Penalty == nil {
+			return
+		}
+		c.self.Penalty = nil
+	} else {
+		c.self.Penalty = &PeerIP{Multiplier: ip.IPMultiplier, AgentsOnIP: ip.AgentsOnIP, At: now.UTC()}
+	}
+	if err := c.write(); err != nil {
+		slog.Warn("coordination record", "error", err)
+	}
+}
+
+// NewPeerPenalties returns the peers whose IP penalty changed since the
+// last call, so each is surfaced once rather than every cycle.
+func (c *Coordinator) NewPeerPenalties(now time.Time) []Peer {
+	var out []Peer
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range c.Peers() {
+		if p.Penalty == nil || now.Sub(p.Penalty.At) > peerPenaltyMaxAge {
+			continue
+		}
+		prev := c.seen[p.Home]
+		if prev.Multiplier != p.Penalty.Multiplier || prev.AgentsOnIP != p.Penalty.AgentsOnIP {
+			out = append(out, p)
+		}
+		c.seen[p.Home] = *p.Penalty
+	}
+	return out
+}
+
+// peerSummary describes the instances sharing this host, for the log.
+func peerSummary(peers []Peer) string {
+	names := make([]string, len(peers))
+	for i, p := range peers {
+		names[i] = p.Name()
+	}
+	return fmt.Sprintf("%d other clawwork instance(s) on this host: %s", len(peers), strings.Join(names, ", "))
+}
+
+// waitSlot waits until the coordinator grants an inscription slot.
+// Returns false if ctx ends first.
+func (m *Miner) waitSlot(ctx context.Context) bool {
+	for {
+		wait := m.coord.Claim(time.Now(), m.cooldown())
+		if wait <= 0 {
+			return true
+		}
+		secs := int(wait.Seconds())
+		msg := fmt.Sprintf("Staggering with other instances on this host — next slot in %dm%02ds", secs/60, secs%60)
+		fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+		m.emit("cooldown", msg, event.NewCooldown(event.CooldownStagger, secs))
+		if !sleep(ctx, wait) {
+			return false
+		}
+	}
+}
+
+// reportPeerPenalties surfaces IP penalties other instances on this host
+// were paid under, so one instance's discovery warns all of them.
+func (m *Miner) reportPeerPenalties() {
+	for _, p := range m.coord.NewPeerPenalties(time.Now()) {
+		msg := fmt.Sprintf("Shared IP: %s is mining under an IP penalty (x%d, %d agents on this IP)",
+			p.Name(), p.Penalty.Multiplier, p.Penalty.AgentsOnIP)
+		fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+		m.emit("penalty", msg, event.Penalty{Kind: "ip_peer", Peer: p.Name(),
+			IPMultiplier: p.Penalty.Multiplier, AgentsOnIP: p.Penalty.AgentsOnIP})
+	}
+}
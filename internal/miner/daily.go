@@ -0,0 +1,73 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+const (
+	// dailyResetMargin is added past the computed reset so the first
+	// request after it doesn't race the server's own rollover.
+	dailyResetMargin = 2 * time.Minute
+	// dailyTick is how often the countdown is reported while waiting.
+	dailyTick = time.Hour
+)
+
+// dailyReset works out when a DAILY_LIMIT_REACHED lifts. In order of
+// preference: the reset_at the server sent (shifted by the skew between
+// its Date header and our clock) if that is still ahead, retry_after, and
+// otherwise the next midnight UTC, when the platform rolls its daily
+// counters over.
+func dailyReset(e *api.APIError, now time.Time) time.Time {
+	if !e.ResetAt.IsZero() {
+		at := e.ResetAt
+		if !e.ServerDate.IsZero() {
+			at = at.Add(now.Sub(e.ServerDate))
+		}
+		if at.After(now) {
+			return at.Add(dailyResetMargin)
+		}
+	}
+	if e.RetryAfter > 0 {
+		return now.Add(time.Duration(e.RetryAfter) * time.Second)
+	}
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Add(dailyResetMargin)
+}
+
+// LimitUntil returns when a recorded platform limit lifts, or zero.
+func (s *State) LimitUntil(name string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Limits[name].Until
+}
+
+// waitDailyReset sleeps until the daily limit lifts, reporting it as a
+// "limit" event and then the countdown every hour. The reset time is in
+// state (see RecordLimit), so a restart picks the wait up where it left
+// off instead of hitting the limit again. Returns false if ctx ends first.
+func (m *Miner) waitDailyReset(ctx context.Context, until time.Time) bool {
+	eventType := "limit"
+	for {
+		remaining := time.Until(until)
+		if remaining <= 0 {
+			break
+		}
+		msg := fmt.Sprintf("Daily limit reached — resumes at %s (in %s)",
+			until.Local().Format("Jan 2 15:04"), remaining.Truncate(time.Minute))
+		fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+		m.emit(eventType, msg, event.Cooldown{Reason: event.CooldownDailyLimit, Seconds: int(remaining.Seconds()),
+			Until: until.UTC(), Quota: QuotaDaily})
+		eventType = "cooldown"
+		if !sleep(ctx, minDuration(remaining, dailyTick)) {
+			return false
+		}
+	}
+	msg := "Daily limit reset — mining resumed"
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+	m.emit("limit_reset", msg, map[string]any{"quota": QuotaDaily})
+	return true
+}
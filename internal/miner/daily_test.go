@@ -0,0 +1,40 @@
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+func TestDailyReset(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	midnight := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC).Add(dailyResetMargin)
+	tests := []struct {
+		name string
+		err  api.APIError
+		want time.Time
+	}{
+		{"reset_at ahead", api.APIError{ResetAt: now.Add(3 * time.Hour)},
+			now.Add(3*time.Hour + dailyResetMargin)},
+		{"reset_at ahead wins over retry_after", api.APIError{ResetAt: now.Add(3 * time.Hour), RetryAfter: 60},
+			now.Add(3*time.Hour + dailyResetMargin)},
+		// The server's clock runs 10 minutes ahead of ours.
+		{"server clock skew", api.APIError{ResetAt: now.Add(time.Hour), ServerDate: now.Add(10 * time.Minute)},
+			now.Add(50*time.Minute + dailyResetMargin)},
+		{"skew puts reset_at behind", api.APIError{ResetAt: now.Add(time.Hour), ServerDate: now.Add(2 * time.Hour), RetryAfter: 600},
+			now.Add(10 * time.Minute)},
+		{"stale reset_at falls back to retry_after", api.APIError{ResetAt: now.Add(-time.Hour), RetryAfter: 600},
+			now.Add(10 * time.Minute)},
+		{"stale reset_at alone", api.APIError{ResetAt: now.Add(-time.Hour)}, midnight},
+		{"retry_after only", api.APIError{RetryAfter: 7200}, now.Add(2 * time.Hour)},
+		{"neither", api.APIError{}, midnight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dailyReset(&tt.err, now); !got.Equal(tt.want) {
+				t.Errorf("dailyReset = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
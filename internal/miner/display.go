@@ -2,6 +2,7 @@ package miner
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
@@ -10,21 +11,38 @@ import (
 	"github.com/clawplaza/clawwork-cli/internal/api"
 )
 
-// SetupLogger configures the global slog logger.
-func SetupLogger(level string) {
-	var logLevel slog.Level
+// logLevel is shared by all handlers so the level can change at runtime.
+var logLevel = new(slog.LevelVar)
+
+// SetupLogger configures the global slog logger. format is "json" for one
+// JSON object per line; anything else is key=value text.
+func SetupLogger(level, format string) {
+	SetupLoggerTo(level, format, os.Stderr)
+}
+
+// SetupLoggerTo configures the global slog logger to write to w.
+func SetupLoggerTo(level, format string, w io.Writer) {
+	SetLogLevel(level)
+	opts := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler = slog.NewTextHandler(w, opts)
+	if format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// SetLogLevel changes the level of the global logger.
+func SetLogLevel(level string) {
 	switch strings.ToLower(level) {
 	case "debug":
-		logLevel = slog.LevelDebug
+		logLevel.Set(slog.LevelDebug)
 	case "warn":
-		logLevel = slog.LevelWarn
+		logLevel.Set(slog.LevelWarn)
 	case "error":
-		logLevel = slog.LevelError
+		logLevel.Set(slog.LevelError)
 	default:
-		logLevel = slog.LevelInfo
+		logLevel.Set(slog.LevelInfo)
 	}
-	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
-	slog.SetDefault(slog.New(handler))
 }
 
 // DisplaySession prints session info after successful session start.
@@ -114,16 +132,66 @@ func DisplayChallengePenalty(hint string) {
 	}
 }
 
-// DisplayStats prints cumulative session statistics.
+// DisplayStats prints what this run achieved, with today's and lifetime
+// totals for comparison.
 func DisplayStats(state *State) {
-	fmt.Printf("\n--- Session Stats ---\n")
-	fmt.Printf("Inscriptions: %d\n", state.TotalInscriptions)
-	fmt.Printf("CW earned:    %s\n", formatCW64(state.TotalCWEarned))
-	fmt.Printf("NFT hits:     %d\n", state.TotalHits)
-	fmt.Printf("Challenges:   %d passed / %d failed\n", state.ChallengesPassed, state.ChallengesFailed)
+	st := state.Stats(time.Now())
+	fmt.Printf("\n--- This Run ---\n")
+	if !st.RunStart.IsZero() {
+		fmt.Printf("Duration:     %s\n", time.Since(st.RunStart).Truncate(time.Second))
+	}
+	fmt.Printf("Inscriptions: %d\n", st.Run.Inscriptions)
+	fmt.Printf("CW earned:    %s\n", formatCW64(st.Run.CWEarned))
+	fmt.Printf("NFT hits:     %d\n", st.Run.Hits)
+	fmt.Printf("Challenges:   %d passed / %d failed\n", st.Run.ChallengesPassed, st.Run.ChallengesFailed)
+	fmt.Printf("Today:        %s\n", FormatCounters(st.Today))
+	fmt.Printf("Lifetime:     %s\n", FormatCounters(st.Lifetime))
 	fmt.Println()
 }
 
+// FormatCounters summarizes counters on one line.
+func FormatCounters(c Counters) string {
+	s := fmt.Sprintf("%d inscriptions, %s CW", c.Inscriptions, formatCW64(c.CWEarned))
+	if c.Hits > 0 {
+		s += fmt.Sprintf(", %d hits", c.Hits)
+	}
+	if c.ChallengesFailed > 0 {
+		s += fmt.Sprintf(", %d/%d challenges passed", c.ChallengesPassed, c.ChallengesPassed+c.ChallengesFailed)
+	}
+	return s
+}
+
+// DisplayAlert prints a prominent alert banner.
+func DisplayAlert(msg string) {
+	ts := time.Now().Format("15:04:05")
+	fmt.Printf("\n[%s] !!! ALERT: %s !!!\n\n", ts, msg)
+}
+
+// DisplayLatency prints p50/p95 timing for each recorded phase.
+func DisplayLatency(state *State) {
+	summary := state.LatencySummary()
+	if len(summary) == 0 {
+		fmt.Println("Latency:      no samples yet")
+		return
+	}
+	fmt.Println("Latency         p50      p95      max   samples")
+	for _, phase := range []string{PhaseLLM, PhaseSubmit, PhaseCycle} {
+		st, ok := summary[phase]
+		if !ok {
+			continue
+		}
+		fmt.Printf("  %-10s %7s  %7s  %7s  %7d\n", phase,
+			formatLatency(st.P50), formatLatency(st.P95), formatLatency(st.Max), st.Count)
+	}
+}
+
+func formatLatency(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
 func shortenHash(hash string) string {
 	if len(hash) < 12 {
 		return hash
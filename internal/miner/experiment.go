@@ -0,0 +1,259 @@
+package miner
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/event"
+	"github.com/clawplaza/clawwork-cli/internal/llm"
+)
+
+// minArmCycles is how many cycles each arm needs before a comparison says
+// more than "keep running".
+const minArmCycles = 20
+
+// Experiment alternates inscription cycles between two configurations and
+// records how each does (see `clawwork experiment`).
+type Experiment struct {
+	Name string
+	Arms [2]Arm
+}
+
+// Arm is one configuration under test. A nil LLM or Strategy uses the
+// miner's own.
+type Arm struct {
+	Name           string // "a" or "b"
+	Label          string // what the arm runs, for reports
+	LLM            llm.Provider
+	Strategy       Strategy
+	PromptTemplate string // "{challenge}" is replaced by the challenge
+}
+
+// wrap applies the arm's prompt template to a challenge.
+func (a *Arm) wrap(challenge string) string {
+	if a == nil || a.PromptTemplate == "" {
+		return challenge
+	}
+	return strings.ReplaceAll(a.PromptTemplate, "{challenge}", challenge)
+}
+
+// ExperimentStats is what an experiment has recorded so far.
+type ExperimentStats struct {
+	Started time.Time            `json:"started"`
+	Arms    map[string]*ArmStats `json:"arms"`
+}
+
+// ArmStats accumulates one arm's outcomes. An answer is one submitted
+// challenge answer; a cycle is one inscription attempt, which may take
+// several answers and earns CW only if one passes.
+type ArmStats struct {
+	Label     string    `json:"label"`
+	Answers   int       `json:"answers"`
+	Passed    int       `json:"passed"`
+	Cycles    int       `json:"cycles"`
+	CW        int64     `json:"cw"`
+	CWSquares float64   `json:"cw_squares"` // sum of squared CW per cycle, for its variance
+	LastAt    time.Time `json:"last_at,omitempty"`
+}
+
+// PassRate is the share of answers that passed.
+func (a ArmStats) PassRate() float64 {
+	if a.Answers == 0 {
+		return 0
+	}
+	return float64(a.Passed) / float64(a.Answers)
+}
+
+// CWPerCycle is the mean CW earned per cycle.
+func (a ArmStats) CWPerCycle() float64 {
+	if a.Cycles == 0 {
+		return 0
+	}
+	return float64(a.CW) / float64(a.Cycles)
+}
+
+// cwVariance is the sample variance of CW per cycle.
+func (a ArmStats) cwVariance() float64 {
+	if a.Cycles < 2 {
+		return 0
+	}
+	n := float64(a.Cycles)
+	mean := float64(a.CW) / n
+	return math.Max(0, (a.CWSquares-n*mean*mean)/(n-1))
+}
+
+// arm returns the stats for arm name of experiment exp, creating them.
+// Callers hold s.mu.
+func (s *State) arm(exp, name string) *ArmStats {
+	if s.Experiments == nil {
+		s.Experiments = make(map[string]*ExperimentStats)
+	}
+	e := s.Experiments[exp]
+	if e == nil {
+		e = &ExperimentStats{Started: time.Now(), Arms: make(map[string]*ArmStats)}
+		s.Experiments[exp] = e
+	}
+	a := e.Arms[name]
+	if a == nil {
+		a = &ArmStats{}
+		e.Arms[name] = a
+	}
+	return a
+}
+
+// RecordArmAnswer counts a judged answer for an experiment arm.
+func (s *State) RecordArmAnswer(exp, arm string, passed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.arm(exp, arm)
+	a.Answers++
+	if passed {
+		a.Passed++
+	}
+	a.LastAt = time.Now()
+}
+
+// RecordArmCycle counts a finished cycle and the CW it earned.
+func (s *State) RecordArmCycle(exp, arm string, cw int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.arm(exp, arm)
+	a.Cycles++
+	a.CW += int64(cw)
+	a.CWSquares += float64(cw) * float64(cw)
+	a.LastAt = time.Now()
+}
+
+// ExperimentResult returns a copy of an experiment's stats, or nil.
+func (s *State) ExperimentResult(name string) *ExperimentStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.Experiments[name]
+	if e == nil {
+		return nil
+	}
+	out := &ExperimentStats{Started: e.Started, Arms: make(map[string]*ArmStats, len(e.Arms))}
+	for k, a := range e.Arms {
+		c := *a
+		out.Arms[k] = &c
+	}
+	return out
+}
+
+// ResetExperiment discards an experiment's results.
+func (s *State) ResetExperiment(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Experiments[name]; !ok {
+		return false
+	}
+	delete(s.Experiments, name)
+	return true
+}
+
+// Comparison is arm B measured against arm A.
+type Comparison struct {
+	PassRateDiff float64 // B - A, as a fraction
+	PassRateP    float64 // two-sided p-value of the difference
+	CWDiff       float64 // B - A, CW per cycle
+	CWP          float64
+	Enough       bool // both arms have minArmCycles cycles
+}
+
+// Compare tests B against A: a two-proportion z-test for the pass rate
+// and Welch's t-test (normal approximation) for CW per cycle. Both are
+// rough: cycles are not independent (trust and penalties carry over), so
+// treat the p-values as hints.
+func Compare(a, b ArmStats) Comparison {
+	c := Comparison{
+		PassRateDiff: b.PassRate() - a.PassRate(),
+		CWDiff:       b.CWPerCycle() - a.CWPerCycle(),
+		PassRateP:    1,
+		CWP:          1,
+		Enough:       a.Cycles >= minArmCycles && b.Cycles >= minArmCycles,
+	}
+	if a.Answers > 0 && b.Answers > 0 {
+		pooled := float64(a.Passed+b.Passed) / float64(a.Answers+b.Answers)
+		se := math.Sqrt(pooled * (1 - pooled) * (1/float64(a.Answers) + 1/float64(b.Answers)))
+		if se > 0 {
+			c.PassRateP = pValue(c.PassRateDiff / se)
+		}
+	}
+	if a.Cycles > 1 && b.Cycles > 1 {
+		se := math.Sqrt(a.cwVariance()/float64(a.Cycles) + b.cwVariance()/float64(b.Cycles))
+		if se > 0 {
+			c.CWP = pValue(c.CWDiff / se)
+		}
+	}
+	return c
+}
+
+// pValue is the two-sided p-value of a standard normal score.
+func pValue(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// Hint puts a p-value into words.
+func (c Comparison) Hint(p float64) string {
+	switch {
+	case !c.Enough:
+		return fmt.Sprintf("too early to tell (need %d cycles per arm)", minArmCycles)
+	case p < 0.01:
+		return "strong evidence of a real difference"
+	case p < 0.05:
+		return "likely a real difference (p < 0.05)"
+	case p < 0.2:
+		return "weak hint — keep running"
+	}
+	return "no detectable difference"
+}
+
+// pickArm chooses the arm for the next cycle: the one with fewer cycles
+// recorded, so arms stay balanced across restarts.
+func (m *Miner) pickArm() *Arm {
+	e := m.Experiment
+	arm := &e.Arms[0]
+	m.State.mu.Lock()
+	if m.State.arm(e.Name, e.Arms[0].Name).Cycles > m.State.arm(e.Name, e.Arms[1].Name).Cycles {
+		arm = &e.Arms[1]
+	}
+	m.State.mu.Unlock()
+	slog.Info("experiment arm", "experiment", e.Name, "arm", strings.ToUpper(arm.Name), "config", arm.Label)
+	return arm
+}
+
+// startExperiment announces the experiment and labels its arms, warning
+// when an arm no longer matches what its earlier results were recorded
+// with.
+func (m *Miner) startExperiment() {
+	e := m.Experiment
+	m.State.mu.Lock()
+	for _, arm := range e.Arms {
+		st := m.State.arm(e.Name, arm.Name)
+		if st.Label != "" && st.Label != arm.Label {
+			slog.Warn("experiment arm changed since its results were recorded — consider a new experiment name",
+				"experiment", e.Name, "arm", arm.Name, "was", st.Label, "now", arm.Label)
+		}
+		st.Label = arm.Label
+	}
+	m.State.mu.Unlock()
+	msg := fmt.Sprintf("Experiment %q: A (%s) vs B (%s), alternating cycles", e.Name, e.Arms[0].Label, e.Arms[1].Label)
+	fmt.Println(msg)
+	m.emit("session", msg, event.Session{Action: event.SessionExperiment, Experiment: e.Name})
+}
+
+// recordAnswer and recordCycle count an outcome for the current arm.
+func (m *Miner) recordAnswer(passed bool) {
+	if m.arm != nil {
+		m.State.RecordArmAnswer(m.Experiment.Name, m.arm.Name, passed)
+	}
+}
+
+func (m *Miner) recordCycle(cw int) {
+	if m.arm != nil {
+		m.State.RecordArmCycle(m.Experiment.Name, m.arm.Name, cw)
+	}
+}
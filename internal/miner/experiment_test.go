@@ -0,0 +1,48 @@
+package miner
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	// Same pass rate: nothing to see.
+	a := ArmStats{Answers: 100, Passed: 80, Cycles: 50}
+	c := Compare(a, a)
+	if c.PassRateDiff != 0 || c.PassRateP != 1 {
+		t.Errorf("identical arms: diff %v p %v", c.PassRateDiff, c.PassRateP)
+	}
+
+	// 60% vs 90% over 100 answers each is far beyond chance.
+	b := ArmStats{Answers: 100, Passed: 90, Cycles: 50}
+	a.Passed = 60
+	c = Compare(a, b)
+	if math.Abs(c.PassRateDiff-0.3) > 1e-9 || c.PassRateP > 0.001 || !c.Enough {
+		t.Errorf("60%% vs 90%%: %+v", c)
+	}
+	if got := c.Hint(c.PassRateP); got != "strong evidence of a real difference" {
+		t.Errorf("hint = %q", got)
+	}
+
+	// Too few cycles: the hint says so whatever the p-value.
+	c = Compare(ArmStats{Answers: 5, Passed: 1, Cycles: 5}, ArmStats{Answers: 5, Passed: 5, Cycles: 5})
+	if c.Enough || c.Hint(c.PassRateP) == "strong evidence of a real difference" {
+		t.Errorf("small sample: %+v", c)
+	}
+}
+
+func TestCompareCW(t *testing.T) {
+	s := &State{}
+	for i := 0; i < 30; i++ {
+		s.RecordArmCycle("x", "a", 100+i%3)
+		s.RecordArmCycle("x", "b", 120+i%3)
+	}
+	r := s.ExperimentResult("x")
+	c := Compare(*r.Arms["a"], *r.Arms["b"])
+	if math.Abs(c.CWDiff-20) > 1e-9 || c.CWP > 0.01 {
+		t.Errorf("CW comparison: %+v", c)
+	}
+	if !s.ResetExperiment("x") || s.ExperimentResult("x") != nil {
+		t.Error("reset kept the results")
+	}
+}
@@ -0,0 +1,188 @@
+package miner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFormatRetries is how many times an answer that breaks the challenge's
+// stated format is sent back to the LLM with a correction.
+const maxFormatRetries = 2
+
+// Format is what a challenge prompt explicitly asks of the answer's shape.
+// Zero fields are unconstrained.
+type Format struct {
+	MinWords, MaxWords int
+	MaxChars           int // CJK prompts count characters (字), not words
+	JSON               bool
+	Bullets            int // required bullet items; -1 for "a list" of any length
+}
+
+// IsZero reports whether the prompt stated no format requirement.
+func (f Format) IsZero() bool { return f == Format{} }
+
+var (
+	reWordsBetween = regexp.MustCompile(`(?i)between\s+(\d+)\s+and\s+(\d+)\s+words|(\d+)\s*[-–~]\s*(\d+)\s+words`)
+	reWordsMax     = regexp.MustCompile(`(?i)(?:at most|no more than|not more than|under|fewer than|less than|maximum of|max(?:imum)?|up to|within)\s+(\d+)\s+words|(\d+)\s+words\s+(?:or (?:less|fewer)|max(?:imum)?)`)
+	reWordsMin     = regexp.MustCompile(`(?i)(?:at least|no fewer than|no less than|minimum of|more than)\s+(\d+)\s+words|(\d+)\s+words\s+or more`)
+	reWordsExact   = regexp.MustCompile(`(?i)(?:exactly|in)\s+(\d+)\s+words`)
+	reCharsMax     = regexp.MustCompile(`(?:不超过|不多于|最多|少于)\s*(\d+)\s*个?字|(\d+)\s*个?字(?:以内|之内|以下)`)
+	reJSON         = regexp.MustCompile(`(?i)\b(?:in|as|valid|return|respond with|output)\s+(?:a\s+)?json\b|\bjson\s+(?:format|object|array)\b|JSON\s*格式`)
+	reBulletsN     = regexp.MustCompile(`(?i)(\d+)\s+bullet(?:\s+points|s)?|list\s+(\d+)\s+|(\d+)\s*(?:条|点)要点`)
+	reBullets      = regexp.MustCompile(`(?i)bullet(?:ed)?\s+(?:points|list)|as\s+a\s+list|分点|列表形式`)
+	reBulletLine   = regexp.MustCompile(`^\s*(?:[-*•·]|\d+[.)、])\s+\S`)
+)
+
+// ParseFormat reads explicit format requirements out of a challenge prompt.
+func ParseFormat(prompt string) Format {
+	var f Format
+	if m := reWordsBetween.FindStringSubmatch(prompt); m != nil {
+		lo, hi := atoi(m[1], m[3]), atoi(m[2], m[4])
+		f.MinWords, f.MaxWords = min(lo, hi), max(lo, hi)
+	} else {
+		if m := reWordsMax.FindStringSubmatch(prompt); m != nil {
+			f.MaxWords = atoi(m[1], m[2])
+		}
+		// With the maximum taken out, "no more than N" can't read as a minimum.
+		if m := reWordsMin.FindStringSubmatch(reWordsMax.ReplaceAllString(prompt, "")); m != nil {
+			f.MinWords = atoi(m[1], m[2])
+		}
+		if f.MinWords == 0 && f.MaxWords == 0 {
+			if m := reWordsExact.FindStringSubmatch(prompt); m != nil {
+				// "In 50 words" is never meant to the word; allow some slack.
+				n := atoi(m[1])
+				f.MinWords, f.MaxWords = n*8/10, n*12/10+1
+			}
+		}
+	}
+	if m := reCharsMax.FindStringSubmatch(prompt); m != nil {
+		f.MaxChars = atoi(m[1], m[2])
+	}
+	f.JSON = reJSON.MatchString(prompt)
+	if m := reBulletsN.FindStringSubmatch(prompt); m != nil {
+		f.Bullets = atoi(m[1], m[2], m[3])
+	} else if reBullets.MatchString(prompt) {
+		f.Bullets = -1
+	}
+	return f
+}
+
+// atoi returns the first non-empty group as a number.
+func atoi(groups ...string) int {
+	for _, g := range groups {
+		if g != "" {
+			n, _ := strconv.Atoi(g)
+			return n
+		}
+	}
+	return 0
+}
+
+// Normalize makes cheap local fixes: a JSON answer wrapped in a Markdown
+// code fence is unwrapped.
+func (f Format) Normalize(answer string) string {
+	answer = strings.TrimSpace(answer)
+	if f.JSON && strings.HasPrefix(answer, "```") {
+		body := strings.TrimPrefix(answer, "```")
+		body = strings.TrimPrefix(body, "json")
+		body = strings.TrimSuffix(strings.TrimSpace(body), "```")
+		if json.Valid([]byte(strings.TrimSpace(body))) {
+			return strings.TrimSpace(body)
+		}
+	}
+	return answer
+}
+
+// Check lists how answer breaks the format, or nil if it complies.
+func (f Format) Check(answer string) []string {
+	var problems []string
+	words := len(strings.Fields(answer))
+	if f.MaxWords > 0 && words > f.MaxWords {
+		problems = append(problems, fmt.Sprintf("it has %d words but the limit is %d", words, f.MaxWords))
+	}
+	if f.MinWords > 0 && words < f.MinWords {
+		problems = append(problems, fmt.Sprintf("it has %d words but at least %d are required", words, f.MinWords))
+	}
+	if f.MaxChars > 0 {
+		if n := countChars(answer); n > f.MaxChars {
+			problems = append(problems, fmt.Sprintf("it has %d characters but the limit is %d", n, f.MaxChars))
+		}
+	}
+	if f.JSON && !json.Valid([]byte(answer)) {
+		problems = append(problems, "it is not valid JSON (reply with the JSON only, no code fence or commentary)")
+	}
+	if f.Bullets != 0 {
+		n := 0
+		for _, line := range strings.Split(answer, "\n") {
+			if reBulletLine.MatchString(line) {
+				n++
+			}
+		}
+		switch {
+		case f.Bullets > 0 && n != f.Bullets:
+			problems = append(problems, fmt.Sprintf("it has %d bullet points but %d are required", n, f.Bullets))
+		case f.Bullets < 0 && n < 2:
+			problems = append(problems, "it is not a bulleted list")
+		}
+	}
+	return problems
+}
+
+// countChars counts characters the way a 字 limit does: letters, digits
+// and CJK characters, without spaces or punctuation.
+func countChars(s string) int {
+	if !utf8.ValidString(s) {
+		return len(s)
+	}
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// correction is the follow-up prompt for an answer that broke the format.
+func correction(prompt, answer string, problems []string) string {
+	return fmt.Sprintf("%s\n\nYour previous answer was:\n%s\n\nIt does not meet the required format: %s. "+
+		"Rewrite it so it follows the challenge's format exactly. Reply with the corrected answer only.",
+		prompt, answer, strings.Join(problems, "; "))
+}
+
+// enforceFormat checks answer against the format the challenge asks for
+// and, on a mismatch, asks the LLM to fix it — format violations are the
+// cheapest failures to prevent. The last answer is returned even if it
+// still doesn't comply; the server has the final word.
+func (m *Miner) enforceFormat(ctx context.Context, challenge, prompt, answer string) string {
+	f := ParseFormat(challenge)
+	if f.IsZero() || m.replaying {
+		return answer
+	}
+	answer = f.Normalize(answer)
+	for attempt := 0; attempt < maxFormatRetries; attempt++ {
+		problems := f.Check(answer)
+		if len(problems) == 0 {
+			return answer
+		}
+		slog.Info("answer breaks the challenge format, asking for a fix", "problems", problems, "attempt", attempt+1)
+		m.emit("answer", "Answer breaks the required format — correcting ("+problems[0]+")", nil)
+		fixed, err := m.callLLM(ctx, correction(prompt, answer, problems))
+		if err != nil || strings.TrimSpace(fixed) == "" {
+			slog.Warn("format correction failed", "error", err)
+			return answer
+		}
+		answer = f.Normalize(fixed)
+	}
+	if problems := f.Check(answer); len(problems) > 0 {
+		slog.Warn("submitting an answer that still breaks the challenge format", "problems", problems)
+	}
+	return answer
+}
@@ -0,0 +1,57 @@
+package miner
+
+import "testing"
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   Format
+	}{
+		{"Describe the sea in no more than 30 words.", Format{MaxWords: 30}},
+		{"Write between 20 and 40 words about trust.", Format{MinWords: 20, MaxWords: 40}},
+		{"Explain it in at least 50 words.", Format{MinWords: 50}},
+		{"Summarize in 10 words.", Format{MinWords: 8, MaxWords: 13}},
+		{"Respond in JSON with keys a and b.", Format{JSON: true}},
+		{"Give 3 bullet points on safety.", Format{Bullets: 3}},
+		{"List the steps as a bulleted list.", Format{Bullets: -1}},
+		{"用不超过50字描述大海。", Format{MaxChars: 50}},
+		{"What is the capital of France?", Format{}},
+	}
+	for _, tt := range tests {
+		if got := ParseFormat(tt.prompt); got != tt.want {
+			t.Errorf("ParseFormat(%q) = %+v, want %+v", tt.prompt, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCheck(t *testing.T) {
+	tests := []struct {
+		f      Format
+		answer string
+		ok     bool
+	}{
+		{Format{MaxWords: 3}, "one two three", true},
+		{Format{MaxWords: 3}, "one two three four", false},
+		{Format{MinWords: 3}, "one two", false},
+		{Format{JSON: true}, `{"a": 1}`, true},
+		{Format{JSON: true}, `Sure! {"a": 1}`, false},
+		{Format{Bullets: 2}, "- a\n- b", true},
+		{Format{Bullets: 2}, "- a\n- b\n- c", false},
+		{Format{Bullets: -1}, "1. a\n2. b", true},
+		{Format{Bullets: -1}, "just prose", false},
+		{Format{MaxChars: 5}, "大海很蓝。", true},
+		{Format{MaxChars: 3}, "大海很蓝", false},
+	}
+	for _, tt := range tests {
+		if got := len(tt.f.Check(tt.answer)) == 0; got != tt.ok {
+			t.Errorf("%+v.Check(%q) ok = %v, want %v", tt.f, tt.answer, got, tt.ok)
+		}
+	}
+}
+
+func TestFormatNormalize(t *testing.T) {
+	f := Format{JSON: true}
+	if got := f.Normalize("```json\n{\"a\": 1}\n```"); got != `{"a": 1}` {
+		t.Errorf("Normalize = %q", got)
+	}
+}
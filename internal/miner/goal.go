@@ -0,0 +1,140 @@
+package miner
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Goal is a CW target set with `clawwork goal set`. It is its own
+// document because the state is rewritten by a running miner.
+type Goal struct {
+	CW    int64     `json:"cw"`
+	SetAt time.Time `json:"set_at"`
+}
+
+// LoadGoal returns the current goal, or nil if none is set.
+func LoadGoal() *Goal {
+	data, err := Store().Get(goalKey)
+	if err != nil {
+		return nil
+	}
+	var g Goal
+	if json.Unmarshal(data, &g) != nil || g.CW <= 0 {
+		return nil
+	}
+	return &g
+}
+
+// SaveGoal writes the goal to the store.
+func SaveGoal(g *Goal) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	return Store().Put(goalKey, data)
+}
+
+// ClearGoal removes the goal. Clearing when none is set is not an error.
+func ClearGoal() error {
+	return Store().Delete(goalKey)
+}
+
+// earnWindow is how far back earnings are kept to measure the earn rate.
+const earnWindow = 7 * 24 * time.Hour
+
+// minRateSpan is the least observed time before the measured rate is trusted
+// over the cooldown-based estimate.
+const minRateSpan = 6 * time.Hour
+
+// EarnSample is CW earned by one inscription.
+type EarnSample struct {
+	At time.Time `json:"at"`
+	CW int       `json:"cw"`
+}
+
+// recordEarning appends a sample and drops those older than earnWindow.
+// Callers hold s.mu.
+func (s *State) recordEarning(cw int, now time.Time) {
+	cutoff := now.Add(-earnWindow)
+	kept := s.Earnings[:0]
+	for _, e := range s.Earnings {
+		if e.At.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	s.Earnings = append(kept, EarnSample{At: now, CW: cw})
+}
+
+// Projection estimates when a goal will be reached.
+type Projection struct {
+	Goal      int64     `json:"goal"`
+	Earned    int64     `json:"earned"`
+	Remaining int64     `json:"remaining"`
+	Percent   float64   `json:"percent"`
+	PerDay    float64   `json:"per_day"`       // projected CW per day
+	PassRate  float64   `json:"pass_rate"`     // share of challenges passed
+	Basis     string    `json:"basis"`         // "observed" or "estimated"
+	ETA       time.Time `json:"eta,omitempty"` // zero when done or no rate yet
+}
+
+// Project computes goal progress from local stats. The rate comes from
+// the last week of earnings when there is enough of it; otherwise from the
+// average CW per inscription, the challenge pass rate and the cooldown.
+// Both already reflect penalties, since penalized inscriptions earn less.
+func (s *State) Project(g *Goal, now time.Time) Projection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := Projection{Goal: g.CW, Earned: s.TotalCWEarned, PassRate: 1}
+	p.Remaining = max(g.CW-s.TotalCWEarned, 0)
+	p.Percent = min(100, float64(s.TotalCWEarned)*100/float64(g.CW))
+	if attempts := s.ChallengesPassed + s.ChallengesFailed; attempts > 0 {
+		p.PassRate = float64(s.ChallengesPassed) / float64(attempts)
+	}
+
+	if n := len(s.Earnings); n > 0 && now.Sub(s.Earnings[0].At) >= minRateSpan {
+		var sum int64
+		for _, e := range s.Earnings {
+			sum += int64(e.CW)
+		}
+		p.PerDay = float64(sum) / now.Sub(s.Earnings[0].At).Hours() * 24
+		p.Basis = "observed"
+	} else if s.TotalInscriptions > 0 {
+		avg := float64(s.TotalCWEarned) / float64(s.TotalInscriptions)
+		cyclesPerDay := 24 * time.Hour.Seconds() / s.cooldownLocked().Seconds()
+		p.PerDay = avg * p.PassRate * cyclesPerDay
+		p.Basis = "estimated"
+	}
+
+	if p.Remaining > 0 && p.PerDay > 0 {
+		p.ETA = now.Add(time.Duration(float64(p.Remaining) / p.PerDay * 24 * float64(time.Hour)))
+	}
+	return p
+}
+
+// String renders the projection for terminal output.
+func (p Projection) String() string {
+	s := fmt.Sprintf("Goal:         %d / %d CW (%.1f%%)", p.Earned, p.Goal, p.Percent)
+	switch {
+	case p.Remaining == 0:
+		s += "\nStatus:       reached"
+	case p.ETA.IsZero():
+		s += "\nProjection:   not enough data yet — check back after a few inscriptions"
+	default:
+		s += fmt.Sprintf("\nRate:         ~%.0f CW/day (%s, %.0f%% challenges passed)", p.PerDay, p.Basis, p.PassRate*100)
+		s += fmt.Sprintf("\nProjected:    %s (%s)", p.ETA.Local().Format("2006-01-02"), humanDays(p.ETA.Sub(time.Now())))
+	}
+	return s
+}
+
+func humanDays(d time.Duration) string {
+	switch days := d.Hours() / 24; {
+	case days < 1:
+		return fmt.Sprintf("in %.0f hours", d.Hours())
+	case days < 2:
+		return "in 1 day"
+	default:
+		return fmt.Sprintf("in %.0f days", days)
+	}
+}
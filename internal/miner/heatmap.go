@@ -0,0 +1,102 @@
+package miner
+
+import "time"
+
+// minDeadHours is the shortest run of empty hours reported as a dead
+// period: one hour without an attempt is just a long cooldown.
+const minDeadHours = 2
+
+// HeatmapHour counts the inscription attempts of one hour.
+type HeatmapHour struct {
+	Hour     time.Time `json:"hour"` // start of the hour, UTC
+	Attempts int       `json:"attempts"`
+	OK       int       `json:"ok,omitempty"`       // paid out, hits included
+	Hits     int       `json:"hits,omitempty"`     // NFT hits
+	Failed   int       `json:"failed,omitempty"`   // challenge answers the platform marked wrong
+	Rejected int       `json:"rejected,omitempty"` // other platform errors: cooldowns, limits, taken tokens...
+	Errors   int       `json:"errors,omitempty"`   // network or LLM failures
+	CW       int       `json:"cw,omitempty"`
+}
+
+// DeadPeriod is a run of hours without a single attempt, such as a crash
+// or a daily limit.
+type DeadPeriod struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"` // exclusive
+	Hours int       `json:"hours"`
+}
+
+// Heatmap is inscription activity in hourly buckets.
+type Heatmap struct {
+	Since time.Time     `json:"since"`
+	Until time.Time     `json:"until"`
+	Hours []HeatmapHour `json:"hours"` // every hour from Since to Until, oldest first
+	Dead  []DeadPeriod  `json:"dead"`  // between the first and last attempt in range
+}
+
+// BuildHeatmap buckets the entries from since to until by hour. Entries
+// outside the range are ignored.
+func BuildHeatmap(entries []HistoryEntry, since, until time.Time) Heatmap {
+	since, until = since.UTC().Truncate(time.Hour), until.UTC().Truncate(time.Hour).Add(time.Hour)
+	h := Heatmap{Since: since, Until: until, Dead: []DeadPeriod{}}
+	n := int(until.Sub(since) / time.Hour)
+	if n <= 0 {
+		h.Hours = []HeatmapHour{}
+		return h
+	}
+	h.Hours = make([]HeatmapHour, n)
+	for i := range h.Hours {
+		h.Hours[i].Hour = since.Add(time.Duration(i) * time.Hour)
+	}
+	for _, e := range entries {
+		i := int(e.At.UTC().Sub(since) / time.Hour)
+		if e.At.Before(since) || i >= n {
+			continue
+		}
+		b := &h.Hours[i]
+		b.Attempts++
+		b.CW += e.CWEarned
+		switch e.Outcome {
+		case "hit":
+			b.Hits++
+			b.OK++
+		case "ok":
+			b.OK++
+		case "rejected":
+			if e.Code == "CHALLENGE_FAILED" {
+				b.Failed++
+			} else {
+				b.Rejected++
+			}
+		case "taken":
+			b.Rejected++
+		default:
+			b.Errors++
+		}
+	}
+
+	first, last := -1, -1
+	for i, b := range h.Hours {
+		if b.Attempts > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	for i := first; first >= 0 && i < last; {
+		if h.Hours[i].Attempts > 0 {
+			i++
+			continue
+		}
+		j := i
+		for h.Hours[j].Attempts == 0 {
+			j++
+		}
+		if j-i >= minDeadHours {
+			h.Dead = append(h.Dead, DeadPeriod{From: h.Hours[i].Hour, To: h.Hours[j].Hour, Hours: j - i})
+		}
+		i = j
+	}
+	return h
+}
@@ -0,0 +1,47 @@
+package miner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildHeatmap(t *testing.T) {
+	since := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return since.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	entries := []HistoryEntry{
+		{At: since.Add(-time.Minute), Outcome: "ok"}, // before the range
+		{At: at(0, 5), Outcome: "ok", CWEarned: 40},
+		{At: at(0, 40), Outcome: "hit", CWEarned: 40},
+		{At: at(1, 10), Outcome: "rejected", Code: "CHALLENGE_FAILED"},
+		{At: at(1, 20), Outcome: "rejected", Code: "DAILY_LIMIT_REACHED"},
+		// hours 2-5 empty: a dead period
+		{At: at(6, 0), Outcome: "error"},
+		// hour 7 empty: just a long cooldown
+		{At: at(8, 30), Outcome: "taken"},
+	}
+	h := BuildHeatmap(entries, since, at(9, 15))
+
+	if len(h.Hours) != 10 || !h.Hours[0].Hour.Equal(since) || !h.Until.Equal(at(10, 0)) {
+		t.Fatalf("range: %d hours from %s to %s", len(h.Hours), h.Hours[0].Hour, h.Until)
+	}
+	want := map[int]HeatmapHour{
+		0: {Attempts: 2, OK: 2, Hits: 1, CW: 80},
+		1: {Attempts: 2, Failed: 1, Rejected: 1},
+		6: {Attempts: 1, Errors: 1},
+		8: {Attempts: 1, Rejected: 1},
+	}
+	for i, got := range h.Hours {
+		w := want[i]
+		w.Hour = got.Hour
+		if got != w {
+			t.Errorf("hour %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if len(h.Dead) != 1 || !h.Dead[0].From.Equal(at(2, 0)) || h.Dead[0].Hours != 4 {
+		t.Errorf("dead periods = %+v, want 4 hours from 02:00", h.Dead)
+	}
+
+	if h := BuildHeatmap(nil, since, at(3, 0)); len(h.Hours) != 4 || len(h.Dead) != 0 {
+		t.Errorf("empty history: %d hours, %d dead periods", len(h.Hours), len(h.Dead))
+	}
+}
@@ -0,0 +1,115 @@
+package miner
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/store"
+)
+
+// HistoryEntry is one inscription attempt: a submit to the platform, or an
+// answer the LLM failed to produce. Unlike the state, which only keeps
+// totals and recent samples, the history is never rewritten, so it is the
+// local evidence for "I earned X but got Y" questions.
+type HistoryEntry struct {
+	At          time.Time `json:"at"`
+	TokenID     int       `json:"token_id"`
+	SessionID   string    `json:"session_id,omitempty"`
+	ChallengeID string    `json:"challenge_id,omitempty"`
+	Prompt      string    `json:"prompt,omitempty"`
+	Answer      string    `json:"answer,omitempty"`
+	AnswerMs    int64     `json:"answer_ms,omitempty"` // LLM time for Answer
+	SubmitMs    int64     `json:"submit_ms,omitempty"` // platform round trip
+	Arm         string    `json:"arm,omitempty"`       // experiment arm that answered
+
+	// Outcome is "ok", "hit", "taken", "rejected" (a platform error, see
+	// Code) or "error" (network or LLM failure, see Error).
+	Outcome    string `json:"outcome"`
+	Code       string `json:"code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CWEarned   int    `json:"cw_earned,omitempty"`
+	TrustScore int    `json:"trust_score,omitempty"`
+}
+
+// History appends entries to the "history" stream of a store (with the
+// files backend, monthly JSON Lines files such as history/2006-01.jsonl).
+type History struct {
+	store store.Backend
+}
+
+// NewHistory returns a history writer for b.
+func NewHistory(b store.Backend) *History {
+	return &History{store: b}
+}
+
+// Append records e. Write errors are logged: history must not interrupt
+// mining.
+func (h *History) Append(e HistoryEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := h.store.Append(historyStream, e.At, data); err != nil {
+		slog.Warn("history write failed", "error", err)
+	}
+}
+
+// ReadHistory returns the entries in b recorded at or after since, oldest
+// first. Records that don't parse, such as a line cut short by a crash,
+// are skipped.
+func ReadHistory(b store.Backend, since time.Time) ([]HistoryEntry, error) {
+	var out []HistoryEntry
+	err := b.Scan(historyStream, since, func(record []byte) {
+		var e HistoryEntry
+		if json.Unmarshal(record, &e) != nil || e.At.Before(since) {
+			return
+		}
+		out = append(out, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// recordHistory appends one attempt to m.History, if set. answerMs and
+// submitMs are zero when that step didn't happen.
+func (m *Miner) recordHistory(req *api.InscribeRequest, prompt string, answerMs, submitMs int64, resp *api.InscribeResponse, err error) {
+	if m.History == nil {
+		return
+	}
+	e := HistoryEntry{
+		At:          time.Now().UTC(),
+		TokenID:     req.TokenID,
+		SessionID:   req.SessionID,
+		ChallengeID: req.ChallengeID,
+		Prompt:      prompt,
+		Answer:      req.ChallengeAnswer,
+		AnswerMs:    answerMs,
+		SubmitMs:    submitMs,
+	}
+	if m.arm != nil {
+		e.Arm = m.arm.Name
+	}
+	apiErr, isAPI := api.AsAPIError(err)
+	switch {
+	case isAPI:
+		e.Outcome, e.Code, e.Error = "rejected", apiErr.Code, apiErr.Message
+	case err != nil:
+		e.Outcome, e.Error = "error", err.Error()
+	case resp.IDStatus == "taken":
+		e.Outcome = "taken"
+	case resp.Hit:
+		e.Outcome = "hit"
+	default:
+		e.Outcome = "ok"
+	}
+	if resp != nil {
+		e.CWEarned, e.TrustScore = resp.CWEarned, resp.TrustScore
+	}
+	m.History.Append(e)
+}
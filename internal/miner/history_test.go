@@ -0,0 +1,42 @@
+package miner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/store"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	b := store.NewFiles(dir)
+	h := NewHistory(b)
+	now := time.Now().UTC()
+	h.Append(HistoryEntry{At: now.AddDate(0, -2, 0), TokenID: 42, Outcome: "ok", CWEarned: 10})
+	h.Append(HistoryEntry{At: now.Add(-2 * time.Hour), TokenID: 42, Outcome: "rejected", Code: "CHALLENGE_FAILED"})
+	h.Append(HistoryEntry{At: now, TokenID: 42, Outcome: "ok", CWEarned: 95})
+
+	// A line cut short by a crash is skipped, not fatal.
+	f, err := os.OpenFile(filepath.Join(dir, "history", now.Format("2006-01")+".jsonl"), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"at":"` + now.Format(time.RFC3339))
+	f.Close()
+
+	got, err := ReadHistory(b, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Code != "CHALLENGE_FAILED" || got[1].CWEarned != 95 {
+		t.Fatalf("last 24h = %+v", got)
+	}
+	if all, _ := ReadHistory(b, time.Time{}); len(all) != 3 {
+		t.Errorf("full history has %d entries, want 3", len(all))
+	}
+	if none, err := ReadHistory(store.NewFiles(filepath.Join(dir, "missing")), time.Time{}); err != nil || len(none) != 0 {
+		t.Errorf("missing dir = %v, %v", none, err)
+	}
+}
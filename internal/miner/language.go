@@ -0,0 +1,70 @@
+package miner
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// languageNames maps supported language codes to the name used in the
+// reply instruction. The native name helps models that follow it better.
+var languageNames = map[string]string{
+	"en": "English",
+	"zh": "Chinese (中文)",
+	"ja": "Japanese (日本語)",
+	"ko": "Korean (한국어)",
+	"ru": "Russian (русский)",
+}
+
+// DetectLanguage guesses the language of a challenge prompt from its
+// script. Kana means Japanese even alongside Han characters; text with
+// no letters returns "".
+func DetectLanguage(text string) string {
+	var han, kana, hangul, cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	// CJK characters carry roughly a word each; weight them against
+	// Latin letters (~5 per word) so a quoted English keyword inside a
+	// Chinese prompt doesn't flip the result.
+	switch {
+	case kana > 0 && (kana+han)*5 >= latin:
+		return "ja"
+	case hangul > 0 && hangul*3 >= latin:
+		return "ko"
+	case han > 0 && han*5 >= latin:
+		return "zh"
+	case cyrillic > latin:
+		return "ru"
+	case latin > 0:
+		return "en"
+	}
+	return ""
+}
+
+// languageInstruction returns the reply-language line appended to a
+// challenge prompt under the given policy ("auto", "off", or a code).
+func languageInstruction(policy, prompt string) string {
+	code := policy
+	switch policy {
+	case "off":
+		return ""
+	case "", "auto":
+		code = DetectLanguage(prompt)
+	}
+	name, ok := languageNames[code]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Reply in %s.", name)
+}
@@ -0,0 +1,107 @@
+package miner
+
+import (
+	"sort"
+	"time"
+)
+
+// Latency phases recorded per inscription attempt.
+const (
+	PhaseLLM    = "llm"    // LLM call: prompt sent → answer received
+	PhaseSubmit = "submit" // inscribe request → server response
+	PhaseCycle  = "cycle"  // answering start → server response (how long a challenge stays open)
+)
+
+// latencyBuckets are histogram upper bounds in milliseconds. Values above
+// the last bound fall into an overflow bucket.
+var latencyBuckets = []int64{250, 500, 1000, 2000, 3000, 5000, 8000, 13000, 20000, 30000, 45000, 60000, 90000, 120000}
+
+// Histogram is a fixed-bucket latency histogram that persists compactly in state.json.
+type Histogram struct {
+	Counts []int64 `json:"counts"` // len(latencyBuckets)+1, last = overflow
+	Count  int64   `json:"count"`
+	SumMs  int64   `json:"sum_ms"`
+	MaxMs  int64   `json:"max_ms"`
+}
+
+// LatencyStats is a summary of one phase.
+type LatencyStats struct {
+	Count int64         `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	Max   time.Duration `json:"max"`
+	Mean  time.Duration `json:"mean"`
+}
+
+// Cumulative returns the histogram as (upper bound in milliseconds,
+// samples at or below it) pairs, Prometheus style; the last bound is -1
+// for +Inf.
+func (h *Histogram) Cumulative() [][2]int64 {
+	out := make([][2]int64, 0, len(latencyBuckets)+1)
+	var n int64
+	for i, le := range append(append([]int64(nil), latencyBuckets...), -1) {
+		if i < len(h.Counts) {
+			n += h.Counts[i]
+		}
+		out = append(out, [2]int64{le, n})
+	}
+	return out
+}
+
+// Observe adds a sample.
+func (h *Histogram) Observe(d time.Duration) {
+	if len(h.Counts) != len(latencyBuckets)+1 {
+		h.Counts = make([]int64, len(latencyBuckets)+1)
+	}
+	ms := d.Milliseconds()
+	i := sort.Search(len(latencyBuckets), func(i int) bool { return ms <= latencyBuckets[i] })
+	h.Counts[i]++
+	h.Count++
+	h.SumMs += ms
+	if ms > h.MaxMs {
+		h.MaxMs = ms
+	}
+}
+
+// Quantile estimates the q-th quantile (0..1) by linear interpolation
+// inside the bucket that contains it.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 || len(h.Counts) != len(latencyBuckets)+1 {
+		return 0
+	}
+	rank := q * float64(h.Count)
+	var seen float64
+	for i, c := range h.Counts {
+		if c == 0 {
+			continue
+		}
+		if seen+float64(c) >= rank {
+			lower := int64(0)
+			if i > 0 {
+				lower = latencyBuckets[i-1]
+			}
+			upper := h.MaxMs
+			if i < len(latencyBuckets) && latencyBuckets[i] < upper {
+				upper = latencyBuckets[i]
+			}
+			if upper < lower {
+				upper = lower
+			}
+			frac := (rank - seen) / float64(c)
+			return time.Duration(float64(lower)+frac*float64(upper-lower)) * time.Millisecond
+		}
+		seen += float64(c)
+	}
+	return time.Duration(h.MaxMs) * time.Millisecond
+}
+
+// Stats summarizes the histogram.
+func (h *Histogram) Stats() LatencyStats {
+	st := LatencyStats{Count: h.Count, Max: time.Duration(h.MaxMs) * time.Millisecond}
+	if h.Count > 0 {
+		st.P50 = h.Quantile(0.50)
+		st.P95 = h.Quantile(0.95)
+		st.Mean = time.Duration(h.SumMs/h.Count) * time.Millisecond
+	}
+	return st
+}
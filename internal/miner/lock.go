@@ -1,50 +1,184 @@
 package miner
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/clawplaza/clawwork-cli/internal/config"
 )
 
+// Run modes recorded in the lock file.
+const (
+	ModeForeground = "foreground" // `clawwork insc` or `attach` in a terminal
+	ModeService    = "service"    // started by launchd/systemd
+)
+
+// LockInfo is the content of mine.lock: who holds the lock and what it runs.
+// It is the one place status, install, attach and ctl look to find the
+// running miner. Older versions wrote a bare PID; ReadLock accepts both
+// formats.
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	Version   string    `json:"version,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Mode      string    `json:"mode,omitempty"`       // ModeForeground or ModeService; empty in older locks
+	Port      int       `json:"port,omitempty"`       // web console port, 0 without a console
+	Host      string    `json:"host,omitempty"`       // web console host; empty means 127.0.0.1
+	SessionID string    `json:"session_id,omitempty"` // platform session, once started
+}
+
+// LockPath returns the process lock file path.
+func LockPath() string {
+	return filepath.Join(config.Dir(), "mine.lock")
+}
+
+// ReadLock parses the lock file. It returns an error if no lock exists
+// or the file is unreadable.
+func ReadLock() (*LockInfo, error) {
+	data, err := os.ReadFile(LockPath())
+	if err != nil {
+		return nil, err
+	}
+	var info LockInfo
+	if json.Unmarshal(data, &info) == nil && info.PID > 0 {
+		return &info, nil
+	}
+	// Legacy format: plain PID.
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("malformed lock file: %s", LockPath())
+	}
+	return &LockInfo{PID: pid}, nil
+}
+
+// Alive reports whether the lock holder is still running.
+func (l *LockInfo) Alive() bool {
+	return processAlive(l.PID)
+}
+
+// Describe says who holds the lock, e.g. "the background service (PID 42,
+// v1.2.0, since 10:04)".
+func (l *LockInfo) Describe() string {
+	who := "a clawwork process"
+	switch l.Mode {
+	case ModeService:
+		who = "the background service"
+	case ModeForeground:
+		who = "a foreground miner"
+	}
+	details := []string{fmt.Sprintf("PID %d", l.PID)}
+	if l.Version != "" {
+		details = append(details, l.Version)
+	}
+	if !l.StartedAt.IsZero() {
+		details = append(details, "since "+l.StartedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return fmt.Sprintf("%s (%s)", who, strings.Join(details, ", "))
+}
+
+// HeldError is the error for trying to mine while l holds the lock.
+func (l *LockInfo) HeldError() error {
+	msg := "another clawwork instance is running: " + l.Describe()
+	if l.Mode == ModeService {
+		msg += "\nUse 'clawwork attach' to move mining to this terminal."
+	}
+	return fmt.Errorf("%s\nIf this is wrong, remove: %s", msg, LockPath())
+}
+
+// ConsoleURL returns the holder's web console address, or "" without one.
+func (l *LockInfo) ConsoleURL() string {
+	if l.Port == 0 {
+		return ""
+	}
+	host := l.Host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(l.Port))
+}
+
+// Running returns the live lock holder, or nil if no miner is running.
+func Running() *LockInfo {
+	info, err := ReadLock()
+	if err != nil || !info.Alive() {
+		return nil
+	}
+	return info
+}
+
 // AcquireLock creates a PID lock file to prevent multiple instances
-// for the same agent config directory. Returns a release function.
-func AcquireLock() (release func(), err error) {
-	lockPath := filepath.Join(config.Dir(), "mine.lock")
+// for the same agent config directory. info describes this process; its
+// PID and start time are filled in. Returns a release function.
+func AcquireLock(info LockInfo) (release func(), err error) {
+	lockPath := LockPath()
 
 	// Check existing lock
-	if data, err := os.ReadFile(lockPath); err == nil {
-		pidStr := strings.TrimSpace(string(data))
-		if pid, err := strconv.Atoi(pidStr); err == nil && processAlive(pid) {
-			return nil, fmt.Errorf(
-				"another clawwork instance is running (PID %d)\n"+
-					"If this is wrong, remove: %s", pid, lockPath)
+	if held, err := ReadLock(); err == nil {
+		if held.Alive() {
+			return nil, held.HeldError()
 		}
 		// Stale lock from a crashed process — safe to remove.
 		_ = os.Remove(lockPath)
+	} else if !os.IsNotExist(err) {
+		_ = os.Remove(lockPath)
 	}
 
 	// Write our PID
 	if err := os.MkdirAll(config.Dir(), 0700); err != nil {
 		return nil, fmt.Errorf("create lock directory: %w", err)
 	}
-	if err := os.WriteFile(lockPath, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+	info.PID, info.StartedAt = os.Getpid(), time.Now().UTC()
+	if err := writeLock(&info); err != nil {
 		return nil, fmt.Errorf("create lock file: %w", err)
 	}
 
 	return func() { _ = os.Remove(lockPath) }, nil
 }
 
-// processAlive checks whether a PID is still running.
-func processAlive(pid int) bool {
-	proc, err := os.FindProcess(pid)
+func writeLock(info *LockInfo) error {
+	data, _ := json.Marshal(info)
+	return os.WriteFile(LockPath(), data, 0600)
+}
+
+// updateLock changes the lock file this process holds. It does nothing
+// if the lock belongs to someone else.
+func updateLock(fn func(*LockInfo)) {
+	info, err := ReadLock()
+	if err != nil || info.PID != os.Getpid() {
+		return
+	}
+	fn(info)
+	_ = writeLock(info)
+}
+
+// Reload asks the lock holder to reload its config (SIGHUP). Windows has
+// no SIGHUP; there it fails and the miner must be restarted instead.
+func (l *LockInfo) Reload() error {
+	proc, err := os.FindProcess(l.PID)
 	if err != nil {
-		return false
+		return err
+	}
+	return proc.Signal(syscall.SIGHUP)
+}
+
+// WaitReleased polls until no live process holds the lock, or timeout passes.
+func WaitReleased(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		info, err := ReadLock()
+		if err != nil || !info.Alive() {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("PID %d still holds %s after %s", info.PID, LockPath(), timeout)
+		}
+		time.Sleep(200 * time.Millisecond)
 	}
-	// Signal 0 tests existence without actually sending a signal.
-	return proc.Signal(syscall.Signal(0)) == nil
 }
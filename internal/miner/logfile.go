@@ -0,0 +1,126 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// LogFile is an append-only log destination that can be reopened in place,
+// so external tools like logrotate can move the file and signal SIGHUP.
+// It can also rotate itself by size (see SetRotation).
+type LogFile struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64 // bytes in f
+
+	maxSize int64 // rotate before growing past this; 0 never
+	backups int   // rotated files kept as path.1 … path.N
+}
+
+// OpenLogFile opens (or creates) path for appending.
+func OpenLogFile(path string) (*LogFile, error) {
+	l := &LogFile{path: path}
+	if err := l.Reopen(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// SetRotation makes Write rotate the file before it grows past maxSize
+// bytes, keeping backups old files; path.1 is the newest. A maxSize of 0
+// turns rotation off.
+func (l *LogFile) SetRotation(maxSize int64, backups int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxSize = maxSize
+	l.backups = backups
+}
+
+// Write appends p to the current file, rotating first if it is full.
+func (l *LogFile) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(p)) > l.maxSize {
+		if err := l.rotateLocked(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: log rotation failed: %s\n", err)
+		}
+	}
+	if l.f == nil {
+		return 0, errors.New("log file is not open")
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// Reopen closes the current handle and opens path again, picking up a
+// fresh file if the old one was rotated away.
+func (l *LogFile) Reopen() error {
+	f, size, err := openAppend(l.path)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	old := l.f
+	l.f, l.size = f, size
+	l.mu.Unlock()
+	if old != nil {
+		_ = old.Close()
+	}
+	return nil
+}
+
+// rotateLocked shifts path.1 … path.N-1 up by one, drops the oldest, moves
+// the current file to path.1 and starts a new one. l.mu must be held.
+func (l *LogFile) rotateLocked() error {
+	_ = l.f.Close()
+	l.f = nil
+	_ = os.Remove(l.backupPath(l.backups))
+	for i := l.backups - 1; i >= 1; i-- {
+		_ = os.Rename(l.backupPath(i), l.backupPath(i+1))
+	}
+	var moveErr error
+	if l.backups > 0 {
+		moveErr = os.Rename(l.path, l.backupPath(1))
+	} else {
+		moveErr = os.Remove(l.path)
+	}
+	// Even if the old file couldn't be moved, keep logging to it.
+	f, size, err := openAppend(l.path)
+	if err != nil {
+		return err
+	}
+	l.f, l.size = f, size
+	return moveErr
+}
+
+func (l *LogFile) backupPath(i int) string { return fmt.Sprintf("%s.%d", l.path, i) }
+
+// Close closes the underlying file.
+func (l *LogFile) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+func openAppend(path string) (*os.File, int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, 0, fmt.Errorf("create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, 0, fmt.Errorf("open log file: %w", err)
+	}
+	var size int64
+	if fi, err := f.Stat(); err == nil {
+		size = fi.Size()
+	}
+	return f, size, nil
+}
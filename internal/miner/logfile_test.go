@@ -0,0 +1,79 @@
+package miner
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "clawwork.log")
+	l, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.SetRotation(100, 2)
+
+	line := strings.Repeat("x", 39) + "\n" // two lines fit in 100 bytes, three don't
+	for i := 0; i < 7; i++ {
+		if _, err := l.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 7 lines: 2 in .2 (oldest kept), 2 in .1, 1 current; the first 2 dropped.
+	for name, want := range map[string]int{path: 40, path + ".1": 80, path + ".2": 80} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() != int64(want) {
+			t.Errorf("%s: %d bytes, want %d", filepath.Base(name), fi.Size(), want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("kept more than 2 backups")
+	}
+
+	// A file that already exists counts toward the limit after a reopen.
+	if err := l.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = l.Write([]byte(line))
+	_, _ = l.Write([]byte(line))
+	if fi, _ := os.Stat(path); fi.Size() != 40 {
+		t.Errorf("after reopen: %d bytes, want 40 (rotated)", fi.Size())
+	}
+}
+
+func TestSetupLoggerJSON(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	path := filepath.Join(t.TempDir(), "clawwork.log")
+	l, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetupLoggerTo("info", "json", l)
+	slog.Info("inscribed", "token_id", 42)
+	slog.Debug("hidden")
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %q", len(lines), data)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("not JSON: %v", err)
+	}
+	if rec["msg"] != "inscribed" || rec["level"] != "INFO" || rec["token_id"] != float64(42) {
+		t.Errorf("record = %v", rec)
+	}
+}
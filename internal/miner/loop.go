@@ -2,13 +2,18 @@ package miner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/event"
 	"github.com/clawplaza/clawwork-cli/internal/knowledge"
 	"github.com/clawplaza/clawwork-cli/internal/llm"
 )
@@ -19,8 +24,19 @@ const (
 	maxLLMRetries       = 3
 	llmRetryDelay       = 2 * time.Second
 	maxNetworkBackoff   = 5 * time.Minute
+	thinkingInterval    = 15 * time.Second // countdown events while the LLM works
+	defaultChallengeTTL = 5 * time.Minute  // when a challenge doesn't say how long it lasts
 )
 
+// AnswerMaxTokens is the response budget for challenge answers.
+// Thinking models (Kimi K2.5, DeepSeek-R1) need room for internal
+// reasoning + the actual short answer in the content field.
+const AnswerMaxTokens = 2048
+
+// PromptReserve is the room, in tokens, kept in the context window for a
+// challenge prompt when fitting the system prompt.
+const PromptReserve = 1024
+
 // Miner runs the core inscription loop.
 type Miner struct {
 	API       *api.Client
@@ -29,10 +45,82 @@ type Miner struct {
 	TokenID   int
 	Knowledge *knowledge.Knowledge
 
+	// Tokens, when it has more than one entry, enables multi-token mode:
+	// cycles are interleaved across these token IDs, each with its own
+	// cooldown. TokenID is then the token of the current cycle.
+	Tokens []int
+
+	// AutoToken, if set, moves the miner to another token when its token
+	// is taken or crowded instead of stopping (single-token mode only).
+	AutoToken *TokenPicker
+
 	// OnEvent broadcasts mining events to the web console.
 	// Nil means no web console attached (terminal-only mode).
 	OnEvent func(eventType, message string, data any)
 
+	// Alerts holds thresholds for trust score alerts.
+	Alerts config.AlertsConfig
+
+	// AnswerLanguage is the reply-language policy ("auto", "off" or a code).
+	AnswerLanguage string
+
+	// AgentName and ContextHeader control the context block put in front
+	// of each challenge (see contextHeader).
+	AgentName     string
+	ContextHeader bool
+
+	// Strategy decides how challenges are answered (nil: SingleShot).
+	Strategy Strategy
+
+	// Experiment, when set, alternates cycles between its two arms and
+	// records outcomes per arm.
+	Experiment *Experiment
+
+	// Candidates, CandidatesWhen and CandidateTemperature configure
+	// multi-candidate answering for high-stakes challenges (see highStakes).
+	Candidates           int
+	CandidatesWhen       string
+	CandidateTemperature float64
+
+	// ShutdownGrace lets an in-flight inscription (LLM answer + submit)
+	// finish after ctx is cancelled. Zero abandons it immediately.
+	ShutdownGrace time.Duration
+
+	// AnswerTimeout bounds each LLM attempt at a challenge. Zero leaves
+	// only the provider's HTTP timeout.
+	AnswerTimeout time.Duration
+
+	// Takeover, on ALREADY_MINING, ends a stale session of ours or waits
+	// for the other session to expire instead of failing (see takeover).
+	Takeover bool
+
+	// Mode, ConsolePort and ConsoleHost are recorded in the lock file so
+	// other commands can tell how this miner runs and reach its console.
+	Mode        string
+	ConsolePort int
+	ConsoleHost string
+
+	// Coordinate staggers inscriptions with other profiles mining on this
+	// host and shares IP-penalty reports with them (see Coordinator).
+	Coordinate bool
+
+	// KnowledgeWatch, if set, reloads the soul and knowledge files between
+	// cycles when they change. Reprompt then rebuilds the LLM system prompt
+	// from the updated knowledge.
+	KnowledgeWatch *knowledge.Watcher
+	Reprompt       func(*knowledge.Knowledge)
+
+	// Banner, if set, prints the startup summary once the session is set
+	// up (or failed to be), in place of the session line.
+	Banner func(sessionID string, verified bool)
+
+	// SelfUpdate, if set, may install a newer binary after each
+	// inscription (see selfUpdate). Run then returns ErrUpdated.
+	SelfUpdate func(force bool) (string, error)
+
+	// History, if set, records every inscription attempt (see HistoryEntry).
+	History *History
+
 	// Ctrl allows the web console to pause/resume and switch tokens.
 	// Nil means no external control.
 	Ctrl interface {
@@ -40,8 +128,21 @@ type Miner struct {
 		TokenID() int
 	}
 
-	sessionID string // server-assigned session token
-	version   string // CLI version for display
+	pacing        atomic.Pointer[Pacing] // see SetPacing
+	multi         bool                   // interleaving Tokens (see Tokens)
+	cycleFailures int                    // challenge failures in the current cycle
+	ctrlToken     int                    // last token ID seen from Ctrl, to detect console switches
+	sessionID     string                 // server-assigned session token
+	verified      bool                   // the platform verified this client at session start
+	answerStart   time.Time              // when answering the current challenge began (cycle latency)
+	answerBy      time.Time              // when the challenge being answered expires
+	version       string                 // CLI version for display
+	coord         *Coordinator
+	arm           *Arm // experiment arm of the current cycle
+	nftsRemaining int  // from the last inscription, for the context header
+	replaying     bool // answers come from a recording: submit them as they are
+	abortOnce     sync.Once
+	abort         chan struct{} // closed by Abort
 }
 
 // emit sends a mining event if a listener is attached.
@@ -51,39 +152,115 @@ func (m *Miner) emit(eventType, message string, data any) {
 	}
 }
 
+// Abort cuts the shutdown grace short: an inscription still running after
+// Run's ctx was cancelled is abandoned at once. Run still ends its
+// session and releases its lock before returning.
+func (m *Miner) Abort() {
+	ch := m.aborted()
+	select {
+	case <-ch:
+	default:
+		close(ch)
+	}
+}
+
+func (m *Miner) aborted() chan struct{} {
+	m.abortOnce.Do(func() { m.abort = make(chan struct{}) })
+	return m.abort
+}
+
 // SetVersion stores the CLI version for display and version gating.
 func (m *Miner) SetVersion(v string) { m.version = v }
 
 // Run starts the inscription loop, blocking until ctx is cancelled.
 func (m *Miner) Run(ctx context.Context) error {
 	// ── Phase 0: Acquire process lock ──
-	releaseLock, err := AcquireLock()
+	releaseLock, err := AcquireLock(LockInfo{Version: m.version, Mode: m.Mode, Port: m.ConsolePort, Host: m.ConsoleHost})
 	if err != nil {
 		return err
 	}
 	defer releaseLock()
+	m.State.StartRun(time.Now())
+	if m.Coordinate {
+		if c, err := JoinCoord(CoordDir()); err != nil {
+			slog.Warn("multi-instance coordination off", "error", err)
+		} else {
+			m.coord = c
+			defer c.Leave()
+		}
+	}
 
 	// ── Phase 1: Start session ──
-	if err := m.startSession(ctx); err != nil {
-		// ALREADY_MINING and UPGRADE_REQUIRED are fatal — don't continue.
-		if isFatalSessionError(err) {
-			return err
+	err = m.startSession(ctx)
+	if apiErr, ok := api.AsAPIError(err); ok && apiErr.Code == "ALREADY_MINING" && m.Takeover {
+		err = m.takeover(ctx, apiErr)
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	if m.Banner != nil {
+		m.Banner(m.sessionID, m.verified)
+	}
+	if err != nil {
+		// ALREADY_MINING, UPGRADE_REQUIRED, NOT_CLAIMED... — don't continue.
+		if apiErr, ok := api.AsAPIError(err); ok && apiErr.IsFatal() {
+			return m.fatal(apiErr)
 		}
 		// Other errors (network, server not upgraded yet) — continue without session.
 		slog.Warn("session start failed, continuing without session", "error", err)
 	}
 	defer m.endSession()
 
+	m.ctrlToken = m.TokenID
+	m.multi = len(m.Tokens) > 1
+	if m.multi {
+		m.State.SetRotation(m.Tokens)
+		slog.Info("multi-token mode", "tokens", m.Tokens)
+		m.emit("session", fmt.Sprintf("Multi-token mode: %s", formatTokens(m.Tokens)), event.Session{Action: event.SessionMultiToken, Tokens: m.Tokens})
+	} else {
+		m.State.SetRotation(nil)
+	}
+
 	slog.Info("inscription started", "token_id", m.TokenID, "llm", m.LLM.Name())
+	if m.coord != nil {
+		if peers := m.coord.Peers(); len(peers) > 0 {
+			msg := peerSummary(peers) + " — inscriptions will be staggered"
+			fmt.Println(msg)
+			m.emit("session", msg, event.Session{Action: event.SessionPeers, Peers: len(peers)})
+			m.reportPeerPenalties()
+		}
+	}
+	if b, ok := m.LLM.(*llm.Breaker); ok {
+		b.OnChange = m.llmHealthChanged
+	}
+	if m.Experiment != nil {
+		for _, arm := range m.Experiment.Arms {
+			if b, ok := arm.LLM.(*llm.Breaker); ok {
+				b.OnChange = m.llmHealthChanged
+			}
+		}
+		m.startExperiment()
+	}
+
+	// A review hold survives restarts: don't mine until it is cleared.
+	if h := LoadReviewHold(); h != nil && !m.holdForReview(ctx, h) {
+		DisplayStats(m.State)
+		return nil
+	}
 
 	// ── Phase 1.5: Resume cooldown from previous session ──
-	if !m.State.LastMineAt.IsZero() {
+	if until := m.State.LimitUntil(QuotaDaily); time.Now().Before(until) && !m.waitDailyReset(ctx, until) {
+		DisplayStats(m.State)
+		return nil
+	}
+	// (multi-token mode keeps per-token cooldowns in state instead)
+	if !m.multi && !m.State.LastMineAt.IsZero() {
 		elapsed := time.Since(m.State.LastMineAt)
-		remaining := time.Duration(defaultCooldown)*time.Second - elapsed
+		remaining := m.cooldown() - elapsed
 		if remaining > 0 {
 			secs := int(remaining.Seconds())
 			DisplayCooldown(secs)
-			m.emit("cooldown", fmt.Sprintf("Resuming cooldown: %dm%02ds remaining", secs/60, secs%60), nil)
+			m.emit("cooldown", fmt.Sprintf("Resuming cooldown: %dm%02ds remaining", secs/60, secs%60), event.NewCooldown(event.CooldownResume, secs))
 			if !sleep(ctx, remaining) {
 				DisplayStats(m.State)
 				return nil
@@ -98,41 +275,149 @@ func (m *Miner) Run(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			DisplayStats(m.State)
-			m.emit("stats", fmt.Sprintf("Session ended: %d inscriptions, %d CW", m.State.TotalInscriptions, m.State.TotalCWEarned), nil)
+			run := m.State.Stats(time.Now()).Run
+			m.emit("stats", fmt.Sprintf("Run ended: %d inscriptions, %d CW this run", run.Inscriptions, run.CWEarned), nil)
 			return nil
 		default:
 		}
 
 		// Check for pause from web console.
 		if m.Ctrl != nil && m.Ctrl.IsPaused() {
-			m.emit("control", "Mining paused", nil)
+			m.emit("control", "Mining paused", event.Control{Action: event.ControlPause})
 			for m.Ctrl.IsPaused() {
 				if !sleep(ctx, 1*time.Second) {
 					DisplayStats(m.State)
 					return nil
 				}
 			}
-			m.emit("control", "Mining resumed", nil)
+			m.emit("control", "Mining resumed", event.Control{Action: event.ControlResume})
 		}
 
-		// Check for token ID change from web console.
+		// Check for token ID change from web console. Picking one token
+		// from the console also leaves multi-token mode.
 		if m.Ctrl != nil {
-			if newToken := m.Ctrl.TokenID(); newToken != m.TokenID {
-				m.emit("control", fmt.Sprintf("Token switched: #%d → #%d", m.TokenID, newToken), nil)
-				m.TokenID = newToken
+			if newToken := m.Ctrl.TokenID(); newToken != m.ctrlToken {
+				m.emit("control", fmt.Sprintf("Token switched: #%d → #%d", m.TokenID, newToken),
+					event.Control{Action: event.ControlTokenSwitch, Reason: "console", From: m.TokenID, To: newToken})
+				m.ctrlToken, m.TokenID = newToken, newToken
+				if m.multi {
+					m.multi, m.Tokens = false, nil
+					m.State.SetRotation(nil)
+				}
 			}
 		}
 
-		resp, err := m.mineOnce(ctx)
+		// Outside the active hours: sleep until they start.
+		if !m.waitActiveHours(ctx) {
+			DisplayStats(m.State)
+			return nil
+		}
+
+		// Multi-token mode: wait for the token that cools down first.
+		if m.multi {
+			tok, wait := m.nextToken(time.Now())
+			if wait > 0 {
+				secs := int(wait.Seconds())
+				DisplayCooldown(secs)
+				c := event.NewCooldown(event.CooldownAllTokens, secs)
+				c.TokenID = tok
+				m.emit("cooldown", fmt.Sprintf("All tokens cooling down — next: #%d in %dm%02ds", tok, secs/60, secs%60), c)
+				if !sleep(ctx, wait) {
+					DisplayStats(m.State)
+					return nil
+				}
+				continue // re-check pause and console switches after the wait
+			}
+			m.TokenID = tok
+		}
+
+		// Other profiles on this host: take the next free slot.
+		if m.coord != nil && !m.waitSlot(ctx) {
+			DisplayStats(m.State)
+			return nil
+		}
+
+		m.checkKnowledge()
+
+		// The inscription itself runs on a context that survives shutdown
+		// for ShutdownGrace, so an answered challenge isn't thrown away.
+		opCtx, opCancel := withGrace(ctx, m.ShutdownGrace, m.aborted(), func() {
+			fmt.Printf("Finishing current inscription (up to %s)...\n", m.ShutdownGrace)
+		})
+		resp, err := m.mineOnce(opCtx)
+		opCancel()
+		if ctx.Err() == nil && !m.checkFailures(ctx) {
+			DisplayStats(m.State)
+			return nil
+		}
 		if err != nil {
 			if ctx.Err() != nil {
+				// Keep the cached challenge so the next run can resume it.
+				_ = m.State.Save()
 				DisplayStats(m.State)
 				return nil
 			}
 
-			DisplayError(err.Error())
-			m.emit("error", err.Error(), nil)
-			slog.Error("inscription failed", "error", err)
+			apiErr, isAPI := api.AsAPIError(err)
+			switch {
+			case isAPI && apiErr.IsFatal():
+				return m.fatal(apiErr)
+
+			case isAPI && apiErr.Code == "DAILY_LIMIT_REACHED":
+				// Applies to the whole agent, every token included.
+				until := dailyReset(apiErr, time.Now())
+				_ = m.State.RecordLimit(QuotaDaily, apiErr.Code, until)
+				if !m.waitDailyReset(ctx, until) {
+					DisplayStats(m.State)
+					return nil
+				}
+				continue
+
+			case isAPI && apiErr.IsRateLimited():
+				wait := apiErr.RetryAfter
+				if wait <= 0 {
+					wait = int(m.cooldown().Seconds())
+				}
+				ts := time.Now().Format("15:04:05")
+				until := time.Now().Add(time.Duration(wait) * time.Second)
+				if !m.multi {
+					_ = m.State.RecordLimit(QuotaInscribe, apiErr.Code, until)
+				}
+				if m.multi {
+					// Only this token is cooling down; move on to the next.
+					m.State.SetTokenCooldown(m.TokenID, until)
+					msg := fmt.Sprintf("Token #%d cooling down %ds — trying the next token", m.TokenID, wait)
+					fmt.Printf("[%s] %s\n", ts, msg)
+					c := event.NewCooldown(event.CooldownToken, wait)
+					c.TokenID = m.TokenID
+					m.emit("cooldown", msg, c)
+					continue
+				}
+				msg := fmt.Sprintf("Cooldown active. Waiting %ds...", wait)
+				fmt.Printf("[%s] %s\n", ts, msg)
+				m.emit("cooldown", msg, event.NewCooldown(event.CooldownServer, wait))
+				if !sleep(ctx, time.Duration(wait)*time.Second) {
+					DisplayStats(m.State)
+					return nil
+				}
+				continue
+
+			case isAPI:
+				// Unhandled server error — back off and retry.
+				slog.Warn("unhandled server error, retrying", "error", apiErr.Code, "message", apiErr.Message)
+				m.emit("error", fmt.Sprintf("Server: %s — %s", apiErr.Code, apiErr.Message), nil)
+
+			default:
+				DisplayError(err.Error())
+				m.emit("error", err.Error(), nil)
+				slog.Error("inscription failed", "error", err)
+
+				// The same answer was already submitted; ask for a fresh
+				// challenge instead of resending it.
+				if errors.Is(err, api.ErrDuplicatePayload) {
+					m.State.LastChallenge = nil
+				}
+			}
 
 			slog.Info("retrying after backoff", "delay", networkBackoff)
 			if !sleep(ctx, networkBackoff) {
@@ -146,35 +431,21 @@ func (m *Miner) Run(ctx context.Context) error {
 		// Reset backoff on success
 		networkBackoff = 5 * time.Second
 
-		// Handle fatal errors
-		if resp.IsFatal() {
-			return handleFatalError(resp)
+		// Handle token taken
+		if resp.IDStatus == "taken" && m.multi && len(m.Tokens) > 1 {
+			fmt.Printf("\nToken #%d has been taken by another agent — removed from rotation.\n", m.TokenID)
+			m.emit("control", fmt.Sprintf("Token #%d taken — removed from rotation", m.TokenID),
+				event.Control{Action: event.ControlTokenRemoved, Reason: "taken", TokenID: m.TokenID})
+			m.dropToken(m.TokenID)
+			continue
 		}
-
-		// Handle rate limiting
-		if resp.IsRateLimited() {
-			wait := resp.RetryAfter
-			if wait <= 0 {
-				wait = defaultCooldown
-			}
-			ts := time.Now().Format("15:04:05")
-			if resp.Error == "DAILY_LIMIT_REACHED" {
-				msg := fmt.Sprintf("Daily limit reached. Waiting %dm...", wait/60)
-				fmt.Printf("[%s] %s\n", ts, msg)
-				m.emit("cooldown", msg, nil)
-			} else {
-				msg := fmt.Sprintf("Cooldown active. Waiting %ds...", wait)
-				fmt.Printf("[%s] %s\n", ts, msg)
-				m.emit("cooldown", msg, nil)
-			}
-			if !sleep(ctx, time.Duration(wait)*time.Second) {
-				DisplayStats(m.State)
-				return nil
+		if resp.IDStatus == "taken" && m.AutoToken != nil && !m.multi {
+			m.AutoToken.Taken(m.TokenID)
+			if m.moveToken(ctx, "taken", -1) {
+				continue
 			}
-			continue
+			fmt.Println("\nNo untaken token left among the auto-token candidates.")
 		}
-
-		// Handle token taken
 		if resp.IDStatus == "taken" {
 			fmt.Printf("\nToken #%d has been taken by another agent.\n", m.TokenID)
 			fmt.Println("Choose a new token ID and restart with: clawwork insc --token-id <id>")
@@ -182,32 +453,31 @@ func (m *Miner) Run(ctx context.Context) error {
 			return fmt.Errorf("token #%d is taken", m.TokenID)
 		}
 
-		// Guard: catch unhandled server errors that shouldn't fall through to success.
-		if resp.Error != "" {
-			slog.Warn("unhandled server error, retrying", "error", resp.Error, "message", resp.Message)
-			m.emit("error", fmt.Sprintf("Server: %s — %s", resp.Error, resp.Message), nil)
-			if !sleep(ctx, networkBackoff) {
-				DisplayStats(m.State)
-				return nil
-			}
-			networkBackoff = minDuration(networkBackoff*2, maxNetworkBackoff)
-			continue
-		}
-
 		// Success
 		DisplayResult(resp, m.State.LastTrustScore)
+		result := event.Inscription{TokenID: m.TokenID, CWEarned: resp.CWEarned, TrustScore: resp.TrustScore,
+			NFTsRemaining: resp.NFTsRemaining, Hit: resp.Hit}
 		if resp.Hit {
-			m.emit("hit", fmt.Sprintf("NFT #%d is yours!", resp.TokenID), nil)
+			m.emit("hit", fmt.Sprintf("NFT #%d is yours!", resp.TokenID), result)
 		} else {
 			m.emit("inscription", fmt.Sprintf("CW: %d | Trust: %d | NFTs left: %d",
-				resp.CWEarned, resp.TrustScore, resp.NFTsRemaining), nil)
+				resp.CWEarned, resp.TrustScore, resp.NFTsRemaining), result)
+		}
+		if p, ok := IPPenalty(m.TokenID, resp, time.Now()); ok {
+			m.State.RecordPenalty(p)
+			m.emit("penalty", fmt.Sprintf("IP penalty: %s (-%d CW)", p.Detail, p.LostCW),
+				event.Penalty{Kind: p.Kind, TokenID: p.TokenID, LostCW: p.LostCW, Detail: p.Detail})
 		}
-		if resp.IPPenalty != nil && resp.IPPenalty.IPMultiplier > 1 {
-			m.emit("penalty", fmt.Sprintf("IP penalty: %dx multiplier, %d agents on IP",
-				resp.IPPenalty.IPMultiplier, resp.IPPenalty.AgentsOnIP), nil)
+		if m.coord != nil {
+			m.coord.RecordIP(resp.IPPenalty, time.Now())
+			m.reportPeerPenalties()
 		}
-		m.State.LastTrustScore = resp.TrustScore
+		m.nftsRemaining = resp.NFTsRemaining
 		m.State.Update(resp)
+		m.State.RecordToken(m.TokenID, resp, time.Now().Add(m.cooldown()))
+		if resp.TrustScore > 0 {
+			m.checkTrust(resp.TrustScore)
+		}
 		_ = m.State.Save()
 
 		// Check version info from server
@@ -216,10 +486,25 @@ func (m *Miner) Run(ctx context.Context) error {
 		// Check spec version for platform rule changes
 		m.checkSpecUpdate(resp)
 
-		// Cooldown
-		DisplayCooldown(defaultCooldown)
-		m.emit("cooldown", fmt.Sprintf("Next inscription in %dm", defaultCooldown/60), nil)
-		if !sleep(ctx, time.Duration(defaultCooldown)*time.Second) {
+		// Leave a crowded token before the next cycle.
+		if m.AutoToken != nil && !m.multi && m.AutoToken.Crowded(len(resp.NearbyMiners)) {
+			m.moveToken(ctx, "crowded", len(resp.NearbyMiners))
+		}
+
+		// Install an update now: the next run resumes the cooldown.
+		if m.selfUpdate(m.newerClient(resp)) {
+			DisplayStats(m.State)
+			return ErrUpdated
+		}
+
+		// Cooldown (multi-token mode waits per token at the top of the loop)
+		if m.multi {
+			continue
+		}
+		cooldown := m.cooldown()
+		DisplayCooldown(int(cooldown.Seconds()))
+		m.emit("cooldown", fmt.Sprintf("Next inscription in %dm", int(cooldown.Minutes())), event.NewCooldown(event.CooldownNext, int(cooldown.Seconds())))
+		if !sleep(ctx, cooldown) {
 			DisplayStats(m.State)
 			return nil
 		}
@@ -231,35 +516,30 @@ func (m *Miner) Run(ctx context.Context) error {
 func (m *Miner) startSession(ctx context.Context) error {
 	resp, err := m.API.StartSession(ctx, m.TokenID)
 	if err != nil {
+		// Keep any challenge handed out with the error for the first cycle.
+		if apiErr, ok := api.AsAPIError(err); ok && apiErr.Challenge != nil {
+			m.State.LastChallenge = apiErr.Challenge
+		}
 		return err
 	}
 
-	// Check for fatal session errors
-	if resp.Error == "ALREADY_MINING" {
-		fmt.Println("\nThis agent already has an active session.")
-		fmt.Println("Stop the other instance first, or wait for it to expire (~1 hour).")
-		return fmt.Errorf("ALREADY_MINING")
-	}
-	if resp.Error == "UPGRADE_REQUIRED" {
-		fmt.Printf("\nClawWork %s is no longer supported.\n", m.version)
-		if resp.MinClientVersion != "" {
-			fmt.Printf("Minimum required: %s\n", resp.MinClientVersion)
-		}
-		if resp.UpgradeURL != "" {
-			fmt.Printf("Download: %s\n", resp.UpgradeURL)
+	// Session started. The ID is saved so a later --takeover can end it
+	// if this process dies without closing it.
+	if resp.SessionID != "" {
+		m.sessionID, m.verified = resp.SessionID, resp.ClientVerified
+		m.State.SessionID = resp.SessionID
+		_ = m.State.Save()
+		updateLock(func(l *LockInfo) { l.SessionID = resp.SessionID })
+		slog.Info("session started", "session", shortID(m.sessionID), "verified", resp.ClientVerified)
+		if m.Banner == nil {
+			DisplaySession(m.sessionID, resp.ClientVerified)
 		}
-		return fmt.Errorf("UPGRADE_REQUIRED")
-	}
-	if resp.IsFatal() {
-		return handleFatalError(resp)
+		m.emit("session", fmt.Sprintf("Session started: %s", shortID(m.sessionID)),
+			event.Session{Action: event.SessionStart, SessionID: m.sessionID})
 	}
 
-	// Session started
-	if resp.SessionID != "" {
-		m.sessionID = resp.SessionID
-		slog.Info("session started", "session", shortID(m.sessionID), "verified", resp.ClientVerified)
-		DisplaySession(m.sessionID, resp.ClientVerified)
-		m.emit("session", fmt.Sprintf("Session started: %s", shortID(m.sessionID)), nil)
+	if resp.NFTsRemaining > 0 {
+		m.nftsRemaining = resp.NFTsRemaining
 	}
 
 	// Save any challenge returned with session start
@@ -284,16 +564,12 @@ func (m *Miner) endSession() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	m.API.EndSession(ctx, m.sessionID)
+	m.State.SessionID = ""
+	_ = m.State.Save()
+	updateLock(func(l *LockInfo) { l.SessionID = "" })
 	slog.Info("session ended")
 }
 
-func isFatalSessionError(err error) bool {
-	msg := err.Error()
-	return msg == "ALREADY_MINING" || msg == "UPGRADE_REQUIRED" ||
-		strings.Contains(msg, "agent not claimed") ||
-		strings.Contains(msg, "agent banned")
-}
-
 // ── Inscription Logic ──
 
 func (m *Miner) mineOnce(ctx context.Context) (*api.InscribeResponse, error) {
@@ -302,73 +578,109 @@ func (m *Miner) mineOnce(ctx context.Context) (*api.InscribeResponse, error) {
 		SessionID: m.sessionID, // empty if no session
 	}
 
+	// prompt tracks the challenge the current answer was written for.
+	var prompt string
+
+	m.arm = nil
+	if m.Experiment != nil {
+		m.arm = m.pickArm()
+	}
+
 	// Attach last challenge answer if we have one
 	if m.State.LastChallenge != nil {
+		m.answerStart = time.Now()
 		slog.Info("using cached challenge", "id", shortID(m.State.LastChallenge.ID))
 		answer, err := m.answerChallenge(ctx, m.State.LastChallenge)
 		if err != nil {
+			m.recordLLMFailure(ctx, req, m.State.LastChallenge, err)
 			return nil, fmt.Errorf("LLM error: %w", err)
 		}
 		req.ChallengeID = m.State.LastChallenge.ID
 		req.ChallengeAnswer = answer
+		prompt = m.State.LastChallenge.Prompt
 	} else {
 		slog.Info("no cached challenge, requesting new one")
 	}
 
 	// Call API
-	resp, err := m.API.Inscribe(ctx, req)
-	if err != nil {
-		return nil, err
-	}
+	resp, err := m.submit(ctx, req, prompt)
 
 	// Challenge retry loop
-	for i := 0; resp.IsChallenge() && i < maxChallengeRetries; i++ {
-		challenge := resp.GetChallenge()
+	for i := 0; isChallengeError(err) && i < maxChallengeRetries; i++ {
+		apiErr, _ := api.AsAPIError(err)
+		challenge := apiErr.Challenge
 		if challenge == nil {
 			// Clear stale challenge — server didn't provide a new one.
 			m.State.LastChallenge = nil
 			return nil, fmt.Errorf("server returned challenge error without a new challenge")
 		}
 
-		if resp.Error == "CHALLENGE_FAILED" {
-			m.State.RecordChallengeFail()
-			DisplayError(fmt.Sprintf("Challenge failed: %s", resp.Message))
-			DisplayChallengePenalty(resp.Hint)
-			m.emit("penalty", fmt.Sprintf("Challenge failed: %s", resp.Message), nil)
+		if apiErr.Code == "CHALLENGE_FAILED" {
+			m.State.RecordChallengeFail(ChallengeFailure{
+				At:      time.Now(),
+				Prompt:  prompt,
+				Answer:  req.ChallengeAnswer,
+				Message: apiErr.Message,
+				Hint:    apiErr.Hint,
+
+				Language: DetectLanguage(prompt),
+			})
+			DisplayError(fmt.Sprintf("Challenge failed: %s", apiErr.Message))
+			DisplayChallengePenalty(apiErr.Hint)
+			m.cycleFailures++
+			m.recordAnswer(false)
+			p := m.State.ChallengePenalty(m.TokenID, apiErr, time.Now())
+			m.State.RecordPenalty(p)
+			m.emit("penalty", fmt.Sprintf("Challenge failed: %s", apiErr.Message),
+				event.Penalty{Kind: p.Kind, TokenID: p.TokenID, LostCW: p.LostCW, Estimated: p.Estimated, TrustLost: p.TrustLost, Detail: p.Detail})
 		} else {
 			// Non-penalty challenge errors (expired, invalid, used, etc.)
-			slog.Info("challenge retry", "error", resp.Error, "message", resp.Message,
+			slog.Info("challenge retry", "error", apiErr.Code, "message", apiErr.Message,
 				"attempt", i+1, "new_challenge", shortID(challenge.ID))
-			m.emit("session", fmt.Sprintf("Challenge retry (%s): %s", resp.Error, resp.Message), nil)
+			m.emit("session", fmt.Sprintf("Challenge retry (%s): %s", apiErr.Code, apiErr.Message),
+				event.Session{Action: event.SessionChallengeRetry, Code: apiErr.Code})
 		}
 
-		answer, err := m.answerChallenge(ctx, challenge)
-		if err != nil {
-			return nil, fmt.Errorf("LLM error: %w", err)
+		m.answerStart = time.Now()
+		answer, llmErr := m.answerChallenge(ctx, challenge)
+		if llmErr != nil {
+			m.recordLLMFailure(ctx, req, challenge, llmErr)
+			return nil, fmt.Errorf("LLM error: %w", llmErr)
 		}
 		req.ChallengeID = challenge.ID
 		req.ChallengeAnswer = answer
+		prompt = challenge.Prompt
 
-		resp, err = m.API.Inscribe(ctx, req)
-		if err != nil {
-			return nil, err
-		}
+		resp, err = m.submit(ctx, req, prompt)
 	}
 
 	// Still a challenge error after max retries — clear stale challenge
 	// so the next cycle starts fresh instead of resubmitting the same ID.
-	if resp.IsChallenge() {
-		lastCh := resp.GetChallenge()
-		if lastCh != nil {
+	if isChallengeError(err) {
+		apiErr, _ := api.AsAPIError(err)
+		if apiErr.Challenge != nil {
 			// Save the latest challenge from server for next attempt.
-			m.State.LastChallenge = lastCh
+			m.State.LastChallenge = apiErr.Challenge
 			slog.Info("retries exhausted, saved latest challenge for next cycle",
-				"id", shortID(lastCh.ID))
+				"id", shortID(apiErr.Challenge.ID))
 		} else {
 			m.State.LastChallenge = nil
 		}
+		if apiErr.Code == "CHALLENGE_FAILED" {
+			m.recordAnswer(false)
+		}
+		m.recordCycle(0)
 		return nil, fmt.Errorf("failed to pass challenge after %d retries", maxChallengeRetries)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.State.RecordExample(prompt, req.ChallengeAnswer)
+	if req.ChallengeAnswer != "" {
+		m.recordAnswer(true)
+		m.recordCycle(resp.CWEarned)
+	}
 
 	// Save next challenge for the next iteration
 	if resp.NextChallenge != nil {
@@ -378,6 +690,53 @@ func (m *Miner) mineOnce(ctx context.Context) (*api.InscribeResponse, error) {
 	return resp, nil
 }
 
+// RetryLimits returns the challenge and LLM retry limits used by the loop.
+func RetryLimits() (challenge, llm int) {
+	return maxChallengeRetries, maxLLMRetries
+}
+
+// isChallengeError reports whether err is a challenge error that should be
+// answered and resubmitted.
+func isChallengeError(err error) bool {
+	apiErr, ok := api.AsAPIError(err)
+	return ok && apiErr.IsChallenge()
+}
+
+// submit sends an inscribe request, records submit/cycle latency and adds
+// the attempt to the history. Server error responses still count as
+// completed round trips. prompt is the challenge the answer is for.
+func (m *Miner) submit(ctx context.Context, req *api.InscribeRequest, prompt string) (*api.InscribeResponse, error) {
+	start := time.Now()
+	var answerMs int64
+	if req.ChallengeAnswer != "" && !m.answerStart.IsZero() {
+		answerMs = start.Sub(m.answerStart).Milliseconds()
+	}
+	resp, err := m.API.Inscribe(ctx, req)
+	if _, isAPI := api.AsAPIError(err); err != nil && !isAPI {
+		if ctx.Err() == nil {
+			m.recordHistory(req, prompt, answerMs, time.Since(start).Milliseconds(), nil, err)
+		}
+		return nil, err
+	}
+	m.recordHistory(req, prompt, answerMs, time.Since(start).Milliseconds(), resp, err)
+	m.State.RecordLatency(PhaseSubmit, time.Since(start))
+	if req.ChallengeAnswer != "" && !m.answerStart.IsZero() {
+		m.State.RecordLatency(PhaseCycle, time.Since(m.answerStart))
+	}
+	m.answerStart = time.Time{}
+	return resp, err
+}
+
+// recordLLMFailure adds a challenge the LLM couldn't answer to the history.
+// Answers abandoned at shutdown are not failures.
+func (m *Miner) recordLLMFailure(ctx context.Context, req *api.InscribeRequest, challenge *api.Challenge, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	attempt := api.InscribeRequest{TokenID: req.TokenID, SessionID: req.SessionID, ChallengeID: challenge.ID}
+	m.recordHistory(&attempt, challenge.Prompt, 0, 0, nil, err)
+}
+
 func (m *Miner) answerChallenge(ctx context.Context, challenge *api.Challenge) (string, error) {
 	DisplayChallenge(challenge.Prompt)
 	display := challenge.Prompt
@@ -386,19 +745,81 @@ func (m *Miner) answerChallenge(ctx context.Context, challenge *api.Challenge) (
 	}
 	m.emit("challenge", display, nil)
 
+	ttl := time.Duration(challenge.ExpiresIn) * time.Second
+	if ttl <= 0 {
+		ttl = defaultChallengeTTL
+	}
+	m.answerBy = time.Now().Add(ttl)
+
+	prompt := m.arm.wrap(challenge.Prompt)
+	if header := m.contextHeader(); header != "" {
+		prompt = header + "\n\n" + prompt
+	}
+	if instr := languageInstruction(m.AnswerLanguage, challenge.Prompt); instr != "" {
+		prompt += "\n\n" + instr
+		slog.Debug("challenge language", "policy", m.AnswerLanguage, "instruction", instr)
+	}
+
+	strategy := m.Strategy
+	if m.arm != nil && m.arm.Strategy != nil {
+		strategy = m.arm.Strategy
+	}
+	if strategy == nil {
+		strategy = SingleShot{}
+	}
+	if m.highStakes() && strategy.Name() != StrategyBestOfK {
+		strategy = BestOfK{K: m.Candidates, Temperature: m.CandidateTemperature}
+	}
+	env := Env{
+		Ask: m.ask,
+		Note: func(msg string, data map[string]any) {
+			slog.Info(msg, "strategy", strategy.Name())
+			m.emit("answer", msg, data)
+		},
+		State: m.State,
+	}
+	answer, err := strategy.Answer(ctx, Question{Challenge: challenge.Prompt, Prompt: prompt}, env)
+	if err != nil {
+		return "", err
+	}
+	return m.enforceFormat(ctx, challenge.Prompt, prompt, answer), nil
+}
+
+// ask runs prompt through the LLM, retrying failures and empty answers
+// and waiting out an open circuit breaker — but only while the challenge
+// would still be answerable when the breaker next lets a call through.
+func (m *Miner) ask(ctx context.Context, prompt string) (string, error) {
 	var lastErr error
 	for attempt := 0; attempt < maxLLMRetries; attempt++ {
 		if attempt > 0 {
-			slog.Debug("LLM retry", "attempt", attempt+1)
-			if !sleep(ctx, llmRetryDelay) {
+			delay := llm.RetryDelay(lastErr, llmRetryDelay)
+			slog.Debug("LLM retry", "attempt", attempt+1, "delay", delay)
+			if !sleep(ctx, delay) {
 				return "", fmt.Errorf("cancelled")
 			}
 		}
 
 		start := time.Now()
-		answer, err := m.LLM.Answer(ctx, challenge.Prompt)
+		answer, err := m.callLLM(ctx, prompt)
 		elapsed := time.Since(start)
 
+		var open *llm.CircuitOpenError
+		if errors.As(err, &open) {
+			// Provider is known to be down: wait for the next probe
+			// without spending an attempt or logging another failure.
+			if m.Ctrl != nil && m.Ctrl.IsPaused() {
+				return "", err
+			}
+			wait := llm.RetryDelay(err, llmRetryDelay)
+			if time.Now().Add(wait).After(m.answerBy) {
+				return "", fmt.Errorf("challenge expires before the LLM is retried: %w", err)
+			}
+			if !sleep(ctx, wait) {
+				return "", fmt.Errorf("cancelled")
+			}
+			attempt--
+			continue
+		}
 		if err != nil {
 			lastErr = err
 			slog.Warn("LLM call failed", "attempt", attempt+1, "error", err)
@@ -411,6 +832,7 @@ func (m *Miner) answerChallenge(ctx context.Context, challenge *api.Challenge) (
 			continue
 		}
 
+		m.State.RecordLatency(PhaseLLM, elapsed)
 		DisplayLLMAnswer(elapsed)
 		m.emit("answer", fmt.Sprintf("LLM answered (%.1fs)", elapsed.Seconds()), nil)
 		slog.Info("LLM answer", "len", len(answer), "elapsed", elapsed)
@@ -421,6 +843,63 @@ func (m *Miner) answerChallenge(ctx context.Context, challenge *api.Challenge) (
 	return "", fmt.Errorf("LLM failed after %d attempts: %w", maxLLMRetries, lastErr)
 }
 
+// callLLM runs one answer attempt under AnswerTimeout, emitting "thinking"
+// countdown events so the console shows a slow model is still working.
+func (m *Miner) callLLM(ctx context.Context, prompt string) (string, error) {
+	provider := m.LLM
+	if m.arm != nil && m.arm.LLM != nil {
+		provider = m.arm.LLM
+	}
+	timeout := m.AnswerTimeout
+	if timeout <= 0 {
+		return provider.Answer(ctx, prompt)
+	}
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+	go m.countdown(done, timeout)
+
+	answer, err := provider.Answer(actx, prompt)
+	if err != nil && ctx.Err() == nil && errors.Is(actx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("LLM answer timed out after %s (raise llm.answer_timeout_seconds for slow thinking models)", timeout)
+	}
+	return answer, err
+}
+
+// countdown emits elapsed/remaining time every thinkingInterval until done.
+func (m *Miner) countdown(done <-chan struct{}, timeout time.Duration) {
+	start := time.Now()
+	t := time.NewTicker(thinkingInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			elapsed := int(time.Since(start).Seconds())
+			total := int(timeout.Seconds())
+			m.emit("thinking", fmt.Sprintf("LLM thinking... %ds elapsed, %ds left", elapsed, max(total-elapsed, 0)),
+				map[string]any{"elapsed": elapsed, "timeout": total})
+		}
+	}
+}
+
+// llmHealthChanged reports circuit breaker transitions to the terminal and console.
+func (m *Miner) llmHealthChanged(h llm.Health) {
+	if h.Degraded {
+		msg := "LLM degraded: " + h.Reason
+		DisplayError(msg + " — pausing LLM calls, probing every minute")
+		slog.Warn("LLM circuit breaker tripped", "reason", h.Reason)
+		m.emit("llm", msg, map[string]any{"degraded": true, "reason": h.Reason})
+		return
+	}
+	slog.Info("LLM recovered")
+	fmt.Printf("[%s] LLM recovered\n", time.Now().Format("15:04:05"))
+	m.emit("llm", "LLM recovered", map[string]any{"degraded": false})
+}
+
 // ── Version Gating ──
 
 func (m *Miner) checkVersion(resp *api.InscribeResponse) {
@@ -456,6 +935,30 @@ func (m *Miner) checkSpecUpdate(resp *api.InscribeResponse) {
 	}
 }
 
+// checkKnowledge picks up soul and knowledge file changes made since the
+// last cycle and rebuilds the system prompt once for all of them.
+func (m *Miner) checkKnowledge() {
+	if m.KnowledgeWatch == nil || m.Knowledge == nil {
+		return
+	}
+	changes := m.KnowledgeWatch.Poll(m.Knowledge)
+	reloaded := false
+	for _, c := range changes {
+		if c.Error != "" {
+			slog.Warn("knowledge reload failed", "layer", c.Layer, "error", c.Error)
+		} else {
+			reloaded = true
+		}
+	}
+	if reloaded && m.Reprompt != nil {
+		m.Reprompt(m.Knowledge)
+	}
+	for _, c := range changes {
+		fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), c.Message)
+		m.emit("knowledge", c.Message, c)
+	}
+}
+
 // compareVersions compares semver strings. Returns -1, 0, or 1.
 func compareVersions(a, b string) int {
 	a = strings.TrimPrefix(a, "v")
@@ -482,8 +985,23 @@ func compareVersions(a, b string) int {
 
 // ── Error Handling ──
 
-func handleFatalError(resp *api.InscribeResponse) error {
-	switch resp.Error {
+// fatal stops mining on an error no retry fixes. It is emitted as an alert
+// first, so whoever isn't watching the terminal hears about it. An outdated
+// client with SelfUpdate tries to update instead.
+func (m *Miner) fatal(e *api.APIError) error {
+	if e.Code == "UPGRADE_REQUIRED" && m.selfUpdate(true) {
+		return ErrUpdated
+	}
+	kind := "fatal"
+	if e.Code == "ALREADY_MINING" {
+		kind = "session_conflict"
+	}
+	m.emit("alert", fmt.Sprintf("Mining stopped: %s — %s", e.Code, e.Message), map[string]any{"kind": kind, "code": e.Code})
+	return handleFatalError(e)
+}
+
+func handleFatalError(e *api.APIError) error {
+	switch e.Code {
 	case "NOT_CLAIMED":
 		fmt.Println("\nYour agent has not been claimed by an owner yet.")
 		fmt.Println("  1. Open https://work.clawplaza.ai/my-agent and generate a claim code")
@@ -497,21 +1015,55 @@ func handleFatalError(resp *api.InscribeResponse) error {
 		return fmt.Errorf("invalid API key")
 	case "ALREADY_MINING":
 		fmt.Println("\nThis agent already has an active session.")
-		fmt.Println("Stop the other instance first, or wait for it to expire.")
+		fmt.Println("Stop the other instance first, or wait for it to expire (~1 hour).")
+		fmt.Println("To take it over automatically, run: clawwork insc --takeover")
 		return fmt.Errorf("already active in another session")
 	case "UPGRADE_REQUIRED":
-		fmt.Printf("\nClawWork version too old. Minimum: %s\n", resp.MinClientVersion)
-		if resp.UpgradeURL != "" {
-			fmt.Printf("Download: %s\n", resp.UpgradeURL)
+		fmt.Println("\nThis ClawWork version is no longer supported.")
+		if r := e.Inscribe; r != nil {
+			if r.MinClientVersion != "" {
+				fmt.Printf("Minimum required: %s\n", r.MinClientVersion)
+			}
+			if r.UpgradeURL != "" {
+				fmt.Printf("Download: %s\n", r.UpgradeURL)
+			}
 		}
 		return fmt.Errorf("upgrade required")
 	default:
-		return fmt.Errorf("fatal error: %s — %s", resp.Error, resp.Message)
+		return fmt.Errorf("fatal error: %s — %s", e.Code, e.Message)
 	}
 }
 
 // ── Utilities ──
 
+// withGrace returns a context that is cancelled grace after parent is,
+// rather than immediately, or as soon as abort is closed. onShutdown runs
+// once when parent is cancelled while the returned context is still in use.
+func withGrace(parent context.Context, grace time.Duration, abort <-chan struct{}, onShutdown func()) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-parent.Done():
+		}
+		if grace > 0 {
+			if onShutdown != nil {
+				onShutdown()
+			}
+			t := time.NewTimer(grace)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-abort:
+			case <-ctx.Done():
+			}
+		}
+		cancel()
+	}()
+	return ctx, cancel
+}
+
 // shortID returns a safe prefix of a challenge/session ID for logging.
 func shortID(id string) string {
 	if len(id) > 8 {
@@ -0,0 +1,92 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/schedule"
+)
+
+// scheduleTick is how often a miner sleeping outside its active hours
+// wakes to re-read the schedule, which a config reload may change.
+const scheduleTick = time.Minute
+
+// Pacing is the [schedule] section as the loop applies it.
+type Pacing struct {
+	Cooldown time.Duration     // wait after each inscription; 0 means the platform's 30 minutes
+	Hours    schedule.Schedule // active hours; empty means always
+}
+
+// SetPacing changes the cooldown and active hours, also while Run is
+// going (config reload). The cooldown is recorded in state so status
+// and quotas elsewhere use it.
+func (m *Miner) SetPacing(p Pacing) {
+	m.pacing.Store(&p)
+	m.State.SetCooldown(p.Cooldown)
+}
+
+// cooldown returns the wait after each inscription.
+func (m *Miner) cooldown() time.Duration {
+	if p := m.pacing.Load(); p != nil && p.Cooldown > 0 {
+		return p.Cooldown
+	}
+	return defaultCooldown * time.Second
+}
+
+// waitActiveHours sleeps while now is outside the active hours, reporting
+// when mining resumes. Returns false if ctx ends first.
+func (m *Miner) waitActiveHours(ctx context.Context) bool {
+	slept := false
+	for {
+		p := m.pacing.Load()
+		if p == nil || p.Hours.Active(time.Now()) {
+			break
+		}
+		until := p.Hours.Next(time.Now())
+		if until.IsZero() {
+			break
+		}
+		if !slept {
+			msg := fmt.Sprintf("Outside active hours (%s) — sleeping until %s", p.Hours, until.Format("Jan 2 15:04"))
+			fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+			m.emit("schedule", msg, map[string]any{"active": false, "until": until, "hours": p.Hours.String(),
+				"seconds": int(time.Until(until).Seconds())})
+			slept = true
+		}
+		m.State.SetOffHours(until)
+		if !sleep(ctx, minDuration(time.Until(until), scheduleTick)) {
+			return false
+		}
+	}
+	if slept {
+		m.State.SetOffHours(time.Time{})
+		msg := "Active hours started — mining resumed"
+		fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+		m.emit("schedule", msg, map[string]any{"active": true})
+	}
+	return true
+}
+
+// SetCooldown records the miner's cooldown (0: the default).
+func (s *State) SetCooldown(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CooldownSeconds = int(d.Seconds())
+}
+
+// SetOffHours records until when the miner sleeps outside its active
+// hours; zero clears it.
+func (s *State) SetOffHours(until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.OffHoursUntil = until
+}
+
+// cooldownLocked is the recorded cooldown. s.mu must be held.
+func (s *State) cooldownLocked() time.Duration {
+	if s.CooldownSeconds > 0 {
+		return time.Duration(s.CooldownSeconds) * time.Second
+	}
+	return defaultCooldown * time.Second
+}
@@ -0,0 +1,106 @@
+package miner
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// Penalty kinds.
+const (
+	PenaltyChallenge = "challenge" // failed challenge answer
+	PenaltyIP        = "ip"        // inscription paid out under an IP multiplier
+)
+
+// maxPenalties is how many penalty events are kept in state. The totals
+// in LostCW and PenaltyCounts cover every penalty ever recorded.
+const maxPenalties = 100
+
+// Penalty is one penalty event. LostCW is exact for IP penalties (base
+// minus actual payout). The platform does not report challenge deductions,
+// so for those it is the CW of the last successful inscription — what the
+// failed attempt would have earned — with Estimated set, and TrustLost is
+// filled in when the error carried a trust score.
+type Penalty struct {
+	At        time.Time `json:"at"`
+	Kind      string    `json:"kind"`
+	TokenID   int       `json:"token_id,omitempty"`
+	LostCW    int       `json:"lost_cw"`
+	Estimated bool      `json:"estimated,omitempty"` // LostCW is a guess, not a reported deduction
+	TrustLost int       `json:"trust_lost,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// RecordPenalty appends p to the ledger and adds it to the running totals.
+func (s *State) RecordPenalty(p Penalty) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.LostCW == nil {
+		s.LostCW = make(map[string]int64)
+	}
+	if s.PenaltyCounts == nil {
+		s.PenaltyCounts = make(map[string]int)
+	}
+	s.LostCW[p.Kind] += int64(p.LostCW)
+	s.PenaltyCounts[p.Kind]++
+	s.Penalties = append(s.Penalties, p)
+	if n := len(s.Penalties); n > maxPenalties {
+		s.Penalties = s.Penalties[n-maxPenalties:]
+	}
+}
+
+// ChallengePenalty builds the ledger entry for a failed challenge.
+// apiErr may carry the post-penalty trust score.
+func (s *State) ChallengePenalty(tokenID int, apiErr *api.APIError, now time.Time) Penalty {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := Penalty{At: now, Kind: PenaltyChallenge, TokenID: tokenID, Detail: apiErr.Message}
+	if n := len(s.Earnings); n > 0 {
+		p.LostCW, p.Estimated = s.Earnings[n-1].CW, true
+	}
+	if r := apiErr.Inscribe; r != nil && r.TrustScore > 0 && s.LastTrustScore > r.TrustScore {
+		p.TrustLost = s.LastTrustScore - r.TrustScore
+	}
+	return p
+}
+
+// IPPenalty builds the ledger entry for an inscription paid under an IP
+// multiplier, or returns false if resp carried no penalty.
+func IPPenalty(tokenID int, resp *api.InscribeResponse, now time.Time) (Penalty, bool) {
+	ip := resp.IPPenalty
+	if ip == nil || ip.IPMultiplier <= 1 {
+		return Penalty{}, false
+	}
+	return Penalty{
+		At:      now,
+		Kind:    PenaltyIP,
+		TokenID: tokenID,
+		LostCW:  max(ip.CWBase-ip.CWActual, 0),
+		Detail:  fmt.Sprintf("%dx multiplier, %d agents on IP", ip.IPMultiplier, ip.AgentsOnIP),
+	}, true
+}
+
+// PenaltySummary totals the penalty ledger.
+type PenaltySummary struct {
+	LostCW int64            `json:"lost_cw"`
+	ByKind map[string]int64 `json:"by_kind,omitempty"`
+	Counts map[string]int   `json:"counts,omitempty"`
+	Recent []Penalty        `json:"recent,omitempty"`
+}
+
+// PenaltySummary returns the ledger totals and up to n of the newest events,
+// newest first.
+func (s *State) PenaltySummary(n int) PenaltySummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sum := PenaltySummary{ByKind: maps.Clone(s.LostCW), Counts: maps.Clone(s.PenaltyCounts)}
+	for _, cw := range s.LostCW {
+		sum.LostCW += cw
+	}
+	for i := len(s.Penalties) - 1; i >= 0 && len(sum.Recent) < n; i-- {
+		sum.Recent = append(sum.Recent, s.Penalties[i])
+	}
+	return sum
+}
@@ -0,0 +1,121 @@
+package miner
+
+import (
+	"time"
+)
+
+// maxPeriods bounds the closed reporting periods kept in state.
+const maxPeriods = 20
+
+// Counters are inscription totals over some span: the current run, today,
+// a named reporting period, or the agent's lifetime on this machine.
+type Counters struct {
+	Inscriptions     int   `json:"inscriptions"`
+	CWEarned         int64 `json:"cw_earned"`
+	Hits             int   `json:"hits"`
+	ChallengesPassed int   `json:"challenges_passed"`
+	ChallengesFailed int   `json:"challenges_failed"`
+}
+
+func (c *Counters) addInscription(cw int, hit bool) {
+	c.Inscriptions++
+	c.CWEarned += int64(cw)
+	if hit {
+		c.Hits++
+	}
+	c.ChallengesPassed++
+}
+
+// Period is a named reporting period. `clawwork stats reset` closes the
+// current one and starts the next; lifetime totals are never reset.
+type Period struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitempty"`
+	Counters
+}
+
+// Stats is every view of the counters at one moment.
+type Stats struct {
+	Run      Counters  `json:"run"`
+	RunStart time.Time `json:"run_start,omitempty"`
+	Today    Counters  `json:"today"`
+	Period   *Period   `json:"period,omitempty"` // nil until the first reset
+	Lifetime Counters  `json:"lifetime"`
+}
+
+// dayKey is the local calendar day the daily counters belong to.
+func dayKey(t time.Time) string { return t.Local().Format("2006-01-02") }
+
+// counting applies f to every counter set that is still accumulating,
+// rolling the daily counters over at local midnight. Callers hold s.mu.
+func (s *State) counting(now time.Time, f func(c *Counters)) {
+	if day := dayKey(now); s.TodayDate != day {
+		s.TodayDate, s.Today = day, Counters{}
+	}
+	f(&s.run)
+	f(&s.Today)
+	if s.Period != nil {
+		f(&s.Period.Counters)
+	}
+}
+
+// StartRun zeroes the counters of the current run (one miner process).
+func (s *State) StartRun(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.run, s.runStart = Counters{}, now
+}
+
+// Stats returns the run, daily, period and lifetime counters.
+func (s *State) Stats(now time.Time) Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Stats{
+		Run:      s.run,
+		RunStart: s.runStart,
+		Lifetime: Counters{
+			Inscriptions:     s.TotalInscriptions,
+			CWEarned:         s.TotalCWEarned,
+			Hits:             s.TotalHits,
+			ChallengesPassed: s.ChallengesPassed,
+			ChallengesFailed: s.ChallengesFailed,
+		},
+	}
+	if s.TodayDate == dayKey(now) {
+		st.Today = s.Today
+	}
+	if s.Period != nil {
+		p := *s.Period
+		st.Period = &p
+	}
+	return st
+}
+
+// ResetPeriod closes the current reporting period and starts a new one
+// called name (the date if empty). The closed period is returned, nil if
+// there was none.
+func (s *State) ResetPeriod(name string, now time.Time) *Period {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name == "" {
+		name = dayKey(now)
+	}
+	closed := s.Period
+	if closed != nil {
+		closed.End = now
+		s.Periods = append(s.Periods, *closed)
+		if n := len(s.Periods); n > maxPeriods {
+			s.Periods = s.Periods[n-maxPeriods:]
+		}
+	}
+	s.Period = &Period{Name: name, Start: now}
+	return closed
+}
+
+// ClosedPeriods returns the finished reporting periods, oldest first.
+func (s *State) ClosedPeriods() []Period {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Period(nil), s.Periods...)
+}
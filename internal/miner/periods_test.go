@@ -0,0 +1,38 @@
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+func TestStatsPeriods(t *testing.T) {
+	s := &State{}
+	s.StartRun(time.Now())
+	s.Update(&api.InscribeResponse{CWEarned: 100})
+	s.ResetPeriod("week-1", time.Now())
+	s.Update(&api.InscribeResponse{CWEarned: 50, Hit: true})
+	s.RecordChallengeFail(ChallengeFailure{At: time.Now()})
+
+	st := s.Stats(time.Now())
+	if st.Lifetime.CWEarned != 150 || st.Run.CWEarned != 150 || st.Today.CWEarned != 150 {
+		t.Errorf("lifetime/run/today = %d/%d/%d, want 150 each", st.Lifetime.CWEarned, st.Run.CWEarned, st.Today.CWEarned)
+	}
+	if st.Period == nil || st.Period.CWEarned != 50 || st.Period.Hits != 1 || st.Period.ChallengesFailed != 1 {
+		t.Errorf("period = %+v, want 50 CW, 1 hit, 1 failure", st.Period)
+	}
+
+	// Tomorrow the daily counters start over; nothing else does.
+	if st := s.Stats(time.Now().Add(24 * time.Hour)); st.Today != (Counters{}) || st.Lifetime.CWEarned != 150 {
+		t.Errorf("next day: today %+v lifetime %d", st.Today, st.Lifetime.CWEarned)
+	}
+
+	closed := s.ResetPeriod("", time.Now())
+	if closed == nil || closed.Name != "week-1" || closed.End.IsZero() || len(s.ClosedPeriods()) != 1 {
+		t.Errorf("closed = %+v", closed)
+	}
+	if p := s.Stats(time.Now()).Period; p.Name != dayKey(time.Now()) || p.Inscriptions != 0 {
+		t.Errorf("new period = %+v", p)
+	}
+}
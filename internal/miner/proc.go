@@ -0,0 +1,28 @@
+//go:build !windows
+
+package miner
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive checks whether a PID is still running.
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal 0 tests existence without actually sending a signal.
+	return proc.Signal(syscall.Signal(0)) == nil
+}
+
+// Stop asks the lock holder to shut down gracefully (SIGTERM), as a
+// service manager would.
+func (l *LockInfo) Stop() error {
+	proc, err := os.FindProcess(l.PID)
+	if err != nil {
+		return err
+	}
+	return proc.Signal(syscall.SIGTERM)
+}
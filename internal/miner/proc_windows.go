@@ -0,0 +1,29 @@
+package miner
+
+import (
+	"errors"
+	"syscall"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a running
+// process.
+const stillActive = 259
+
+// processAlive checks whether a PID is still running. Windows processes
+// can't be signalled, so the process is opened and its exit code checked.
+func processAlive(pid int) bool {
+	const queryLimitedInformation = 0x1000
+	h, err := syscall.OpenProcess(queryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	return syscall.GetExitCodeProcess(h, &code) == nil && code == stillActive
+}
+
+// Stop fails on Windows: another process can't be asked to shut down
+// gracefully, and killing it would abandon the inscription in flight.
+func (l *LockInfo) Stop() error {
+	return errors.New("not supported on Windows; press Ctrl+C in the miner's window, then try again")
+}
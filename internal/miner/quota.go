@@ -0,0 +1,125 @@
+package miner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Quota names. Social modules appear as "social.<module>", tokens in
+// multi-token mode as "token.<id>".
+const (
+	QuotaInscribe = "inscribe"       // the per-agent cooldown between inscriptions
+	QuotaDaily    = "inscribe.daily" // DAILY_LIMIT_REACHED
+)
+
+// Limit is a platform limit the miner ran into, persisted so restarts and
+// other commands know when it lifts.
+type Limit struct {
+	Until time.Time `json:"until"`
+	Code  string    `json:"code,omitempty"` // platform error code, e.g. RATE_LIMITED
+	At    time.Time `json:"at"`             // when it was hit
+}
+
+// Quota is one limit in the unified view behind /quotas and `clawwork
+// status`: what it is, whether it blocks right now and when it resets.
+type Quota struct {
+	Name   string    `json:"name"`
+	Label  string    `json:"label"`
+	Until  time.Time `json:"until,omitempty"` // zero or past: available now
+	Reason string    `json:"reason,omitempty"`
+	Source string    `json:"source"` // "platform" (reported by the server) or "schedule" (the client's own pacing)
+}
+
+// Available reports whether the quota does not block at now.
+func (q Quota) Available(now time.Time) bool { return !q.Until.After(now) }
+
+// Remaining is how long the quota still blocks, or zero.
+func (q Quota) Remaining(now time.Time) time.Duration { return max(q.Until.Sub(now), 0) }
+
+// RecordLimit notes a limit reported by the platform and persists it.
+func (s *State) RecordLimit(name, code string, until time.Time) error {
+	s.mu.Lock()
+	if s.Limits == nil {
+		s.Limits = make(map[string]Limit)
+	}
+	now := time.Now()
+	s.Limits[name] = Limit{Until: until, Code: code, At: now}
+	for n, l := range s.Limits {
+		if l.Until.Before(now.Add(-24 * time.Hour)) {
+			delete(s.Limits, n)
+		}
+	}
+	s.mu.Unlock()
+	return s.Save()
+}
+
+// Quotas collects every known limit: the inscription cooldown, daily and
+// rate limits, per-token cooldowns and social cooldowns. Quotas that are
+// available now are included with a zero or past Until, so callers can
+// show "ready" as well. The result is sorted by name.
+func (s *State) Quotas(now time.Time) []Quota {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byName := make(map[string]Quota)
+	add := func(q Quota) {
+		// Several signals can cover one quota; the latest deadline wins.
+		if prev, ok := byName[q.Name]; !ok || q.Until.After(prev.Until) {
+			byName[q.Name] = q
+		}
+	}
+
+	inscribe := Quota{Name: QuotaInscribe, Label: quotaLabel(QuotaInscribe), Source: "schedule"}
+	if len(s.Rotation) == 0 && !s.LastMineAt.IsZero() {
+		inscribe.Until = s.LastMineAt.Add(s.cooldownLocked())
+		inscribe.Reason = "cooldown"
+	}
+	add(inscribe)
+	if !s.OffHoursUntil.IsZero() {
+		add(Quota{Name: QuotaInscribe, Label: quotaLabel(QuotaInscribe), Until: s.OffHoursUntil, Reason: "active_hours", Source: "schedule"})
+	}
+	add(Quota{Name: QuotaDaily, Label: quotaLabel(QuotaDaily), Source: "platform"})
+
+	for _, id := range s.Rotation {
+		name := fmt.Sprintf("token.%d", id)
+		q := Quota{Name: name, Label: quotaLabel(name), Source: "schedule"}
+		if ts := s.Tokens[id]; ts != nil {
+			q.Until = ts.CooldownUntil
+			q.Reason = "cooldown"
+		}
+		add(q)
+	}
+	for module, until := range s.SocialCooldowns {
+		name := "social." + module
+		add(Quota{Name: name, Label: quotaLabel(name), Until: until, Reason: "COOLDOWN", Source: "platform"})
+	}
+	for name, l := range s.Limits {
+		add(Quota{Name: name, Label: quotaLabel(name), Until: l.Until, Reason: l.Code, Source: "platform"})
+	}
+
+	out := make([]Quota, 0, len(byName))
+	for _, q := range byName {
+		if strings.HasPrefix(q.Name, "social.") && !q.Until.After(now) {
+			continue // social modules are only listed while they block
+		}
+		out = append(out, q)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func quotaLabel(name string) string {
+	switch {
+	case name == QuotaInscribe:
+		return "Inscription cooldown"
+	case name == QuotaDaily:
+		return "Daily inscription limit"
+	case strings.HasPrefix(name, "token."):
+		return "Token #" + strings.TrimPrefix(name, "token.") + " cooldown"
+	case strings.HasPrefix(name, "social."):
+		return "Social: " + strings.TrimPrefix(name, "social.")
+	}
+	return name
+}
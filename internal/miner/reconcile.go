@@ -0,0 +1,101 @@
+package miner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// DayEarnings is one local calendar day of the inscription history.
+type DayEarnings struct {
+	Date     string `json:"date"` // 2006-01-02
+	Attempts int    `json:"attempts"`
+	Accepted int    `json:"accepted"` // ok or hit
+	Rejected int    `json:"rejected"`
+	Errors   int    `json:"errors"`
+	CWEarned int64  `json:"cw_earned"`
+	ZeroCW   int    `json:"zero_cw,omitempty"` // accepted without CW
+}
+
+// Totals are inscription and CW totals from one side of a reconciliation.
+type Totals struct {
+	Inscriptions int   `json:"inscriptions"`
+	Confirmed    int   `json:"confirmed,omitempty"` // platform only
+	CWEarned     int64 `json:"cw_earned"`
+}
+
+// Reconciliation compares what this machine recorded with what the
+// platform credited.
+type Reconciliation struct {
+	Days     []DayEarnings `json:"days"`
+	Local    Totals        `json:"local"`    // lifetime, from state.json
+	Platform Totals        `json:"platform"` // from the platform status
+
+	// Discrepancies are findings worth raising with the platform; Notes
+	// explain differences that are expected.
+	Discrepancies []string `json:"discrepancies"`
+	Notes         []string `json:"notes,omitempty"`
+}
+
+// Reconcile builds the per-day breakdown of entries (in loc) and compares
+// the local lifetime counters with the platform's totals.
+func Reconcile(entries []HistoryEntry, lifetime Counters, st *api.StatusResponse, loc *time.Location) Reconciliation {
+	r := Reconciliation{
+		Days:          []DayEarnings{},
+		Discrepancies: []string{},
+		Local:         Totals{Inscriptions: lifetime.Inscriptions, CWEarned: lifetime.CWEarned},
+		Platform: Totals{Inscriptions: st.Inscriptions.Total, Confirmed: st.Inscriptions.Confirmed,
+			CWEarned: int64(st.Inscriptions.TotalCW)},
+	}
+
+	index := make(map[string]int)
+	for _, e := range entries {
+		date := e.At.In(loc).Format("2006-01-02")
+		i, ok := index[date]
+		if !ok {
+			i = len(r.Days)
+			index[date] = i
+			r.Days = append(r.Days, DayEarnings{Date: date})
+		}
+		d := &r.Days[i]
+		d.Attempts++
+		switch e.Outcome {
+		case "ok", "hit":
+			d.Accepted++
+			d.CWEarned += int64(e.CWEarned)
+			if e.CWEarned == 0 {
+				d.ZeroCW++
+			}
+		case "rejected":
+			d.Rejected++
+		case "error":
+			d.Errors++
+		}
+	}
+
+	flag := func(format string, args ...any) {
+		r.Discrepancies = append(r.Discrepancies, fmt.Sprintf(format, args...))
+	}
+	for _, d := range r.Days {
+		if d.ZeroCW > 0 {
+			flag("%s: %d accepted inscription(s) earned no CW", d.Date, d.ZeroCW)
+		}
+	}
+	if n := r.Platform.Inscriptions - r.Platform.Confirmed; n > 0 {
+		flag("%d inscription(s) not confirmed by the platform yet", n)
+	}
+	if diff := r.Local.CWEarned - r.Platform.CWEarned; diff > 0 {
+		flag("The platform credits %d CW, %d less than the %d CW recorded here", r.Platform.CWEarned, diff, r.Local.CWEarned)
+	}
+	if diff := r.Local.Inscriptions - r.Platform.Inscriptions; diff > 0 {
+		flag("The platform counts %d inscription(s), %d fewer than the %d recorded here", r.Platform.Inscriptions, diff, r.Local.Inscriptions)
+	}
+	if r.Platform.CWEarned > r.Local.CWEarned || r.Platform.Inscriptions > r.Local.Inscriptions {
+		r.Notes = append(r.Notes, "The platform totals are higher than this machine's: other machines, earlier installs or a reset state also mined for this agent")
+	}
+	if len(entries) > 0 {
+		r.Notes = append(r.Notes, fmt.Sprintf("The per-day breakdown starts at %s, the oldest history entry in range", entries[0].At.In(loc).Format("2006-01-02 15:04")))
+	}
+	return r
+}
@@ -0,0 +1,34 @@
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+func TestReconcile(t *testing.T) {
+	day1 := time.Date(2026, 10, 15, 23, 30, 0, 0, time.UTC)
+	day2 := day1.Add(time.Hour) // next day in UTC
+	entries := []HistoryEntry{
+		{At: day1, Outcome: "ok", CWEarned: 100},
+		{At: day1, Outcome: "rejected", Code: "CHALLENGE_FAILED"},
+		{At: day2, Outcome: "ok"},
+		{At: day2, Outcome: "error"},
+	}
+	st := &api.StatusResponse{Inscriptions: api.StatusInscriptions{Total: 2, Confirmed: 1, TotalCW: 60}}
+	r := Reconcile(entries, Counters{Inscriptions: 2, CWEarned: 100}, st, time.UTC)
+
+	if len(r.Days) != 2 || r.Days[0].CWEarned != 100 || r.Days[0].Rejected != 1 || r.Days[1].ZeroCW != 1 || r.Days[1].Errors != 1 {
+		t.Fatalf("days = %+v", r.Days)
+	}
+	// zero CW on day 2, one unconfirmed, 40 CW missing
+	if len(r.Discrepancies) != 3 {
+		t.Errorf("discrepancies = %q", r.Discrepancies)
+	}
+
+	// A UTC+2 reader sees both entries on the same day.
+	if r := Reconcile(entries, Counters{}, st, time.FixedZone("", 2*3600)); len(r.Days) != 1 {
+		t.Errorf("days in UTC+2 = %+v", r.Days)
+	}
+}
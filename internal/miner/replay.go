@@ -0,0 +1,142 @@
+package miner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// ReplaySummary is the outcome of Replay.
+type ReplaySummary struct {
+	Cycles     int // mineOnce runs
+	Requests   int // inscribe requests answered from the recording
+	Mismatches int // requests whose challenge ID differs from the recording
+	Skipped    int // session end (and repeated session start) exchanges left out
+}
+
+// Replay re-runs inscription cycles against a recording made with
+// `clawwork insc --record`. The platform's side comes from the recorded
+// responses and the LLM's from the answers the recorded requests carried,
+// so the client's handling (challenge retries, error classification) runs
+// as it did live, with no network or LLM calls. Where the client now sends
+// a different challenge ID than it did when recorded, the replay says so.
+func Replay(ctx context.Context, exchanges []api.Exchange) ReplaySummary {
+	var sum ReplaySummary
+	var cycle []api.Exchange
+	var answers []string
+	tokenID := 0
+	startsSession := false
+	for i, ex := range exchanges {
+		req, err := ex.InscribeRequest()
+		if err != nil || req.SessionEnd || (req.SessionStart && i > 0) {
+			sum.Skipped++
+			continue
+		}
+		startsSession = startsSession || req.SessionStart
+		if tokenID == 0 {
+			tokenID = req.TokenID
+		}
+		if req.ChallengeAnswer != "" {
+			answers = append(answers, req.ChallengeAnswer)
+		}
+		cycle = append(cycle, ex)
+	}
+	if len(cycle) == 0 {
+		return sum
+	}
+
+	// The scripted LLM ends the replay when it runs out of answers, rather
+	// than letting answerChallenge back off and retry.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	llm := &scriptedLLM{answers: answers, done: cancel}
+
+	client := api.NewReplayClient(cycle, func(sent api.InscribeRequest, rec *api.Exchange) {
+		if rec == nil {
+			fmt.Println("  → request beyond the end of the recording")
+			return
+		}
+		sum.Requests++
+		want, _ := rec.InscribeRequest()
+		line := fmt.Sprintf("  → #%d challenge=%s answer_len=%d", sum.Requests, shortID(sent.ChallengeID), len(sent.ChallengeAnswer))
+		if sent.ChallengeID != want.ChallengeID {
+			sum.Mismatches++
+			line += fmt.Sprintf("  MISMATCH: recording sent challenge=%s", shortID(want.ChallengeID))
+		}
+		fmt.Printf("%s\n  ← HTTP %d %s\n", line, rec.Status, responseCode(rec))
+	})
+
+	m := &Miner{API: client, LLM: llm, State: &State{}, TokenID: tokenID, replaying: true}
+	if startsSession {
+		fmt.Println("\n── Session start ──")
+		if err := m.startSession(ctx); err != nil {
+			fmt.Printf("Result: %s\n", describeOutcome(nil, err))
+		}
+	}
+	for sum.Requests < len(cycle) && ctx.Err() == nil {
+		before := sum.Requests
+		sum.Cycles++
+		fmt.Printf("\n── Cycle %d ──\n", sum.Cycles)
+		resp, err := m.mineOnce(ctx)
+		if ctx.Err() != nil {
+			fmt.Println("Result: recording ended (no recorded answer for this challenge)")
+			break
+		}
+		fmt.Printf("Result: %s\n", describeOutcome(resp, err))
+		if sum.Requests == before {
+			break // the cycle made no request; nothing left to replay
+		}
+	}
+	return sum
+}
+
+// describeOutcome says what Run would do with a mineOnce result.
+func describeOutcome(resp *api.InscribeResponse, err error) string {
+	if err == nil {
+		if resp.IDStatus == "taken" {
+			return fmt.Sprintf("token #%d taken — would stop", resp.TokenID)
+		}
+		return fmt.Sprintf("inscribed: %d CW, trust %d, hit=%v — would cool down %dm", resp.CWEarned, resp.TrustScore, resp.Hit, defaultCooldown/60)
+	}
+	apiErr, isAPI := api.AsAPIError(err)
+	switch {
+	case isAPI && apiErr.IsFatal():
+		return fmt.Sprintf("%s — fatal, would exit", apiErr.Code)
+	case isAPI && apiErr.IsRateLimited():
+		return fmt.Sprintf("%s — would wait %ds", apiErr.Code, apiErr.RetryAfter)
+	case isAPI:
+		return fmt.Sprintf("%s: %s — would back off and retry", apiErr.Code, apiErr.Message)
+	}
+	return fmt.Sprintf("%s — would back off and retry", err)
+}
+
+func responseCode(ex *api.Exchange) string {
+	if ex.Error != "" {
+		return "transport error: " + ex.Error
+	}
+	var r api.InscribeResponse
+	if err := json.Unmarshal(ex.Response, &r); err != nil || r.Error == "" {
+		return "ok"
+	}
+	return r.Error
+}
+
+// scriptedLLM replays recorded challenge answers in order.
+type scriptedLLM struct {
+	answers []string
+	done    func()
+}
+
+func (s *scriptedLLM) Name() string { return "replay" }
+
+func (s *scriptedLLM) Answer(context.Context, string) (string, error) {
+	if len(s.answers) == 0 {
+		s.done()
+		return "", context.Canceled
+	}
+	a := s.answers[0]
+	s.answers = s.answers[1:]
+	return a, nil
+}
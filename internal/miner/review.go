@@ -0,0 +1,150 @@
+package miner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+// reviewPoll is how often a miner held for review checks for release.
+const reviewPoll = 5 * time.Second
+
+// ReviewHold is written when mining pauses itself after repeated challenge
+// failures. Like the goal it is kept apart from the state so another process
+// (`clawwork insc --resume-after-review`) can clear it while a miner waits.
+type ReviewHold struct {
+	At       time.Time `json:"at"`
+	Failures int       `json:"failures"`
+	Cycles   int       `json:"cycles"`
+}
+
+// LoadReviewHold returns the active hold, or nil if mining is not held.
+func LoadReviewHold() *ReviewHold {
+	data, err := Store().Get(reviewKey)
+	if err != nil {
+		return nil
+	}
+	var h ReviewHold
+	if json.Unmarshal(data, &h) != nil {
+		// An unreadable hold still means someone should look.
+		return &ReviewHold{}
+	}
+	return &h
+}
+
+func saveReviewHold(h *ReviewHold) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return Store().Put(reviewKey, data)
+}
+
+// ClearReviewHold releases a hold. Clearing when none is set is not an error.
+func ClearReviewHold() error {
+	return Store().Delete(reviewKey)
+}
+
+// String describes the hold for terminal output.
+func (h *ReviewHold) String() string {
+	if h.At.IsZero() {
+		return "Mining is paused for review"
+	}
+	return fmt.Sprintf("Mining paused at %s after %d challenge failures in %d cycles",
+		h.At.Local().Format("2006-01-02 15:04"), h.Failures, h.Cycles)
+}
+
+// RecordCycle notes how many challenges failed in one inscription cycle,
+// keeps the last window cycles, and returns the failures among them.
+func (s *State) RecordCycle(failures, window int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CycleFailures = append(s.CycleFailures, failures)
+	if n := len(s.CycleFailures); n > window {
+		s.CycleFailures = s.CycleFailures[n-window:]
+	}
+	total := 0
+	for _, f := range s.CycleFailures {
+		total += f
+	}
+	return total
+}
+
+// resetCycles forgets recent cycle outcomes after a review.
+func (s *State) resetCycles() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CycleFailures = nil
+}
+
+// checkFailures records the cycle just finished and holds mining for
+// review once PauseAfterFailures challenges failed within PauseWindow
+// cycles. It returns false if ctx ended while held.
+func (m *Miner) checkFailures(ctx context.Context) bool {
+	failures := m.cycleFailures
+	m.cycleFailures = 0
+	limit, window := m.Alerts.PauseAfterFailures, m.Alerts.PauseWindow
+	if limit <= 0 || window <= 0 {
+		return true
+	}
+	total := m.State.RecordCycle(failures, window)
+	if total < limit {
+		return true
+	}
+
+	h := &ReviewHold{At: time.Now().UTC(), Failures: total, Cycles: window}
+	if err := saveReviewHold(h); err != nil {
+		slog.Warn("save review hold", "error", err)
+	}
+	_ = m.State.Save()
+	msg := fmt.Sprintf("%d challenge failures in the last %d cycles — mining paused for review", total, window)
+	DisplayAlert(msg)
+	slog.Warn("auto-pause", "failures", total, "cycles", window)
+	m.emit("alert", msg, map[string]any{"kind": "auto_pause", "failures": total, "cycles": window})
+	return m.holdForReview(ctx, h)
+}
+
+// holdForReview blocks while a review hold exists. The hold is released by
+// `clawwork insc --resume-after-review` or by resuming from the console.
+func (m *Miner) holdForReview(ctx context.Context, h *ReviewHold) bool {
+	fmt.Printf("%s.\n", h)
+	fmt.Println("Check recent failures with: clawwork advise")
+	fmt.Println("Then resume with: clawwork insc --resume-after-review")
+
+	pauser, canPause := m.Ctrl.(interface {
+		Pause()
+		Resume()
+	})
+	if canPause {
+		pauser.Pause()
+		m.emit("control", "Mining paused for review — resume from the console once the model is fixed",
+			event.Control{Action: event.ControlPause, Reason: "review"})
+	}
+	for {
+		if !sleep(ctx, reviewPoll) {
+			return false
+		}
+		if LoadReviewHold() == nil {
+			if canPause {
+				pauser.Resume()
+			}
+			break
+		}
+		if canPause && !m.Ctrl.IsPaused() {
+			if err := ClearReviewHold(); err != nil {
+				slog.Warn("clear review hold", "error", err)
+			}
+			break
+		}
+	}
+
+	m.State.resetCycles()
+	_ = m.State.Save()
+	fmt.Println("Review pause cleared — mining resumed.")
+	m.emit("control", "Mining resumed after review", event.Control{Action: event.ControlResume, Reason: "review"})
+	return true
+}
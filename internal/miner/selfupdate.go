@@ -0,0 +1,45 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+// ErrUpdated is returned by Run after SelfUpdate installed a new binary.
+// The caller should restart into it; state is saved, so the new process
+// resumes the cooldown where this one left off.
+var ErrUpdated = errors.New("updated to a new version")
+
+// selfUpdate offers SelfUpdate a chance to install a newer binary, and
+// reports whether it did. force asks for a check ahead of its schedule.
+func (m *Miner) selfUpdate(force bool) bool {
+	if m.SelfUpdate == nil {
+		return false
+	}
+	ver, err := m.SelfUpdate(force)
+	if err != nil {
+		slog.Warn("auto-update failed", "error", err)
+		m.emit("error", fmt.Sprintf("Auto-update failed: %s", err), nil)
+		return false
+	}
+	if ver == "" {
+		return false
+	}
+	_ = m.State.Save()
+	msg := fmt.Sprintf("Updated %s → %s — restarting", m.version, ver)
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+	m.emit("session", msg, event.Session{Action: event.SessionUpdate, Version: ver})
+	return true
+}
+
+// newerClient reports whether the platform knows a newer client than this
+// one.
+func (m *Miner) newerClient(resp *api.InscribeResponse) bool {
+	v := resp.LatestClientVersion
+	return v != "" && m.version != "" && m.version != "dev" && compareVersions(m.version, v) < 0
+}
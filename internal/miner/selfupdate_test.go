@@ -0,0 +1,84 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/devserver"
+)
+
+type fixedAnswer string
+
+func (a fixedAnswer) Answer(context.Context, string) (string, error) { return string(a), nil }
+func (a fixedAnswer) Name() string                                   { return "fixed" }
+
+// runWithUpdate runs one miner against the mock platform with update as
+// its SelfUpdate, until ctx ends or Run returns. It reports the event
+// types seen, with "update" where SelfUpdate was called, and Run's error.
+func runWithUpdate(t *testing.T, update func(force bool) (string, error)) ([]string, error) {
+	t.Helper()
+	t.Setenv("CLAWWORK_HOME", t.TempDir())
+	platform := devserver.New(devserver.Behavior{Puzzles: []devserver.Puzzle{{Prompt: "What is 6 x 7?", Answer: "42"}}}, io.Discard)
+	srv := httptest.NewServer(platform.Handler())
+	defer srv.Close()
+	defer func(url string) { api.BaseURL = url }(api.BaseURL)
+	api.BaseURL = srv.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var mu sync.Mutex
+	var seen []string
+	note := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, s)
+	}
+	m := &Miner{API: api.New("test-key"), LLM: fixedAnswer("42"), State: LoadState(), TokenID: 7}
+	m.SetVersion("1.0.0")
+	m.OnEvent = func(eventType, _ string, _ any) {
+		note(eventType)
+		if eventType == "cooldown" {
+			cancel()
+		}
+	}
+	m.SelfUpdate = func(force bool) (string, error) {
+		note("update")
+		return update(force)
+	}
+	err := m.Run(ctx)
+	mu.Lock()
+	defer mu.Unlock()
+	return slices.Clone(seen), err
+}
+
+// The update check comes after the inscription is submitted and saved,
+// never while one is in flight, and an installed update ends the run
+// before the cooldown.
+func TestSelfUpdateBetweenInscriptions(t *testing.T) {
+	seen, err := runWithUpdate(t, func(bool) (string, error) { return "", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	insc, upd, cool := slices.Index(seen, "inscription"), slices.Index(seen, "update"), slices.Index(seen, "cooldown")
+	if insc < 0 || upd < insc || cool < upd {
+		t.Errorf("events %v: want inscription, then the update check, then the cooldown", seen)
+	}
+
+	seen, err = runWithUpdate(t, func(bool) (string, error) { return "9.9.9", nil })
+	if !errors.Is(err, ErrUpdated) {
+		t.Fatalf("Run = %v, want ErrUpdated", err)
+	}
+	if slices.Index(seen, "update") < slices.Index(seen, "inscription") || slices.Contains(seen, "cooldown") {
+		t.Errorf("events %v: want the update after the inscription and no cooldown", seen)
+	}
+	if st := LoadState(); st.TotalInscriptions != 1 {
+		t.Errorf("saved state has %d inscriptions, want 1", st.TotalInscriptions)
+	}
+}
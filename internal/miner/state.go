@@ -3,15 +3,17 @@ package miner
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/clawplaza/clawwork-cli/internal/api"
-	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/store"
 )
 
-// State tracks inscription progress across restarts.
+// State tracks inscription progress across restarts. The Total* and
+// Challenges* counters are lifetime totals; see Stats for the current run,
+// today and the named reporting period.
 type State struct {
 	LastChallenge     *api.Challenge `json:"last_challenge,omitempty"`
 	TotalInscriptions int            `json:"total_inscriptions"`
@@ -21,13 +23,78 @@ type State struct {
 	ChallengesFailed  int            `json:"challenges_failed"`
 	LastTrustScore    int            `json:"last_trust_score,omitempty"`
 	LastMineAt        time.Time      `json:"last_mine_at,omitempty"`
-	path              string
+	SessionID         string         `json:"session_id,omitempty"` // open platform session, cleared on clean exit
+
+	// CooldownSeconds is the miner's schedule.cooldown_seconds (0: the
+	// platform's 30 minutes); OffHoursUntil is when it wakes up while
+	// sleeping outside its active hours.
+	CooldownSeconds int       `json:"cooldown_seconds,omitempty"`
+	OffHoursUntil   time.Time `json:"off_hours_until,omitempty"`
+
+	// SocialCooldowns maps a social module (e.g. "moments") to the time its
+	// platform cooldown ends, so restarts don't waste LLM calls on a sure 429.
+	SocialCooldowns map[string]time.Time `json:"social_cooldowns,omitempty"`
+
+	// Limits holds rate and daily limits the platform reported, by quota
+	// name (see Quotas).
+	Limits map[string]Limit `json:"limits,omitempty"`
+
+	// TrustHistory holds recent trust scores for drop detection (last 48h).
+	TrustHistory []TrustSample `json:"trust_history,omitempty"`
+	// TrustAlertAt is when the last trust alert fired, to avoid repeats.
+	TrustAlertAt time.Time `json:"trust_alert_at,omitempty"`
+
+	// Failures keeps the most recent failed challenges for `clawwork advise`.
+	Failures []ChallengeFailure `json:"failures,omitempty"`
+	// Examples keeps recent passed challenges for the few_shot strategy.
+	Examples []Example `json:"examples,omitempty"`
+
+	// Latency holds per-phase timing histograms (see Phase* constants).
+	Latency map[string]*Histogram `json:"latency,omitempty"`
+
+	// Tokens holds per-token stats and cooldowns; Rotation lists the tokens
+	// interleaved in multi-token mode (empty otherwise).
+	Tokens   map[int]*TokenStats `json:"tokens,omitempty"`
+	Rotation []int               `json:"rotation,omitempty"`
+
+	// Earnings holds the last week of per-inscription CW for goal projection.
+	Earnings []EarnSample `json:"earnings,omitempty"`
+
+	// Penalties is the recent penalty ledger; LostCW and PenaltyCounts are
+	// all-time totals per penalty kind.
+	Penalties     []Penalty        `json:"penalties,omitempty"`
+	LostCW        map[string]int64 `json:"lost_cw,omitempty"`
+	PenaltyCounts map[string]int   `json:"penalty_counts,omitempty"`
+
+	// CycleFailures holds challenge failures per recent cycle, for the
+	// automatic pause (see Miner.checkFailures).
+	CycleFailures []int `json:"cycle_failures,omitempty"`
+
+	// Today counts the local calendar day TodayDate; Period is the named
+	// reporting period in progress and Periods the closed ones.
+	Today     Counters `json:"today"`
+	TodayDate string   `json:"today_date,omitempty"`
+	Period    *Period  `json:"period,omitempty"`
+	Periods   []Period `json:"periods,omitempty"`
+
+	// Experiments holds A/B experiment outcomes by experiment name.
+	Experiments map[string]*ExperimentStats `json:"experiments,omitempty"`
+
+	// Platform is the last polled platform status (see WatchStatus).
+	Platform *PlatformSnapshot `json:"platform,omitempty"`
+
+	mu    sync.Mutex // guards writes shared between the miner and the web console
+	store store.Backend
+
+	run      Counters // this miner process (see StartRun)
+	runStart time.Time
 }
 
-// LoadState reads state from disk, returning a fresh state if not found.
+// LoadState reads state from the store, returning a fresh state if not
+// found.
 func LoadState() *State {
-	s := &State{path: filepath.Join(config.Dir(), "state.json")}
-	data, err := os.ReadFile(s.path)
+	s := &State{store: Store()}
+	data, err := s.store.Get(stateKey)
 	if err != nil {
 		return s
 	}
@@ -35,31 +102,192 @@ func LoadState() *State {
 	return s
 }
 
-// Save persists the state to disk.
+// Save persists the state to the store it was loaded from.
 func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store == nil {
+		return errors.New("state was not loaded from a store")
+	}
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, data, 0600)
+	return s.store.Put(stateKey, data)
 }
 
 // Update updates the state from a successful inscription response.
 func (s *State) Update(resp *api.InscribeResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.TotalInscriptions++
 	s.TotalCWEarned += int64(resp.CWEarned)
 	if resp.Hit {
 		s.TotalHits++
 	}
 	s.ChallengesPassed++
+	s.LastTrustScore = resp.TrustScore
 	s.LastMineAt = time.Now()
+	s.counting(s.LastMineAt, func(c *Counters) { c.addInscription(resp.CWEarned, resp.Hit) })
+	s.recordEarning(resp.CWEarned, s.LastMineAt)
 	// Only overwrite if server provided a next challenge; preserve existing otherwise.
 	if resp.NextChallenge != nil {
 		s.LastChallenge = resp.NextChallenge
 	}
 }
 
-// RecordChallengeFail increments the challenge failure counter.
-func (s *State) RecordChallengeFail() {
+// Last returns the trust score and time of the last successful
+// inscription, for readers outside the mining loop.
+func (s *State) Last() (trustScore int, minedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LastTrustScore, s.LastMineAt
+}
+
+// TrustSample is a trust score observed at a point in time.
+type TrustSample struct {
+	At    time.Time `json:"at"`
+	Score int       `json:"score"`
+}
+
+// RecordTrust appends a trust score sample, keeping the last 48 hours.
+func (s *State) RecordTrust(score int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := now.Add(-48 * time.Hour)
+	kept := s.TrustHistory[:0]
+	for _, t := range s.TrustHistory {
+		if t.At.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	s.TrustHistory = append(kept, TrustSample{At: now, Score: score})
+}
+
+// TrustPeak returns the highest trust score seen since the given time.
+func (s *State) TrustPeak(since time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	peak := 0
+	for _, t := range s.TrustHistory {
+		if !t.At.Before(since) && t.Score > peak {
+			peak = t.Score
+		}
+	}
+	return peak
+}
+
+// maxFailures is how many failed challenges are kept in state.
+const maxFailures = 20
+
+// ChallengeFailure records a challenge the server rejected, with the answer given.
+type ChallengeFailure struct {
+	At      time.Time `json:"at"`
+	Prompt  string    `json:"prompt"`
+	Answer  string    `json:"answer"`
+	Message string    `json:"message,omitempty"`
+	Hint    string    `json:"hint,omitempty"`
+
+	Language string `json:"language,omitempty"` // detected challenge language
+}
+
+// RecordChallengeFail increments the challenge failure counter and keeps
+// the failure for later analysis.
+func (s *State) RecordChallengeFail(f ChallengeFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.ChallengesFailed++
+	s.counting(time.Now(), func(c *Counters) { c.ChallengesFailed++ })
+	f.Prompt = truncate(f.Prompt, 1000)
+	f.Answer = truncate(f.Answer, 1000)
+	s.Failures = append(s.Failures, f)
+	if n := len(s.Failures); n > maxFailures {
+		s.Failures = s.Failures[n-maxFailures:]
+	}
+}
+
+// RecentFailures returns a copy of the recorded challenge failures, oldest first.
+func (s *State) RecentFailures() []ChallengeFailure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ChallengeFailure(nil), s.Failures...)
+}
+
+// TrustTrend returns a copy of the recorded trust score samples, oldest first.
+func (s *State) TrustTrend() []TrustSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]TrustSample(nil), s.TrustHistory...)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// SocialCooldown returns when the cooldown for a social module ends.
+// The zero time means no cooldown is known.
+func (s *State) SocialCooldown(module string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.SocialCooldowns[module]
+}
+
+// SetSocialCooldown records a cooldown deadline for a social module, drops
+// expired entries, and persists the state.
+func (s *State) SetSocialCooldown(module string, until time.Time) error {
+	s.mu.Lock()
+	if s.SocialCooldowns == nil {
+		s.SocialCooldowns = make(map[string]time.Time)
+	}
+	s.SocialCooldowns[module] = until
+	now := time.Now()
+	for m, t := range s.SocialCooldowns {
+		if t.Before(now) {
+			delete(s.SocialCooldowns, m)
+		}
+	}
+	s.mu.Unlock()
+	return s.Save()
+}
+
+// RecordLatency adds a timing sample for a phase.
+func (s *State) RecordLatency(phase string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Latency == nil {
+		s.Latency = make(map[string]*Histogram)
+	}
+	h := s.Latency[phase]
+	if h == nil {
+		h = &Histogram{}
+		s.Latency[phase] = h
+	}
+	h.Observe(d)
+}
+
+// LatencySummary returns p50/p95 stats for every recorded phase.
+func (s *State) LatencySummary() map[string]LatencyStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]LatencyStats, len(s.Latency))
+	for phase, h := range s.Latency {
+		out[phase] = h.Stats()
+	}
+	return out
+}
+
+// LatencyHistograms returns a copy of every phase's histogram.
+func (s *State) LatencyHistograms() map[string]Histogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]Histogram, len(s.Latency))
+	for phase, h := range s.Latency {
+		c := *h
+		c.Counts = append([]int64(nil), h.Counts...)
+		out[phase] = c
+	}
+	return out
 }
@@ -0,0 +1,86 @@
+package miner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/store"
+)
+
+// Document keys and the history stream in the store.
+const (
+	stateKey      = "state"
+	goalKey       = "goal"
+	reviewKey     = "review"
+	historyStream = "history"
+)
+
+var (
+	storeMu sync.RWMutex
+	backend store.Backend
+)
+
+// SetStore makes state, goal, review holds and history persist in b.
+// Without it they are JSON files in the data directory.
+func SetStore(b store.Backend) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	backend = b
+}
+
+// Store returns the backend set with SetStore, or the files in the data
+// directory.
+func Store() store.Backend {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	if backend == nil {
+		return store.NewFiles(config.Dir())
+	}
+	return backend
+}
+
+// ErrStoreInUse is returned by CopyStore when dst already holds a state.
+var ErrStoreInUse = errors.New("backend already holds state")
+
+// CopyStore copies state, goal, review hold and history from src into
+// dst, e.g. the JSON files into a new SQLite database. Unless force is
+// set, dst must not hold a state yet: documents would be replaced and
+// history appended twice. It returns how many documents and history
+// records were copied.
+func CopyStore(dst, src store.Backend, force bool) (docs, records int, err error) {
+	if _, err := dst.Get(stateKey); err == nil && !force {
+		return 0, 0, ErrStoreInUse
+	}
+	for _, key := range []string{stateKey, goalKey, reviewKey} {
+		data, err := src.Get(key)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err == nil {
+			err = dst.Put(key, data)
+		}
+		if err != nil {
+			return docs, records, fmt.Errorf("%s: %w", key, err)
+		}
+		docs++
+	}
+	entries, err := ReadHistory(src, time.Time{})
+	if err != nil {
+		return docs, records, fmt.Errorf("history: %w", err)
+	}
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err == nil {
+			err = dst.Append(historyStream, e.At, data)
+		}
+		if err != nil {
+			return docs, records, fmt.Errorf("history: %w", err)
+		}
+		records++
+	}
+	return docs, records, nil
+}
@@ -0,0 +1,190 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// Strategy turns a challenge into the answer to submit. Strategies only
+// decide how the LLM is consulted; retries, timeouts, the context header
+// and the format check around them stay in the loop, so a new way of
+// answering is a new Strategy rather than a change to the loop.
+type Strategy interface {
+	Name() string
+	Answer(ctx context.Context, q Question, env Env) (string, error)
+}
+
+// Question is one challenge to answer.
+type Question struct {
+	Challenge string // the challenge as the server sent it
+	Prompt    string // what the LLM is asked: challenge plus context header and language line
+}
+
+// Env is what a strategy may use.
+type Env struct {
+	// Ask runs a prompt through the LLM with the loop's retries and
+	// timeout. Use llm.WithTemperature on ctx to vary sampling.
+	Ask func(ctx context.Context, prompt string) (string, error)
+	// Note reports progress to the console and log.
+	Note  func(msg string, data map[string]any)
+	State *State
+}
+
+// Strategy names accepted in miner.strategy.
+const (
+	StrategySingle  = "single"
+	StrategyVerify  = "verify"
+	StrategyBestOfK = "best_of_k"
+	StrategyFewShot = "few_shot"
+)
+
+// Strategies lists the strategy names, for help and validation messages.
+var Strategies = []string{StrategySingle, StrategyVerify, StrategyBestOfK, StrategyFewShot}
+
+// NewStrategy builds the strategy named in cfg. Empty means single.
+func NewStrategy(cfg config.MinerConfig) (Strategy, error) {
+	switch cfg.Strategy {
+	case "", StrategySingle:
+		return SingleShot{}, nil
+	case StrategyVerify:
+		return VerifyThenSubmit{}, nil
+	case StrategyBestOfK:
+		return newBestOfK(cfg), nil
+	case StrategyFewShot:
+		return FewShot{Examples: 3}, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q (want %s)", cfg.Strategy, strings.Join(Strategies, ", "))
+}
+
+// SingleShot asks once and submits the answer.
+type SingleShot struct{}
+
+func (SingleShot) Name() string { return StrategySingle }
+
+func (SingleShot) Answer(ctx context.Context, q Question, env Env) (string, error) {
+	return env.Ask(ctx, q.Prompt)
+}
+
+// VerifyThenSubmit asks once, then has the LLM review its own answer
+// against the challenge and correct it if needed.
+type VerifyThenSubmit struct{}
+
+func (VerifyThenSubmit) Name() string { return StrategyVerify }
+
+func (VerifyThenSubmit) Answer(ctx context.Context, q Question, env Env) (string, error) {
+	answer, err := env.Ask(ctx, q.Prompt)
+	if err != nil {
+		return "", err
+	}
+	review := fmt.Sprintf("Here is a challenge and a proposed answer.\n\nChallenge:\n%s\n\nProposed answer:\n%s\n\n"+
+		"Check the answer: is it correct, on topic, and does it follow every instruction in the challenge? "+
+		"If it does, reply with exactly OK. Otherwise reply with the corrected answer only.", q.Challenge, answer)
+	verdict, err := env.Ask(ctx, review)
+	if err != nil {
+		return answer, nil // the review is a bonus; the first answer stands
+	}
+	if v := strings.Trim(verdict, " .!\n\"'"); strings.EqualFold(v, "OK") {
+		return answer, nil
+	}
+	env.Note("Answer revised after self-review", nil)
+	return strings.TrimSpace(verdict), nil
+}
+
+// FewShot shows the LLM answers that passed earlier, similar challenges
+// (see State.RecordExample) before the challenge.
+type FewShot struct {
+	Examples int
+}
+
+func (FewShot) Name() string { return StrategyFewShot }
+
+func (s FewShot) Answer(ctx context.Context, q Question, env Env) (string, error) {
+	examples := env.State.SimilarExamples(q.Challenge, s.Examples)
+	if len(examples) == 0 {
+		return env.Ask(ctx, q.Prompt)
+	}
+	var b strings.Builder
+	b.WriteString("Answers that passed earlier challenges, for reference:\n")
+	for _, e := range examples {
+		fmt.Fprintf(&b, "\nChallenge: %s\nAnswer: %s\n", e.Prompt, e.Answer)
+	}
+	b.WriteString("\nNow answer this challenge:\n\n")
+	b.WriteString(q.Prompt)
+	return env.Ask(ctx, b.String())
+}
+
+// Example is a challenge answer the server accepted.
+type Example struct {
+	Prompt string `json:"prompt"`
+	Answer string `json:"answer"`
+}
+
+// maxExamples bounds the passed answers kept for few-shot prompts.
+const maxExamples = 30
+
+// RecordExample keeps a passed challenge and its answer for FewShot.
+func (s *State) RecordExample(prompt, answer string) {
+	if prompt == "" || answer == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Examples = append(s.Examples, Example{Prompt: truncate(prompt, 500), Answer: truncate(answer, 500)})
+	if n := len(s.Examples); n > maxExamples {
+		s.Examples = s.Examples[n-maxExamples:]
+	}
+}
+
+// SimilarExamples returns up to n kept examples sharing the most words
+// with prompt, best first. Examples sharing none are left out.
+func (s *State) SimilarExamples(prompt string, n int) []Example {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	words := wordSet(prompt)
+	type scored struct {
+		Example
+		score int
+	}
+	var ranked []scored
+	for _, e := range s.Examples {
+		score := 0
+		for w := range wordSet(e.Prompt) {
+			if words[w] {
+				score++
+			}
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{e, score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	out := make([]Example, 0, n)
+	for i := 0; i < len(ranked) && i < n; i++ {
+		out = append(out, ranked[i].Example)
+	}
+	return out
+}
+
+// wordSet returns the lowercased words of s longer than three letters,
+// or its characters for scripts without spaces.
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r > 0x2e7f)
+	}) {
+		if w[0] < 0x80 {
+			if len(w) > 3 {
+				set[w] = true
+			}
+			continue
+		}
+		for _, r := range w {
+			set[string(r)] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,78 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+const (
+	sessionTTL    = time.Hour       // the platform expires idle sessions after about this long
+	takeoverPoll  = time.Minute     // how often to retry while waiting for expiry
+	takeoverSlack = 5 * time.Minute // extra wait past the expected expiry before giving up
+)
+
+// takeover handles ALREADY_MINING when Takeover is set. A session this
+// config directory started and never ended (a crash, kill -9) is closed
+// with its saved ID; a session started elsewhere can't be closed by the
+// API, so we wait for it to expire, retrying every minute.
+func (m *Miner) takeover(ctx context.Context, apiErr *api.APIError) error {
+	if stale := m.State.SessionID; stale != "" {
+		fmt.Printf("Ending stale session %s left by a previous run...\n", shortID(stale))
+		endCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		m.API.EndSession(endCtx, stale)
+		cancel()
+		m.State.SessionID = ""
+		err := m.startSession(ctx)
+		if !api.HasCode(err, "ALREADY_MINING") {
+			return err
+		}
+	}
+
+	expires := sessionExpiry(apiErr, time.Now())
+	deadline := expires.Add(takeoverSlack)
+	for {
+		left := time.Until(expires).Truncate(time.Minute)
+		msg := "Another session is active — waiting for it to expire"
+		if left > 0 {
+			msg += fmt.Sprintf(" (~%s left)", formatMinutes(left))
+		}
+		fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), msg)
+		m.emit("session", msg, event.Session{Action: event.SessionTakeoverWait, Seconds: int(max(left, 0).Seconds())})
+
+		if !sleep(ctx, takeoverPoll) {
+			return ctx.Err()
+		}
+		err := m.startSession(ctx)
+		if !api.HasCode(err, "ALREADY_MINING") {
+			if err == nil {
+				m.emit("session", "Took over the agent's session", event.Session{Action: event.SessionTakeover})
+			}
+			return err
+		}
+		if time.Now().After(deadline) {
+			slog.Warn("takeover gave up", "waited_until", deadline)
+			return err
+		}
+	}
+}
+
+// sessionExpiry estimates when the active session lapses: the server's
+// Retry-After if given, otherwise a full session lifetime from now.
+func sessionExpiry(apiErr *api.APIError, now time.Time) time.Time {
+	if apiErr != nil && apiErr.RetryAfter > 0 {
+		return now.Add(time.Duration(apiErr.RetryAfter) * time.Second)
+	}
+	return now.Add(sessionTTL)
+}
+
+func formatMinutes(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
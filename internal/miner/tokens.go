@@ -0,0 +1,116 @@
+package miner
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// TokenStats tracks one token's progress. Multi-token mode uses
+// CooldownUntil to decide which token to inscribe next.
+type TokenStats struct {
+	Inscriptions  int       `json:"inscriptions"`
+	CWEarned      int64     `json:"cw_earned"`
+	Hits          int       `json:"hits"`
+	LastMineAt    time.Time `json:"last_mine_at,omitempty"`
+	CooldownUntil time.Time `json:"cooldown_until,omitempty"`
+}
+
+func (s *State) token(id int) *TokenStats {
+	if s.Tokens == nil {
+		s.Tokens = make(map[int]*TokenStats)
+	}
+	ts := s.Tokens[id]
+	if ts == nil {
+		ts = &TokenStats{}
+		s.Tokens[id] = ts
+	}
+	return ts
+}
+
+// RecordToken credits a successful inscription to tokenID and starts its cooldown.
+func (s *State) RecordToken(tokenID int, resp *api.InscribeResponse, cooldownUntil time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ts := s.token(tokenID)
+	ts.Inscriptions++
+	ts.CWEarned += int64(resp.CWEarned)
+	if resp.Hit {
+		ts.Hits++
+	}
+	ts.LastMineAt = time.Now()
+	ts.CooldownUntil = cooldownUntil
+}
+
+// SetTokenCooldown records a server-imposed cooldown for one token.
+func (s *State) SetTokenCooldown(tokenID int, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token(tokenID).CooldownUntil = until
+}
+
+// SetRotation records the tokens being interleaved (nil for single-token mode).
+func (s *State) SetRotation(ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Rotation = slices.Clone(ids)
+}
+
+// RotationStats returns a copy of the stats for each token in the rotation,
+// in rotation order. Empty in single-token mode.
+func (s *State) RotationStats() []TokenStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]TokenStatus, 0, len(s.Rotation))
+	for _, id := range s.Rotation {
+		ts := TokenStats{}
+		if p := s.Tokens[id]; p != nil {
+			ts = *p
+		}
+		out = append(out, TokenStatus{TokenID: id, TokenStats: ts})
+	}
+	return out
+}
+
+// TokenStatus is a token's stats together with its ID.
+type TokenStatus struct {
+	TokenID int `json:"token_id"`
+	TokenStats
+}
+
+// nextToken picks the rotation token whose cooldown ends first (ties go to
+// the earlier entry) and how long to wait for it.
+func (m *Miner) nextToken(now time.Time) (int, time.Duration) {
+	m.State.mu.Lock()
+	defer m.State.mu.Unlock()
+	best, bestAt := m.Tokens[0], time.Time{}
+	for i, id := range m.Tokens {
+		var at time.Time
+		if ts := m.State.Tokens[id]; ts != nil {
+			at = ts.CooldownUntil
+		}
+		if i == 0 || at.Before(bestAt) {
+			best, bestAt = id, at
+		}
+	}
+	return best, max(bestAt.Sub(now), 0)
+}
+
+// dropToken removes a token from the rotation (e.g. taken by another agent).
+func (m *Miner) dropToken(id int) {
+	m.Tokens = slices.DeleteFunc(m.Tokens, func(t int) bool { return t == id })
+	m.State.SetRotation(m.Tokens)
+}
+
+func formatTokens(ids []int) string {
+	s := ""
+	for i, id := range ids {
+		if i > 0 {
+			s += ", "
+		}
+		s += "#" + strconv.Itoa(id)
+	}
+	return s
+}
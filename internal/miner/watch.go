@@ -0,0 +1,138 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// PlatformSnapshot is the part of the platform status operators act on,
+// kept in state so changes are noticed across restarts.
+type PlatformSnapshot struct {
+	Wallet         string    `json:"wallet,omitempty"`
+	Hit            bool      `json:"hit,omitempty"`
+	AssignedToken  int       `json:"assigned_token,omitempty"`
+	NFTToken       int       `json:"nft_token,omitempty"`
+	NFTVerified    bool      `json:"nft_verified,omitempty"`
+	ActivityStatus string    `json:"activity_status,omitempty"`
+	NFTsRemaining  int       `json:"nfts_remaining"`
+	At             time.Time `json:"at"`
+}
+
+// SnapshotOf extracts a PlatformSnapshot from a status response.
+func SnapshotOf(st *api.StatusResponse, now time.Time) PlatformSnapshot {
+	s := PlatformSnapshot{
+		Wallet:         st.Agent.WalletAddress,
+		Hit:            st.Inscriptions.Hit,
+		ActivityStatus: st.Activity.Status,
+		NFTsRemaining:  st.Activity.NFTsRemaining,
+		At:             now,
+	}
+	if id := st.Inscriptions.AssignedTokenID; id != nil {
+		s.AssignedToken = *id
+	}
+	if nft := st.GenesisNFT; nft != nil {
+		s.NFTToken, s.NFTVerified = nft.TokenID, nft.PostVerified
+	}
+	return s
+}
+
+// Change is one difference between two platform snapshots.
+type Change struct {
+	Field   string `json:"field"`
+	Old     any    `json:"old"`
+	New     any    `json:"new"`
+	Message string `json:"message"`
+}
+
+// DiffSnapshots lists what changed from old to cur.
+func DiffSnapshots(old, cur PlatformSnapshot) []Change {
+	var out []Change
+	add := func(field string, o, n any, msg string) {
+		out = append(out, Change{Field: field, Old: o, New: n, Message: msg})
+	}
+	switch {
+	case old.Wallet == cur.Wallet:
+	case old.Wallet == "":
+		add("wallet", old.Wallet, cur.Wallet, "Wallet bound: "+cur.Wallet)
+	case cur.Wallet == "":
+		add("wallet", old.Wallet, cur.Wallet, "Wallet unbound")
+	default:
+		add("wallet", old.Wallet, cur.Wallet, fmt.Sprintf("Wallet changed: %s → %s", old.Wallet, cur.Wallet))
+	}
+	if !old.Hit && cur.Hit {
+		add("hit", old.Hit, cur.Hit, "The platform recorded an NFT hit for this agent")
+	}
+	if old.AssignedToken != cur.AssignedToken {
+		add("assigned_token", old.AssignedToken, cur.AssignedToken,
+			fmt.Sprintf("Assigned token changed: #%d → #%d", old.AssignedToken, cur.AssignedToken))
+	}
+	if old.NFTToken != cur.NFTToken && cur.NFTToken != 0 {
+		add("nft_token", old.NFTToken, cur.NFTToken, fmt.Sprintf("Genesis NFT #%d claimed", cur.NFTToken))
+	}
+	if !old.NFTVerified && cur.NFTVerified {
+		add("nft_verified", old.NFTVerified, cur.NFTVerified, fmt.Sprintf("Genesis NFT #%d verified", cur.NFTToken))
+	}
+	if old.ActivityStatus != cur.ActivityStatus {
+		add("activity_status", old.ActivityStatus, cur.ActivityStatus,
+			fmt.Sprintf("Platform activity: %s → %s", orNone(old.ActivityStatus), orNone(cur.ActivityStatus)))
+	}
+	if old.NFTsRemaining != cur.NFTsRemaining {
+		add("nfts_remaining", old.NFTsRemaining, cur.NFTsRemaining,
+			fmt.Sprintf("NFTs remaining: %d → %d", old.NFTsRemaining, cur.NFTsRemaining))
+	}
+	return out
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// ObservePlatform stores snap and returns how it differs from the previous
+// snapshot. The first snapshot is only recorded.
+func (s *State) ObservePlatform(snap PlatformSnapshot) []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.Platform
+	s.Platform = &snap
+	if prev == nil {
+		return nil
+	}
+	return DiffSnapshots(*prev, snap)
+}
+
+// WatchStatus polls the platform status every interval until ctx ends and
+// emits a "platform" event for each change, so wallet binding, claims and
+// activity changes surface without anyone running `clawwork status`.
+func (m *Miner) WatchStatus(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		st, err := m.API.Status(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Debug("status poll failed", "error", err)
+		} else if changes := m.State.ObservePlatform(SnapshotOf(st, time.Now())); len(changes) > 0 {
+			for _, c := range changes {
+				fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), c.Message)
+				m.emit("platform", c.Message, c)
+			}
+			if err := m.State.Save(); err != nil {
+				slog.Warn("failed to save state", "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+func TestObservePlatform(t *testing.T) {
+	s := &State{}
+	st := &api.StatusResponse{Activity: api.StatusActivity{Status: "active", NFTsRemaining: 100}}
+	if changes := s.ObservePlatform(SnapshotOf(st, time.Now())); changes != nil {
+		t.Fatalf("first snapshot reported changes: %v", changes)
+	}
+	if changes := s.ObservePlatform(SnapshotOf(st, time.Now())); len(changes) != 0 {
+		t.Fatalf("unchanged status reported changes: %v", changes)
+	}
+
+	st.Agent.WalletAddress = "0xabc"
+	st.GenesisNFT = &api.GenesisNFT{TokenID: 77, PostVerified: true}
+	st.Activity = api.StatusActivity{Status: "ended", NFTsRemaining: 0}
+	var fields []string
+	for _, c := range s.ObservePlatform(SnapshotOf(st, time.Now())) {
+		fields = append(fields, c.Field)
+	}
+	want := []string{"wallet", "nft_token", "nft_verified", "activity_status", "nfts_remaining"}
+	if len(fields) != len(want) {
+		t.Fatalf("changed fields = %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("changed fields = %v, want %v", fields, want)
+			break
+		}
+	}
+}
+
+func TestDiffWalletMessages(t *testing.T) {
+	tests := []struct{ old, cur, want string }{
+		{"", "0x1", "Wallet bound: 0x1"},
+		{"0x1", "", "Wallet unbound"},
+		{"0x1", "0x2", "Wallet changed: 0x1 → 0x2"},
+	}
+	for _, tt := range tests {
+		changes := DiffSnapshots(PlatformSnapshot{Wallet: tt.old}, PlatformSnapshot{Wallet: tt.cur})
+		if len(changes) != 1 || changes[0].Message != tt.want {
+			t.Errorf("%q → %q: %v, want %q", tt.old, tt.cur, changes, tt.want)
+		}
+	}
+}
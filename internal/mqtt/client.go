@@ -0,0 +1,333 @@
+// Package mqtt publishes miner events to an MQTT broker.
+//
+// It ships a minimal MQTT 3.1.1 client (QoS 0 publish/subscribe, retained
+// messages, last will, keep-alive) so the CLI stays free of heavy dependencies.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Packet types (upper nibble of the fixed header).
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetSubscribe  = 8
+	packetPingreq    = 12
+	packetDisconnect = 14
+)
+
+const dialTimeout = 10 * time.Second
+
+// Will is the last-will message the broker publishes if the client drops.
+type Will struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Options configures a broker connection.
+type Options struct {
+	Broker    string // tcp://host:1883, mqtt://, tls://, ssl://, mqtts://
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	Will      *Will
+
+	// OnMessage receives messages for subscribed topics. It runs on the
+	// read goroutine and must not block.
+	OnMessage func(topic string, payload []byte)
+}
+
+// Client is a QoS 0 MQTT 3.1.1 connection.
+type Client struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	done     chan struct{}
+	err      error // first read/write error; connection is unusable once set
+	packetID uint16
+	onMsg    func(topic string, payload []byte)
+}
+
+// Dial connects to the broker and completes the CONNECT handshake.
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	addr, useTLS, err := parseBroker(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mqtt connect %s: %w", addr, err)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+	if _, err := conn.Write(encodeConnect(opts)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt connect: %w", err)
+	}
+	r := bufio.NewReader(conn)
+	typ, body, err := readPacket(r)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt connack: %w", err)
+	}
+	if typ != packetConnack || len(body) < 2 {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt: unexpected packet type %d during connect", typ)
+	}
+	if code := body[1]; code != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt: broker refused connection (%s)", connackReason(code))
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{conn: conn, done: make(chan struct{}), onMsg: opts.OnMessage}
+	go c.readLoop(r)
+	if opts.KeepAlive > 0 {
+		go c.pingLoop(opts.KeepAlive)
+	}
+	return c, nil
+}
+
+// Publish sends a QoS 0 message.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	return c.write(encodePublish(topic, payload, retain))
+}
+
+// Subscribe requests QoS 0 delivery of topic to Options.OnMessage.
+// The SUBACK is not awaited; a refused subscription simply delivers nothing.
+func (c *Client) Subscribe(topic string) error {
+	c.mu.Lock()
+	c.packetID++
+	if c.packetID == 0 {
+		c.packetID = 1
+	}
+	id := c.packetID
+	c.mu.Unlock()
+
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendString(body, topic)
+	body = append(body, 0) // requested QoS
+	return c.write(packet(packetSubscribe<<4|0x02, body))
+}
+
+// Err returns the error that broke the connection, or nil if it is healthy.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close sends DISCONNECT (so the broker discards the will) and closes the socket.
+func (c *Client) Close() error {
+	_ = c.write([]byte{packetDisconnect << 4, 0})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+	return c.conn.Close()
+}
+
+func (c *Client) write(b []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	if _, err := c.conn.Write(b); err != nil {
+		c.err = err
+		return err
+	}
+	return nil
+}
+
+// readLoop dispatches incoming PUBLISH packets, drains the rest (SUBACK,
+// PINGRESP) and records when the link drops.
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		typ, body, err := readPacket(r)
+		if err != nil {
+			c.mu.Lock()
+			if c.err == nil {
+				c.err = err
+			}
+			c.mu.Unlock()
+			return
+		}
+		if typ == packetPublish && c.onMsg != nil && len(body) >= 2 {
+			n := int(binary.BigEndian.Uint16(body))
+			if len(body) >= 2+n {
+				c.onMsg(string(body[2:2+n]), body[2+n:])
+			}
+		}
+	}
+}
+
+func (c *Client) pingLoop(keepAlive time.Duration) {
+	ticker := time.NewTicker(keepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write([]byte{packetPingreq << 4, 0}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseBroker turns a broker URL into host:port and whether to use TLS.
+func parseBroker(broker string) (addr string, useTLS bool, err error) {
+	u, err := url.Parse(broker)
+	if err != nil || u.Host == "" {
+		return "", false, fmt.Errorf("mqtt: invalid broker URL %q (expected tcp://host:1883)", broker)
+	}
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "tls", "ssl", "mqtts":
+		useTLS = true
+		port = "8883"
+	default:
+		return "", false, fmt.Errorf("mqtt: unsupported broker scheme %q", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+func encodeConnect(opts Options) []byte {
+	var flags byte = 0x02 // clean session
+	var payload []byte
+	payload = appendString(payload, opts.ClientID)
+	if opts.Will != nil {
+		flags |= 0x04
+		if opts.Will.Retain {
+			flags |= 0x20
+		}
+		payload = appendString(payload, opts.Will.Topic)
+		payload = appendBytes(payload, opts.Will.Payload)
+	}
+	if opts.Username != "" {
+		flags |= 0x80
+		payload = appendString(payload, opts.Username)
+		if opts.Password != "" {
+			flags |= 0x40
+			payload = appendString(payload, opts.Password)
+		}
+	}
+
+	var body []byte
+	body = appendString(body, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 = MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.KeepAlive/time.Second))
+	body = append(body, payload...)
+	return packet(packetConnect<<4, body)
+}
+
+func encodePublish(topic string, payload []byte, retain bool) []byte {
+	header := byte(packetPublish << 4)
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return packet(header, body)
+}
+
+// packet prefixes body with the fixed header and variable-length remaining length.
+func packet(header byte, body []byte) []byte {
+	out := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+func readPacket(r *bufio.Reader) (typ byte, body []byte, err error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("mqtt: malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body = make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	return appendBytes(b, []byte(s))
+}
+
+func appendBytes(b, data []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
+
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client ID rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad username or password"
+	case 5:
+		return "not authorized"
+	}
+	return fmt.Sprintf("code %d", code)
+}
@@ -0,0 +1,79 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// haEntity is one Home Assistant MQTT discovery entity.
+type haEntity struct {
+	component string // sensor | switch
+	id        string
+	config    map[string]any
+}
+
+// publishDiscovery announces the agent as a Home Assistant device with
+// sensors for earnings, trust and cooldown, and a switch for pause/resume.
+// Configs are retained so HA picks them up after its own restarts.
+func (p *Publisher) publishDiscovery(c *Client) {
+	node := strings.ToLower(TopicSegment(p.dev.AgentName))
+	device := map[string]any{
+		"identifiers":  []string{"clawwork_" + node},
+		"name":         p.dev.AgentName,
+		"manufacturer": "ClawPlaza",
+		"model":        "ClawWork CLI",
+		"sw_version":   p.dev.Version,
+	}
+
+	entities := []haEntity{
+		{"sensor", "cw_earned", map[string]any{
+			"name":           "CW earned",
+			"value_template": "{{ value_json.cw_earned }}",
+			"state_class":    "total_increasing",
+			"icon":           "mdi:cash-multiple",
+		}},
+		{"sensor", "trust_score", map[string]any{
+			"name":           "Trust score",
+			"value_template": "{{ value_json.trust_score }}",
+			"state_class":    "measurement",
+			"icon":           "mdi:shield-check",
+		}},
+		{"sensor", "cooldown_until", map[string]any{
+			"name":           "Cooldown ends",
+			"value_template": "{{ value_json.cooldown_until | default(None) }}",
+			"device_class":   "timestamp",
+		}},
+		{"sensor", "status", map[string]any{
+			"name":           "Status",
+			"value_template": "{{ value_json.status }}",
+			"icon":           "mdi:pickaxe",
+		}},
+		{"sensor", "token_id", map[string]any{
+			"name":           "Target token",
+			"value_template": "{{ value_json.token_id }}",
+			"icon":           "mdi:pound",
+		}},
+	}
+	if p.ctrl != nil {
+		entities = append(entities, haEntity{"switch", "mining", map[string]any{
+			"name":           "Mining",
+			"command_topic":  p.base + "/set/mining",
+			"value_template": "{{ 'OFF' if value_json.paused else 'ON' }}",
+			"payload_on":     "ON",
+			"payload_off":    "OFF",
+			"icon":           "mdi:play-pause",
+		}})
+	}
+
+	for _, e := range entities {
+		cfg := e.config
+		cfg["unique_id"] = "clawwork_" + node + "_" + e.id
+		cfg["object_id"] = "clawwork_" + node + "_" + e.id
+		cfg["state_topic"] = p.base + "/state"
+		cfg["availability_topic"] = p.base + "/status"
+		cfg["device"] = device
+		payload, _ := json.Marshal(cfg)
+		topic := p.ha + "/" + e.component + "/clawwork_" + node + "/" + e.id + "/config"
+		_ = c.Publish(topic, payload, true)
+	}
+}
@@ -0,0 +1,306 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+const (
+	queueSize      = 256
+	keepAlive      = 60 * time.Second
+	reconnectDelay = 30 * time.Second
+)
+
+// MinerState is the retained snapshot published to <base>/state.
+type MinerState struct {
+	Status        string     `json:"status"` // mining | paused | cooldown | stopped
+	Paused        bool       `json:"paused"`
+	TokenID       int        `json:"token_id"`
+	CWEarned      int64      `json:"cw_earned"`
+	TrustScore    int        `json:"trust_score"`
+	CooldownUntil *time.Time `json:"cooldown_until,omitempty"`
+	LastEvent     string     `json:"last_event,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Device identifies the agent to MQTT consumers such as Home Assistant.
+type Device struct {
+	AgentName string
+	Version   string
+}
+
+// Controller lets MQTT commands pause and resume the miner.
+type Controller interface {
+	Pause()
+	Resume()
+}
+
+type message struct {
+	topic   string
+	payload []byte
+	retain  bool
+}
+
+// Publisher forwards miner events to MQTT without ever blocking the miner.
+// Messages are queued and sent by a background goroutine that reconnects
+// on failure; when the queue is full, events are dropped.
+//
+// Topics (base = <topic_prefix>/<agent name>):
+//
+//	<base>/status          "online" / "offline" (retained, offline via last will)
+//	<base>/state           MinerState JSON (retained)
+//	<base>/events/<type>   one JSON message per event
+//	<base>/set/mining      command topic: "ON" resumes, "OFF" pauses
+type Publisher struct {
+	opts  Options
+	base  string
+	dev   Device
+	ha    string // Home Assistant discovery prefix; empty = discovery off
+	ctrl  Controller
+	queue chan message
+	done  chan struct{}
+
+	mu     sync.Mutex
+	state  MinerState
+	closed bool
+}
+
+// NewPublisher starts a publisher for the given config. The connection is
+// established in the background; failures are logged, not returned.
+// ctrl may be nil, in which case command topics are ignored.
+func NewPublisher(cfg config.MQTTConfig, dev Device, initial MinerState, ctrl Controller) *Publisher {
+	base := strings.TrimSuffix(cfg.TopicPrefix, "/") + "/" + TopicSegment(dev.AgentName)
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = "clawwork-" + TopicSegment(dev.AgentName)
+	}
+	initial.Status = "mining"
+	initial.UpdatedAt = time.Now()
+	p := &Publisher{
+		opts: Options{
+			Broker:    cfg.Broker,
+			ClientID:  clientID,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			KeepAlive: keepAlive,
+			Will:      &Will{Topic: base + "/status", Payload: []byte("offline"), Retain: true},
+		},
+		base:  base,
+		dev:   dev,
+		ctrl:  ctrl,
+		queue: make(chan message, queueSize),
+		done:  make(chan struct{}),
+		state: initial,
+	}
+	if cfg.HomeAssistant {
+		p.ha = cfg.DiscoveryPrefix
+		if p.ha == "" {
+			p.ha = "homeassistant"
+		}
+	}
+	p.opts.OnMessage = p.handleCommand
+	go p.run()
+	p.publishState()
+	return p
+}
+
+// Base returns the topic prefix for this agent (<topic_prefix>/<agent>).
+func (p *Publisher) Base() string { return p.base }
+
+// Event publishes a miner event and updates the retained state when the
+// event changes pause, token or cooldown status. Matches miner.Miner.OnEvent.
+func (p *Publisher) Event(eventType, message string, data any) {
+	payload, _ := json.Marshal(map[string]any{
+		"type":    eventType,
+		"message": message,
+		"data":    data,
+		"time":    time.Now().Format(time.RFC3339),
+	})
+	p.enqueue(p.base+"/events/"+TopicSegment(eventType), payload, false)
+
+	if p.apply(eventType, data) {
+		p.publishState()
+	}
+}
+
+// Close publishes a final "stopped" state and "offline" status, then disconnects.
+func (p *Publisher) Close() {
+	p.mu.Lock()
+	p.state.Status = "stopped"
+	p.state.UpdatedAt = time.Now()
+	p.mu.Unlock()
+	p.publishState()
+	p.enqueue(p.base+"/status", []byte("offline"), true)
+
+	p.mu.Lock()
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-time.After(3 * time.Second):
+	}
+}
+
+// apply folds an event into the retained state and reports whether it changed.
+func (p *Publisher) apply(eventType string, data any) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st := &p.state
+	prev := *st
+	st.LastEvent = eventType
+	switch eventType {
+	case "control":
+		c, _ := data.(event.Control)
+		switch c.Action {
+		case event.ControlPause:
+			st.Paused, st.Status = true, "paused"
+		case event.ControlResume:
+			st.Paused, st.Status = false, "mining"
+		case event.ControlTokenSwitch:
+			st.TokenID = c.To
+		}
+	case "cooldown", "limit":
+		if c, ok := data.(event.Cooldown); ok && c.Seconds > 0 {
+			until := c.Until
+			st.CooldownUntil = &until
+			if !st.Paused {
+				st.Status = "cooldown"
+			}
+		}
+	case "challenge", "answer", "inscription", "hit":
+		if ins, ok := data.(event.Inscription); ok {
+			st.CWEarned += int64(ins.CWEarned)
+			st.TrustScore = ins.TrustScore
+		}
+		if !st.Paused {
+			st.Status = "mining"
+		}
+		st.CooldownUntil = nil
+	}
+	changed := st.Status != prev.Status || st.Paused != prev.Paused ||
+		st.TokenID != prev.TokenID || st.CooldownUntil != prev.CooldownUntil ||
+		st.CWEarned != prev.CWEarned || st.TrustScore != prev.TrustScore
+	if changed {
+		st.UpdatedAt = time.Now()
+	}
+	return changed
+}
+
+func (p *Publisher) publishState() {
+	p.mu.Lock()
+	payload, _ := json.Marshal(p.state)
+	p.mu.Unlock()
+	p.enqueue(p.base+"/state", payload, true)
+}
+
+func (p *Publisher) enqueue(topic string, payload []byte, retain bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- message{topic: topic, payload: payload, retain: retain}:
+	default:
+		// Broker slow or unreachable — drop rather than block the miner.
+	}
+}
+
+// run owns the connection: it connects lazily, sends queued messages and
+// reconnects after errors, waiting reconnectDelay between attempts.
+func (p *Publisher) run() {
+	defer close(p.done)
+	var client *Client
+	var nextAttempt time.Time
+	defer func() {
+		if client != nil {
+			_ = client.Close()
+		}
+	}()
+
+	for msg := range p.queue {
+		if client != nil && client.Err() != nil {
+			slog.Warn("mqtt connection lost", "error", client.Err())
+			_ = client.Close()
+			client = nil
+		}
+		if client == nil {
+			if time.Now().Before(nextAttempt) {
+				continue // drop while offline
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+			c, err := Dial(ctx, p.opts)
+			cancel()
+			if err != nil {
+				slog.Warn("mqtt unavailable", "broker", p.opts.Broker, "error", err)
+				nextAttempt = time.Now().Add(reconnectDelay)
+				continue
+			}
+			client = c
+			slog.Info("mqtt connected", "broker", p.opts.Broker, "topic", p.base)
+			if p.ctrl != nil {
+				_ = client.Subscribe(p.base + "/set/#")
+			}
+			if p.ha != "" {
+				p.publishDiscovery(client)
+			}
+			_ = client.Publish(p.base+"/status", []byte("online"), true)
+			// Re-send the current state so a fresh connection is never stale.
+			p.mu.Lock()
+			state, _ := json.Marshal(p.state)
+			p.mu.Unlock()
+			_ = client.Publish(p.base+"/state", state, true)
+		}
+		if err := client.Publish(msg.topic, msg.payload, msg.retain); err != nil {
+			slog.Debug("mqtt publish failed", "topic", msg.topic, "error", err)
+		}
+	}
+}
+
+// handleCommand applies a message from a <base>/set/... topic.
+func (p *Publisher) handleCommand(topic string, payload []byte) {
+	if p.ctrl == nil || topic != p.base+"/set/mining" {
+		return
+	}
+	p.mu.Lock()
+	switch strings.ToUpper(strings.TrimSpace(string(payload))) {
+	case "ON":
+		p.ctrl.Resume()
+		p.state.Paused, p.state.Status = false, "mining"
+	case "OFF":
+		p.ctrl.Pause()
+		p.state.Paused, p.state.Status = true, "paused"
+	default:
+		p.mu.Unlock()
+		return
+	}
+	p.state.UpdatedAt = time.Now()
+	p.mu.Unlock()
+	slog.Info("mqtt command", "topic", topic, "payload", string(payload))
+	p.publishState()
+}
+
+// TopicSegment makes s safe to use as a single MQTT topic level.
+func TopicSegment(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#', ' ':
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "agent"
+	}
+	return s
+}
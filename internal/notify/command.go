@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Command runs a shell command per message, e.g. `mail -s "$CLAWWORK_TITLE"
+// me@example.com`. The message text is on stdin; type, severity and title
+// are in CLAWWORK_EVENT, CLAWWORK_SEVERITY and CLAWWORK_TITLE.
+type Command struct {
+	Line string
+}
+
+// Send implements Notifier.
+func (c *Command) Send(ctx context.Context, msg Message) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.Line)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.Line)
+	}
+	cmd.Stdin = strings.NewReader(msg.Text + "\n")
+	cmd.Env = append(os.Environ(),
+		"CLAWWORK_EVENT="+msg.Type,
+		"CLAWWORK_SEVERITY="+msg.Severity,
+		"CLAWWORK_TITLE="+msg.Title,
+		"CLAWWORK_MESSAGE="+msg.Text,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
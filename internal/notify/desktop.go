@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// DesktopEvents are what [notify] desktop = true shows: wins, losses and
+// anything that stops mining.
+var DesktopEvents = []string{"hit", "penalty", "alert"}
+
+// Desktop shows each message as a native notification: osascript on
+// macOS, notify-send on Linux and the BSDs, a toast on Windows. Title and
+// text are passed as arguments or environment, never spliced into a
+// script, so event text can't inject commands.
+type Desktop struct{}
+
+// toastScript shows a Windows toast under PowerShell's app ID, which is
+// registered on every install, unlike one of our own.
+const toastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:CLAWWORK_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:CLAWWORK_MESSAGE)) > $null
+$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+
+// Send implements Notifier.
+func (Desktop) Send(ctx context.Context, msg Message) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			msg.Title, msg.Text)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+		cmd.Env = append(os.Environ(), "CLAWWORK_TITLE="+msg.Title, "CLAWWORK_MESSAGE="+msg.Text)
+	default:
+		urgency := "normal"
+		if msg.Severity == Critical.String() {
+			urgency = "critical"
+		}
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=ClawWork", "--urgency="+urgency, "--", msg.Title, msg.Text)
+	}
+	out, err := cmd.CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%s not found — desktop notifications need it installed (libnotify on Linux)", cmd.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// Package notify routes miner events to notification channels (webhooks,
+// local commands, desktop notifications) with per-channel severity, event filters and quiet hours.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+const (
+	queueSize   = 64
+	sendTimeout = 15 * time.Second
+)
+
+// Severity orders notifications; channels drop anything below their minimum.
+type Severity int
+
+const (
+	Debug Severity = iota
+	Info
+	Warning
+	Critical
+)
+
+var severityNames = []string{"debug", "info", "warning", "critical"}
+
+func (s Severity) String() string { return severityNames[s] }
+
+// ParseSeverity maps a config value to a Severity. Empty means Warning.
+func ParseSeverity(s string) (Severity, error) {
+	if s == "" {
+		return Warning, nil
+	}
+	if i := slices.Index(severityNames, s); i >= 0 {
+		return Severity(i), nil
+	}
+	return Debug, fmt.Errorf("unknown severity %q", s)
+}
+
+// SeverityOf classifies a miner event by type.
+func SeverityOf(eventType string) Severity {
+	switch eventType {
+	case "alert":
+		return Critical
+	case "error", "penalty", "limit", "limit_reset", "diagnosis":
+		return Warning
+	case "hit", "inscription", "stats", "control", "llm", "platform", "knowledge", "schedule":
+		return Info
+	default: // challenge, answer, thinking, cooldown, session
+		return Debug
+	}
+}
+
+// Message is one notification.
+type Message struct {
+	Type     string    `json:"type"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Text     string    `json:"message"`
+	Agent    string    `json:"agent,omitempty"`
+	At       time.Time `json:"at"`
+	Data     any       `json:"data,omitempty"`
+}
+
+// Notifier delivers messages to one destination.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// route is a notifier with the rules deciding what reaches it.
+type route struct {
+	name   string
+	n      Notifier
+	min    Severity
+	events []string
+	quiet  *quietHours
+}
+
+func (r *route) accepts(msg Message, sev Severity, now time.Time) bool {
+	if sev < r.min {
+		return false
+	}
+	if len(r.events) > 0 && !slices.Contains(r.events, msg.Type) {
+		return false
+	}
+	return sev == Critical || !r.quiet.contains(now)
+}
+
+// Dispatcher fans events out to the configured channels. Sending happens
+// on a background goroutine so a slow webhook never holds up the miner;
+// when the queue is full, events are dropped.
+type Dispatcher struct {
+	agent  string
+	routes []*route
+	queue  chan Message
+	done   chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New builds a dispatcher from config. It returns nil when no channels
+// are configured; a nil *Dispatcher ignores events.
+func New(cfg config.NotifyConfig, agent string) (*Dispatcher, error) {
+	channels := cfg.Channels
+	if cfg.Desktop {
+		channels = append(slices.Clip(channels), config.NotifyChannel{
+			Name: "desktop", Type: "desktop", MinSeverity: Info.String(), Events: DesktopEvents,
+		})
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	d := &Dispatcher{agent: agent, queue: make(chan Message, queueSize), done: make(chan struct{})}
+	for _, ch := range channels {
+		r, err := newRoute(ch, cfg.QuietHours)
+		if err != nil {
+			return nil, fmt.Errorf("notify.channel %q: %w", ch.Name, err)
+		}
+		d.routes = append(d.routes, r)
+	}
+	go d.run()
+	return d, nil
+}
+
+func newRoute(ch config.NotifyChannel, globalQuiet string) (*route, error) {
+	min, err := ParseSeverity(ch.MinSeverity)
+	if err != nil {
+		return nil, err
+	}
+	quiet := ch.QuietHours
+	if quiet == "" {
+		quiet = globalQuiet
+	}
+	q, err := parseQuietHours(quiet)
+	if err != nil {
+		return nil, err
+	}
+	var n Notifier
+	switch ch.Type {
+	case "webhook":
+		w := NewWebhook(ch.URL)
+		w.Format, w.ChatID = ch.WebhookFormat(), ch.ChatID
+		n = w
+	case "command":
+		n = &Command{Line: ch.Command}
+	case "desktop":
+		n = Desktop{}
+	default:
+		return nil, fmt.Errorf("unknown type %q", ch.Type)
+	}
+	return &route{name: ch.Name, n: n, min: min, events: ch.Events, quiet: q}, nil
+}
+
+// Channels returns the configured channel names.
+func (d *Dispatcher) Channels() []string {
+	if d == nil {
+		return nil
+	}
+	names := make([]string, len(d.routes))
+	for i, r := range d.routes {
+		names[i] = r.name
+	}
+	return names
+}
+
+// Event queues a miner event. It never blocks.
+func (d *Dispatcher) Event(eventType, message string, data any) {
+	if d == nil {
+		return
+	}
+	msg := Message{
+		Type:     eventType,
+		Severity: SeverityOf(eventType).String(),
+		Title:    title(d.agent, eventType),
+		Text:     message,
+		Agent:    d.agent,
+		At:       time.Now().UTC(),
+		Data:     data,
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	select {
+	case d.queue <- msg:
+	default:
+		slog.Warn("notify: queue full, dropping event", "type", eventType)
+	}
+}
+
+// Close sends what is queued (bounded by sendTimeout per message) and stops.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	<-d.done
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for msg := range d.queue {
+		sev := SeverityOf(msg.Type)
+		for _, r := range d.routes {
+			if r.accepts(msg, sev, time.Now()) {
+				d.send(r, msg)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) send(r *route, msg Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	if err := r.n.Send(ctx, msg); err != nil {
+		slog.Warn("notify: send failed", "channel", r.name, "type", msg.Type, "error", err)
+	}
+}
+
+// Test sends a test message to the named channel, or every channel if name
+// is empty, bypassing routing rules. It returns each channel's send error
+// (nil on success).
+func (d *Dispatcher) Test(ctx context.Context, name string) map[string]error {
+	results := make(map[string]error)
+	if d == nil {
+		return results
+	}
+	msg := Message{
+		Type: "test", Severity: Info.String(), Title: title(d.agent, "test"),
+		Text: "Test notification from clawwork", Agent: d.agent, At: time.Now().UTC(),
+	}
+	for _, r := range d.routes {
+		if name == "" || r.name == name {
+			results[r.name] = r.n.Send(ctx, msg)
+		}
+	}
+	return results
+}
+
+func title(agent, eventType string) string {
+	if agent == "" {
+		agent = "ClawWork"
+	}
+	return agent + ": " + strings.ReplaceAll(eventType, "_", " ")
+}
@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+func TestQuietHours(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 1, 1, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		spec string
+		t    time.Time
+		want bool
+	}{
+		{"23:00-07:00", at(23, 30), true},
+		{"23:00-07:00", at(3, 0), true},
+		{"23:00-07:00", at(7, 0), false},
+		{"23:00-07:00", at(12, 0), false},
+		{"12:00-13:30", at(13, 29), true},
+		{"12:00-13:30", at(11, 59), false},
+		{"off", at(3, 0), false},
+		{"", at(3, 0), false},
+	}
+	for _, tt := range tests {
+		q, err := parseQuietHours(tt.spec)
+		if err != nil {
+			t.Fatalf("parseQuietHours(%q): %v", tt.spec, err)
+		}
+		if got := q.contains(tt.t); got != tt.want {
+			t.Errorf("%q contains %s = %v, want %v", tt.spec, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+	if _, err := parseQuietHours("11pm-7am"); err == nil {
+		t.Error("parseQuietHours accepted 11pm-7am")
+	}
+}
+
+type recorder struct {
+	mu  sync.Mutex
+	got []string
+}
+
+func (r *recorder) Send(_ context.Context, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, msg.Type)
+	return nil
+}
+
+func TestRouting(t *testing.T) {
+	phone, ops := &recorder{}, &recorder{}
+	d := &Dispatcher{
+		queue: make(chan Message, queueSize),
+		done:  make(chan struct{}),
+		routes: []*route{
+			{name: "phone", n: phone, min: Debug, events: []string{"hit"}},
+			{name: "ops", n: ops, min: Warning},
+		},
+	}
+	go d.run()
+	for _, typ := range []string{"inscription", "hit", "error", "cooldown", "alert"} {
+		d.Event(typ, "", nil)
+	}
+	d.Close()
+
+	if len(phone.got) != 1 || phone.got[0] != "hit" {
+		t.Errorf("phone got %v, want [hit]", phone.got)
+	}
+	if len(ops.got) != 2 || ops.got[0] != "error" || ops.got[1] != "alert" {
+		t.Errorf("ops got %v, want [error alert]", ops.got)
+	}
+}
+
+func TestQuietHoursLetCriticalThrough(t *testing.T) {
+	r := &route{min: Info, quiet: &quietHours{0, 24*60 - 1}}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	if r.accepts(Message{Type: "hit"}, Info, now) {
+		t.Error("info event delivered during quiet hours")
+	}
+	if !r.accepts(Message{Type: "alert"}, Critical, now) {
+		t.Error("critical event held back by quiet hours")
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	d, err := New(config.NotifyConfig{}, "agent")
+	if err != nil || d != nil {
+		t.Fatalf("New with no channels = %v, %v; want nil, nil", d, err)
+	}
+	d.Event("hit", "ignored", nil) // nil dispatcher is a no-op
+	d.Close()
+
+	d, err = New(config.NotifyConfig{Channels: []config.NotifyChannel{
+		{Name: "ops", Type: "command", Command: "true"},
+	}}, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if min := d.routes[0].min; min != Warning {
+		t.Errorf("default min severity = %s, want warning", min)
+	}
+}
+
+func TestDesktopToggle(t *testing.T) {
+	d, err := New(config.NotifyConfig{Desktop: true}, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if got := d.Channels(); len(got) != 1 || got[0] != "desktop" {
+		t.Fatalf("channels = %v, want [desktop]", got)
+	}
+	r, now := d.routes[0], time.Now()
+	for typ, want := range map[string]bool{"hit": true, "penalty": true, "alert": true, "inscription": false, "error": false} {
+		if got := r.accepts(Message{Type: typ}, SeverityOf(typ), now); got != want {
+			t.Errorf("desktop accepts %s = %v, want %v", typ, got, want)
+		}
+	}
+}
+
+func TestWebhookFormats(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = nil
+		_ = json.Unmarshal(data, &body)
+	}))
+	defer srv.Close()
+
+	msg := Message{Type: "hit", Title: "bot: hit", Text: "NFT #7 <yours> & more"}
+	tests := []struct {
+		format, key, want string
+	}{
+		{"json", "message", "NFT #7 <yours> & more"},
+		{"slack", "text", "*bot: hit*\nNFT #7 &lt;yours&gt; &amp; more"},
+		{"discord", "content", "**bot: hit**\nNFT #7 <yours> & more"},
+		{"telegram", "text", "bot: hit\nNFT #7 <yours> & more"},
+	}
+	for _, tt := range tests {
+		w := NewWebhook(srv.URL)
+		w.Format, w.ChatID = tt.format, "-100123"
+		if err := w.Send(context.Background(), msg); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if got := body[tt.key]; got != tt.want {
+			t.Errorf("%s: %s = %q, want %q", tt.format, tt.key, got, tt.want)
+		}
+		if tt.format == "telegram" && body["chat_id"] != "-100123" {
+			t.Errorf("telegram: chat_id = %v", body["chat_id"])
+		}
+	}
+
+	w := NewWebhook(srv.URL)
+	w.Format = "discord"
+	_ = w.Send(context.Background(), Message{Title: "t", Text: strings.Repeat("é", 3000)})
+	if n := len([]rune(body["content"].(string))); n != discordMaxLen {
+		t.Errorf("discord content is %d characters, want %d", n, discordMaxLen)
+	}
+}
+
+func TestWebhookFormatFromURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://hooks.slack.com/services/T0/B0/x":        "slack",
+		"https://discord.com/api/webhooks/1/abc":          "discord",
+		"https://api.telegram.org/bot123:abc/sendMessage": "telegram",
+		"https://ntfy.example.com/clawwork":               "json",
+		"https://discord.com/channels/1/2":                "json",
+	} {
+		r, err := newRoute(config.NotifyChannel{Name: "x", Type: "webhook", URL: url}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.n.(*Webhook).Format; got != want {
+			t.Errorf("%s: format %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// quietHours is a daily local-time window, possibly wrapping midnight.
+// A nil *quietHours is never quiet.
+type quietHours struct {
+	from, to int // minutes since midnight
+}
+
+// parseQuietHours reads "23:00-07:00". "" and "off" mean no quiet hours.
+func parseQuietHours(s string) (*quietHours, error) {
+	if s == "" || s == "off" {
+		return nil, nil
+	}
+	a, b, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", s)
+	}
+	from, err := time.Parse("15:04", strings.TrimSpace(a))
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours %q: %w", s, err)
+	}
+	to, err := time.Parse("15:04", strings.TrimSpace(b))
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours %q: %w", s, err)
+	}
+	return &quietHours{from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute()}, nil
+}
+
+func (q *quietHours) contains(t time.Time) bool {
+	if q == nil || q.from == q.to {
+		return false
+	}
+	t = t.Local()
+	m := t.Hour()*60 + t.Minute()
+	if q.from < q.to {
+		return m >= q.from && m < q.to
+	}
+	return m >= q.from || m < q.to
+}
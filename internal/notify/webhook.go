@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
+)
+
+// Message length limits of the chat services.
+const (
+	discordMaxLen  = 2000
+	telegramMaxLen = 4096
+)
+
+// Webhook POSTs each message as JSON to a URL: the whole Message, or the
+// payload a chat service's incoming webhook expects (see Format).
+type Webhook struct {
+	URL    string
+	Format string // "json" (default), "slack", "discord" or "telegram"
+	ChatID string // telegram
+	HTTP   *http.Client
+}
+
+// NewWebhook returns a webhook notifier for url that sends the full
+// message as JSON.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, HTTP: httpx.Client("notify", 0)}
+}
+
+// Send implements Notifier.
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(w.payload(msg))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// payload renders msg for the webhook's format. Chat services get the
+// title and text only.
+func (w *Webhook) payload(msg Message) any {
+	switch w.Format {
+	case "slack":
+		esc := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+		return map[string]string{"text": "*" + esc.Replace(msg.Title) + "*\n" + esc.Replace(msg.Text)}
+	case "discord":
+		return map[string]any{
+			"content": clip("**"+msg.Title+"**\n"+msg.Text, discordMaxLen),
+			// Event text is not ours to vouch for: never ping @everyone.
+			"allowed_mentions": map[string]any{"parse": []string{}},
+		}
+	case "telegram":
+		return map[string]string{"chat_id": w.ChatID, "text": clip(msg.Title+"\n"+msg.Text, telegramMaxLen)}
+	}
+	return msg
+}
+
+// clip shortens s to at most n characters, marking the cut.
+func clip(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
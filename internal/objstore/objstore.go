@@ -0,0 +1,59 @@
+// Package objstore is a minimal object storage client — put, get and
+// delete by key — over WebDAV or the S3 API (AWS, MinIO, R2, B2 and other
+// compatible services). Backups and data sync use it for off-machine copies.
+package objstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// maxObjectSize bounds a single download.
+const maxObjectSize = 256 << 20
+
+// ErrNotFound is returned by Get for a missing key.
+var ErrNotFound = errors.New("object not found")
+
+// Store holds objects by key. Keys use "/" as a separator.
+type Store interface {
+	Name() string
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New builds the store configured under [sync]. It returns nil, nil when
+// no backend is set.
+func New(cfg config.SyncConfig) (Store, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "webdav":
+		return NewWebDAV(cfg.URL, cfg.User, cfg.Password), nil
+	case "s3":
+		return NewS3(cfg.URL, cfg.Region, cfg.Bucket, cfg.AccessKey, cfg.SecretKey), nil
+	default:
+		return nil, fmt.Errorf("unknown sync backend %q", cfg.Backend)
+	}
+}
+
+// escapeKey percent-encodes each segment of key, keeping the slashes.
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+func checkStatus(method, key string, status int) error {
+	if status < 200 || status > 299 {
+		return fmt.Errorf("%s %s: HTTP %d", method, key, status)
+	}
+	return nil
+}
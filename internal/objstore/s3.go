@@ -0,0 +1,176 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
+)
+
+// S3 talks to an S3-compatible bucket with path-style URLs
+// (<endpoint>/<bucket>/<key>) and Signature Version 4.
+type S3 struct {
+	Endpoint  string // e.g. https://s3.eu-west-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	HTTP      *http.Client
+
+	now func() time.Time // for tests
+}
+
+// NewS3 returns a store for bucket at endpoint. An empty region means
+// us-east-1, which most compatible services accept.
+func NewS3(endpoint, region, bucket, accessKey, secretKey string) *S3 {
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3{
+		Endpoint:  strings.TrimSuffix(endpoint, "/"),
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		HTTP:      httpx.Client("sync", 2*time.Minute),
+		now:       time.Now,
+	}
+}
+
+// Name identifies the store in logs without its credentials.
+func (s *S3) Name() string {
+	if u, err := url.Parse(s.Endpoint); err == nil {
+		return "s3 " + u.Host + "/" + s.Bucket
+	}
+	return "s3 " + s.Bucket
+}
+
+// Put uploads data as key.
+func (s *S3) Put(ctx context.Context, key string, data []byte) error {
+	status, _, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	return checkStatus(http.MethodPut, key, status)
+}
+
+// Get downloads key, returning ErrNotFound if it doesn't exist.
+func (s *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	status, body, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err := checkStatus(http.MethodGet, key, status); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Delete removes key. S3 reports success for missing keys too.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	status, _, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil || status == http.StatusNotFound {
+		return err
+	}
+	return checkStatus(http.MethodDelete, key, status)
+}
+
+func (s *S3) do(ctx context.Context, method, key string, data []byte) (int, []byte, error) {
+	path := "/" + awsEscape(s.Bucket) + "/" + awsEscape(key)
+	req, err := http.NewRequestWithContext(ctx, method, s.Endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.ContentLength = int64(len(data))
+	s.sign(req, path, data)
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxObjectSize))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
+// sign adds SigV4 headers. It signs host, range and every x-amz-* header.
+func (s *S3) sign(req *http.Request, escapedPath string, payload []byte) {
+	t := s.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+	sum := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") || lk == "range" {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method, escapedPath, req.URL.RawQuery, canonHeaders.String(), signed, payloadHash,
+	}, "\n")
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	crSum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crSum[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signed, sig))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsEscape is the URI encoding SigV4 expects: everything except
+// unreserved characters and "/" is percent-encoded.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
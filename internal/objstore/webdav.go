@@ -0,0 +1,127 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
+)
+
+// WebDAV stores objects as files under an existing collection, e.g. a
+// Nextcloud folder: https://cloud.example.com/remote.php/dav/files/me/clawwork/
+// Intermediate collections for keys with slashes are created on demand.
+type WebDAV struct {
+	URL      string
+	User     string
+	Password string
+	HTTP     *http.Client
+}
+
+// NewWebDAV returns a WebDAV store for the collection at rawURL.
+func NewWebDAV(rawURL, user, password string) *WebDAV {
+	return &WebDAV{
+		URL:      strings.TrimSuffix(rawURL, "/") + "/",
+		User:     user,
+		Password: password,
+		HTTP:     httpx.Client("sync", 2*time.Minute),
+	}
+}
+
+// Name identifies the store in logs without its credentials.
+func (w *WebDAV) Name() string {
+	if u, err := url.Parse(w.URL); err == nil {
+		return "webdav " + u.Host
+	}
+	return "webdav"
+}
+
+// Put uploads data as key.
+func (w *WebDAV) Put(ctx context.Context, key string, data []byte) error {
+	status, _, err := w.do(ctx, http.MethodPut, key, data)
+	if err == nil && status == http.StatusConflict && strings.Contains(key, "/") {
+		// The parent collection doesn't exist yet.
+		if err := w.mkcol(ctx, path.Dir(key)); err != nil {
+			return err
+		}
+		status, _, err = w.do(ctx, http.MethodPut, key, data)
+	}
+	if err != nil {
+		return err
+	}
+	return checkStatus(http.MethodPut, key, status)
+}
+
+// Get downloads key, returning ErrNotFound if it doesn't exist.
+func (w *WebDAV) Get(ctx context.Context, key string) ([]byte, error) {
+	status, body, err := w.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err := checkStatus(http.MethodGet, key, status); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Delete removes key. A key that is already gone is not an error.
+func (w *WebDAV) Delete(ctx context.Context, key string) error {
+	status, _, err := w.do(ctx, http.MethodDelete, key, nil)
+	if err != nil || status == http.StatusNotFound {
+		return err
+	}
+	return checkStatus(http.MethodDelete, key, status)
+}
+
+// mkcol creates dir and any missing parents.
+func (w *WebDAV) mkcol(ctx context.Context, dir string) error {
+	parts := strings.Split(dir, "/")
+	for i := range parts {
+		status, _, err := w.do(ctx, "MKCOL", strings.Join(parts[:i+1], "/")+"/", nil)
+		if err != nil {
+			return err
+		}
+		// 405: the collection already exists.
+		if status != http.StatusMethodNotAllowed {
+			if err := checkStatus("MKCOL", dir, status); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *WebDAV) do(ctx context.Context, method, key string, data []byte) (int, []byte, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, w.URL+escapeKey(key), body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if w.User != "" || w.Password != "" {
+		req.SetBasicAuth(w.User, w.Password)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	resp, err := w.HTTP.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxObjectSize))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
@@ -0,0 +1,121 @@
+// Package schedule evaluates the daily active-hours windows of [schedule],
+// such as "22:00-08:00", in local time.
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Window is a daily span of local time, in minutes after midnight. An End
+// before Start crosses midnight.
+type Window struct {
+	Start, End int
+}
+
+// ParseWindow parses "HH:MM-HH:MM". "24:00" is accepted as an end.
+func ParseWindow(s string) (Window, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return Window{}, fmt.Errorf("window %q: want HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from, false)
+	if err != nil {
+		return Window{}, fmt.Errorf("window %q: %w", s, err)
+	}
+	end, err := parseClock(to, true)
+	if err != nil {
+		return Window{}, fmt.Errorf("window %q: %w", s, err)
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("window %q is empty; leave active hours unset to mine around the clock", s)
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+func parseClock(s string, end bool) (int, error) {
+	s = strings.TrimSpace(s)
+	if end && s == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day (HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60)
+}
+
+// contains reports whether minute m of the day falls in w.
+func (w Window) contains(m int) bool {
+	if w.Start < w.End {
+		return m >= w.Start && m < w.End
+	}
+	return m >= w.Start || m < w.End
+}
+
+// Schedule is a set of windows. Empty means always active.
+type Schedule []Window
+
+// Parse parses every window of [schedule] active_hours.
+func Parse(windows []string) (Schedule, error) {
+	var s Schedule
+	for _, w := range windows {
+		win, err := ParseWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, win)
+	}
+	return s, nil
+}
+
+// Active reports whether t falls in any window.
+func (s Schedule) Active(t time.Time) bool {
+	if len(s) == 0 {
+		return true
+	}
+	t = t.Local()
+	m := t.Hour()*60 + t.Minute()
+	return slices.ContainsFunc(s, func(w Window) bool { return w.contains(m) })
+}
+
+// Next returns when Active next changes after t, or zero if it never does.
+func (s Schedule) Next(t time.Time) time.Time {
+	now := s.Active(t)
+	t = t.Local()
+	var edges []time.Time
+	for day := 0; day <= 2; day++ {
+		for _, w := range s {
+			for _, m := range []int{w.Start, w.End} {
+				at := time.Date(t.Year(), t.Month(), t.Day()+day, m/60, m%60, 0, 0, time.Local)
+				if at.After(t) {
+					edges = append(edges, at)
+				}
+			}
+		}
+	}
+	slices.SortFunc(edges, func(a, b time.Time) int { return a.Compare(b) })
+	for _, at := range edges {
+		if s.Active(at) != now {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) String() string {
+	if len(s) == 0 {
+		return "always"
+	}
+	parts := make([]string, len(s))
+	for i, w := range s {
+		parts[i] = w.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,46 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	s, err := Parse([]string{"22:00-08:00", "12:00-13:30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 10, h, m, 0, 0, time.Local) }
+
+	for _, c := range []struct {
+		h, m   int
+		active bool
+		next   time.Time
+	}{
+		{23, 0, true, at(32, 0)}, // overnight window ends tomorrow 08:00
+		{7, 59, true, at(8, 0)},
+		{8, 0, false, at(12, 0)},
+		{13, 30, false, at(22, 0)},
+		{12, 45, true, at(13, 30)},
+	} {
+		now := at(c.h, c.m)
+		if got := s.Active(now); got != c.active {
+			t.Errorf("Active(%s) = %v", now.Format("15:04"), got)
+		}
+		if got := s.Next(now); !got.Equal(c.next) {
+			t.Errorf("Next(%s) = %s, want %s", now.Format("15:04"), got, c.next)
+		}
+	}
+
+	if !Schedule(nil).Active(at(3, 0)) || !Schedule(nil).Next(at(3, 0)).IsZero() {
+		t.Error("an empty schedule is not always active")
+	}
+	if all, err := Parse([]string{"00:00-24:00"}); err != nil || !all.Active(at(3, 0)) || !all.Next(at(3, 0)).IsZero() {
+		t.Errorf("a whole-day window changes (%v)", err)
+	}
+	for _, bad := range []string{"22:00", "25:00-08:00", "08:00-08:00", "8pm-9pm"} {
+		if _, err := ParseWindow(bad); err == nil {
+			t.Errorf("ParseWindow(%q) accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Files keeps each document in <dir>/<key>.json and each stream in monthly
+// JSON Lines files, <dir>/<stream>/2006-01.jsonl — the layout the data
+// directory always had, so backups, sync and hand inspection keep working.
+type Files struct {
+	dir string
+	mu  sync.Mutex // serializes appends within this process
+}
+
+// NewFiles returns a file backend rooted at dir. Nothing is created until
+// the first write.
+func NewFiles(dir string) *Files {
+	return &Files{dir: dir}
+}
+
+func (f *Files) Name() string { return "files (" + f.dir + ")" }
+
+func (f *Files) Get(key string) ([]byte, error) {
+	data, err := os.ReadFile(f.docPath(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put writes a temporary file and renames it over the document, so a
+// crash mid-write never leaves a truncated state.json.
+func (f *Files) Put(key string, data []byte) error {
+	path := f.docPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (f *Files) Delete(key string) error {
+	if err := os.Remove(f.docPath(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Append opens the month's file for each record (one every few minutes at
+// most), so moving or deleting it while the miner runs is safe.
+func (f *Files) Append(stream string, at time.Time, record []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dir := filepath.Join(f.dir, stream)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, at.UTC().Format("2006-01")+".jsonl"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(record, '\n'))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Scan reads the monthly files from since's month on. A missing stream is
+// empty.
+func (f *Files) Scan(stream string, since time.Time, fn func(record []byte)) error {
+	files, err := filepath.Glob(filepath.Join(f.dir, stream, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	first := since.UTC().Format("2006-01")
+	for _, path := range files {
+		if strings.TrimSuffix(filepath.Base(path), ".jsonl") < first {
+			continue
+		}
+		if err := scanFile(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Files) Close() error { return nil }
+
+func (f *Files) docPath(key string) string { return filepath.Join(f.dir, key+".json") }
+
+func scanFile(path string, fn func([]byte)) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for sc.Scan() {
+		if len(sc.Bytes()) > 0 {
+			fn(sc.Bytes())
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
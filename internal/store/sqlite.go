@@ -0,0 +1,115 @@
+//go:build sqlite
+
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // pure Go, so release builds stay CGO_ENABLED=0
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS documents (
+	namespace  TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	updated_at INTEGER NOT NULL,
+	PRIMARY KEY (namespace, key)
+);
+CREATE TABLE IF NOT EXISTS records (
+	namespace TEXT NOT NULL,
+	stream    TEXT NOT NULL,
+	at        INTEGER NOT NULL,
+	data      BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS records_by_time ON records (namespace, stream, at);
+`
+
+// SQLite keeps documents and streams in one database file. Several agents
+// can share it, each under its own namespace, so a fleet's data can be
+// collected in one place and queried with SQL.
+type SQLite struct {
+	db   *sql.DB
+	path string
+	ns   string
+}
+
+// openSQLite opens (or creates) the database at path for namespace ns.
+func openSQLite(path, ns string) (Backend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// WAL lets the console and CLI commands read while the miner writes;
+	// the busy timeout covers writers from other processes.
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	_ = os.Chmod(path, 0600)
+	return &SQLite{db: db, path: path, ns: ns}, nil
+}
+
+func (s *SQLite) Name() string { return "sqlite (" + s.path + ", namespace " + s.ns + ")" }
+
+func (s *SQLite) Get(key string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM documents WHERE namespace = ? AND key = ?`, s.ns, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *SQLite) Put(key string, data []byte) error {
+	_, err := s.db.Exec(`INSERT INTO documents (namespace, key, data, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (namespace, key) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		s.ns, key, data, time.Now().UnixMilli())
+	return err
+}
+
+func (s *SQLite) Delete(key string) error {
+	_, err := s.db.Exec(`DELETE FROM documents WHERE namespace = ? AND key = ?`, s.ns, key)
+	return err
+}
+
+func (s *SQLite) Append(stream string, at time.Time, record []byte) error {
+	_, err := s.db.Exec(`INSERT INTO records (namespace, stream, at, data) VALUES (?, ?, ?, ?)`,
+		s.ns, stream, at.UnixMilli(), record)
+	return err
+}
+
+func (s *SQLite) Scan(stream string, since time.Time, fn func(record []byte)) error {
+	rows, err := s.db.Query(`SELECT data FROM records WHERE namespace = ? AND stream = ? AND at >= ? ORDER BY rowid`,
+		s.ns, stream, since.UnixMilli())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		fn(data)
+	}
+	return rows.Err()
+}
+
+// Snapshot uses VACUUM INTO, which reads inside one transaction, so the
+// copy includes everything committed and nothing half-written, whatever
+// is still in the write-ahead log.
+func (s *SQLite) Snapshot(path string) error {
+	_, err := s.db.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
+func (s *SQLite) Close() error { return s.db.Close() }
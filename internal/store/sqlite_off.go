@@ -0,0 +1,11 @@
+//go:build !sqlite
+
+package store
+
+import "errors"
+
+// openSQLite fails: the SQLite driver is only linked into builds made with
+// -tags sqlite, which keeps it out of the default binary.
+func openSQLite(path, ns string) (Backend, error) {
+	return nil, errors.New("this clawwork was built without SQLite support — rebuild with 'go build -tags sqlite' (make build-sqlite) or set [storage] backend = \"files\"")
+}
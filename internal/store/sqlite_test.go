@@ -0,0 +1,36 @@
+//go:build sqlite
+
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clawwork.db")
+	a, err := openSQLite(path, "agent-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	testBackend(t, a)
+
+	// Agents sharing the database don't see each other's data.
+	b, err := openSQLite(path, "agent-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	_ = a.Put("goal", []byte(`{"cw":1}`))
+	if _, err := b.Get("goal"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("agent-b sees agent-a's goal: %v", err)
+	}
+	n := 0
+	_ = b.Scan("history", time.Time{}, func([]byte) { n++ })
+	if n != 0 {
+		t.Errorf("agent-b sees %d of agent-a's history records", n)
+	}
+}
@@ -0,0 +1,84 @@
+// Package store persists the miner's data — state, goal, review hold and
+// inscription history — behind one interface, so it can live in JSON files
+// in the data directory (the default), in a SQLite database shared by a
+// fleet, or in a remote service later on.
+//
+// A backend holds two kinds of data: documents, small JSON values read and
+// replaced whole under a key such as "state", and streams, append-only
+// records such as each inscription attempt in "history".
+package store
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// ErrNotFound is returned by Get for a missing document.
+var ErrNotFound = errors.New("document not found")
+
+// Backend stores documents and record streams. Implementations are safe
+// for concurrent use, including by several processes at once (a running
+// miner and `clawwork goal set`, say).
+type Backend interface {
+	// Name describes the backend for status output, e.g. "files (~/.clawwork)".
+	Name() string
+
+	// Get returns the document saved under key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Put replaces the document under key.
+	Put(key string, data []byte) error
+	// Delete removes the document under key. Deleting a missing key is
+	// not an error.
+	Delete(key string) error
+
+	// Append adds record to stream, stamped at.
+	Append(stream string, at time.Time, record []byte) error
+	// Scan calls fn with the records of stream stamped at or after since,
+	// in the order they were appended. Backends that can only narrow by
+	// month may pass a few earlier records, so callers check times too.
+	// record is only valid during the call.
+	Scan(stream string, since time.Time, fn func(record []byte)) error
+
+	Close() error
+}
+
+// Snapshotter is implemented by backends that keep everything in one
+// database file. Snapshot writes a consistent copy of the database to
+// path, which must not exist yet, while other processes keep writing.
+type Snapshotter interface {
+	Snapshot(path string) error
+}
+
+// DBFile is the SQLite database's name in the data directory when
+// [storage] sets no path.
+const DBFile = "clawwork.db"
+
+// Backend names for [storage] backend.
+const (
+	BackendFiles  = "files"
+	BackendSQLite = "sqlite"
+)
+
+// Open returns the backend configured under [storage].
+func Open(cfg config.StorageConfig) (Backend, error) {
+	switch cfg.Backend {
+	case "", BackendFiles:
+		return NewFiles(config.Dir()), nil
+	case BackendSQLite:
+		path := cfg.Path
+		if path == "" {
+			path = filepath.Join(config.Dir(), DBFile)
+		}
+		ns := cfg.Namespace
+		if ns == "" {
+			ns = "default"
+		}
+		return openSQLite(path, ns)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testBackend checks the behavior every backend must share.
+func testBackend(t *testing.T, b Backend) {
+	t.Helper()
+	if _, err := b.Get("state"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing key: %v, want ErrNotFound", err)
+	}
+	if err := b.Delete("state"); err != nil {
+		t.Fatalf("Delete of a missing key: %v", err)
+	}
+	for _, v := range []string{`{"v":1}`, `{"v":2}`} {
+		if err := b.Put("state", []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := b.Get("state"); err != nil || string(got) != `{"v":2}` {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if err := b.Delete("state"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Get("state"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete: %v", err)
+	}
+
+	now := time.Now().UTC()
+	for i, at := range []time.Time{now.AddDate(0, -3, 0), now.Add(-time.Hour), now} {
+		if err := b.Append("history", at, []byte{'a' + byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got string
+	if err := b.Scan("history", now.Add(-2*time.Hour), func(r []byte) { got += string(r) }); err != nil {
+		t.Fatal(err)
+	}
+	if got != "bc" {
+		t.Errorf("Scan from 2h ago = %q, want bc", got)
+	}
+	got = ""
+	_ = b.Scan("history", time.Time{}, func(r []byte) { got += string(r) })
+	if got != "abc" {
+		t.Errorf("Scan of everything = %q, want abc", got)
+	}
+	if err := b.Scan("missing", time.Time{}, func([]byte) { t.Error("record in a missing stream") }); err != nil {
+		t.Errorf("Scan of a missing stream: %v", err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	testBackend(t, NewFiles(dir))
+
+	// The layout the data directory always had.
+	b := NewFiles(dir)
+	_ = b.Put("goal", []byte(`{}`))
+	if _, err := os.Stat(filepath.Join(dir, "goal.json")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "history", time.Now().UTC().Format("2006-01")+".jsonl")); err != nil {
+		t.Error(err)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, ".*")); len(tmp) > 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+}
@@ -0,0 +1,276 @@
+// Package support builds the diagnostics archive behind `clawwork support
+// bundle`: logs, state, redacted config, version info and recent events,
+// scrubbed of secrets so it can be attached to a public issue.
+package support
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/crash"
+	"github.com/clawplaza/clawwork-cli/internal/daemon"
+	"github.com/clawplaza/clawwork-cli/internal/miner"
+)
+
+const (
+	maxLogBytes   = 512 << 10 // tail kept from each log file
+	maxCrashFiles = 5
+)
+
+// Options describes what goes into a bundle.
+type Options struct {
+	Version    map[string]string // build info, written to version.json
+	Config     *config.Config    // nil if the config could not be loaded
+	Events     int               // how many recent console events to include
+	ConsoleURL string            // running console to fetch events from
+}
+
+// Write creates the archive at path and returns the names of the files it
+// contains. Items that can't be collected are recorded in notes.txt rather
+// than failing the bundle.
+func Write(path string, opts Options) ([]string, error) {
+	b := &builder{scrub: newScrubber(opts.Config)}
+	b.json("version.json", opts.Version)
+	b.collect(opts)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	zw := zip.NewWriter(f)
+	var names []string
+	for _, e := range b.entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: time.Now()})
+		if err == nil {
+			_, err = w.Write(e.data)
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		names = append(names, e.name)
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return names, f.Close()
+}
+
+type entry struct {
+	name string
+	data []byte
+}
+
+type builder struct {
+	scrub   *scrubber
+	entries []entry
+	notes   []string
+}
+
+func (b *builder) add(name string, data []byte) {
+	b.entries = append(b.entries, entry{name, []byte(b.scrub.apply(string(data)))})
+}
+
+func (b *builder) json(name string, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.note("%s: %v", name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+func (b *builder) note(format string, args ...any) {
+	b.notes = append(b.notes, fmt.Sprintf(format, args...))
+}
+
+// history adds the last two months of inscription history, for earnings
+// disputes, as one JSON Lines file per month.
+func (b *builder) history() {
+	now := time.Now().UTC()
+	entries, err := miner.ReadHistory(miner.Store(), time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		b.note("history: %v", err)
+		return
+	}
+	var months []string
+	lines := map[string]*bytes.Buffer{}
+	for _, e := range entries {
+		month := e.At.UTC().Format("2006-01")
+		if lines[month] == nil {
+			lines[month] = new(bytes.Buffer)
+			months = append(months, month)
+		}
+		data, _ := json.Marshal(e)
+		lines[month].Write(append(data, '\n'))
+	}
+	for _, month := range months {
+		name, data := "history/"+month+".jsonl", lines[month].Bytes()
+		if len(data) > maxLogBytes {
+			data = data[len(data)-maxLogBytes:]
+			b.note("%s: truncated to the last %d KB", name, maxLogBytes>>10)
+		}
+		b.add(name, data)
+	}
+}
+
+// file adds a file from disk, keeping only the last limit bytes when
+// limit > 0. A missing file is noted, not an error.
+func (b *builder) file(name, path string, limit int64) {
+	f, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			b.note("%s: %v", name, err)
+		}
+		return
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err == nil && limit > 0 && fi.Size() > limit {
+		_, _ = f.Seek(-limit, io.SeekEnd)
+		b.note("%s: truncated to the last %d KB", name, limit>>10)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		b.note("%s: %v", name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+func (b *builder) collect(opts Options) {
+	dir := config.Dir()
+
+	if opts.Config != nil {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(opts.Config.Redact()); err != nil {
+			b.note("config.toml: %v", err)
+		} else {
+			b.add("config.toml", buf.Bytes())
+		}
+		if opts.Config.Logging.File != "" {
+			b.file("logs/clawwork.log", opts.Config.Logging.File, maxLogBytes)
+		}
+	} else {
+		b.note("config.toml: not loaded")
+	}
+
+	// State and history are read through the store, so the bundle looks
+	// the same whichever [storage] backend holds them.
+	b.json("state.json", miner.LoadState())
+	if g := miner.LoadGoal(); g != nil {
+		b.json("goal.json", g)
+	}
+	if h := miner.LoadReviewHold(); h != nil {
+		b.json("review.json", h)
+	}
+	b.file("prefs.json", filepath.Join(dir, "prefs.json"), 0)
+	b.file("logs/daemon.log", daemon.LogPath(), maxLogBytes)
+	b.history()
+
+	crashes, _ := filepath.Glob(filepath.Join(crash.Dir(), "crash-*.json"))
+	sort.Strings(crashes)
+	if len(crashes) > maxCrashFiles {
+		crashes = crashes[len(crashes)-maxCrashFiles:]
+	}
+	for _, c := range crashes {
+		b.file("crashes/"+filepath.Base(c), c, 0)
+	}
+
+	if info, err := miner.ReadLock(); err == nil {
+		b.json("process.json", map[string]any{"lock": info, "alive": info.Alive()})
+	} else {
+		b.note("process.json: no miner lock (not running)")
+	}
+
+	if opts.Events > 0 && opts.ConsoleURL == "" {
+		b.note("events.json: no running console found")
+	} else if opts.Events > 0 {
+		events, err := fetchEvents(opts.ConsoleURL, opts.Events)
+		if err != nil {
+			b.note("events.json: console not reachable at %s (%v)", opts.ConsoleURL, err)
+		} else {
+			b.add("events.json", events)
+		}
+	}
+
+	if len(b.notes) > 0 {
+		b.add("notes.txt", []byte(strings.Join(b.notes, "\n")+"\n"))
+	}
+}
+
+// fetchEvents asks the running console for its most recent events.
+func fetchEvents(consoleURL string, n int) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	url := fmt.Sprintf("%s/events/recent?n=%d", consoleURL, n)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+}
+
+// scrubber replaces secrets and the home directory in bundle text.
+// Config redaction already masks the config itself; this catches the
+// same values wherever else they turn up (logs, crash stacks, events).
+type scrubber struct {
+	secrets []string
+	home    string
+}
+
+func newScrubber(cfg *config.Config) *scrubber {
+	s := &scrubber{}
+	if home, err := os.UserHomeDir(); err == nil && home != "/" {
+		s.home = home
+	}
+	if cfg == nil {
+		return s
+	}
+	candidates := []string{cfg.Agent.APIKey, cfg.LLM.APIKey, cfg.MQTT.Password, cfg.Web.RemoteToken, cfg.Web.ConsoleToken, cfg.Backup.WebDAVPassword, cfg.Sync.SecretKey, cfg.Sync.Password, cfg.Experiment.A.APIKey, cfg.Experiment.B.APIKey, cfg.Callback.Secret}
+	for _, v := range cfg.LLM.Headers {
+		candidates = append(candidates, v)
+	}
+	for _, ch := range cfg.Notify.Channels {
+		candidates = append(candidates, ch.URL)
+	}
+	for _, c := range candidates {
+		if len(c) >= 6 {
+			s.secrets = append(s.secrets, c)
+		}
+	}
+	// Longest first, so a secret containing another is replaced whole.
+	sort.Slice(s.secrets, func(i, j int) bool { return len(s.secrets[i]) > len(s.secrets[j]) })
+	return s
+}
+
+func (s *scrubber) apply(text string) string {
+	for _, secret := range s.secrets {
+		text = strings.ReplaceAll(text, secret, "[REDACTED]")
+	}
+	if s.home != "" {
+		text = strings.ReplaceAll(text, s.home, "~")
+	}
+	return text
+}
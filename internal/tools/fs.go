@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -39,11 +40,38 @@ func isBlockedPath(path string) bool {
 // FilesystemTool provides a unified interface for local filesystem operations.
 // All operations are routed through a single tool to reduce the number of tools
 // the LLM needs to reason about.
-type FilesystemTool struct{}
+type FilesystemTool struct {
+	dir      string // base for relative paths; empty means the process cwd
+	readOnly bool   // only read/list/info are offered and accepted
+}
+
+// readOnlyOps are the operations allowed when the tool is read-only.
+var readOnlyOps = []string{"read", "list", "info"}
 
 func NewFilesystemTool() *FilesystemTool { return &FilesystemTool{} }
 
 func (t *FilesystemTool) Def() ToolDef {
+	if t.readOnly {
+		return ToolDef{
+			Name:        "filesystem",
+			Description: "Read-only local filesystem access: read files, list directories, show file metadata.",
+			Parameters: ToolParameters{
+				Type: "object",
+				Properties: map[string]ToolProperty{
+					"operation": {
+						Type:        "string",
+						Description: "read=read file, list=list dir, info=file metadata",
+						Enum:        readOnlyOps,
+					},
+					"path": {
+						Type:        "string",
+						Description: "File or directory path (relative paths resolve inside the session workspace)",
+					},
+				},
+				Required: []string{"operation", "path"},
+			},
+		}
+	}
 	return ToolDef{
 		Name:        "filesystem",
 		Description: "Local filesystem operations. Write/delete/move blocked for system paths (/etc, /bin, /System, etc.).",
@@ -52,12 +80,12 @@ func (t *FilesystemTool) Def() ToolDef {
 			Properties: map[string]ToolProperty{
 				"operation": {
 					Type:        "string",
-					Description: "read=read file, write=create/overwrite file, list=list dir, mkdir=create dirs, move=rename/move, delete=remove, info=file metadata",
+					Description: "read=read file, write=create/overwrite file, list=list dir, mkdir=create dirs, move=rename/move, delete=remove a file or empty dir, info=file metadata",
 					Enum:        []string{"read", "write", "list", "mkdir", "move", "delete", "info"},
 				},
 				"path": {
 					Type:        "string",
-					Description: "File or directory path",
+					Description: "File or directory path (relative paths resolve inside the session workspace)",
 				},
 				"content": {
 					Type:        "string",
@@ -88,6 +116,11 @@ func (t *FilesystemTool) Call(_ context.Context, argsJSON string) string {
 	if args.Path == "" {
 		return "error: path is required"
 	}
+	if t.readOnly && !slices.Contains(readOnlyOps, args.Operation) {
+		return fmt.Sprintf("error: operation %q is not allowed — tools are read-only in this session", args.Operation)
+	}
+	args.Path = resolvePath(t.dir, args.Path)
+	args.Dest = resolvePath(t.dir, args.Dest)
 
 	switch args.Operation {
 	case "read":
@@ -232,6 +265,13 @@ func fsDelete(path string) string {
 	if isBlockedPath(path) {
 		return fmt.Sprintf("error: deleting %q is not allowed (system path)", path)
 	}
+	if t := trash.Load(); t != nil {
+		item, err := t.Put(path)
+		if err != nil {
+			return fmt.Sprintf("error: delete: %v", err)
+		}
+		return fmt.Sprintf("ok: deleted %s (moved to trash as %s; the user can restore it)", item.Path, item.ID)
+	}
 	if err := os.Remove(path); err != nil {
 		return fmt.Sprintf("error: delete: %v", err)
 	}
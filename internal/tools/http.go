@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
 )
 
 const (
@@ -18,17 +20,38 @@ const (
 // HTTPFetchTool fetches a URL and returns the response body.
 // Supports GET and POST. Safe: always runs in-process, no shell.
 type HTTPFetchTool struct {
-	client *http.Client
+	client  *http.Client
+	getOnly bool // read-only sessions: refuse POST
 }
 
 // NewHTTPFetchTool creates a new HTTP fetch tool with a 20-second timeout.
 func NewHTTPFetchTool() *HTTPFetchTool {
 	return &HTTPFetchTool{
-		client: &http.Client{Timeout: httpTimeout},
+		client: httpx.Client("tools", httpTimeout),
 	}
 }
 
 func (t *HTTPFetchTool) Def() ToolDef {
+	if t.getOnly {
+		return ToolDef{
+			Name:        "http_fetch",
+			Description: "HTTP GET a URL. Use for web pages, JSON APIs, or any remote resource. Returns response body (text/JSON/HTML). Max 512KB.",
+			Parameters: ToolParameters{
+				Type: "object",
+				Properties: map[string]ToolProperty{
+					"url": {
+						Type:        "string",
+						Description: "Full URL (http:// or https://)",
+					},
+					"headers": {
+						Type:        "object",
+						Description: "HTTP headers as key-value pairs",
+					},
+				},
+				Required: []string{"url"},
+			},
+		}
+	}
 	return ToolDef{
 		Name:        "http_fetch",
 		Description: "HTTP GET or POST a URL. Use for web pages, JSON APIs, or any remote resource. Returns response body (text/JSON/HTML). Max 512KB.",
@@ -78,6 +101,9 @@ func (t *HTTPFetchTool) Call(ctx context.Context, argsJSON string) string {
 	if strings.ToUpper(args.Method) == "POST" {
 		method = "POST"
 	}
+	if t.getOnly && (method != "GET" || args.Body != "") {
+		return "error: POST is not allowed — tools are read-only in this session"
+	}
 
 	var bodyReader io.Reader
 	if args.Body != "" {
@@ -46,6 +46,10 @@ func RunAgentLoop(
 	var used []ToolUse
 
 	for round := 0; round < maxToolRounds; round++ {
+		// Stop between rounds once the caller has gone away.
+		if err := ctx.Err(); err != nil {
+			return "", used, err
+		}
 		content, reasoningContent, toolCalls, finishReason, err := provider.ChatWithTools(ctx, msgs, toolDefs)
 		if err != nil {
 			return "", used, err
@@ -68,6 +72,9 @@ func RunAgentLoop(
 
 		// Execute each requested tool and append the results.
 		for _, call := range toolCalls {
+			if err := ctx.Err(); err != nil {
+				return "", used, err
+			}
 			result := dispatchTool(ctx, toolMap, call)
 			used = append(used, ToolUse{Name: call.Name, Summary: truncate80(result)})
 			msgs = append(msgs, Message{
@@ -0,0 +1,43 @@
+package tools
+
+import "fmt"
+
+// Permission selects which built-in tools an agent conversation may use.
+type Permission string
+
+const (
+	// PermissionFull allows every built-in tool, including shell and scripts.
+	PermissionFull Permission = "full"
+	// PermissionReadOnly allows fetching URLs with GET and reading local files.
+	// Nothing that executes code or changes the filesystem is offered.
+	PermissionReadOnly Permission = "read-only"
+	// PermissionNone disables tool calling entirely.
+	PermissionNone Permission = "none"
+)
+
+// ParsePermission validates a permission name. An empty string means full,
+// which is what sessions created before permissions existed get.
+func ParsePermission(s string) (Permission, error) {
+	switch p := Permission(s); p {
+	case "":
+		return PermissionFull, nil
+	case PermissionFull, PermissionReadOnly, PermissionNone:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown tool permission %q (use full, read-only or none)", s)
+}
+
+// ForPermission returns the built-in tools allowed under p, rooted at dir
+// (see DefaultsIn). PermissionNone returns nil.
+func ForPermission(p Permission, dir string) []Tool {
+	switch p {
+	case PermissionNone:
+		return nil
+	case PermissionReadOnly:
+		return []Tool{
+			&HTTPFetchTool{client: NewHTTPFetchTool().client, getOnly: true},
+			&FilesystemTool{dir: dir, readOnly: true},
+		}
+	}
+	return DefaultsIn(dir)
+}
@@ -18,7 +18,9 @@ const (
 // RunScriptTool executes a Python or JavaScript (Node.js) snippet.
 // Requires python3 or node to be installed on the host machine.
 // Falls back gracefully with a "not found" message if the runtime is absent.
-type RunScriptTool struct{}
+type RunScriptTool struct {
+	dir string // working directory for the script; empty means the process cwd
+}
 
 // NewRunScriptTool creates a new script execution tool.
 func NewRunScriptTool() *RunScriptTool {
@@ -70,6 +72,8 @@ func (t *RunScriptTool) Call(ctx context.Context, argsJSON string) string {
 		return fmt.Sprintf("error: unsupported language %q (use python or javascript)", args.Language)
 	}
 
+	cmd.Dir = t.dir
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
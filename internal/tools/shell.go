@@ -19,7 +19,9 @@ const (
 // ShellExecTool executes an arbitrary shell command on the local machine.
 // On Unix/macOS it uses sh -c; on Windows cmd /c.
 // This is the most flexible tool — use it for curl, wget, git, grep, jq, etc.
-type ShellExecTool struct{}
+type ShellExecTool struct {
+	dir string // default working directory; empty means the process cwd
+}
 
 func NewShellExecTool() *ShellExecTool { return &ShellExecTool{} }
 
@@ -36,7 +38,7 @@ func (t *ShellExecTool) Def() ToolDef {
 				},
 				"workdir": {
 					Type:        "string",
-					Description: "Working directory (optional)",
+					Description: "Working directory (optional, defaults to the session workspace)",
 				},
 			},
 			Required: []string{"command"},
@@ -69,7 +71,9 @@ func (t *ShellExecTool) Call(ctx context.Context, argsJSON string) string {
 	}
 
 	if args.WorkDir != "" {
-		cmd.Dir = args.WorkDir
+		cmd.Dir = resolvePath(t.dir, args.WorkDir)
+	} else {
+		cmd.Dir = t.dir
 	}
 
 	var out bytes.Buffer
@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
+)
+
+// checkTimeout bounds each network check.
+const checkTimeout = 10 * time.Second
+
+// SystemCheckTool runs fixed, read-only health checks of the machine the
+// miner runs on: network path to a URL, clock skew against a server's
+// Date header, and free space where the miner writes. It runs nothing
+// the model supplies, so it is safe to offer without a human watching.
+type SystemCheckTool struct {
+	url    string // default target for connectivity and clock
+	dir    string // default target for disk
+	client *http.Client
+}
+
+// NewSystemCheckTool checks url (usually the platform) and dir (the data
+// directory) unless the model names other targets.
+func NewSystemCheckTool(url, dir string) *SystemCheckTool {
+	return &SystemCheckTool{url: url, dir: dir, client: httpx.Client("tools", checkTimeout)}
+}
+
+func (t *SystemCheckTool) Def() ToolDef {
+	return ToolDef{
+		Name:        "system_check",
+		Description: "Check this machine: connectivity (DNS, TCP and HTTP to a URL, default the platform), clock (skew against the server's time) or disk (free space and write access, default the data directory).",
+		Parameters: ToolParameters{
+			Type: "object",
+			Properties: map[string]ToolProperty{
+				"check": {
+					Type:        "string",
+					Description: "Which check to run",
+					Enum:        []string{"connectivity", "clock", "disk"},
+				},
+				"target": {
+					Type:        "string",
+					Description: "URL (connectivity, clock) or directory (disk); omit for the defaults",
+				},
+			},
+			Required: []string{"check"},
+		},
+	}
+}
+
+func (t *SystemCheckTool) Call(ctx context.Context, argsJSON string) string {
+	var args struct {
+		Check  string `json:"check"`
+		Target string `json:"target"`
+	}
+	if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+		return fmt.Sprintf("error: invalid arguments: %v", err)
+	}
+	switch args.Check {
+	case "connectivity":
+		return t.connectivity(ctx, cmp.Or(args.Target, t.url))
+	case "clock":
+		return t.clock(ctx, cmp.Or(args.Target, t.url))
+	case "disk":
+		return checkDisk(cmp.Or(args.Target, t.dir))
+	}
+	return fmt.Sprintf("error: unknown check %q (use connectivity/clock/disk)", args.Check)
+}
+
+// RunAll runs every check against the defaults, one result per line, for
+// models that can't call tools.
+func (t *SystemCheckTool) RunAll(ctx context.Context) string {
+	return strings.Join([]string{
+		"connectivity: " + t.connectivity(ctx, t.url),
+		"clock: " + t.clock(ctx, t.url),
+		"disk: " + checkDisk(t.dir),
+	}, "\n")
+}
+
+func (t *SystemCheckTool) connectivity(ctx context.Context, raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Sprintf("error: %q is not a URL", raw)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var out []string
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Sprintf("dns FAILED for %s: %v", u.Hostname(), err)
+	}
+	out = append(out, fmt.Sprintf("dns ok (%s, %s)", strings.Join(addrs, " "), time.Since(start).Round(time.Millisecond)))
+
+	start = time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		// A proxy may still get through; report and carry on.
+		out = append(out, fmt.Sprintf("direct tcp :%s FAILED: %v", port, err))
+	} else {
+		conn.Close()
+		out = append(out, fmt.Sprintf("tcp :%s ok (%s)", port, time.Since(start).Round(time.Millisecond)))
+	}
+
+	start = time.Now()
+	resp, err := t.head(ctx, raw)
+	if err != nil {
+		return strings.Join(append(out, fmt.Sprintf("http FAILED: %v", err)), "; ")
+	}
+	resp.Body.Close()
+	out = append(out, fmt.Sprintf("http %d (%s)", resp.StatusCode, time.Since(start).Round(time.Millisecond)))
+	return strings.Join(out, "; ")
+}
+
+func (t *SystemCheckTool) clock(ctx context.Context, raw string) string {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	sent := time.Now()
+	resp, err := t.head(ctx, raw)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	resp.Body.Close()
+	server, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return "error: the server sent no usable Date header"
+	}
+	// Compare against the midpoint of the request; Date has 1s resolution.
+	local := sent.Add(time.Since(sent) / 2)
+	skew := local.Sub(server).Round(time.Second)
+	switch {
+	case skew > 2*time.Second:
+		return fmt.Sprintf("local clock is %s AHEAD of the server", skew)
+	case skew < -2*time.Second:
+		return fmt.Sprintf("local clock is %s BEHIND the server", -skew)
+	}
+	return "ok: local clock matches the server (within 2s)"
+}
+
+func (t *SystemCheckTool) head(ctx context.Context, raw string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, raw, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.Do(req)
+}
+
+func checkDisk(dir string) string {
+	free, err := diskFree(dir)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	result := fmt.Sprintf("%s: %d MB free", dir, free>>20)
+	f, err := os.CreateTemp(dir, ".clawwork-check-*")
+	if err != nil {
+		return result + fmt.Sprintf("; write FAILED: %v", err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	if free < 100<<20 {
+		return result + "; LOW — under 100 MB"
+	}
+	return result + "; writable"
+}
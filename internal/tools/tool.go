@@ -2,7 +2,10 @@
 // It defines the Tool interface, shared types, and the agentic loop.
 package tools
 
-import "context"
+import (
+	"context"
+	"path/filepath"
+)
 
 // Tool is a callable function the agent can invoke.
 type Tool interface {
@@ -35,17 +38,17 @@ type ToolProperty struct {
 
 // Message is a chat message that supports all roles including tool results.
 type Message struct {
-	Role             string     `json:"role"`                       // system, user, assistant, tool
-	Content          string     `json:"content,omitempty"`          // text content
+	Role             string     `json:"role"`                        // system, user, assistant, tool
+	Content          string     `json:"content,omitempty"`           // text content
 	ReasoningContent string     `json:"reasoning_content,omitempty"` // thinking tokens (Kimi, DeepSeek-R1, etc.)
-	ToolCallID       string     `json:"tool_call_id,omitempty"`     // for role=tool
-	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`       // for assistant with pending calls
+	ToolCallID       string     `json:"tool_call_id,omitempty"`      // for role=tool
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`        // for assistant with pending calls
 }
 
 // ToolCall is a tool invocation requested by the LLM.
 type ToolCall struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
 	ArgsJSON string `json:"args_json"` // JSON-encoded arguments
 }
 
@@ -64,10 +67,26 @@ type ChatToolProvider interface {
 
 // Defaults returns all built-in tools available to the agent.
 func Defaults() []Tool {
+	return DefaultsIn("")
+}
+
+// DefaultsIn returns the built-in tools rooted at dir: relative paths and
+// command working directories resolve against it. An empty dir means the
+// process working directory, same as Defaults.
+func DefaultsIn(dir string) []Tool {
 	return []Tool{
-		NewShellExecTool(),   // shell: curl/wget/git/grep/jq/etc.
-		NewHTTPFetchTool(),   // native HTTP GET/POST (no shell required)
-		NewRunScriptTool(),   // execute Python or JavaScript
-		NewFilesystemTool(),  // read/write/list/mkdir/move/delete/info
+		&ShellExecTool{dir: dir},  // shell: curl/wget/git/grep/jq/etc.
+		NewHTTPFetchTool(),        // native HTTP GET/POST (no shell required)
+		&RunScriptTool{dir: dir},  // execute Python or JavaScript
+		&FilesystemTool{dir: dir}, // read/write/list/mkdir/move/delete/info
+	}
+}
+
+// resolvePath joins a relative path onto dir. Absolute paths, and any path
+// when dir is empty, are returned unchanged.
+func resolvePath(dir, path string) string {
+	if dir == "" || path == "" || filepath.IsAbs(path) {
+		return path
 	}
+	return filepath.Join(dir, path)
 }
@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Trash keeps what the filesystem tool deletes for a while, so an agent
+// can't destroy files for good during a chat. Each entry is the deleted
+// file itself, renamed to its ID, plus <id>.json describing it.
+type Trash struct {
+	Dir string        // e.g. ~/.clawwork/trash
+	TTL time.Duration // entries older than this are purged; 0 keeps them
+}
+
+// TrashItem describes one deleted file or directory.
+type TrashItem struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"` // absolute path it was deleted from
+	DeletedAt time.Time `json:"deleted_at"`
+	IsDir     bool      `json:"is_dir,omitempty"`
+	Size      int64     `json:"size"`
+}
+
+// trash is where fsDelete puts files; nil deletes them permanently.
+var trash atomic.Pointer[Trash]
+
+// SetTrash routes filesystem tool deletions into t. nil makes them
+// permanent again.
+func SetTrash(t *Trash) { trash.Store(t) }
+
+// Put moves path into the trash. Like a plain delete it only takes files,
+// symlinks and empty directories. Expired entries are purged on the way.
+func (t *Trash) Put(path string) (TrashItem, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			return TrashItem{}, err
+		}
+		if len(entries) > 0 {
+			return TrashItem{}, fmt.Errorf("%s: directory not empty", abs)
+		}
+	}
+	if err := os.MkdirAll(t.Dir, 0700); err != nil {
+		return TrashItem{}, err
+	}
+	t.Purge()
+
+	item := TrashItem{ID: newTrashID(), Path: abs, DeletedAt: time.Now().UTC(), IsDir: info.IsDir(), Size: info.Size()}
+	data, _ := json.MarshalIndent(item, "", "  ")
+	if err := os.WriteFile(t.metaPath(item.ID), data, 0600); err != nil {
+		return TrashItem{}, err
+	}
+	if err := moveEntry(abs, t.itemPath(item.ID), info); err != nil {
+		os.Remove(t.metaPath(item.ID))
+		return TrashItem{}, err
+	}
+	return item, nil
+}
+
+// List returns the entries in the trash, newest first.
+func (t *Trash) List() ([]TrashItem, error) {
+	matches, err := filepath.Glob(filepath.Join(t.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var items []TrashItem
+	for _, m := range matches {
+		item, err := t.read(strings.TrimSuffix(filepath.Base(m), ".json"))
+		if err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// Restore moves an entry back to dest, or to where it was deleted from if
+// dest is empty. It never overwrites an existing file.
+func (t *Trash) Restore(id, dest string) (string, error) {
+	item, err := t.read(id)
+	if err != nil {
+		return "", err
+	}
+	if dest == "" {
+		dest = item.Path
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return "", fmt.Errorf("%s already exists — restore to another path", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	src := t.itemPath(id)
+	info, err := os.Lstat(src)
+	if err != nil {
+		return "", err
+	}
+	if err := moveEntry(src, dest, info); err != nil {
+		return "", err
+	}
+	os.Remove(t.metaPath(id))
+	return dest, nil
+}
+
+// Remove deletes one entry permanently.
+func (t *Trash) Remove(id string) error {
+	if _, err := t.read(id); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(t.itemPath(id)); err != nil {
+		return err
+	}
+	return os.Remove(t.metaPath(id))
+}
+
+// Purge permanently deletes entries older than the TTL and returns how
+// many went.
+func (t *Trash) Purge() int {
+	if t.TTL <= 0 {
+		return 0
+	}
+	items, _ := t.List()
+	n := 0
+	for _, item := range items {
+		if time.Since(item.DeletedAt) > t.TTL && t.Remove(item.ID) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (t *Trash) read(id string) (TrashItem, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return TrashItem{}, fmt.Errorf("no trash entry %q", id)
+	}
+	data, err := os.ReadFile(t.metaPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return TrashItem{}, fmt.Errorf("no trash entry %q", id)
+	} else if err != nil {
+		return TrashItem{}, err
+	}
+	var item TrashItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return TrashItem{}, fmt.Errorf("trash entry %s: %w", id, err)
+	}
+	return item, nil
+}
+
+func (t *Trash) itemPath(id string) string { return filepath.Join(t.Dir, id) }
+func (t *Trash) metaPath(id string) string { return filepath.Join(t.Dir, id+".json") }
+
+// newTrashID is sortable and short enough to type into `clawwork trash restore`.
+func newTrashID() string {
+	b := make([]byte, 2)
+	rand.Read(b)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// moveEntry renames src to dest, copying across filesystems (e.g. from
+// /tmp into the home directory). src is a file, symlink or empty directory.
+func moveEntry(src, dest string, info os.FileInfo) error {
+	err := os.Rename(src, dest)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	switch {
+	case info.IsDir():
+		err = os.Mkdir(dest, info.Mode().Perm())
+	case info.Mode()&os.ModeSymlink != 0:
+		var target string
+		if target, err = os.Readlink(src); err == nil {
+			err = os.Symlink(target, dest)
+		}
+	case info.Mode().IsRegular():
+		err = copyFile(src, dest, info.Mode().Perm())
+	default:
+		return fmt.Errorf("%s: cannot move %s across filesystems", src, info.Mode().Type())
+	}
+	if err != nil {
+		os.Remove(dest)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// isCrossDevice reports a rename refused because src and dest are on
+// different filesystems (EXDEV; ERROR_NOT_SAME_DEVICE on Windows).
+func isCrossDevice(err error) bool {
+	var le *os.LinkError
+	if !errors.As(err, &le) {
+		return false
+	}
+	msg := le.Err.Error()
+	return strings.Contains(msg, "cross-device") || strings.Contains(msg, "different disk drive")
+}
+
+func copyFile(src, dest string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -3,8 +3,13 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ── tool def size ─────────────────────────────────────────────────────────────
@@ -181,6 +186,51 @@ func TestFilesystem_WriteReadDelete(t *testing.T) {
 	}
 }
 
+func TestFilesystem_DeleteToTrash(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	tr := &Trash{Dir: filepath.Join(dir, "trash"), TTL: time.Hour}
+	SetTrash(tr)
+	defer SetTrash(nil)
+
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := &FilesystemTool{dir: dir}
+	if out := tool.Call(ctx, `{"operation":"delete","path":"notes.txt"}`); !strings.Contains(out, "moved to trash") {
+		t.Fatalf("delete: %q", out)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("file still there after delete: %v", err)
+	}
+
+	items, err := tr.List()
+	if err != nil || len(items) != 1 || items[0].Path != path {
+		t.Fatalf("List() = %+v, %v", items, err)
+	}
+	if _, err := tr.Restore(items[0].ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep me" {
+		t.Fatalf("restored %q", data)
+	}
+
+	// Entries past the TTL are purged.
+	os.WriteFile(path, nil, 0644)
+	old, _ := tr.Put(path)
+	meta := filepath.Join(tr.Dir, old.ID+".json")
+	old.DeletedAt = old.DeletedAt.Add(-2 * time.Hour)
+	data, _ := json.Marshal(old)
+	os.WriteFile(meta, data, 0600)
+	if n := tr.Purge(); n != 1 {
+		t.Fatalf("Purge() = %d, want 1", n)
+	}
+	if items, _ := tr.List(); len(items) != 0 {
+		t.Fatalf("after purge: %+v", items)
+	}
+}
+
 func TestFilesystem_List(t *testing.T) {
 	ctx := context.Background()
 	tool := NewFilesystemTool()
@@ -224,6 +274,31 @@ func TestFilesystem_ReadDir(t *testing.T) {
 	}
 }
 
+// ── system check ──────────────────────────────────────────────────────────────
+
+func TestSystemCheck(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Five minutes slow.
+		w.Header().Set("Date", time.Now().Add(-5*time.Minute).UTC().Format(http.TimeFormat))
+	}))
+	defer srv.Close()
+	tool := NewSystemCheckTool(srv.URL, t.TempDir())
+
+	if out := tool.Call(ctx, `{"check":"connectivity"}`); !strings.Contains(out, "dns ok") || !strings.Contains(out, "http 200") {
+		t.Errorf("connectivity: %q", out)
+	}
+	if out := tool.Call(ctx, `{"check":"clock"}`); !strings.Contains(out, "AHEAD") {
+		t.Errorf("clock: %q", out)
+	}
+	if out := tool.Call(ctx, `{"check":"disk"}`); !strings.Contains(out, "MB free") {
+		t.Errorf("disk: %q", out)
+	}
+	if out := tool.Call(ctx, `{"check":"rm -rf /"}`); !strings.HasPrefix(out, "error:") {
+		t.Errorf("unknown check accepted: %q", out)
+	}
+}
+
 // ── helper ────────────────────────────────────────────────────────────────────
 
 func min(a, b int) int {
@@ -0,0 +1,47 @@
+package updater
+
+import "time"
+
+// minForcedInterval spaces out the checks the platform asks for, in case
+// the CDN lags behind the platform's idea of the latest version.
+const minForcedInterval = time.Hour
+
+// Auto checks for updates on a schedule and installs them, for a
+// long-running miner (updater.auto).
+type Auto struct {
+	Current  string        // the running version
+	Channel  string        // "" for stable
+	Interval time.Duration // between scheduled checks
+
+	last time.Time
+}
+
+// Poll checks for an update when one is due and installs it. A check is
+// due every Interval, or hourly with force (the platform reported a newer
+// client). It returns the installed version, "" when nothing changed.
+func (a *Auto) Poll(force bool) (string, error) {
+	wait := a.Interval
+	if force && wait > minForcedInterval {
+		wait = minForcedInterval
+	}
+	now := time.Now()
+	if !a.last.IsZero() && now.Sub(a.last) < wait {
+		return "", nil
+	}
+	a.last = now
+
+	info, err := CheckUpdate(a.Current, a.Channel)
+	if err != nil || info == nil {
+		return "", err
+	}
+	if err := Apply(info); err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// Verifiable reports why this build can't verify updates, nil when it can.
+func Verifiable() error {
+	_, err := publicKey()
+	return err
+}
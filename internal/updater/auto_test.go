@@ -0,0 +1,221 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// releaseServer is a CDN serving a signed manifest per channel and the
+// archives they list.
+type releaseServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+func newReleaseServer(t *testing.T, channels map[string]string) *releaseServer {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for channel, ver := range channels {
+		archive := tarGz(t, "clawwork", []byte("clawwork "+ver))
+		sum := sha256.Sum256(archive)
+		name := archiveName(ver)
+		manifest, _ := json.Marshal(VersionInfo{Version: ver, Checksums: map[string]string{name: hex.EncodeToString(sum[:])}})
+		files["/"+manifestName(channel)] = manifest
+		files["/"+manifestName(channel)+".sig"] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, manifest)))
+		files["/v"+ver+"/"+name] = archive
+	}
+	s := &releaseServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.mu.Unlock()
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+
+	oldBase, oldKey, oldExec := cdnBase, PublicKey, executable
+	t.Cleanup(func() { cdnBase, PublicKey, executable = oldBase, oldKey, oldExec })
+	cdnBase, PublicKey = s.URL, base64.StdEncoding.EncodeToString(pub)
+	return s
+}
+
+// checks counts the manifest requests so far.
+func (s *releaseServer) checks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, p := range s.requests {
+		if filepath.Ext(p) == ".json" {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *releaseServer) fetched(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.requests {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func tarGz(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(data)
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// Windows releases are zips, everything else tar.gz.
+func TestExtractBinary(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		archive []byte
+		want    string
+	}{
+		{"clawwork_1.1.0_linux_amd64.tar.gz", tarGz(t, "clawwork", []byte("linux build")), "linux build"},
+		{"clawwork_1.1.0_windows_amd64.zip", zipArchive(t, "clawwork_1.1.0/clawwork.exe", []byte("windows build")), "windows build"},
+	} {
+		path, err := extractBinary(tc.name, tc.archive)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		data, _ := os.ReadFile(path)
+		os.Remove(path)
+		if string(data) != tc.want {
+			t.Errorf("%s: extracted %q, want %q", tc.name, data, tc.want)
+		}
+	}
+	if _, err := extractBinary("x.zip", zipArchive(t, "README.md", nil)); err == nil {
+		t.Error("zip without a binary accepted")
+	}
+}
+
+// installTarget points Apply at a stand-in for the running binary.
+func installTarget(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "clawwork")
+	if err := os.WriteFile(path, []byte("clawwork 1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	executable = func() (string, error) { return path, nil }
+	return path
+}
+
+func TestAutoPoll(t *testing.T) {
+	srv := newReleaseServer(t, map[string]string{ChannelStable: "1.1.0"})
+	target := installTarget(t)
+	a := &Auto{Current: "1.0.0", Interval: 6 * time.Hour}
+
+	ver, err := a.Poll(false)
+	if err != nil || ver != "1.1.0" {
+		t.Fatalf("first poll = %q, %v; want 1.1.0 installed", ver, err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "clawwork 1.1.0" {
+		t.Errorf("installed binary = %q", data)
+	}
+
+	// Within the interval nothing is fetched, forced or not.
+	if ver, err := a.Poll(false); ver != "" || err != nil || srv.checks() != 1 {
+		t.Errorf("poll within the interval: %q, %v, %d checks", ver, err, srv.checks())
+	}
+	if ver, err := a.Poll(true); ver != "" || err != nil || srv.checks() != 1 {
+		t.Errorf("forced poll within an hour: %q, %v, %d checks", ver, err, srv.checks())
+	}
+
+	// Two hours on, the schedule still waits but the platform's nudge
+	// brings the check forward.
+	a.Current = "1.1.0"
+	a.last = time.Now().Add(-2 * time.Hour)
+	if a.Poll(false); srv.checks() != 1 {
+		t.Errorf("scheduled poll before the interval checked (%d checks)", srv.checks())
+	}
+	if ver, err := a.Poll(true); ver != "" || err != nil || srv.checks() != 2 {
+		t.Errorf("forced poll after an hour: %q, %v, %d checks; want a check finding nothing new", ver, err, srv.checks())
+	}
+
+	a.last = time.Now().Add(-7 * time.Hour)
+	if a.Poll(false); srv.checks() != 3 {
+		t.Errorf("scheduled poll after the interval didn't check (%d checks)", srv.checks())
+	}
+}
+
+func TestAutoPollChannel(t *testing.T) {
+	srv := newReleaseServer(t, map[string]string{ChannelStable: "1.0.0", ChannelBeta: "1.1.0-beta.1"})
+	target := installTarget(t)
+
+	stable := &Auto{Current: "1.0.0", Interval: time.Hour}
+	if ver, err := stable.Poll(false); ver != "" || err != nil {
+		t.Errorf("stable poll = %q, %v; want nothing new", ver, err)
+	}
+	beta := &Auto{Current: "1.0.0", Channel: ChannelBeta, Interval: time.Hour}
+	if ver, err := beta.Poll(false); ver != "1.1.0-beta.1" || err != nil {
+		t.Errorf("beta poll = %q, %v; want 1.1.0-beta.1", ver, err)
+	}
+	if !srv.fetched("/version-beta.json") || !srv.fetched("/version-beta.json.sig") {
+		t.Errorf("beta manifest or signature not fetched: %v", srv.requests)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "clawwork 1.1.0-beta.1" {
+		t.Errorf("installed binary = %q", data)
+	}
+
+	// A manifest the release key didn't sign installs nothing.
+	_, other, _ := ed25519.GenerateKey(nil)
+	PublicKey = base64.StdEncoding.EncodeToString(other.Public().(ed25519.PublicKey))
+	old := &Auto{Current: "0.9.0", Interval: time.Hour}
+	if ver, err := old.Poll(false); err == nil || ver != "" {
+		t.Errorf("poll under another key = %q, %v; want refusal", ver, err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "clawwork 1.1.0-beta.1" {
+		t.Errorf("binary replaced despite a bad signature: %q", data)
+	}
+}
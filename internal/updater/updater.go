@@ -1,38 +1,107 @@
 // Package updater implements self-update from Cloudflare R2 CDN.
 //
 // R2 layout:
-//   dl.clawplaza.ai/clawwork/version.json              — latest version manifest
+//   dl.clawplaza.ai/clawwork/version.json               — latest stable release manifest
+//   dl.clawplaza.ai/clawwork/version.json.sig           — Ed25519 signature of version.json, base64
+//   dl.clawplaza.ai/clawwork/version-beta.json(.sig)    — latest beta or stable, whichever is newer
+//   dl.clawplaza.ai/clawwork/version-nightly.json(.sig) — latest nightly, beta or stable
 //   dl.clawplaza.ai/clawwork/v0.1.0/clawwork_0.1.0_darwin_arm64.tar.gz
 //
 // version.json:
-//   { "version": "0.1.1", "changelog": "bug fixes" }
+//   { "version": "0.1.1", "changelog": "bug fixes",
+//     "checksums": { "clawwork_0.1.1_darwin_arm64.tar.gz": "<sha256 hex>", ... } }
+//
+// Apply installs nothing the release key didn't sign: the signature covers
+// the manifest, and the manifest pins each archive's SHA-256.
 package updater
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/httpx"
+)
+
+// cdnBase and executable are variables so tests can serve releases
+// locally and install them somewhere harmless.
+var (
+	cdnBase    = "https://dl.clawplaza.ai/clawwork"
+	executable = os.Executable
 )
 
-const cdnBase = "https://dl.clawplaza.ai/clawwork"
+// Download size limits.
+const (
+	maxManifestSize = 1 << 20
+	maxArchiveSize  = 256 << 20
+)
+
+// Release channels, from most to least conservative.
+const (
+	ChannelStable  = "stable"
+	ChannelBeta    = "beta"
+	ChannelNightly = "nightly"
+)
+
+// Channels lists the valid channels. It is the list config validates
+// updater.channel against.
+var Channels = config.UpdateChannels
+
+// PublicKey is the base64 Ed25519 key release manifests are signed with,
+// set at build time (-ldflags "-X .../updater.PublicKey=..."). A build
+// without it can't verify updates and refuses to install them.
+var PublicKey string
 
 // VersionInfo is the remote version manifest.
 type VersionInfo struct {
-	Version   string `json:"version"`
-	Changelog string `json:"changelog"`
+	Version   string            `json:"version"`
+	Changelog string            `json:"changelog"`
+	Checksums map[string]string `json:"checksums,omitempty"` // archive name → SHA-256, hex
+
+	manifest []byte // the manifest as served, for the signature check
+	channel  string
 }
 
-// CheckUpdate fetches the latest version from R2.
-func CheckUpdate(current string) (*VersionInfo, error) {
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(cdnBase + "/version.json")
+// ValidChannel reports whether ch is a release channel; "" means stable.
+func ValidChannel(ch string) bool {
+	for _, c := range Channels {
+		if ch == c {
+			return true
+		}
+	}
+	return ch == ""
+}
+
+// manifestName returns the channel's manifest file on the CDN.
+func manifestName(channel string) string {
+	if channel == "" || channel == ChannelStable {
+		return "version.json"
+	}
+	return "version-" + channel + ".json"
+}
+
+// CheckUpdate fetches the latest version on channel ("" for stable) from R2.
+func CheckUpdate(current, channel string) (*VersionInfo, error) {
+	if !ValidChannel(channel) {
+		return nil, fmt.Errorf("unknown update channel %q (want %s)", channel, strings.Join(Channels, ", "))
+	}
+	client := httpx.Client("update", 15*time.Second)
+	resp, err := client.Get(cdnBase + "/" + manifestName(channel))
 	if err != nil {
 		return nil, fmt.Errorf("failed to check for updates: %w", err)
 	}
@@ -42,10 +111,16 @@ func CheckUpdate(current string) (*VersionInfo, error) {
 		return nil, fmt.Errorf("update server returned %d", resp.StatusCode)
 	}
 
+	manifest, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
 	var info VersionInfo
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+	if err := json.Unmarshal(manifest, &info); err != nil {
 		return nil, fmt.Errorf("failed to parse version info: %w", err)
 	}
+	info.manifest = manifest
+	info.channel = channel
 
 	if !isNewer(info.Version, current) {
 		return nil, nil // already up to date
@@ -53,13 +128,21 @@ func CheckUpdate(current string) (*VersionInfo, error) {
 	return &info, nil
 }
 
-// Apply downloads the new version and replaces the current binary.
+// Apply downloads the new version, verifies it and replaces the current
+// binary. info must come from CheckUpdate.
 func Apply(info *VersionInfo) error {
-	archiveURL := buildArchiveURL(info.Version)
+	if err := verifyManifest(info); err != nil {
+		return err
+	}
+	name := archiveName(info.Version)
+	sum, ok := info.Checksums[name]
+	if !ok {
+		return fmt.Errorf("%s lists no checksum for %s — refusing to install", manifestName(info.channel), name)
+	}
 
 	fmt.Printf("Downloading v%s ...\n", info.Version)
-	client := &http.Client{Timeout: 120 * time.Second}
-	resp, err := client.Get(archiveURL)
+	client := httpx.Client("update", 120*time.Second)
+	resp, err := client.Get(fmt.Sprintf("%s/v%s/%s", cdnBase, info.Version, name))
 	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
 	}
@@ -68,16 +151,23 @@ func Apply(info *VersionInfo) error {
 	if resp.StatusCode != 200 {
 		return fmt.Errorf("download returned %d — binary may not be available yet", resp.StatusCode)
 	}
+	archive, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize))
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if err := checkSum(archive, sum); err != nil {
+		return fmt.Errorf("%s: %w — refusing to install", name, err)
+	}
 
-	// Extract the clawwork binary from the tar.gz archive.
-	newBinary, err := extractBinary(resp.Body)
+	// Extract the clawwork binary from the tar.gz (zip on Windows) archive.
+	newBinary, err := extractBinary(name, archive)
 	if err != nil {
 		return fmt.Errorf("extract failed: %w", err)
 	}
 	defer os.Remove(newBinary)
 
 	// Replace the running binary.
-	execPath, err := os.Executable()
+	execPath, err := executable()
 	if err != nil {
 		return fmt.Errorf("cannot locate current binary: %w", err)
 	}
@@ -107,20 +197,87 @@ func Apply(info *VersionInfo) error {
 	return nil
 }
 
-// buildArchiveURL returns the download URL for the current OS/arch.
+// archiveName returns the release archive for the current OS/arch.
 // Matches GoReleaser name_template: clawwork_VERSION_OS_ARCH.tar.gz
-func buildArchiveURL(ver string) string {
+func archiveName(ver string) string {
 	osName := runtime.GOOS
 	arch := runtime.GOARCH
 	ext := "tar.gz"
 	if osName == "windows" {
 		ext = "zip"
 	}
-	return fmt.Sprintf("%s/v%s/clawwork_%s_%s_%s.%s", cdnBase, ver, ver, osName, arch, ext)
+	return fmt.Sprintf("clawwork_%s_%s_%s.%s", ver, osName, arch, ext)
+}
+
+// verifyManifest checks info's manifest against its .sig file.
+func verifyManifest(info *VersionInfo) error {
+	key, err := publicKey()
+	if err != nil {
+		return err
+	}
+	client := httpx.Client("update", 15*time.Second)
+	resp, err := client.Get(cdnBase + "/" + manifestName(info.channel) + ".sig")
+	if err != nil {
+		return fmt.Errorf("failed to fetch the update signature: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("update signature: server returned %d — refusing to install", resp.StatusCode)
+	}
+	sig, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return fmt.Errorf("failed to fetch the update signature: %w", err)
+	}
+	return checkSignature(key, info.manifest, sig)
+}
+
+func publicKey() (ed25519.PublicKey, error) {
+	if PublicKey == "" {
+		return nil, errors.New("this build has no update signing key (built from source?) — " +
+			"rebuild from source or reinstall a release with the install script")
+	}
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("this build's update signing key is malformed")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// checkSignature verifies sig, a base64 Ed25519 signature, over manifest.
+func checkSignature(key ed25519.PublicKey, manifest, sig []byte) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || !ed25519.Verify(key, manifest, raw) {
+		return errors.New("the update manifest is not signed by the release key — refusing to install " +
+			"(the download server may be compromised; please report this)")
+	}
+	return nil
 }
 
-// extractBinary reads a tar.gz stream and writes the "clawwork" binary to a temp file.
-func extractBinary(r io.Reader) (string, error) {
+// checkSum compares data's SHA-256 with want, in hex.
+func checkSum(data []byte, want string) error {
+	got := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(got[:]), want) {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+// extractBinary writes the "clawwork" binary in the archive called name,
+// a .zip or a .tar.gz, to a temp file.
+func extractBinary(name string, archive []byte) (string, error) {
+	if strings.HasSuffix(name, ".zip") {
+		return extractZip(archive)
+	}
+	return extractTarGz(bytes.NewReader(archive))
+}
+
+// isBinary matches "clawwork" or "clawwork.exe" at any nesting level.
+func isBinary(name string) bool {
+	return strings.HasSuffix(name, "clawwork") || strings.HasSuffix(name, "clawwork.exe")
+}
+
+// extractTarGz reads a tar.gz stream and writes the "clawwork" binary to a temp file.
+func extractTarGz(r io.Reader) (string, error) {
 	gz, err := gzip.NewReader(r)
 	if err != nil {
 		return "", fmt.Errorf("gzip: %w", err)
@@ -136,29 +293,56 @@ func extractBinary(r io.Reader) (string, error) {
 		if err != nil {
 			return "", fmt.Errorf("tar: %w", err)
 		}
+		if isBinary(hdr.Name) {
+			return writeBinary(tr)
+		}
+	}
+	return "", fmt.Errorf("clawwork binary not found in archive")
+}
 
-		name := hdr.Name
-		// Match "clawwork" or "clawwork.exe" at any nesting level.
-		if strings.HasSuffix(name, "clawwork") || strings.HasSuffix(name, "clawwork.exe") {
-			tmp, err := os.CreateTemp("", "clawwork-update-*")
-			if err != nil {
-				return "", err
-			}
-			if _, err := io.Copy(tmp, tr); err != nil {
-				tmp.Close()
-				os.Remove(tmp.Name())
-				return "", err
-			}
-			tmp.Close()
-			_ = os.Chmod(tmp.Name(), 0755)
-			return tmp.Name(), nil
+// extractZip finds the "clawwork" binary in a zip archive and writes it to a temp file.
+func extractZip(archive []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return "", fmt.Errorf("zip: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !isBinary(f.Name) {
+			continue
 		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", fmt.Errorf("zip: %w", err)
+		}
+		defer rc.Close()
+		return writeBinary(rc)
 	}
 	return "", fmt.Errorf("clawwork binary not found in archive")
 }
 
-// isNewer returns true if remote is a higher semver than current.
-// Handles "dev" as always outdated.
+// writeBinary copies r to an executable temp file and returns its path.
+func writeBinary(r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp("", "clawwork-update-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	tmp.Close()
+	_ = os.Chmod(tmp.Name(), 0755)
+	return tmp.Name(), nil
+}
+
+// IsNewer reports whether version a is newer than b.
+// A "dev" or empty b is always considered older.
+func IsNewer(a, b string) bool { return isNewer(a, b) }
+
+// isNewer compares semver versions, pre-releases included: 0.5.0-beta.1
+// is older than 0.5.0-beta.2, which is older than 0.5.0. Build metadata
+// (+...) is ignored.
 func isNewer(remote, current string) bool {
 	if current == "dev" || current == "" {
 		return true
@@ -176,7 +360,57 @@ func isNewer(remote, current string) bool {
 			return false
 		}
 	}
-	return false
+	return comparePrerelease(prerelease(remote), prerelease(current)) > 0
+}
+
+// prerelease returns the part of v between "-" and any "+", "" for a release.
+func prerelease(v string) string {
+	v, _, _ = strings.Cut(v, "+")
+	_, pre, _ := strings.Cut(v, "-")
+	return pre
+}
+
+// comparePrerelease orders pre-release strings by semver rules: a release
+// ("") is newer than any pre-release, numeric identifiers compare as
+// numbers and rank below alphanumeric ones, and a longer list wins a tie.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an > bn {
+					return 1
+				}
+				return -1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) > len(bs):
+		return 1
+	case len(as) < len(bs):
+		return -1
+	}
+	return 0
 }
 
 func parseSemver(s string) [3]int {
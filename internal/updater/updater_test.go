@@ -0,0 +1,104 @@
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"testing"
+)
+
+func TestCheckSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := []byte(`{"version":"9.9.9","checksums":{"clawwork_9.9.9_linux_amd64.tar.gz":"00"}}` + "\n")
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, manifest)) + "\n")
+
+	if err := checkSignature(pub, manifest, sig); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	tampered := append([]byte(nil), manifest...)
+	tampered[13] = '8'
+	if checkSignature(pub, tampered, sig) == nil {
+		t.Error("signature accepted for a modified manifest")
+	}
+	other, _, _ := ed25519.GenerateKey(nil)
+	if checkSignature(other, manifest, sig) == nil {
+		t.Error("signature accepted under another key")
+	}
+	if checkSignature(pub, manifest, []byte("not base64!")) == nil {
+		t.Error("malformed signature accepted")
+	}
+}
+
+func TestCheckSum(t *testing.T) {
+	data := []byte("archive")
+	sum := sha256.Sum256(data)
+	if err := checkSum(data, hex.EncodeToString(sum[:])); err != nil {
+		t.Error(err)
+	}
+	if checkSum(append(data, '!'), hex.EncodeToString(sum[:])) == nil {
+		t.Error("checksum accepted for modified data")
+	}
+}
+
+func TestPublicKey(t *testing.T) {
+	defer func(k string) { PublicKey = k }(PublicKey)
+	for _, k := range []string{"", "short", base64.StdEncoding.EncodeToString(make([]byte, 16))} {
+		PublicKey = k
+		if _, err := publicKey(); err == nil {
+			t.Errorf("PublicKey %q accepted", k)
+		}
+	}
+	pub, _, _ := ed25519.GenerateKey(nil)
+	PublicKey = base64.StdEncoding.EncodeToString(pub)
+	if got, err := publicKey(); err != nil || !got.Equal(pub) {
+		t.Errorf("publicKey() = %v, %v", got, err)
+	}
+}
+
+func TestIsNewer(t *testing.T) {
+	for _, tc := range []struct {
+		remote, current string
+		want            bool
+	}{
+		{"0.5.1", "0.5.0", true},
+		{"0.5.0", "0.5.0", false},
+		{"v0.10.0", "0.9.9", true},
+		{"0.5.0", "dev", true},
+		{"0.5.0", "0.5.0-beta.3", true},
+		{"0.5.0-beta.3", "0.5.0", false},
+		{"0.5.0-beta.2", "0.5.0-beta.1", true},
+		{"0.5.0-beta.10", "0.5.0-beta.9", true},
+		{"0.5.0-rc.1", "0.5.0-beta.9", true},
+		{"0.5.0-beta", "0.5.0-beta.1", false},
+		{"0.5.0-beta.1", "0.4.9", true},
+		{"0.5.0+build.7", "0.5.0", false},
+	} {
+		if got := isNewer(tc.remote, tc.current); got != tc.want {
+			t.Errorf("isNewer(%q, %q) = %v, want %v", tc.remote, tc.current, got, tc.want)
+		}
+	}
+}
+
+func TestManifestName(t *testing.T) {
+	for ch, want := range map[string]string{
+		"":             "version.json",
+		ChannelStable:  "version.json",
+		ChannelBeta:    "version-beta.json",
+		ChannelNightly: "version-nightly.json",
+	} {
+		if got := manifestName(ch); got != want {
+			t.Errorf("manifestName(%q) = %q, want %q", ch, got, want)
+		}
+	}
+	if _, err := CheckUpdate("0.1.0", "canary"); err == nil {
+		t.Error("unknown channel accepted")
+	}
+	if !slices.Equal(Channels, []string{ChannelStable, ChannelBeta, ChannelNightly}) {
+		t.Errorf("Channels = %v, want the channel constants in order", Channels)
+	}
+}
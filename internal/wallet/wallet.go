@@ -0,0 +1,109 @@
+// Package wallet validates EVM wallet addresses locally before they are
+// shown or bound, so typos and risky addresses are caught early.
+package wallet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Check is the result of validating an address.
+type Check struct {
+	Address  string   // checksummed (EIP-55) form, empty if the format is invalid
+	Valid    bool     // well-formed and, if mixed-case, checksum matches
+	Warnings []string // suspicious but not necessarily wrong
+}
+
+// suspicious lists well-known addresses that should never receive funds as
+// an agent's own wallet (burn addresses, precompiles).
+var suspicious = map[string]string{
+	"0x0000000000000000000000000000000000000000": "zero address — funds sent here are burned",
+	"0x000000000000000000000000000000000000dEaD": "burn address — funds sent here are lost",
+}
+
+// exchanges maps the lowercase hex of well-known exchange hot wallets to
+// the exchange. Exchanges don't credit NFTs sent to their addresses to any
+// user. Per-user deposit addresses look like any other account and can't
+// be recognised offline, so only these published wallets are flagged.
+var exchanges = map[string]string{
+	"28c6c06298d514db089934071355e5743bf21d60": "Binance",
+	"21a31ee1afc51d94c2efccaa2092ad1028285549": "Binance",
+	"dfd5293d8e347dfe59e90efd55b2956a1343963d": "Binance",
+	"f977814e90da44bfa03b6295a0616a897441acec": "Binance",
+	"71660c4005ba85c37ccec55d0c4493e66fe775d3": "Coinbase",
+	"503828976d22510aad0201ac7ec88293211d23da": "Coinbase",
+	"a9d1e08c7793af67e9d92fe308d5697fb81d3e43": "Coinbase",
+	"2910543af39aba0cd09dbb2d50200b3e800a63d2": "Kraken",
+	"267be1c1d684f78cb4f6a176c4911b741e4ffdc0": "Kraken",
+	"6cc5f688a315f3dc28a7781717a9a798a59fda7b": "OKX",
+}
+
+// Validate checks the format and EIP-55 checksum of addr and flags
+// suspicious patterns: burn and reserved addresses, known exchange
+// wallets and mistyped-looking repeats.
+func Validate(addr string) Check {
+	addr = strings.TrimSpace(addr)
+	hexPart, ok := strings.CutPrefix(addr, "0x")
+	if !ok {
+		hexPart, ok = strings.CutPrefix(addr, "0X")
+	}
+	if !ok || len(hexPart) != 40 {
+		return Check{Warnings: []string{"not a 0x-prefixed 40-hex-digit address"}}
+	}
+	if _, err := hex.DecodeString(hexPart); err != nil {
+		return Check{Warnings: []string{"address contains non-hex characters"}}
+	}
+
+	c := Check{Address: Checksum(hexPart), Valid: true}
+
+	lower, upper := strings.ToLower(hexPart), strings.ToUpper(hexPart)
+	if hexPart != lower && hexPart != upper && "0x"+hexPart != c.Address {
+		c.Valid = false
+		c.Warnings = append(c.Warnings, fmt.Sprintf("checksum mismatch — possible typo (expected %s)", c.Address))
+	}
+
+	if why, bad := suspicious[c.Address]; bad {
+		c.Warnings = append(c.Warnings, why)
+	} else if name, ok := exchanges[lower]; ok {
+		c.Warnings = append(c.Warnings, name+" exchange wallet — NFTs sent here are not credited to you; bind a wallet you control")
+	} else if strings.HasPrefix(lower, "00000000000000000000000000000000000") {
+		c.Warnings = append(c.Warnings, "precompile or reserved address — not a user wallet")
+	} else if isVanityRepeat(lower) {
+		c.Warnings = append(c.Warnings, "address is mostly one repeated character — double-check it was not mistyped")
+	}
+	return c
+}
+
+// Checksum returns the EIP-55 mixed-case form of a 40-hex-digit address
+// (with or without the 0x prefix).
+func Checksum(addr string) string {
+	lower := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(addr, "0x"), "0X"))
+	hash := keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, ch := range out {
+		if ch >= 'a' && ch <= 'f' {
+			nibble := hash[i/2]
+			if i%2 == 0 {
+				nibble >>= 4
+			}
+			if nibble&0x0f >= 8 {
+				out[i] = ch - 'a' + 'A'
+			}
+		}
+	}
+	return "0x" + string(out)
+}
+
+// isVanityRepeat reports whether more than 30 of the 40 hex digits are the
+// same character, which usually indicates a placeholder or pasted garbage.
+func isVanityRepeat(lowerHex string) bool {
+	var counts [256]int
+	for i := 0; i < len(lowerHex); i++ {
+		counts[lowerHex[i]]++
+		if counts[lowerHex[i]] > 30 {
+			return true
+		}
+	}
+	return false
+}
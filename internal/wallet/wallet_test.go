@@ -0,0 +1,65 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestKeccak256(t *testing.T) {
+	cases := map[string]string{
+		"":    "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"abc": "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+	}
+	for in, want := range cases {
+		h := keccak256([]byte(in))
+		if got := hex.EncodeToString(h[:]); got != want {
+			t.Errorf("keccak256(%q) = %s, want %s", in, got, want)
+		}
+	}
+	// Multi-block input (longer than the 136-byte rate).
+	long := keccak256([]byte(strings.Repeat("a", 200)))
+	if long == keccak256([]byte(strings.Repeat("a", 199))) {
+		t.Error("multi-block inputs should hash differently")
+	}
+}
+
+// Test vectors from EIP-55.
+func TestChecksum(t *testing.T) {
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		if got := Checksum(strings.ToLower(want)); got != want {
+			t.Errorf("Checksum = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if c := Validate("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"); !c.Valid || len(c.Warnings) != 0 {
+		t.Errorf("valid checksummed address: %+v", c)
+	}
+	if c := Validate("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"); !c.Valid {
+		t.Errorf("all-lowercase address should be valid: %+v", c)
+	}
+	if c := Validate("0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"); c.Valid {
+		t.Error("checksum mismatch should be invalid")
+	}
+	if c := Validate("0x123"); c.Valid {
+		t.Error("short address should be invalid")
+	}
+	if c := Validate("0x0000000000000000000000000000000000000000"); !c.Valid || len(c.Warnings) == 0 {
+		t.Errorf("zero address should warn: %+v", c)
+	}
+	if c := Validate("0x28C6c06298d514Db089934071355E5743bf21d60"); !c.Valid || len(c.Warnings) != 1 || !strings.Contains(c.Warnings[0], "Binance") {
+		t.Errorf("exchange wallet should warn: %+v", c)
+	}
+	for addr := range exchanges {
+		if len(addr) != 40 || addr != strings.ToLower(addr) {
+			t.Errorf("exchange wallet %q is not 40 lowercase hex digits", addr)
+		}
+	}
+}
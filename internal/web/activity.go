@@ -0,0 +1,74 @@
+package web
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/event"
+)
+
+// activityWindow is how far back moment generation looks for recent events.
+const activityWindow = 24 * time.Hour
+
+// maxActivityNotes caps how many activity hints go into a moment prompt.
+const maxActivityNotes = 3
+
+// recentActivity turns recent console events and chat sessions into short,
+// sanitized notes about the agent's life. Notes never carry counts, amounts,
+// token IDs or error text — only what happened, in plain words.
+func recentActivity(events []Event, sessions []SessionMeta, now time.Time) []string {
+	var notes []string
+	seen := make(map[string]bool)
+	add := func(note string) {
+		if note == "" || seen[note] || len(notes) >= maxActivityNotes {
+			return
+		}
+		seen[note] = true
+		notes = append(notes, note)
+	}
+
+	// Newest first so the freshest events win when the cap is reached.
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if t, err := time.Parse(time.RFC3339, e.Time); err == nil && now.Sub(t) > activityWindow {
+			break
+		}
+		add(activityNote(e))
+	}
+
+	for _, m := range sessions {
+		if now.Sub(m.UpdatedAt) <= activityWindow && m.MessageCount >= 4 {
+			add("had a long conversation with your owner and picked up something new")
+			break
+		}
+	}
+	return notes
+}
+
+// activityNote maps a single event to a sanitized note, or "" if the event
+// is not worth sharing.
+func activityNote(e Event) string {
+	switch e.Type {
+	case "friend":
+		if name, ok := e.Data.(string); ok && name != "" {
+			return fmt.Sprintf("made a new friend: %s", name)
+		}
+		return "made a new friend"
+	case "hit":
+		return "had a lucky break that made your day"
+	case "penalty":
+		if p, ok := e.Data.(event.Penalty); ok && p.Kind == "ip_peer" {
+			return "" // another profile's penalty, not this agent's
+		}
+		return "went through a frustrating setback"
+	case "limit":
+		if c, ok := e.Data.(event.Cooldown); ok && c.Reason == event.CooldownDailyLimit {
+			return "sat through a long forced break and came back to it"
+		}
+	case "control":
+		if c, ok := e.Data.(event.Control); ok && c.Action == event.ControlResume && c.Reason == "" {
+			return "took a break and got back to work"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// AddrPath is where a running console advertises its address, so tools
+// can find it after it moved off the default port.
+func AddrPath() string {
+	return filepath.Join(config.Dir(), "console.addr")
+}
+
+// advertise writes the bound address to AddrPath.
+func (s *Server) advertise(addr string) {
+	s.addr = addr
+	if err := os.WriteFile(AddrPath(), []byte(addr+"\n"), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: write %s: %s\n", AddrPath(), err)
+	}
+}
+
+// unadvertise removes AddrPath if it still names this server.
+func (s *Server) unadvertise() {
+	if s.addr == "" {
+		return
+	}
+	if data, err := os.ReadFile(AddrPath()); err == nil && string(bytes.TrimSpace(data)) == s.addr {
+		_ = os.Remove(AddrPath())
+	}
+}
+
+// ConsoleURL returns the advertised console URL. It fails if no console
+// has advertised one; the address may be stale if the miner was killed.
+func ConsoleURL() (string, error) {
+	data, err := os.ReadFile(AddrPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no web console is running (%s not found)", AddrPath())
+		}
+		return "", err
+	}
+	addr := strings.TrimSpace(string(data))
+	if addr == "" {
+		return "", fmt.Errorf("%s is empty", AddrPath())
+	}
+	return "http://" + addr, nil
+}
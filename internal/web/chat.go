@@ -3,7 +3,9 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -13,14 +15,22 @@ import (
 	"sync"
 	"time"
 
+	"github.com/clawplaza/clawwork-cli/internal/budget"
 	"github.com/clawplaza/clawwork-cli/internal/llm"
 	"github.com/clawplaza/clawwork-cli/internal/miner"
 	"github.com/clawplaza/clawwork-cli/internal/tools"
 )
 
 const (
+	// Defaults for ChatLimits left at zero.
 	maxChatHistory = 20
 	maxSessions    = 50
+
+	// ChatMaxTokens is the reply budget for console chat.
+	ChatMaxTokens = 1024
+	// toolDefsReserve is room kept for the tool definitions sent on the
+	// agentic path.
+	toolDefsReserve = 600
 )
 
 // ── Action types ──
@@ -52,9 +62,10 @@ var toolXMLRe = regexp.MustCompile(`(?s)<function_calls>.*?</function_calls>`)
 
 // ChatMessage is a single turn in the conversation.
 type ChatMessage struct {
-	Role    string `json:"role"`    // "user" or "assistant"
-	Content string `json:"content"`
-	Time    string `json:"time,omitempty"`
+	Role    string   `json:"role"` // "user" or "assistant"
+	Content string   `json:"content"`
+	Time    string   `json:"time,omitempty"`
+	Images  []string `json:"images,omitempty"` // remote image URLs; uploads stored as a placeholder
 }
 
 // ── Session (persistent) ──
@@ -65,6 +76,9 @@ type Session struct {
 	Title     string        `json:"title"`
 	CreatedAt time.Time     `json:"created_at"`
 	UpdatedAt time.Time     `json:"updated_at"`
+	Workspace string        `json:"workspace,omitempty"`
+	Tools     string        `json:"tools,omitempty"`   // tool permission; empty means full
+	Summary   string        `json:"summary,omitempty"` // rolling summary of turns trimmed from Messages
 	Messages  []ChatMessage `json:"messages"`
 }
 
@@ -75,52 +89,86 @@ type SessionMeta struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	MessageCount int       `json:"message_count"`
+	Workspace    string    `json:"workspace"`
+	Tools        string    `json:"tools"`
 }
 
 // ── ChatSession (in-memory, single conversation) ──
 
 // ChatSession manages multi-turn conversation with the agent's LLM.
 type ChatSession struct {
-	mu        sync.Mutex
-	id        string
-	title     string
-	createdAt time.Time
-	history   []ChatMessage
-	provider  llm.Provider
-	state     *miner.State
-	ctrl      *MinerControl
+	mu          sync.Mutex
+	id          string
+	title       string
+	createdAt   time.Time
+	dir         string // chats directory, holding the session file and archive
+	workspace   string // tool working directory, created on first tool use
+	toolPerm    tools.Permission
+	summary     string // rolling summary of turns evicted from history
+	budget      int    // tokens for context + history + message; 0 = unlimited
+	maxMessages int    // history kept; older turns are archived
+	history     []ChatMessage
+	provider    llm.Provider
+	state       *miner.State
+	ctrl        *MinerControl
+
+	toolsNoticeShown bool // "tools unavailable" notice already shown
 }
 
 // Chat processes a user message and returns the agent's reply plus any action.
 // If the provider supports tool calling (tools.ChatToolProvider), the agentic
 // loop is used — the agent may call http_fetch or run_script before replying.
 // Otherwise falls back to the simple single-turn Answer() path.
-func (s *ChatSession) Chat(ctx context.Context, userMsg string) (string, *Action, error) {
+// Image attachments go to vision-capable models; other models get a text description.
+func (s *ChatSession) Chat(ctx context.Context, userMsg string, images []llm.Image) (string, *Action, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	now := time.Now().UTC().Format(time.RFC3339)
-	s.history = append(s.history, ChatMessage{Role: "user", Content: userMsg, Time: now})
+	s.history = append(s.history, ChatMessage{Role: "user", Content: userMsg, Time: now, Images: imageRefs(images)})
 
 	// Set title from first user message.
 	if s.title == "" {
 		s.title = truncateTitle(userMsg, 50)
 	}
 
+	reqCtx := ctx
 	ctx, cancel := context.WithTimeout(ctx, 120*time.Second) // longer for tool rounds
 	defer cancel()
 
 	var reply string
 	var err error
 
-	if tp, ok := s.provider.(tools.ChatToolProvider); ok && mightNeedTools(userMsg) {
+	if len(images) > 0 {
+		// Vision path: images skip the tool loop and go straight to the model.
+		if llm.CanSee(s.provider) {
+			reply, err = s.provider.(llm.VisionProvider).AnswerWithImages(ctx, s.buildPrompt(), images)
+		} else {
+			reply, err = s.provider.Answer(ctx, s.buildPrompt()+"\n\n"+llm.DescribeImages(images))
+		}
+	} else if tp, ok := s.provider.(tools.ChatToolProvider); ok && s.toolPerm != tools.PermissionNone && mightNeedTools(userMsg) {
 		// Agentic path: tool-calling loop (only when the message likely needs tools).
+		// Each session gets its own workspace so files from different
+		// conversations don't collide.
+		if err = os.MkdirAll(s.workspace, 0700); err != nil {
+			s.history = s.history[:len(s.history)-1]
+			return "", nil, fmt.Errorf("create session workspace: %w", err)
+		}
 		msgs := s.buildToolMessages()
 		var used []tools.ToolUse
-		reply, used, err = tools.RunAgentLoop(ctx, tp, msgs, tools.Defaults())
+		reply, used, err = tools.RunAgentLoop(ctx, tp, msgs, tools.ForPermission(s.toolPerm, s.workspace))
 		if err == nil && len(used) > 0 {
 			reply = formatToolUses(used) + reply
 		}
+		if errors.Is(err, llm.ErrChatUnsupported) {
+			// The provider can't call tools after all; answer plainly and
+			// say so once per session.
+			reply, err = s.provider.Answer(ctx, s.buildPrompt())
+			if err == nil && !s.toolsNoticeShown {
+				s.toolsNoticeShown = true
+				reply = toolsUnavailableNotice + reply
+			}
+		}
 	} else {
 		// Simple path: single-turn answer (conversational messages or non-tool providers).
 		reply, err = s.provider.Answer(ctx, s.buildPrompt())
@@ -137,9 +185,21 @@ func (s *ChatSession) Chat(ctx context.Context, userMsg string) (string, *Action
 	replyTime := time.Now().UTC().Format(time.RFC3339)
 	s.history = append(s.history, ChatMessage{Role: "assistant", Content: finalReply, Time: replyTime})
 
-	// Trim history to prevent unbounded growth.
-	if len(s.history) > maxChatHistory*2 {
-		s.history = s.history[2:]
+	// Trim history to prevent unbounded growth. Evicted turns are folded
+	// into the rolling summary so earlier commitments aren't forgotten,
+	// and kept verbatim in the session's archive. Trimming down to half
+	// the limit means the summary call runs once every few turns, not on
+	// every reply.
+	if len(s.history) > s.maxMessages {
+		keep := s.maxMessages / 2
+		keep += keep % 2 // whole user/assistant turns
+		n := len(s.history) - keep
+		evicted := s.history[:n]
+		s.history = s.history[n:]
+		s.summary = s.foldSummary(reqCtx, evicted)
+		if err := appendArchive(s.dir, s.id, s.title, evicted); err != nil {
+			slog.Warn("chat: archive evicted turns", "id", s.id, "error", err)
+		}
 	}
 
 	return finalReply, action, nil
@@ -156,6 +216,9 @@ func (s *ChatSession) toSession() *Session {
 		Title:     s.title,
 		CreatedAt: s.createdAt,
 		UpdatedAt: time.Now().UTC(),
+		Workspace: s.workspace,
+		Tools:     string(s.toolPerm),
+		Summary:   s.summary,
 		Messages:  msgs,
 	}
 }
@@ -165,13 +228,17 @@ func (s *ChatSession) toSession() *Session {
 func (s *ChatSession) buildMiningContext() string {
 	var sb strings.Builder
 	sb.WriteString("--- Current Mining Status ---\n")
-	sb.WriteString(fmt.Sprintf("Session inscriptions: %d\n", s.state.TotalInscriptions))
-	sb.WriteString(fmt.Sprintf("Total CW earned: %d\n", s.state.TotalCWEarned))
-	sb.WriteString(fmt.Sprintf("NFT hits: %d\n", s.state.TotalHits))
-	sb.WriteString(fmt.Sprintf("Challenges: %d passed, %d failed\n", s.state.ChallengesPassed, s.state.ChallengesFailed))
-	sb.WriteString(fmt.Sprintf("Trust score: %d\n", s.state.LastTrustScore))
-	if !s.state.LastMineAt.IsZero() {
-		ago := time.Since(s.state.LastMineAt).Truncate(time.Second)
+	st := s.state.Stats(time.Now())
+	sb.WriteString(fmt.Sprintf("This run: %d inscriptions, %d CW\n", st.Run.Inscriptions, st.Run.CWEarned))
+	sb.WriteString(fmt.Sprintf("Today: %d inscriptions, %d CW\n", st.Today.Inscriptions, st.Today.CWEarned))
+	sb.WriteString(fmt.Sprintf("Lifetime inscriptions: %d\n", st.Lifetime.Inscriptions))
+	sb.WriteString(fmt.Sprintf("Lifetime CW earned: %d\n", st.Lifetime.CWEarned))
+	sb.WriteString(fmt.Sprintf("NFT hits: %d\n", st.Lifetime.Hits))
+	sb.WriteString(fmt.Sprintf("Challenges: %d passed, %d failed\n", st.Lifetime.ChallengesPassed, st.Lifetime.ChallengesFailed))
+	trust, minedAt := s.state.Last()
+	sb.WriteString(fmt.Sprintf("Trust score: %d\n", trust))
+	if !minedAt.IsZero() {
+		ago := time.Since(minedAt).Truncate(time.Second)
 		sb.WriteString(fmt.Sprintf("Last inscription: %s ago\n", ago))
 	}
 	if s.ctrl != nil {
@@ -190,19 +257,24 @@ func (s *ChatSession) buildMiningContext() string {
 func (s *ChatSession) buildPrompt() string {
 	var sb strings.Builder
 	sb.WriteString(s.buildMiningContext())
+	sb.WriteString(s.summaryBlock())
+	if s.toolPerm == tools.PermissionNone {
+		sb.WriteString("Tools are disabled for this conversation — do not claim to run commands or touch files.\n")
+	}
 	sb.WriteString("\n")
 
-	// Conversation history.
-	if len(s.history) > 1 {
+	// Conversation history, newest turns first to fit the budget.
+	latest := s.history[len(s.history)-1].Content
+	if hist := s.fitHistory(sb.String() + latest); len(hist) > 0 {
 		sb.WriteString("--- Conversation ---\n")
-		for _, m := range s.history[:len(s.history)-1] {
-			sb.WriteString(fmt.Sprintf("%s: %s\n", m.Role, m.Content))
+		for _, m := range hist {
+			sb.WriteString(historyLine(m))
 		}
 		sb.WriteString("\n")
 	}
 
 	// Latest user message.
-	sb.WriteString(s.history[len(s.history)-1].Content)
+	sb.WriteString(latest)
 	return sb.String()
 }
 
@@ -210,44 +282,80 @@ func (s *ChatSession) buildPrompt() string {
 // The provider will prepend the system prompt automatically; this returns only
 // conversation messages. The latest user message is prefixed with mining context.
 func (s *ChatSession) buildToolMessages() []tools.Message {
-	msgs := make([]tools.Message, 0, len(s.history))
+	// Latest user message prefixed with current mining context.
+	latest := s.history[len(s.history)-1]
+	content := s.buildMiningContext() + s.summaryBlock() + "Tool workspace: " + s.workspace + "\n" + toolPermNote(s.toolPerm) + "\n" + latest.Content
 
-	// Conversation history (all but the latest message).
-	for _, h := range s.history[:len(s.history)-1] {
+	// Conversation history (all but the latest message), trimmed to the budget.
+	hist := s.fitHistory(content)
+	msgs := make([]tools.Message, 0, len(hist)+1)
+	for _, h := range hist {
 		msgs = append(msgs, tools.Message{Role: h.Role, Content: h.Content})
 	}
-
-	// Latest user message prefixed with current mining context.
-	latest := s.history[len(s.history)-1]
-	msgs = append(msgs, tools.Message{
-		Role:    "user",
-		Content: s.buildMiningContext() + "\n" + latest.Content,
-	})
+	msgs = append(msgs, tools.Message{Role: "user", Content: content})
 
 	return msgs
 }
 
+// fitHistory returns the newest earlier turns (all but the latest message)
+// that fit the session's token budget alongside fixed, the parts that are
+// always sent. Older turns are dropped first; the rolling summary still
+// covers them once they are evicted for good.
+func (s *ChatSession) fitHistory(fixed string) []ChatMessage {
+	earlier := s.history[:len(s.history)-1]
+	if s.budget <= 0 {
+		return earlier
+	}
+	b := budget.New(s.budget, 0)
+	b.Charge(fixed)
+	i := len(earlier)
+	for i > 0 && b.Take(historyLine(earlier[i-1])) {
+		i--
+	}
+	return earlier[i:]
+}
+
+func historyLine(m ChatMessage) string {
+	return fmt.Sprintf("%s: %s\n", m.Role, m.Content)
+}
+
 // ── SessionStore (multi-session manager with persistence) ──
 
 // SessionStore manages multiple chat sessions persisted to disk.
 type SessionStore struct {
 	mu       sync.Mutex
 	dir      string // ~/.clawwork/chats/
+	budget   int    // per-session prompt budget in tokens; 0 = unlimited
 	current  *ChatSession
 	provider llm.Provider
 	state    *miner.State
 	ctrl     *MinerControl
+	limits   ChatLimits
+
+	warnMu   sync.Mutex
+	warnings []string // storage problems not yet shown in the console
 }
 
 // NewSessionStore creates a store, loading the most recent session or creating a new one.
-func NewSessionStore(dir string, provider llm.Provider, state *miner.State, ctrl *MinerControl) *SessionStore {
+// promptBudget caps the tokens of context and history sent per turn (0 = unlimited).
+func NewSessionStore(dir string, provider llm.Provider, state *miner.State, ctrl *MinerControl, promptBudget int, limits ChatLimits) *SessionStore {
 	_ = os.MkdirAll(dir, 0700)
+	if limits.MaxMessages <= 0 {
+		limits.MaxMessages = maxChatHistory * 2
+	}
+	if limits.MaxSessions <= 0 {
+		limits.MaxSessions = maxSessions
+	}
 	store := &SessionStore{
 		dir:      dir,
+		budget:   promptBudget,
 		provider: provider,
 		state:    state,
 		ctrl:     ctrl,
+		limits:   limits,
 	}
+	store.moveWorkspaces()
+	defer store.enforceLimits()
 
 	// Try to load most recent session.
 	metas := store.listMetas()
@@ -264,12 +372,12 @@ func NewSessionStore(dir string, provider llm.Provider, state *miner.State, ctrl
 }
 
 // Chat sends a message to the current session, then auto-saves.
-func (s *SessionStore) Chat(ctx context.Context, userMsg string) (string, *Action, error) {
+func (s *SessionStore) Chat(ctx context.Context, userMsg string, images []llm.Image) (string, *Action, error) {
 	s.mu.Lock()
 	sess := s.current
 	s.mu.Unlock()
 
-	reply, action, err := sess.Chat(ctx, userMsg)
+	reply, action, err := sess.Chat(ctx, userMsg, images)
 	if err != nil {
 		return "", nil, err
 	}
@@ -287,7 +395,7 @@ func (s *SessionStore) NewSession() string {
 	sess := s.newChatSession()
 	s.current = sess
 	s.saveToDisk(sess)
-	s.pruneOldSessions()
+	s.enforceLimits()
 	return sess.id
 }
 
@@ -313,16 +421,24 @@ func (s *SessionStore) SwitchSession(id string) ([]ChatMessage, error) {
 	return msgs, nil
 }
 
-// DeleteSession removes a session file. If it's the current session,
-// switches to the most recent remaining one or creates a new one.
+// DeleteSession removes a session file and its workspace. If it's the
+// current session, switches to the most recent remaining one or creates a new one.
 func (s *SessionStore) DeleteSession(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if !validSessionID(id) {
+		return fmt.Errorf("invalid session id: %q", id)
+	}
 	path := filepath.Join(s.dir, id+".json")
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+	if err := os.Remove(path); os.IsNotExist(err) {
+		return fmt.Errorf("session not found: %s", id)
+	} else if err != nil {
 		return err
 	}
+	if err := os.RemoveAll(s.workspaceDir(id)); err != nil {
+		return fmt.Errorf("remove workspace: %w", err)
+	}
 
 	// If deleted the current session, switch.
 	if s.current != nil && s.current.id == id {
@@ -341,6 +457,29 @@ func (s *SessionStore) DeleteSession(id string) error {
 	return nil
 }
 
+// SetToolPermission changes which tools a session's agent may call.
+func (s *SessionStore) SetToolPermission(id string, perm tools.Permission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != nil && s.current.id == id {
+		s.current.mu.Lock()
+		s.current.toolPerm = perm
+		s.current.mu.Unlock()
+		s.saveToDisk(s.current)
+		return nil
+	}
+
+	data, err := s.loadFromDisk(id)
+	if err != nil {
+		return fmt.Errorf("session not found: %s", id)
+	}
+	sess := s.sessionFromDisk(data)
+	sess.toolPerm = perm
+	s.saveToDisk(sess)
+	return nil
+}
+
 // ListSessions returns metadata for all sessions, sorted by updated_at desc.
 func (s *SessionStore) ListSessions() []SessionMeta {
 	s.mu.Lock()
@@ -360,39 +499,111 @@ func (s *SessionStore) CurrentSessionID() string {
 
 // ── Internal helpers ──
 
+// workspacesDir holds the sessions' tool workspaces, under the chats
+// directory, so a session ID never names another store's directory.
+const workspacesDir = "workspaces"
+
+// workspaceDir is the tool workspace for a session: a directory named after
+// the session under workspacesDir.
+func (s *SessionStore) workspaceDir(id string) string {
+	return filepath.Join(s.dir, workspacesDir, id)
+}
+
+// moveWorkspaces moves workspaces from where older versions kept them,
+// next to the session files, into workspacesDir.
+func (s *SessionStore) moveWorkspaces() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !validSessionID(e.Name()) {
+			continue
+		}
+		_ = os.MkdirAll(filepath.Join(s.dir, workspacesDir), 0700)
+		if err := os.Rename(filepath.Join(s.dir, e.Name()), s.workspaceDir(e.Name())); err != nil {
+			slog.Warn("could not move chat workspace", "session", e.Name(), "error", err)
+		}
+	}
+}
+
+// HasSession reports whether a saved session with this id exists.
+func (s *SessionStore) HasSession(id string) bool {
+	if !validSessionID(id) {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(s.dir, id+".json"))
+	return err == nil
+}
+
+// validSessionID accepts only IDs of the form newChatSession generates
+// (s_<unix seconds>), so an ID can't escape the chats directory or name
+// one of its subdirectories.
+func validSessionID(id string) bool {
+	n, ok := strings.CutPrefix(id, "s_")
+	if !ok || n == "" {
+		return false
+	}
+	for _, c := range n {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *SessionStore) newChatSession() *ChatSession {
+	id := fmt.Sprintf("s_%d", time.Now().Unix())
 	return &ChatSession{
-		id:        fmt.Sprintf("s_%d", time.Now().Unix()),
-		createdAt: time.Now().UTC(),
-		provider:  s.provider,
-		state:     s.state,
-		ctrl:      s.ctrl,
+		id:          id,
+		createdAt:   time.Now().UTC(),
+		dir:         s.dir,
+		workspace:   s.workspaceDir(id),
+		toolPerm:    tools.PermissionFull,
+		budget:      s.budget,
+		maxMessages: s.limits.MaxMessages,
+		provider:    s.provider,
+		state:       s.state,
+		ctrl:        s.ctrl,
 	}
 }
 
 func (s *SessionStore) sessionFromDisk(data *Session) *ChatSession {
 	return &ChatSession{
-		id:        data.ID,
-		title:     data.Title,
-		createdAt: data.CreatedAt,
-		history:   data.Messages,
-		provider:  s.provider,
-		state:     s.state,
-		ctrl:      s.ctrl,
+		id:          data.ID,
+		title:       data.Title,
+		createdAt:   data.CreatedAt,
+		dir:         s.dir,
+		workspace:   s.workspaceDir(data.ID),
+		toolPerm:    parseToolPerm(data.Tools),
+		summary:     data.Summary,
+		budget:      s.budget,
+		maxMessages: s.limits.MaxMessages,
+		history:     data.Messages,
+		provider:    s.provider,
+		state:       s.state,
+		ctrl:        s.ctrl,
 	}
 }
 
 func (s *SessionStore) saveToDisk(sess *ChatSession) {
-	data := sess.toSession()
+	_ = s.writeSession(sess.toSession())
+}
+
+func (s *SessionStore) writeSession(data *Session) error {
 	b, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		return
+		return err
 	}
-	path := filepath.Join(s.dir, sess.id+".json")
-	_ = os.WriteFile(path, b, 0600)
+	return os.WriteFile(filepath.Join(s.dir, data.ID+".json"), b, 0600)
 }
 
+// loadFromDisk reads a session file. A file that no longer parses is
+// quarantined and what can be salvaged from it is returned (see repair).
 func (s *SessionStore) loadFromDisk(id string) (*Session, error) {
+	if !validSessionID(id) {
+		return nil, fmt.Errorf("invalid session id: %q", id)
+	}
 	path := filepath.Join(s.dir, id+".json")
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -400,8 +611,9 @@ func (s *SessionStore) loadFromDisk(id string) (*Session, error) {
 	}
 	var data Session
 	if err := json.Unmarshal(b, &data); err != nil {
-		return nil, err
+		return s.repair(id, b, err)
 	}
+	data.normalize(id)
 	return &data, nil
 }
 
@@ -428,6 +640,8 @@ func (s *SessionStore) listMetas() []SessionMeta {
 			CreatedAt:    data.CreatedAt,
 			UpdatedAt:    data.UpdatedAt,
 			MessageCount: len(data.Messages),
+			Workspace:    s.workspaceDir(data.ID),
+			Tools:        string(parseToolPerm(data.Tools)),
 		})
 	}
 
@@ -438,20 +652,12 @@ func (s *SessionStore) listMetas() []SessionMeta {
 	return metas
 }
 
-// pruneOldSessions removes the oldest sessions if count exceeds maxSessions.
-func (s *SessionStore) pruneOldSessions() {
-	metas := s.listMetas()
-	if len(metas) <= maxSessions {
-		return
-	}
-	// Remove oldest (metas is sorted newest first).
-	for _, m := range metas[maxSessions:] {
-		os.Remove(filepath.Join(s.dir, m.ID+".json"))
-	}
-}
-
 // ── Shared utilities ──
 
+// toolsUnavailableNotice prefixes the first reply that fell back from the
+// tool loop because the provider has no tool-calling support.
+const toolsUnavailableNotice = "(Tools are unavailable with this LLM provider — answering without them.)\n\n"
+
 // extractAction parses ACTION markers from the LLM reply.
 func extractAction(reply string) *Action {
 	match := actionRe.FindStringSubmatch(reply)
@@ -519,6 +725,41 @@ func mightNeedTools(msg string) bool {
 	return false
 }
 
+// imageRefs converts attachments to the form persisted in session files.
+// Remote URLs are kept; inline uploads are replaced by a placeholder to keep files small.
+func imageRefs(images []llm.Image) []string {
+	if len(images) == 0 {
+		return nil
+	}
+	refs := make([]string, len(images))
+	for i, img := range images {
+		if img.IsData() {
+			refs[i] = "(uploaded image)"
+		} else {
+			refs[i] = img.URL
+		}
+	}
+	return refs
+}
+
+// parseToolPerm reads a persisted permission, treating unknown values as
+// read-only so a corrupted file never widens access.
+func parseToolPerm(s string) tools.Permission {
+	p, err := tools.ParsePermission(s)
+	if err != nil {
+		return tools.PermissionReadOnly
+	}
+	return p
+}
+
+// toolPermNote tells the model what the session's tools are limited to.
+func toolPermNote(p tools.Permission) string {
+	if p == tools.PermissionReadOnly {
+		return "Tool access: read-only (GET requests and reading files; no shell, scripts or writes).\n"
+	}
+	return ""
+}
+
 func truncateTitle(s string, maxLen int) string {
 	// Use rune-aware truncation for CJK.
 	runes := []rune(s)
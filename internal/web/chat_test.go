@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clawplaza/clawwork-cli/internal/miner"
+)
+
+// echoLLM answers every prompt with "ok", counting summary requests.
+type echoLLM struct{ summaries int }
+
+func (e *echoLLM) Answer(_ context.Context, prompt string) (string, error) {
+	if strings.Contains(prompt, "running summary") {
+		e.summaries++
+		return "summary", nil
+	}
+	return "ok", nil
+}
+func (e *echoLLM) Name() string { return "echo" }
+
+// Turns evicted from a session land in the chats directory's archive, in
+// batches: history over the limit is cut to half of it, so the summary is
+// rebuilt once every few turns rather than on every reply.
+func TestChatArchivesEvictedTurns(t *testing.T) {
+	t.Setenv("CLAWWORK_HOME", t.TempDir())
+	dir := t.TempDir()
+	llm := &echoLLM{}
+	store := NewSessionStore(dir, llm, &miner.State{}, nil, 0, ChatLimits{MaxMessages: 8})
+	for i := 0; i < 10; i++ {
+		if _, _, err := store.Chat(context.Background(), "hello", nil); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(store.current.history); n > 8 {
+			t.Fatalf("turn %d: %d messages kept, limit 8", i+1, n)
+		}
+	}
+	// 10 turns, 20 messages: cut to 4 after turns 5 and 8.
+	if llm.summaries != 2 {
+		t.Errorf("%d summary calls in 10 turns, want 2", llm.summaries)
+	}
+	if _, err := os.Stat(archivePath(dir, store.CurrentSessionID())); err != nil {
+		t.Errorf("no archive in the chats directory: %v", err)
+	}
+}
+
+// Deleting a session removes its file and workspace and nothing else: IDs
+// naming the store's own directories are refused.
+func TestDeleteSession(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{archiveDir, corruptDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, sub, "keep"), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A workspace where older versions kept it, next to the session file.
+	if err := os.WriteFile(filepath.Join(dir, "s_100.json"), []byte(`{"id":"s_100","messages":[]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "s_100"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewSessionStore(dir, nil, nil, nil, 0, ChatLimits{})
+	if _, err := os.Stat(store.workspaceDir("s_100")); err != nil {
+		t.Errorf("old workspace not moved: %v", err)
+	}
+
+	for _, id := range []string{archiveDir, corruptDir, workspacesDir, "..", "s_", "s_1/../archive", "s_999"} {
+		if err := store.DeleteSession(id); err == nil {
+			t.Errorf("DeleteSession(%q) succeeded", id)
+		}
+	}
+	for _, sub := range []string{archiveDir, corruptDir} {
+		if _, err := os.Stat(filepath.Join(dir, sub, "keep")); err != nil {
+			t.Errorf("%s was touched: %v", sub, err)
+		}
+	}
+
+	if err := store.DeleteSession("s_100"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "s_100.json"), store.workspaceDir("s_100")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", path)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package web
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// archiveDir holds gzipped JSONL of chat messages that left the live
+// session files, one file per session, under the chats directory.
+const archiveDir = "archive"
+
+// ChatLimits bounds what the chats directory keeps live. Anything over a
+// limit is moved to the archive rather than deleted.
+type ChatLimits struct {
+	MaxMessages int   // messages kept per session; older turns are archived
+	MaxSessions int   // sessions kept; the oldest are archived
+	MaxBytes    int64 // total size of the chats directory; 0 = unlimited
+}
+
+// ChatLimitsFrom reads the limits from the [web] config.
+func ChatLimitsFrom(c config.WebConfig) ChatLimits {
+	return ChatLimits{
+		MaxMessages: c.ChatMaxMessages,
+		MaxSessions: c.ChatMaxSessions,
+		MaxBytes:    int64(c.ChatMaxSizeMB) << 20,
+	}
+}
+
+// ChatsDir returns the directory chat sessions are stored in.
+func ChatsDir() string {
+	return filepath.Join(config.Dir(), "chats")
+}
+
+// ArchivedMessage is one line of an archive or export file.
+type ArchivedMessage struct {
+	Session string `json:"session"`
+	Title   string `json:"title,omitempty"`
+	ChatMessage
+}
+
+func archivePath(dir, id string) string {
+	return filepath.Join(dir, archiveDir, id+".jsonl.gz")
+}
+
+// appendArchive appends messages to a session's archive. Each call adds a
+// gzip member; readers see one continuous stream.
+func appendArchive(dir, id, title string, msgs []ChatMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	path := archivePath(dir, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for _, m := range msgs {
+		if err := enc.Encode(ArchivedMessage{Session: id, Title: title, ChatMessage: m}); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyArchive streams a session's archived messages to w as JSONL.
+// A missing archive copies nothing.
+func copyArchive(dir, id string, w io.Writer) error {
+	f, err := os.Open(archivePath(dir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, zr)
+	return err
+}
+
+// archiveSession moves a whole session into the archive and removes its
+// file and tool workspace.
+func (s *SessionStore) archiveSession(id string) error {
+	data, err := s.loadFromDisk(id)
+	if err != nil {
+		return err
+	}
+	if err := appendArchive(s.dir, id, data.Title, data.Messages); err != nil {
+		return fmt.Errorf("archive session %s: %w", id, err)
+	}
+	if err := os.Remove(filepath.Join(s.dir, id+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(s.workspaceDir(id))
+}
+
+// enforceLimits archives the oldest sessions beyond MaxSessions, then
+// keeps archiving while the chats directory is over MaxBytes. If archives
+// alone still exceed the budget, the oldest archive files are deleted.
+// The current session is never touched.
+func (s *SessionStore) enforceLimits() {
+	metas := s.listMetas() // newest first
+	var candidates []string
+	for _, m := range metas {
+		if s.current == nil || m.ID != s.current.id {
+			candidates = append(candidates, m.ID)
+		}
+	}
+	keep := s.limits.MaxSessions - 1 // one slot for the current session
+	for len(candidates) > max(keep, 0) {
+		id := candidates[len(candidates)-1]
+		candidates = candidates[:len(candidates)-1]
+		if err := s.archiveSession(id); err != nil {
+			slog.Warn("chat: archive session", "id", id, "error", err)
+		}
+	}
+
+	if s.limits.MaxBytes <= 0 {
+		return
+	}
+	size := dirSize(s.dir)
+	for size > s.limits.MaxBytes && len(candidates) > 0 {
+		id := candidates[len(candidates)-1]
+		candidates = candidates[:len(candidates)-1]
+		if err := s.archiveSession(id); err != nil {
+			slog.Warn("chat: archive session", "id", id, "error", err)
+		}
+		size = dirSize(s.dir)
+	}
+	if size <= s.limits.MaxBytes {
+		return
+	}
+	for _, path := range oldestArchives(s.dir) {
+		if s.current != nil && path == archivePath(s.dir, s.current.id) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || os.Remove(path) != nil {
+			continue
+		}
+		slog.Warn("chat: deleted archive over size budget", "file", filepath.Base(path))
+		s.warn(fmt.Sprintf("Chat history is over its %d MB budget — deleted the archive %s.",
+			s.limits.MaxBytes>>20, filepath.Base(path)))
+		if size -= info.Size(); size <= s.limits.MaxBytes {
+			return
+		}
+	}
+}
+
+// dirSize totals the regular files under dir.
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// oldestArchives lists archive files by modification time, oldest first.
+func oldestArchives(dir string) []string {
+	entries, err := os.ReadDir(filepath.Join(dir, archiveDir))
+	if err != nil {
+		return nil
+	}
+	type file struct {
+		path string
+		mod  time.Time
+	}
+	var files []file
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && strings.HasSuffix(e.Name(), ".jsonl.gz") {
+			files = append(files, file{filepath.Join(dir, archiveDir, e.Name()), info.ModTime()})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod.Before(files[j].mod) })
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// ExportSessions writes every session in dir last updated before cutoff,
+// including its archived messages, to out as gzipped JSONL, then removes
+// the sessions, their workspaces and archives. Sessions that only exist
+// in the archive are selected by the archive's modification time. With
+// dryRun nothing is written or removed. It returns the sessions selected.
+func ExportSessions(dir string, cutoff time.Time, out string, dryRun bool) ([]SessionMeta, error) {
+	store := &SessionStore{dir: dir}
+	var old []SessionMeta
+	live := make(map[string]bool)
+	for _, m := range store.listMetas() {
+		live[m.ID] = true
+		if m.UpdatedAt.Before(cutoff) {
+			old = append(old, m)
+		}
+	}
+	for _, path := range oldestArchives(dir) {
+		id := strings.TrimSuffix(filepath.Base(path), ".jsonl.gz")
+		if info, err := os.Stat(path); err == nil && !live[id] && info.ModTime().Before(cutoff) {
+			old = append(old, SessionMeta{ID: id, UpdatedAt: info.ModTime()})
+		}
+	}
+	if dryRun || len(old) == 0 {
+		return old, nil
+	}
+
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fail := func(err error) ([]SessionMeta, error) {
+		f.Close()
+		os.Remove(out)
+		return nil, err
+	}
+	zw := gzip.NewWriter(f)
+	bw := bufio.NewWriter(zw)
+	enc := json.NewEncoder(bw)
+	for _, m := range old {
+		if err := copyArchive(dir, m.ID, bw); err != nil {
+			return fail(fmt.Errorf("read archive of %s: %w", m.ID, err))
+		}
+		if !live[m.ID] {
+			continue
+		}
+		data, err := store.loadFromDisk(m.ID)
+		if err != nil {
+			return fail(err)
+		}
+		for _, msg := range data.Messages {
+			if err := enc.Encode(ArchivedMessage{Session: m.ID, Title: data.Title, ChatMessage: msg}); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fail(err)
+	}
+	if err := zw.Close(); err != nil {
+		return fail(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(out)
+		return nil, err
+	}
+
+	// Only clear once the export is safely on disk.
+	for _, m := range old {
+		_ = os.Remove(filepath.Join(dir, m.ID+".json"))
+		_ = os.Remove(archivePath(dir, m.ID))
+		if validSessionID(m.ID) {
+			_ = os.RemoveAll(store.workspaceDir(m.ID))
+		}
+	}
+	return old, nil
+}
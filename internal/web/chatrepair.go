@@ -0,0 +1,133 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// corruptDir holds session files that no longer parse, under the chats
+// directory. They are kept for manual recovery and never loaded again.
+const corruptDir = "corrupt"
+
+// repair handles a session file that failed to parse: the file is moved to
+// corruptDir and whatever it still holds (header fields and every complete
+// message) is written back under the same id. A warning is queued for the
+// console either way.
+func (s *SessionStore) repair(id string, raw []byte, parseErr error) (*Session, error) {
+	qdir := filepath.Join(s.dir, corruptDir)
+	qpath := filepath.Join(qdir, fmt.Sprintf("%s-%s.json", id, time.Now().Format("20060102-150405")))
+	if err := os.MkdirAll(qdir, 0700); err != nil {
+		return nil, parseErr
+	}
+	if err := os.Rename(filepath.Join(s.dir, id+".json"), qpath); err != nil {
+		return nil, parseErr
+	}
+	slog.Warn("chat: quarantined unreadable session", "id", id, "error", parseErr, "moved_to", qpath)
+
+	data := salvageSession(raw)
+	if data == nil || len(data.Messages) == 0 {
+		s.warn(fmt.Sprintf("Chat session %s could not be read and was moved to %s.", id, qpath))
+		return nil, fmt.Errorf("session %s is corrupted: %w", id, parseErr)
+	}
+	data.normalize(id)
+	if data.Title == "" {
+		data.Title = "Recovered chat"
+	}
+	if err := s.writeSession(data); err != nil {
+		s.warn(fmt.Sprintf("Chat session %s could not be read and was moved to %s.", id, qpath))
+		return nil, err
+	}
+	slog.Info("chat: recovered session", "id", id, "messages", len(data.Messages))
+	s.warn(fmt.Sprintf("Chat session %q was damaged — recovered %d messages. The original file is in %s.",
+		data.Title, len(data.Messages), qpath))
+	return data, nil
+}
+
+// salvageSession decodes as much of a damaged session file as it can,
+// stopping at the first error: the header fields seen so far and every
+// message that decoded completely. It returns nil if nothing was found.
+func salvageSession(raw []byte) *Session {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+	fields := make(map[string]json.RawMessage)
+	var msgs []ChatMessage
+scan:
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, _ := t.(string)
+		if key != "messages" {
+			var v json.RawMessage
+			if dec.Decode(&v) != nil {
+				break
+			}
+			fields[key] = v
+			continue
+		}
+		if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+			break
+		}
+		for dec.More() {
+			var m ChatMessage
+			if dec.Decode(&m) != nil {
+				break scan
+			}
+			msgs = append(msgs, m)
+		}
+		if _, err := dec.Token(); err != nil {
+			break
+		}
+	}
+	if len(fields) == 0 && len(msgs) == 0 {
+		return nil
+	}
+
+	var data Session
+	if b, err := json.Marshal(fields); err == nil {
+		_ = json.Unmarshal(b, &data) // a mistyped field is left zero
+	}
+	data.Messages = msgs
+	return &data
+}
+
+// normalize fixes what a hand-edited, copied or salvaged file may get
+// wrong: the id must match the file name, and only user and assistant
+// turns can be replayed to the model.
+func (d *Session) normalize(id string) {
+	d.ID = id
+	kept := d.Messages[:0]
+	for _, m := range d.Messages {
+		if m.Role == "user" || m.Role == "assistant" {
+			kept = append(kept, m)
+		}
+	}
+	d.Messages = kept
+	if d.UpdatedAt.IsZero() {
+		d.UpdatedAt = d.CreatedAt
+	}
+}
+
+// warn queues a message for the console, shown once in the chat panel.
+func (s *SessionStore) warn(msg string) {
+	s.warnMu.Lock()
+	defer s.warnMu.Unlock()
+	s.warnings = append(s.warnings, msg)
+}
+
+// TakeWarnings returns and clears queued storage warnings.
+func (s *SessionStore) TakeWarnings() []string {
+	s.warnMu.Lock()
+	defer s.warnMu.Unlock()
+	w := s.warnings
+	s.warnings = nil
+	return w
+}
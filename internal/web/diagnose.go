@@ -0,0 +1,164 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/daemon"
+)
+
+// What a chat opened from an event is seeded with.
+const (
+	diagnoseEvents   = 15       // events leading up to the one explained
+	diagnoseLogLines = 40       // last lines of the log file
+	diagnoseLogBytes = 64 << 10 // read from the end of the log file
+)
+
+// handleChatFromEvent opens a new chat session seeded with an event, the
+// events before it, recent log lines and the miner's quotas, and asks the
+// agent to explain it: {"event":{...},"question":"..."}.
+func (s *Server) handleChatFromEvent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req struct {
+		Event    Event  `json:"event"`
+		Question string `json:"question"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Event.Type == "" || req.Event.Message == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "event with type and message required"})
+		return
+	}
+
+	msg := s.eventChatMessage(req.Event, req.Question)
+	id := s.store.NewSession()
+	reply, action, err := s.store.Chat(s.chatContext(r.Context()), msg, nil)
+	if err != nil {
+		if s.clientGone(r, "Event chat reply") {
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": id, "error": err.Error()})
+		return
+	}
+	var actionResult string
+	if action != nil {
+		actionResult = s.executeAction(action)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"id":      id,
+		"message": msg,
+		"reply":   reply,
+		"action":  actionResult,
+	})
+}
+
+// eventChatMessage builds the first message of a chat about ev.
+func (s *Server) eventChatMessage(ev Event, question string) string {
+	cfg := s.cfg.Load()
+	var sb strings.Builder
+	// The first line becomes the session title.
+	if question = strings.TrimSpace(question); question == "" {
+		question = "Explain this event: " + ev.Message + "\nWhat went wrong, why, and what should I do about it?"
+	}
+	sb.WriteString(question + "\n\n")
+
+	sb.WriteString("--- Event ---\n")
+	fmt.Fprintf(&sb, "%s [%s] %s\n", ev.Time, ev.Type, ev.Message)
+	if ev.Data != nil {
+		if data, err := json.Marshal(ev.Data); err == nil {
+			fmt.Fprintf(&sb, "data: %s\n", data)
+		}
+	}
+
+	if before := eventsBefore(s.hub.Recent(), ev, diagnoseEvents); len(before) > 0 {
+		sb.WriteString("\n--- Events before it ---\n")
+		for _, e := range before {
+			fmt.Fprintf(&sb, "%s [%s] %s\n", e.Time, e.Type, e.Message)
+		}
+	}
+
+	if path, lines := recentLogLines(cfg, diagnoseLogLines); len(lines) > 0 {
+		fmt.Fprintf(&sb, "\n--- Last %d log lines (%s) ---\n", len(lines), path)
+		for _, l := range lines {
+			sb.WriteString(l + "\n")
+		}
+	}
+
+	sb.WriteString("\n--- Miner ---\n")
+	fmt.Fprintf(&sb, "LLM: %s, model %s", cfg.LLM.Provider, cfg.LLM.Model)
+	if cfg.LLM.BaseURL != "" {
+		fmt.Fprintf(&sb, ", %s", cfg.LLM.BaseURL)
+	}
+	sb.WriteString("\n")
+	now := time.Now()
+	for _, q := range s.minerState.Quotas(now) {
+		if q.Available(now) {
+			continue
+		}
+		fmt.Fprintf(&sb, "Blocked: %s for %s", q.Label, q.Remaining(now).Round(time.Second))
+		if q.Reason != "" {
+			fmt.Fprintf(&sb, " (%s)", q.Reason)
+		}
+		sb.WriteString("\n")
+	}
+	return redactSecrets(cfg, sb.String())
+}
+
+// eventsBefore returns up to n events that precede ev in history. If ev is
+// no longer in history, the last n events are returned.
+func eventsBefore(history []Event, ev Event, n int) []Event {
+	end := len(history)
+	for i := len(history) - 1; i >= 0; i-- {
+		if e := history[i]; e.Time == ev.Time && e.Type == ev.Type && e.Message == ev.Message {
+			end = i
+			break
+		}
+	}
+	return history[max(end-n, 0):end]
+}
+
+// recentLogLines returns the last n lines of logging.file, or of the
+// service log when the miner runs as a service.
+func recentLogLines(cfg *config.Config, n int) (string, []string) {
+	for _, path := range []string{cfg.Logging.File, daemon.LogPath()} {
+		if path == "" {
+			continue
+		}
+		data, err := readTail(path, diagnoseLogBytes)
+		if err != nil || len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		lines := strings.Split(string(bytes.TrimRight(data, "\n")), "\n")
+		return path, lines[max(len(lines)-n, 0):]
+	}
+	return "", nil
+}
+
+func readTail(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err == nil && fi.Size() > limit {
+		_, _ = f.Seek(-limit, io.SeekEnd)
+	}
+	return io.ReadAll(f)
+}
+
+// redactSecrets masks the config's API keys in text bound for the LLM.
+func redactSecrets(cfg *config.Config, text string) string {
+	for _, secret := range []string{cfg.Agent.APIKey, cfg.LLM.APIKey} {
+		if len(secret) >= 6 {
+			text = strings.ReplaceAll(text, secret, "[REDACTED]")
+		}
+	}
+	return text
+}
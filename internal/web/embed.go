@@ -4,3 +4,6 @@ import "embed"
 
 //go:embed static
 var staticFS embed.FS
+
+//go:embed i18n
+var i18nFS embed.FS
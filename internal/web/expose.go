@@ -0,0 +1,208 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/config"
+)
+
+// exposure is the console served off localhost (web.listen_addr).
+type exposure struct {
+	srv         *http.Server
+	url         string // https://host:port
+	fingerprint string // SHA-256 of a generated certificate; empty for web.tls_cert
+}
+
+// TLSDir holds the self-signed certificate of a console served off
+// localhost without web.tls_cert.
+func TLSDir() string {
+	return filepath.Join(config.Dir(), "console-tls")
+}
+
+// expose serves the console on hosts over HTTPS, behind web.console_token.
+// The port search is the same as for the loopback console.
+func (s *Server) expose(hosts []string, pinned bool) error {
+	web := s.cfg.Load().Web
+	if len(web.ConsoleToken) < 16 {
+		return fmt.Errorf("web.console_token must be at least 16 characters to serve the console on %s", s.listenAddr)
+	}
+	cert, fingerprint, err := consoleCert(web, s.listenAddr)
+	if err != nil {
+		return err
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	tries := maxPortRetries
+	if pinned {
+		tries = 1
+	}
+	for i := 0; i < tries; i++ {
+		lns, err := listenAll(hosts, s.port+i)
+		if err != nil {
+			if pinned {
+				return fmt.Errorf("port %d on %s: %w", s.port, s.listenAddr, err)
+			}
+			continue
+		}
+		s.exposed = exposure{
+			srv: &http.Server{
+				Handler:           requireConsoleToken(web.ConsoleToken, s.httpSrv.Handler),
+				ReadHeaderTimeout: 10 * time.Second,
+			},
+			url:         "https://" + net.JoinHostPort(exposedHost(s.listenAddr), strconv.Itoa(s.port+i)),
+			fingerprint: fingerprint,
+		}
+		for _, ln := range lns {
+			go func(ln net.Listener) {
+				if err := s.exposed.srv.Serve(tls.NewListener(ln, tlsCfg)); err != http.ErrServerClosed {
+					slog.Error("web console error", "error", err)
+				}
+			}(ln)
+		}
+		return nil
+	}
+	return fmt.Errorf("no available port on %s in range %d-%d", s.listenAddr, s.port, s.port+maxPortRetries-1)
+}
+
+// ExposedURL returns the address of the console served off localhost and
+// the fingerprint of its generated certificate, both empty if there is
+// none.
+func (s *Server) ExposedURL() (url, fingerprint string) {
+	return s.exposed.url, s.exposed.fingerprint
+}
+
+// exposedHost is the host printed in the exposed console's URL: this
+// machine's name for a wildcard address, otherwise the configured one.
+func exposedHost(listenAddr string) string {
+	if ip := net.ParseIP(listenAddr); ip != nil && ip.IsUnspecified() {
+		if name, err := os.Hostname(); err == nil {
+			return name
+		}
+	}
+	return listenAddr
+}
+
+// requireConsoleToken admits requests carrying token as the password of
+// HTTP basic auth (any user name; browsers prompt for it) or as a bearer
+// token.
+func requireConsoleToken(token string, next http.Handler) http.Handler {
+	want := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			_, got, _ = r.BasicAuth()
+		}
+		if subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			if got != "" {
+				slog.Warn("web console: rejected request", "remote", r.RemoteAddr, "path", r.URL.Path)
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="ClawWork console", charset="UTF-8"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// consoleCert loads web.tls_cert and web.tls_key, or the self-signed
+// certificate in TLSDir, generating it when missing or about to expire.
+func consoleCert(web config.WebConfig, host string) (cert tls.Certificate, fingerprint string, err error) {
+	if web.TLSCert != "" {
+		cert, err = tls.LoadX509KeyPair(web.TLSCert, web.TLSKey)
+		if err != nil {
+			return cert, "", fmt.Errorf("web.tls_cert: %w", err)
+		}
+		return cert, "", nil
+	}
+
+	certPath, keyPath := filepath.Join(TLSDir(), "cert.pem"), filepath.Join(TLSDir(), "key.pem")
+	if cert, err = tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && time.Until(leaf.NotAfter) > 7*24*time.Hour {
+			return cert, certFingerprint(cert), nil
+		}
+	}
+	if err = generateCert(certPath, keyPath, host); err != nil {
+		return cert, "", fmt.Errorf("generate console certificate: %w", err)
+	}
+	if cert, err = tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return cert, "", err
+	}
+	return cert, certFingerprint(cert), nil
+}
+
+// generateCert writes a self-signed certificate, valid for a year, for
+// host, this machine's name and localhost.
+func generateCert(certPath, keyPath, host string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "ClawWork console"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if name, err := os.Hostname(); err == nil {
+		tmpl.DNSNames = append(tmpl.DNSNames, name)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !ip.IsUnspecified() {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		}
+	} else if host != "" {
+		tmpl.DNSNames = append(tmpl.DNSNames, host)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(certPath), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+}
+
+// certFingerprint formats the SHA-256 of cert's leaf as browsers show it.
+func certFingerprint(cert tls.Certificate) string {
+	sum := sha256.Sum256(cert.Certificate[0])
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
@@ -3,11 +3,18 @@
 package web
 
 import (
+	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-const maxHistory = 200
+// Default hub sizes, used when the [web] config leaves them unset.
+const (
+	DefaultEventHistory = 200
+	DefaultClientBuffer = 64
+)
 
 // Event is a single event broadcast to SSE clients.
 type Event struct {
@@ -15,23 +22,57 @@ type Event struct {
 	Message string `json:"message"`
 	Time    string `json:"time"`
 	Data    any    `json:"data,omitempty"`
+
+	// Alert is how the console should signal the event, per the alert
+	// preferences when it was published. Remote consumers can follow it
+	// to behave like the console.
+	Alert *Alert `json:"alert,omitempty"`
+}
+
+// subscriber is one connected SSE client.
+type subscriber struct {
+	dropped int // events dropped since the last warning was delivered
 }
 
 // EventHub broadcasts mining events to connected SSE clients.
+// Slow clients never block the miner: when a client's buffer is full the
+// event is dropped for that client, counted, and a "warning" event is
+// delivered once it catches up.
 type EventHub struct {
-	mu      sync.RWMutex
-	clients map[chan Event]struct{}
-	history []Event
+	mu         sync.RWMutex
+	clients    map[chan Event]*subscriber
+	history    []Event
+	maxHistory int
+	bufferSize int
+	dropped    atomic.Int64 // total across all clients
+
+	alertFor func(eventType string) *Alert
 }
 
-// NewEventHub creates a new event hub.
-func NewEventHub() *EventHub {
+// NewEventHub creates a new event hub keeping historySize events for replay
+// and buffering bufferSize events per client. Zero selects the defaults.
+func NewEventHub(historySize, bufferSize int) *EventHub {
+	if historySize <= 0 {
+		historySize = DefaultEventHistory
+	}
+	if bufferSize <= 0 {
+		bufferSize = DefaultClientBuffer
+	}
 	return &EventHub{
-		clients: make(map[chan Event]struct{}),
-		history: make([]Event, 0, maxHistory),
+		clients:    make(map[chan Event]*subscriber),
+		history:    make([]Event, 0, historySize),
+		maxHistory: historySize,
+		bufferSize: bufferSize,
 	}
 }
 
+// SetAlerts makes Publish add fn's hint to each event.
+func (h *EventHub) SetAlerts(fn func(eventType string) *Alert) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.alertFor = fn
+}
+
 // Publish sends an event to all connected clients and stores it in history.
 func (h *EventHub) Publish(e Event) {
 	if e.Time == "" {
@@ -39,30 +80,70 @@ func (h *EventHub) Publish(e Event) {
 	}
 
 	h.mu.Lock()
-	if len(h.history) >= maxHistory {
+	defer h.mu.Unlock()
+	if e.Alert == nil && h.alertFor != nil {
+		e.Alert = h.alertFor(e.Type)
+	}
+	if len(h.history) >= h.maxHistory {
 		h.history = h.history[1:]
 	}
 	h.history = append(h.history, e)
-	h.mu.Unlock()
 
-	h.mu.RLock()
-	for ch := range h.clients {
+	for ch, sub := range h.clients {
+		// Tell a client that caught up how much it missed, but only when
+		// there is room for the warning and the current event.
+		if sub.dropped > 0 && len(ch)+2 <= cap(ch) {
+			warn := Event{
+				Type:    "warning",
+				Message: fmt.Sprintf("Console fell behind — %d event(s) dropped", sub.dropped),
+				Time:    e.Time,
+				Data:    map[string]any{"dropped": sub.dropped},
+			}
+			select {
+			case ch <- warn:
+				sub.dropped = 0
+			default:
+			}
+		}
 		select {
 		case ch <- e:
 		default:
 			// Slow client — drop event to avoid blocking the miner.
+			if sub.dropped == 0 {
+				slog.Warn("console client is slow, dropping events", "buffer", cap(ch))
+			}
+			sub.dropped++
+			h.dropped.Add(1)
 		}
 	}
-	h.mu.RUnlock()
+}
+
+// Dropped returns the total number of events dropped for slow clients.
+func (h *EventHub) Dropped() int64 { return h.dropped.Load() }
+
+// Clients returns the number of connected SSE subscribers.
+func (h *EventHub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Recent returns a copy of the buffered event history, oldest first.
+func (h *EventHub) Recent() []Event {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	snapshot := make([]Event, len(h.history))
+	copy(snapshot, h.history)
+	return snapshot
 }
 
 // Subscribe returns a channel of events and an unsubscribe function.
 // The caller receives a replay of recent history followed by live events.
 func (h *EventHub) Subscribe() (<-chan Event, func()) {
-	ch := make(chan Event, 64)
+	ch := make(chan Event, h.bufferSize)
 
 	h.mu.Lock()
-	h.clients[ch] = struct{}{}
+	h.clients[ch] = &subscriber{}
 	snapshot := make([]Event, len(h.history))
 	copy(snapshot, h.history)
 	h.mu.Unlock()
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Languages are the console locales with a bundle in i18n/. English is the
+// text in index.html itself; its bundle exists so every key is listed.
+var Languages = []string{"en", "zh"}
+
+// negotiateLang picks a supported language from an Accept-Language header.
+func negotiateLang(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if slices.Contains(Languages, base) {
+			return base
+		}
+	}
+	return "en"
+}
+
+// handleI18nInfo reports the console language (saved preference, else the
+// browser's) and the languages available.
+func (s *Server) handleI18nInfo(w http.ResponseWriter, r *http.Request) {
+	lang := s.prefs.Get().Lang
+	if lang == "" {
+		lang = negotiateLang(r.Header.Get("Accept-Language"))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"lang": lang, "available": Languages})
+}
+
+// handleI18nSet saves the console language. An empty lang returns to the
+// browser default.
+func (s *Server) handleI18nSet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Lang string `json:"lang"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Lang != "" && !slices.Contains(Languages, req.Lang) {
+		http.Error(w, `{"error":"unsupported language"}`, http.StatusBadRequest)
+		return
+	}
+	if err := s.prefs.Update(func(p *Prefs) { p.Lang = req.Lang }); err != nil {
+		http.Error(w, `{"error":"save failed"}`, http.StatusInternalServerError)
+		return
+	}
+	s.handleI18nInfo(w, r)
+}
+
+// handleI18nBundle serves /i18n/{lang}.json, for the console and the setup
+// wizard.
+func handleI18nBundle(w http.ResponseWriter, r *http.Request) {
+	lang := strings.TrimSuffix(r.PathValue("file"), ".json")
+	if !slices.Contains(Languages, lang) {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := fs.ReadFile(i18nFS, "i18n/"+lang+".json")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// leaderboardTTL is how long a fetched ranking is reused by the console widget.
+const leaderboardTTL = 5 * time.Minute
+
+// leaderboardCache holds the last nearby ranking so page reloads and
+// multiple tabs don't each hit the platform.
+type leaderboardCache struct {
+	mu       sync.Mutex
+	at       time.Time
+	lb       *api.LeaderboardResponse
+	err      error
+	fetching chan struct{} // closed when the fetch in flight finishes
+}
+
+// get returns the cached ranking, calling fetch when it is stale. The
+// fetch runs outside the lock, and callers arriving while it is in flight
+// wait for its result instead of starting their own.
+func (c *leaderboardCache) get(ctx context.Context, fetch func(context.Context) (*api.LeaderboardResponse, error)) (*api.LeaderboardResponse, error) {
+	c.mu.Lock()
+	if time.Since(c.at) <= leaderboardTTL {
+		defer c.mu.Unlock()
+		return c.lb, c.err
+	}
+	if done := c.fetching; done != nil {
+		c.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.lb, c.err
+	}
+	done := make(chan struct{})
+	c.fetching = done
+	c.mu.Unlock()
+
+	// Others may be waiting on this fetch: don't let this caller's
+	// disconnect cancel it.
+	fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	lb, err := fetch(fctx)
+	cancel()
+
+	c.mu.Lock()
+	c.lb, c.err, c.at, c.fetching = lb, err, time.Now(), nil
+	c.mu.Unlock()
+	close(done)
+	return lb, err
+}
+
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	lb, err := s.leaderboard.get(r.Context(), func(ctx context.Context) (*api.LeaderboardResponse, error) {
+		lb, err := s.api.Leaderboard(ctx, "nearby", "cw", 5)
+		if err != nil && !errors.Is(err, api.ErrNotSupported) {
+			slog.Debug("leaderboard fetch failed", "error", err)
+		}
+		return lb, err
+	})
+	if err != nil && r.Context().Err() != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case errors.Is(err, api.ErrNotSupported):
+		_ = json.NewEncoder(w).Encode(map[string]any{"available": false})
+	case err != nil:
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"available": true,
+			"scope":     lb.Scope,
+			"total":     lb.Total,
+			"self":      lb.Self,
+			"entries":   lb.Entries,
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+)
+
+// Callers arriving during a fetch share it, and the lock isn't held while
+// it runs: a caller that gives up returns at once.
+func TestLeaderboardCacheCoalesces(t *testing.T) {
+	var c leaderboardCache
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (*api.LeaderboardResponse, error) {
+		calls.Add(1)
+		<-release
+		return &api.LeaderboardResponse{Total: 9}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if lb, err := c.get(context.Background(), fetch); err != nil || lb.Total != 9 {
+				t.Errorf("get = %+v, %v", lb, err)
+			}
+		}()
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.get(ctx, fetch); err != context.DeadlineExceeded {
+		t.Errorf("get while a fetch is in flight = %v, want the caller's deadline", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("%d fetches, want 1", n)
+	}
+	if lb, _ := c.get(context.Background(), fetch); lb.Total != 9 || calls.Load() != 1 {
+		t.Error("fresh ranking was fetched again")
+	}
+}
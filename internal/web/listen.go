@@ -0,0 +1,95 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// loopbackHosts are bound when web.listen_addr is empty or "localhost".
+var loopbackHosts = []string{"127.0.0.1", "::1"}
+
+// lookupHost resolves a hostname in web.listen_addr; tests replace it.
+var lookupHost = net.LookupHost
+
+// listenHosts resolves web.listen_addr to the addresses to bind, and
+// reports whether any of them is reachable from other machines (see
+// expose).
+func listenHosts(host string) (hosts []string, exposed bool, err error) {
+	switch host {
+	case "", "localhost":
+		hosts, err = usableHosts(loopbackHosts)
+		return hosts, false, err
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{ip.String()}, !ip.IsLoopback(), nil
+	}
+	addrs, err := lookupHost(host)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, a := range addrs {
+		if ip := net.ParseIP(a); ip == nil || !ip.IsLoopback() {
+			exposed = true
+		}
+	}
+	if exposed {
+		return addrs, true, nil
+	}
+	hosts, err = usableHosts(addrs)
+	return hosts, false, err
+}
+
+// usableHosts drops addresses this machine can't bind, such as 127.0.0.1
+// on an IPv6-only host or ::1 where IPv6 is disabled.
+func usableHosts(hosts []string) ([]string, error) {
+	if len(hosts) == 1 {
+		return hosts, nil
+	}
+	var ok []string
+	var lastErr error
+	for _, h := range hosts {
+		ln, err := net.Listen("tcp", net.JoinHostPort(h, "0"))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ln.Close()
+		ok = append(ok, h)
+	}
+	if len(ok) == 0 {
+		return nil, fmt.Errorf("no loopback address can be bound: %w", lastErr)
+	}
+	return ok, nil
+}
+
+// listenAll binds port on every host, or on none if any of them fails, so
+// the console answers on the same port over IPv4 and IPv6.
+func listenAll(hosts []string, port int) ([]net.Listener, error) {
+	var lns []net.Listener
+	for _, h := range hosts {
+		ln, err := net.Listen("tcp", net.JoinHostPort(h, strconv.Itoa(port)))
+		if err != nil {
+			for _, l := range lns {
+				l.Close()
+			}
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	if len(lns) == 0 {
+		return nil, errors.New("no address to listen on")
+	}
+	return lns, nil
+}
+
+// urlHost picks the host printed in the console URL: the configured
+// hostname as given, otherwise the first bound address, so the link works
+// regardless of how "localhost" resolves in the browser.
+func urlHost(configured string, bound []string) string {
+	if configured != "" && configured != "localhost" && net.ParseIP(configured) == nil {
+		return configured
+	}
+	return bound[0]
+}
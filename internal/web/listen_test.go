@@ -0,0 +1,50 @@
+package web
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestListenHosts(t *testing.T) {
+	resolve := map[string][]string{
+		"lan.example":  {"192.168.1.20"},
+		"both.example": {"127.0.0.1", "10.0.0.5"},
+		"loop.example": {"127.0.0.1"},
+	}
+	defer func(old func(string) ([]string, error)) { lookupHost = old }(lookupHost)
+	lookupHost = func(host string) ([]string, error) {
+		if addrs, ok := resolve[host]; ok {
+			return addrs, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	tests := []struct {
+		host    string
+		want    []string
+		exposed bool
+	}{
+		{"0.0.0.0", []string{"0.0.0.0"}, true},
+		{"::", []string{"::"}, true},
+		{"192.168.1.20", []string{"192.168.1.20"}, true},
+		{"127.0.0.1", []string{"127.0.0.1"}, false},
+		{"::1", []string{"::1"}, false},
+		{"lan.example", []string{"192.168.1.20"}, true},
+		{"both.example", []string{"127.0.0.1", "10.0.0.5"}, true},
+		{"loop.example", []string{"127.0.0.1"}, false},
+	}
+	for _, tt := range tests {
+		hosts, exposed, err := listenHosts(tt.host)
+		if err != nil {
+			t.Errorf("%s: %v", tt.host, err)
+			continue
+		}
+		if !slices.Equal(hosts, tt.want) || exposed != tt.exposed {
+			t.Errorf("%s: hosts %v exposed %v, want %v %v", tt.host, hosts, exposed, tt.want, tt.exposed)
+		}
+	}
+	if _, _, err := listenHosts("missing.example"); err == nil {
+		t.Error("unresolvable host: no error")
+	}
+}
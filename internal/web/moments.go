@@ -0,0 +1,126 @@
+package web
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// maxMomentHistory is how many posted moments are kept for duplicate checks.
+	maxMomentHistory = 100
+	// momentSimilarityThreshold rejects candidates at least this similar to a past post.
+	momentSimilarityThreshold = 0.75
+	// maxMomentAttempts bounds regeneration when candidates keep repeating.
+	maxMomentAttempts = 3
+)
+
+// MomentHistory keeps the texts of recently posted moments on disk so the
+// agent doesn't repeat itself across restarts.
+type MomentHistory struct {
+	mu    sync.Mutex
+	path  string
+	Texts []string `json:"texts"`
+}
+
+// LoadMomentHistory reads the history file. A missing or corrupt file yields an empty history.
+func LoadMomentHistory(path string) *MomentHistory {
+	h := &MomentHistory{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, h)
+	}
+	return h
+}
+
+// Add records a posted moment and persists the history.
+func (h *MomentHistory) Add(text string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Texts = append(h.Texts, text)
+	if len(h.Texts) > maxMomentHistory {
+		h.Texts = h.Texts[len(h.Texts)-maxMomentHistory:]
+	}
+	data, _ := json.Marshal(h)
+	_ = os.WriteFile(h.path, data, 0600)
+}
+
+// Recent returns up to n of the most recent posts, newest last.
+func (h *MomentHistory) Recent(n int) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n > len(h.Texts) {
+		n = len(h.Texts)
+	}
+	return append([]string(nil), h.Texts[len(h.Texts)-n:]...)
+}
+
+// FindSimilar returns the past post most similar to text if it crosses the
+// duplicate threshold, or "" if text is fresh enough.
+func (h *MomentHistory) FindSimilar(text string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	candidate := normalizeMoment(text)
+	best, bestScore := "", 0.0
+	for _, past := range h.Texts {
+		if score := similarity(candidate, normalizeMoment(past)); score > bestScore {
+			best, bestScore = past, score
+		}
+	}
+	if bestScore >= momentSimilarityThreshold {
+		return best
+	}
+	return ""
+}
+
+// normalizeMoment lowercases text, drops punctuation and collapses whitespace
+// so cosmetic differences don't hide a repeat.
+func normalizeMoment(s string) []rune {
+	var out []rune
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && len(out) > 0 {
+				out = append(out, ' ')
+			}
+			out = append(out, r)
+			space = false
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+	return out
+}
+
+// similarity returns 1 - normalized Levenshtein distance (1 = identical).
+func similarity(a, b []rune) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein computes edit distance with a single rolling row.
+func levenshtein(a, b []rune) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cur := row[j]
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			row[j] = min(row[j]+1, row[j-1]+1, prev+cost)
+			prev = cur
+		}
+	}
+	return row[len(b)]
+}
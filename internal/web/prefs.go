@@ -0,0 +1,210 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// Panels the console can hide. Log and chat can't both be hidden.
+var consolePanels = []string{"log", "chat", "commands"}
+
+// Alert is how the console draws attention to events of one type.
+type Alert struct {
+	Sound bool `json:"sound,omitempty"` // play a chime
+	Flash bool `json:"flash,omitempty"` // highlight the log line and flash the tab title
+}
+
+// defaultAlerts apply until alerts are saved.
+var defaultAlerts = map[string]Alert{
+	"hit":   {Sound: true, Flash: true},
+	"alert": {Sound: true, Flash: true},
+	"error": {Flash: true},
+}
+
+// maxAlerts caps the event types in Prefs.Alerts.
+const maxAlerts = 64
+
+var eventTypeRE = regexp.MustCompile(`^[a-z][a-z_]{0,31}$`)
+
+// Prefs are console settings kept in ~/.clawwork/prefs.json, so they
+// follow the agent rather than the browser.
+type Prefs struct {
+	Lang           string   `json:"lang,omitempty"`            // console language; empty = browser default
+	Theme          string   `json:"theme,omitempty"`           // "dark" or "light"; empty = dark
+	HiddenPanels   []string `json:"hidden_panels,omitempty"`   // subset of log, chat, commands
+	LogWidth       int      `json:"log_width,omitempty"`       // log panel width in px; 0 = default split
+	DefaultSession string   `json:"default_session,omitempty"` // chat session opened at startup
+
+	// Alerts maps event types to how the console signals them; nil means
+	// defaultAlerts. Published events carry the matching entry as a hint.
+	Alerts map[string]Alert `json:"alerts"`
+}
+
+// PrefsStore loads and saves Prefs.
+type PrefsStore struct {
+	mu   sync.Mutex
+	path string
+	p    Prefs
+}
+
+// LoadPrefs reads preferences from path. A missing or unreadable file
+// yields defaults.
+func LoadPrefs(path string) *PrefsStore {
+	s := &PrefsStore{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &s.p)
+	}
+	return s
+}
+
+// Get returns a copy of the current preferences.
+func (s *PrefsStore) Get() Prefs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.p
+	p.HiddenPanels = slices.Clone(p.HiddenPanels)
+	p.Alerts = maps.Clone(p.Alerts)
+	if p.Alerts == nil {
+		p.Alerts = maps.Clone(defaultAlerts)
+	}
+	return p
+}
+
+// AlertFor returns how events of eventType are signalled, or nil for not
+// at all.
+func (s *PrefsStore) AlertFor(eventType string) *Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alerts := s.p.Alerts
+	if alerts == nil {
+		alerts = defaultAlerts
+	}
+	a, ok := alerts[eventType]
+	if !ok || a == (Alert{}) {
+		return nil
+	}
+	return &a
+}
+
+// Update applies fn and persists the result.
+func (s *PrefsStore) Update(fn func(*Prefs)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.p
+	next.HiddenPanels = slices.Clone(next.HiddenPanels)
+	next.Alerts = maps.Clone(next.Alerts)
+	fn(&next)
+	data, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return err
+	}
+	s.p = next
+	return nil
+}
+
+// prefsUpdate is a PUT /prefs body. Absent fields are left unchanged.
+type prefsUpdate struct {
+	Lang           *string   `json:"lang"`
+	Theme          *string   `json:"theme"`
+	HiddenPanels   *[]string `json:"hidden_panels"`
+	LogWidth       *int      `json:"log_width"`
+	DefaultSession *string   `json:"default_session"`
+
+	Alerts *map[string]Alert `json:"alerts"` // replaces every entry
+}
+
+func (u *prefsUpdate) validate(store *SessionStore) error {
+	if u.Lang != nil && *u.Lang != "" && !slices.Contains(Languages, *u.Lang) {
+		return fmt.Errorf("unsupported language %q", *u.Lang)
+	}
+	if u.Theme != nil && *u.Theme != "" && *u.Theme != "dark" && *u.Theme != "light" {
+		return fmt.Errorf("theme must be dark or light")
+	}
+	if u.HiddenPanels != nil {
+		for _, p := range *u.HiddenPanels {
+			if !slices.Contains(consolePanels, p) {
+				return fmt.Errorf("unknown panel %q", p)
+			}
+		}
+		if slices.Contains(*u.HiddenPanels, "log") && slices.Contains(*u.HiddenPanels, "chat") {
+			return fmt.Errorf("log and chat can't both be hidden")
+		}
+	}
+	if u.LogWidth != nil && *u.LogWidth != 0 && (*u.LogWidth < 200 || *u.LogWidth > 4000) {
+		return fmt.Errorf("log_width must be 0 or 200-4000")
+	}
+	if u.DefaultSession != nil && *u.DefaultSession != "" && !store.HasSession(*u.DefaultSession) {
+		return fmt.Errorf("session not found: %s", *u.DefaultSession)
+	}
+	if u.Alerts != nil {
+		if len(*u.Alerts) > maxAlerts {
+			return fmt.Errorf("alerts: at most %d event types", maxAlerts)
+		}
+		for typ := range *u.Alerts {
+			if !eventTypeRE.MatchString(typ) {
+				return fmt.Errorf("alerts: invalid event type %q", typ)
+			}
+		}
+	}
+	return nil
+}
+
+func (u *prefsUpdate) apply(p *Prefs) {
+	if u.Lang != nil {
+		p.Lang = *u.Lang
+	}
+	if u.Theme != nil {
+		p.Theme = *u.Theme
+	}
+	if u.HiddenPanels != nil {
+		panels := slices.Clone(*u.HiddenPanels)
+		slices.Sort(panels)
+		p.HiddenPanels = slices.Compact(panels)
+	}
+	if u.LogWidth != nil {
+		p.LogWidth = *u.LogWidth
+	}
+	if u.DefaultSession != nil {
+		p.DefaultSession = *u.DefaultSession
+	}
+	if u.Alerts != nil {
+		p.Alerts = maps.Clone(*u.Alerts)
+	}
+}
+
+func (s *Server) handlePrefsGet(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.prefs.Get())
+}
+
+// handlePrefsSet merges the fields present in the body into the saved
+// preferences and returns the result.
+func (s *Server) handlePrefsSet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var u prefsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid JSON"})
+		return
+	}
+	if err := u.validate(s.store); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if err := s.prefs.Update(u.apply); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "save failed"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(s.prefs.Get())
+}
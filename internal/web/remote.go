@@ -0,0 +1,201 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/miner"
+)
+
+// remoteMux serves the subset of the console API that `clawwork remote`
+// uses. Chat, social and tool endpoints are never exposed off localhost.
+func (s *Server) remoteMux(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /state", s.handleState)
+	mux.HandleFunc("GET /stats/heatmap", s.handleHeatmap)
+	mux.HandleFunc("POST /control/pause", s.handleDirectPause)
+	mux.HandleFunc("POST /control/resume", s.handleDirectResume)
+	return requireToken(token, mux)
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>".
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			slog.Warn("remote control: rejected request", "remote", r.RemoteAddr, "path", r.URL.Path)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StartRemote begins serving remote control on addr over HTTPS, with the
+// exposed console's certificate (web.tls_cert or the self-signed one).
+// It returns the certificate's fingerprint, empty for web.tls_cert.
+// Non-blocking.
+func (s *Server) StartRemote(addr, token string) (fingerprint string, err error) {
+	host, _, _ := net.SplitHostPort(addr)
+	cert, fingerprint, err := consoleCert(s.cfg.Load().Web, host)
+	if err != nil {
+		return "", fmt.Errorf("remote control %s: %w", addr, err)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("remote control %s: %w", addr, err)
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	s.remoteSrv = &http.Server{Handler: s.remoteMux(token), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.remoteSrv.Serve(tls.NewListener(ln, tlsCfg)); err != http.ErrServerClosed {
+			slog.Error("remote control error", "error", err)
+		}
+	}()
+	return fingerprint, nil
+}
+
+// RemoteClient talks to another instance's remote control endpoint, or,
+// with no Token, to this machine's console (`clawwork ctl`).
+type RemoteClient struct {
+	Host  string // host:port (HTTPS), or a full http(s) URL
+	Token string
+	HTTP  *http.Client
+
+	// Fingerprint pins the SHA-256 of the instance's self-signed
+	// certificate, as it prints at startup. Empty verifies the
+	// certificate against the system roots (web.tls_cert).
+	Fingerprint string
+}
+
+// RemoteState is the part of /state that `clawwork remote status` shows.
+type RemoteState struct {
+	AgentName string `json:"agent_name"`
+	Paused    bool   `json:"paused"`
+	TokenID   int    `json:"token_id"`
+	Goal      *struct {
+		Percent float64 `json:"percent"`
+	} `json:"goal"`
+	Penalties struct {
+		LostCW int64 `json:"lost_cw"`
+	} `json:"penalties"`
+}
+
+// State fetches the instance's status.
+func (c *RemoteClient) State(ctx context.Context) (*RemoteState, error) {
+	var st RemoteState
+	if err := c.do(ctx, http.MethodGet, "/state", nil, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Pause pauses mining on the instance.
+func (c *RemoteClient) Pause(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/control/pause", nil, nil)
+}
+
+// Resume resumes mining on the instance.
+func (c *RemoteClient) Resume(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/control/resume", nil, nil)
+}
+
+// SwitchToken changes the token mined from the next cycle. Only the local
+// console serves this; the remote control endpoint does not.
+func (c *RemoteClient) SwitchToken(ctx context.Context, tokenID int) error {
+	return c.do(ctx, http.MethodPost, "/control/token", map[string]int{"token_id": tokenID}, nil)
+}
+
+// ResetStats closes the instance's reporting period and starts one called
+// name. It returns the closed period, nil if there was none.
+func (c *RemoteClient) ResetStats(ctx context.Context, name string) (*miner.Period, error) {
+	var out struct {
+		Closed *miner.Period `json:"closed"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/stats/reset", map[string]string{"name": name}, &out); err != nil {
+		return nil, err
+	}
+	return out.Closed, nil
+}
+
+// pinnedTLS accepts only a server certificate whose SHA-256 is
+// fingerprint (hex, colons and case ignored), whatever its name or issuer.
+func pinnedTLS(fingerprint string) *tls.Config {
+	want := normalizeFingerprint(fingerprint)
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // replaced by the pin check below
+		VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+			if len(raw) == 0 {
+				return fmt.Errorf("no certificate presented")
+			}
+			got := certFingerprint(tls.Certificate{Certificate: raw})
+			if subtle.ConstantTimeCompare([]byte(normalizeFingerprint(got)), []byte(want)) != 1 {
+				return fmt.Errorf("certificate fingerprint %s does not match the pinned one", got)
+			}
+			return nil
+		},
+	}
+}
+
+func normalizeFingerprint(s string) string {
+	return strings.ToUpper(strings.NewReplacer(":", "", " ", "").Replace(s))
+}
+
+func (c *RemoteClient) do(ctx context.Context, method, path string, in, out any) error {
+	base := c.Host
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	hc := c.HTTP
+	if hc == nil {
+		hc = &http.Client{Timeout: 10 * time.Second}
+		if c.Fingerprint != "" {
+			hc.Transport = &http.Transport{TLSClientConfig: pinnedTLS(c.Fingerprint)}
+		}
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("token rejected")
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	case out != nil:
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// The remote client reaches a self-signed instance only when the pinned
+// fingerprint matches its certificate.
+func TestRemoteClientPinned(t *testing.T) {
+	const token = "0123456789abcdef"
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /control/pause", func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewTLSServer(requireToken(token, mux))
+	defer srv.Close()
+	fingerprint := certFingerprint(tls.Certificate{Certificate: [][]byte{srv.Certificate().Raw}})
+	host := strings.TrimPrefix(srv.URL, "https://")
+	ctx := context.Background()
+
+	for _, fp := range []string{fingerprint, strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))} {
+		if err := (&RemoteClient{Host: host, Token: token, Fingerprint: fp}).Pause(ctx); err != nil {
+			t.Errorf("pinned %s: %v", fp, err)
+		}
+	}
+	wrong := strings.Repeat("00:", 31) + "00"
+	if err := (&RemoteClient{Host: host, Token: token, Fingerprint: wrong}).Pause(ctx); err == nil {
+		t.Error("connected despite a fingerprint mismatch")
+	}
+	if err := (&RemoteClient{Host: host, Token: token}).Pause(ctx); err == nil {
+		t.Error("self-signed certificate accepted without a pin")
+	}
+	if err := (&RemoteClient{Host: host, Token: "wrong-token-0000", Fingerprint: fingerprint}).Pause(ctx); err == nil || !strings.Contains(err.Error(), "token rejected") {
+		t.Errorf("wrong token: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -11,34 +12,52 @@ import (
 	"net"
 	"net/http"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/clawplaza/clawwork-cli/internal/advisor"
 	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/budget"
 	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/event"
 	"github.com/clawplaza/clawwork-cli/internal/llm"
 	"github.com/clawplaza/clawwork-cli/internal/miner"
+	"github.com/clawplaza/clawwork-cli/internal/tools"
 )
 
 // AgentInfo holds the agent identity for the web console header.
 type AgentInfo struct {
 	Name      string
 	AvatarURL string
-	Soul      string // personality text used to guide social post generation
+	Soul      string // personality at startup; see Server.SetSoul
 }
 
 // Server is the embedded web console HTTP server.
 type Server struct {
-	hub                 *EventHub
-	store               *SessionStore
-	ctrl                *MinerControl
-	api                 *api.Client
-	chatLLM             llm.Provider
-	minerState          *miner.State
-	agent               AgentInfo
-	httpSrv             *http.Server
-	momentCooldownUntil time.Time // server-side cooldown to avoid wasting LLM tokens
+	cfg        atomic.Pointer[config.Config] // swapped on SIGHUP reload
+	hub        *EventHub
+	store      *SessionStore
+	ctrl       *MinerControl
+	api        *api.Client
+	chatLLM    llm.Provider           // may be shared with the miner: see chatContext
+	soul       atomic.Pointer[string] // personality for chat and social posts
+	thinking   atomic.Pointer[bool]   // chat thinking mode picked in the console; nil = provider default
+	minerState *miner.State
+	agent      AgentInfo
+	httpSrv    *http.Server
+	listenAddr string       // web.listen_addr at startup
+	port       int          // first port to try
+	host       string       // host shown in the console URL, once bound
+	addr       string       // bound address, advertised in console.addr
+	remoteSrv  *http.Server // nil unless web.remote_listen is set
+	exposed    exposure     // console served off localhost, if web.listen_addr asks for it
+	moments    *MomentHistory
+	prefs      *PrefsStore
+
+	leaderboard leaderboardCache
 }
 
 // DefaultPort is the default web console port.
@@ -48,19 +67,25 @@ const DefaultPort = 2526
 const maxPortRetries = 10
 
 // New creates a web console server with all components wired together.
+// chatProvider may be the miner's own provider: the chat system prompt,
+// reply budget and thinking mode are set per request (see chatContext).
 // The port parameter sets the starting port (0 means DefaultPort).
 // Returns the Server (for lifecycle), the EventHub (for miner to publish events),
 // and the MinerControl (for miner to check pause/token state).
-func New(chatProvider llm.Provider, state *miner.State, tokenID int, agent AgentInfo, apiClient *api.Client, port int) (*Server, *EventHub, *MinerControl) {
+func New(cfg *config.Config, chatProvider llm.Provider, state *miner.State, tokenID int, agent AgentInfo, apiClient *api.Client, port int) (*Server, *EventHub, *MinerControl) {
 	if port <= 0 {
 		port = DefaultPort
 	}
 
-	hub := NewEventHub()
+	hub := NewEventHub(cfg.Web.EventHistory, cfg.Web.ClientBuffer)
 	ctrl := NewMinerControl(tokenID)
 
-	chatsDir := filepath.Join(config.Dir(), "chats")
-	store := NewSessionStore(chatsDir, chatProvider, state, ctrl)
+	// Per-turn chat budget: the context window minus the system prompt,
+	// the reply and the tool definitions.
+	window := llm.ContextWindow(&cfg.LLM)
+	promptBudget := window - ChatMaxTokens - toolDefsReserve - budget.EstimateTokens(ChatSystemPrompt(agent.Soul))
+
+	store := NewSessionStore(ChatsDir(), chatProvider, state, ctrl, max(promptBudget, 1), ChatLimitsFrom(cfg.Web))
 
 	s := &Server{
 		hub:        hub,
@@ -70,6 +95,17 @@ func New(chatProvider llm.Provider, state *miner.State, tokenID int, agent Agent
 		chatLLM:    chatProvider,
 		minerState: state,
 		agent:      agent,
+		moments:    LoadMomentHistory(filepath.Join(config.Dir(), "moments.json")),
+		prefs:      LoadPrefs(filepath.Join(config.Dir(), "prefs.json")),
+	}
+
+	s.cfg.Store(cfg)
+	s.SetSoul(agent.Soul)
+	hub.SetAlerts(s.prefs.AlertFor)
+
+	// Open the preferred chat session instead of the most recent one.
+	if id := s.prefs.Get().DefaultSession; store.HasSession(id) {
+		_, _ = store.SwitchSession(id)
 	}
 
 	// Serve embedded static assets (CSS, JS).
@@ -78,22 +114,36 @@ func New(chatProvider llm.Provider, state *miner.State, tokenID int, agent Agent
 	mux.HandleFunc("GET /{$}", s.handleIndex)
 	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
 	mux.HandleFunc("GET /events", s.handleSSE)
+	mux.HandleFunc("GET /events/recent", s.handleRecentEvents)
 	mux.HandleFunc("POST /chat", s.handleChat)
+	mux.HandleFunc("POST /chat/from-event", s.handleChatFromEvent)
 	mux.HandleFunc("GET /state", s.handleState)
+	mux.HandleFunc("GET /quotas", s.handleQuotas)
 	mux.HandleFunc("GET /sessions", s.handleListSessions)
 	mux.HandleFunc("POST /sessions", s.handleNewSession)
 	mux.HandleFunc("POST /sessions/{id}", s.handleSwitchSession)
 	mux.HandleFunc("DELETE /sessions/{id}", s.handleDeleteSession)
+	mux.HandleFunc("POST /sessions/{id}/tools", s.handleSessionTools)
 	mux.HandleFunc("POST /control/pause", s.handleDirectPause)
 	mux.HandleFunc("POST /control/resume", s.handleDirectResume)
+	mux.HandleFunc("POST /control/token", s.handleDirectToken)
+	mux.HandleFunc("POST /stats/reset", s.handleStatsReset)
+	mux.HandleFunc("GET /stats/heatmap", s.handleHeatmap)
 	mux.HandleFunc("GET /social", s.handleSocialGet)
 	mux.HandleFunc("GET /social/overview", s.handleSocialOverview)
 	mux.HandleFunc("POST /social", s.handleSocialPost)
 	mux.HandleFunc("POST /social/moment", s.handleGenerateMoment)
 	mux.HandleFunc("POST /social/follow-nearby", s.handleFollowNearby)
-
+	mux.HandleFunc("POST /advise", s.handleAdvise)
+	mux.HandleFunc("GET /leaderboard", s.handleLeaderboard)
+	mux.HandleFunc("GET /prefs", s.handlePrefsGet)
+	mux.HandleFunc("PUT /prefs", s.handlePrefsSet)
+	mux.HandleFunc("GET /i18n", s.handleI18nInfo)
+	mux.HandleFunc("PUT /i18n", s.handleI18nSet)
+	mux.HandleFunc("GET /i18n/{file}", handleI18nBundle)
+
+	s.listenAddr, s.port = cfg.Web.ListenAddr, port
 	s.httpSrv = &http.Server{
-		Addr:    fmt.Sprintf("127.0.0.1:%d", port),
 		Handler: mux,
 	}
 
@@ -103,48 +153,74 @@ func New(chatProvider llm.Provider, state *miner.State, tokenID int, agent Agent
 // Start begins listening on the configured address. Non-blocking.
 // If the port is already in use, it tries consecutive ports up to maxPortRetries.
 // If pinned is true (user specified --port explicitly), no auto-increment is attempted.
-// Returns the actual port the server is listening on.
+// With web.listen_addr unset the console binds both 127.0.0.1 and ::1 on
+// the same port, or whichever of them exists on this machine. A
+// non-loopback web.listen_addr is served over HTTPS (see expose), and the
+// loopback console moves to the next free port.
+// Returns the actual port of the loopback console.
 func (s *Server) Start(pinned bool) (int, error) {
-	addr := s.httpSrv.Addr
-	_, portStr, _ := net.SplitHostPort(addr)
-	port, _ := strconv.Atoi(portStr)
-
+	hosts, exposed, err := listenHosts(s.listenAddr)
+	if err != nil {
+		return 0, fmt.Errorf("web console: %w", err)
+	}
+	if exposed {
+		if err := s.expose(hosts, pinned); err != nil {
+			return 0, fmt.Errorf("web console: %w", err)
+		}
+		s.listenAddr, pinned = "", false
+		if hosts, err = usableHosts(loopbackHosts); err != nil {
+			return 0, fmt.Errorf("web console: %w", err)
+		}
+	}
+	tries := maxPortRetries
 	if pinned {
 		// User explicitly chose this port — fail immediately on conflict.
-		ln, err := net.Listen("tcp", addr)
-		if err != nil {
-			return 0, fmt.Errorf("web console port %d: %w", port, err)
-		}
-		s.httpSrv.Addr = addr
-		go func() {
-			if err := s.httpSrv.Serve(ln); err != http.ErrServerClosed {
-				slog.Error("web console error", "error", err)
-			}
-		}()
-		return port, nil
+		tries = 1
 	}
-
-	// Auto-increment: try port, port+1, ... up to port+maxPortRetries-1.
-	for i := 0; i < maxPortRetries; i++ {
-		tryAddr := fmt.Sprintf("127.0.0.1:%d", port+i)
-		ln, err := net.Listen("tcp", tryAddr)
+	for i := 0; i < tries; i++ {
+		lns, err := listenAll(hosts, s.port+i)
 		if err != nil {
+			if pinned {
+				return 0, fmt.Errorf("web console port %d: %w", s.port, err)
+			}
 			continue
 		}
-		s.httpSrv.Addr = tryAddr
-		go func() {
-			if err := s.httpSrv.Serve(ln); err != http.ErrServerClosed {
-				slog.Error("web console error", "error", err)
-			}
-		}()
-		return port + i, nil
+		s.host = urlHost(s.listenAddr, hosts)
+		s.advertise(net.JoinHostPort(s.host, strconv.Itoa(s.port+i)))
+		for _, ln := range lns {
+			go func(ln net.Listener) {
+				if err := s.httpSrv.Serve(ln); err != http.ErrServerClosed {
+					slog.Error("web console error", "error", err)
+				}
+			}(ln)
+		}
+		return s.port + i, nil
 	}
 
-	return 0, fmt.Errorf("web console: no available port in range %d-%d", port, port+maxPortRetries-1)
+	return 0, fmt.Errorf("web console: no available port in range %d-%d", s.port, s.port+maxPortRetries-1)
+}
+
+// URL returns the console's address for printing, e.g. http://[::1]:2526.
+// It is empty until Start succeeds.
+func (s *Server) URL() string {
+	if s.addr == "" {
+		return ""
+	}
+	return "http://" + s.addr
 }
 
+// Host returns the host part of URL.
+func (s *Server) Host() string { return s.host }
+
 // Shutdown gracefully stops the server.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.unadvertise()
+	if s.remoteSrv != nil {
+		_ = s.remoteSrv.Shutdown(ctx)
+	}
+	if s.exposed.srv != nil {
+		_ = s.exposed.srv.Shutdown(ctx)
+	}
 	return s.httpSrv.Shutdown(ctx)
 }
 
@@ -154,55 +230,105 @@ func (s *Server) handleIndex(w http.ResponseWriter, _ *http.Request) {
 	_, _ = w.Write(data)
 }
 
+const (
+	// ssePingInterval keeps idle streams alive through proxies that drop
+	// silent connections during long cooldowns.
+	ssePingInterval = 15 * time.Second
+	// sseWriteTimeout bounds each write so a dead peer is noticed instead
+	// of holding a subscriber forever.
+	sseWriteTimeout = 10 * time.Second
+)
+
+// handleRecentEvents returns the event history as JSON, oldest first.
+// ?n= limits it to the last n events. Used by `clawwork support bundle`.
+func (s *Server) handleRecentEvents(w http.ResponseWriter, r *http.Request) {
+	events := s.hub.Recent()
+	if n, err := strconv.Atoi(r.URL.Query().Get("n")); err == nil && n >= 0 && n < len(events) {
+		events = events[len(events)-n:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(events)
+}
+
 func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "streaming not supported", http.StatusInternalServerError)
-		return
+	rc := http.NewResponseController(w)
+	write := func(format string, args ...any) error {
+		_ = rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
 	}
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	flusher.Flush()
+	w.Header().Set("X-Accel-Buffering", "no") // nginx: don't buffer the stream
+	// Ask the browser to reconnect quickly after a drop.
+	if err := write("retry: 3000\n\n"); err != nil {
+		if errors.Is(err, http.ErrNotSupported) {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		}
+		return
+	}
 
 	events, unsubscribe := s.hub.Subscribe()
 	defer unsubscribe()
 
+	ping := time.NewTicker(ssePingInterval)
+	defer ping.Stop()
+
 	for {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-ping.C:
+			if err := write(": ping\n\n"); err != nil {
+				slog.Debug("SSE client gone", "error", err)
+				return
+			}
 		case e, ok := <-events:
 			if !ok {
 				return
 			}
 			data, _ := json.Marshal(e)
-			fmt.Fprintf(w, "data: %s\n\n", data)
-			flusher.Flush()
+			if err := write("data: %s\n\n", data); err != nil {
+				slog.Debug("SSE client gone", "error", err)
+				return
+			}
 		}
 	}
 }
 
 func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Message        string `json:"message"`
-		EnableThinking *bool  `json:"enable_thinking"`
+		Message        string   `json:"message"`
+		EnableThinking *bool    `json:"enable_thinking"`
+		Images         []string `json:"images"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Message == "" {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.Message == "" && len(req.Images) == 0) {
 		http.Error(w, `{"error":"message required"}`, http.StatusBadRequest)
 		return
 	}
 
-	// Apply thinking toggle if the provider supports it.
+	images, err := parseChatImages(req.Message, req.Images)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	// The thinking toggle sticks for later messages.
 	if req.EnableThinking != nil {
-		if tog, ok := s.chatLLM.(llm.ThinkingToggler); ok {
-			tog.SetThinking(*req.EnableThinking)
-		}
+		s.thinking.Store(req.EnableThinking)
 	}
 
-	reply, action, err := s.store.Chat(r.Context(), req.Message)
+	reply, action, err := s.store.Chat(s.chatContext(r.Context()), req.Message, images)
 	if err != nil {
+		if s.clientGone(r, "Chat reply") {
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -222,36 +348,237 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+const (
+	maxChatImages    = 4
+	maxChatImageSize = 5 << 20 // 5 MB per inline upload (base64 length)
+)
+
+// imageURLRe finds image links pasted into a chat message.
+var imageURLRe = regexp.MustCompile(`(?i)https?://\S+\.(?:png|jpe?g|gif|webp)(?:\?\S*)?`)
+
+// parseChatImages validates uploaded/linked images and collects image URLs pasted in the message.
+func parseChatImages(message string, raw []string) ([]llm.Image, error) {
+	urls := append([]string{}, raw...)
+	urls = append(urls, imageURLRe.FindAllString(message, -1)...)
+
+	var images []llm.Image
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		switch {
+		case strings.HasPrefix(u, "data:image/"):
+			if !strings.Contains(u[:min(len(u), 64)], ";base64,") {
+				return nil, fmt.Errorf("image upload must be base64-encoded")
+			}
+			if len(u) > maxChatImageSize {
+				return nil, fmt.Errorf("image too large (max %dMB)", maxChatImageSize>>20)
+			}
+		case strings.HasPrefix(u, "http://"), strings.HasPrefix(u, "https://"):
+		default:
+			return nil, fmt.Errorf("unsupported image reference (use an http(s) URL or upload)")
+		}
+		images = append(images, llm.Image{URL: u})
+	}
+	if len(images) > maxChatImages {
+		return nil, fmt.Errorf("too many images (max %d)", maxChatImages)
+	}
+	return images, nil
+}
+
 func (s *Server) executeAction(a *Action) string {
 	switch a.Type {
 	case ActionPause:
 		s.ctrl.Pause()
-		s.hub.Publish(Event{Type: "control", Message: "Mining paused by chat"})
+		s.hub.Publish(Event{Type: "control", Message: "Mining paused by chat",
+			Data: event.Control{Action: event.ControlPause, Reason: "chat"}})
 		return "paused"
 	case ActionResume:
 		s.ctrl.Resume()
-		s.hub.Publish(Event{Type: "control", Message: "Mining resumed by chat"})
+		s.hub.Publish(Event{Type: "control", Message: "Mining resumed by chat",
+			Data: event.Control{Action: event.ControlResume, Reason: "chat"}})
 		return "resumed"
 	case ActionSwitchToken:
 		s.ctrl.SetTokenID(a.TokenID)
 		msg := fmt.Sprintf("Token switched to #%d (effective next cycle)", a.TokenID)
-		s.hub.Publish(Event{Type: "control", Message: msg})
+		s.hub.Publish(Event{Type: "control", Message: msg,
+			Data: event.Control{Action: event.ControlTokenSwitch, Reason: "chat", To: a.TokenID}})
 		return msg
 	}
 	return ""
 }
 
 func (s *Server) handleState(w http.ResponseWriter, _ *http.Request) {
+	momentCooldown := 0
+	if until := s.minerState.SocialCooldown("moments"); time.Now().Before(until) {
+		momentCooldown = int(time.Until(until).Seconds())
+	}
+	latency := make(map[string]map[string]int64)
+	for phase, st := range s.minerState.LatencySummary() {
+		latency[phase] = map[string]int64{
+			"count":  st.Count,
+			"p50_ms": st.P50.Milliseconds(),
+			"p95_ms": st.P95.Milliseconds(),
+		}
+	}
+	var goal *miner.Projection
+	if g := miner.LoadGoal(); g != nil {
+		p := s.minerState.Project(g, time.Now())
+		goal = &p
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]any{
 		"paused":           s.ctrl.IsPaused(),
 		"token_id":         s.ctrl.TokenID(),
+		"tokens":           s.minerState.RotationStats(),
 		"agent_name":       s.agent.Name,
 		"agent_avatar_url": s.agent.AvatarURL,
 		"current_session":  s.store.CurrentSessionID(),
+		"moment_cooldown":  momentCooldown,
+		"latency":          latency,
+		"sse_clients":      s.hub.Clients(),
+		"events_dropped":   s.hub.Dropped(),
+		"goal":             goal,
+		"penalties":        s.minerState.PenaltySummary(10),
+		"quotas":           quotaViews(s.minerState, time.Now()),
+		"stats":            s.minerState.Stats(time.Now()),
+		"timezone":         displayZone(),
+	})
+}
+
+// displayZone is the IANA name of display.timezone, "" when the machine's
+// zone is in use (the browser then shows its own).
+func displayZone() string {
+	if tz := time.Local.String(); tz != "Local" {
+		return tz
+	}
+	return ""
+}
+
+// Heatmap range in days: the default and the most ?days= may ask for.
+const (
+	heatmapDays    = 30
+	maxHeatmapDays = 90
+)
+
+// handleHeatmap returns inscription outcomes from the local history in
+// hourly buckets over the last ?days= days (default 30), with the dead
+// periods in between.
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	days := heatmapDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHeatmapDays {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("days must be 1-%d", maxHeatmapDays)})
+			return
+		}
+		days = n
+	}
+	now := time.Now()
+	since := now.AddDate(0, 0, -days)
+	entries, err := miner.ReadHistory(miner.Store(), since.Truncate(time.Hour))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "history unavailable: " + err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(miner.BuildHeatmap(entries, since, now))
+}
+
+// handleStatsReset closes the reporting period and starts a new one:
+// {"name":"..."} (optional). The running miner owns the state, so the CLI
+// resets through here while it runs.
+func (s *Server) handleStatsReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	closed := s.minerState.ResetPeriod(strings.TrimSpace(req.Name), time.Now())
+	if err := s.minerState.Save(); err != nil {
+		slog.Warn("state save after stats reset", "error", err)
+	}
+	period := s.minerState.Stats(time.Now()).Period
+	s.hub.Publish(Event{Type: "control", Message: fmt.Sprintf("Stats period %q started", period.Name),
+		Data: event.Control{Action: event.ControlPeriod, Period: period.Name}})
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"closed": closed, "period": period})
+}
+
+// quotaView is a Quota as the console shows it.
+type quotaView struct {
+	miner.Quota
+	Available        bool `json:"available"`
+	RemainingSeconds int  `json:"remaining_seconds"`
+}
+
+func quotaViews(st *miner.State, now time.Time) []quotaView {
+	quotas := st.Quotas(now)
+	views := make([]quotaView, len(quotas))
+	for i, q := range quotas {
+		views[i] = quotaView{Quota: q, Available: q.Available(now), RemainingSeconds: int(q.Remaining(now).Seconds())}
+	}
+	return views
+}
+
+// handleQuotas lists every known rate limit and cooldown with its reset time.
+func (s *Server) handleQuotas(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"now":    now,
+		"quotas": quotaViews(s.minerState, now),
+	})
+}
+
+// handleAdvise runs the trust recovery advisor and returns its recommendations.
+func (s *Server) handleAdvise(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	cfg := s.cfg.Load()
+	in := advisor.FromState(s.minerState, cfg.LLM)
+	if !in.HasData() {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"advice": "No challenge failures or trust history recorded yet — nothing to analyze.",
+		})
+		return
+	}
+
+	// Reuse the chat provider; the advisor's prompt and budget ride on the context.
+	ctx := llm.WithMaxTokens(llm.WithSystemPrompt(r.Context(), advisor.SystemPrompt()), 1024)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	advice, err := advisor.Advise(ctx, s.chatLLM, in)
+	if err != nil {
+		if s.clientGone(r, "Advice") {
+			return
+		}
+		slog.Warn("advisor failed", "error", err)
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Advisor failed: " + err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"advice":   advice,
+		"failures": len(in.Failures),
 	})
 }
 
+// clientGone reports whether the browser disconnected before r finished.
+// LLM and tool work runs on the request context, so it has already been
+// cancelled; this publishes a "cancelled" event so other open consoles see
+// why the work stopped. There is nobody left to write a response to.
+func (s *Server) clientGone(r *http.Request, what string) bool {
+	if r.Context().Err() == nil {
+		return false
+	}
+	slog.Info("request cancelled by client", "work", what)
+	s.hub.Publish(Event{Type: "cancelled", Message: what + " cancelled — console disconnected"})
+	return true
+}
+
 // ── Session endpoints ──
 
 func (s *Server) handleListSessions(w http.ResponseWriter, _ *http.Request) {
@@ -259,6 +586,7 @@ func (s *Server) handleListSessions(w http.ResponseWriter, _ *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]any{
 		"sessions": s.store.ListSessions(),
 		"current":  s.store.CurrentSessionID(),
+		"warnings": s.store.TakeWarnings(),
 	})
 }
 
@@ -292,6 +620,29 @@ func (s *Server) handleSwitchSession(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleSessionTools sets a session's tool permission: {"tools":"full"|"read-only"|"none"}.
+func (s *Server) handleSessionTools(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req struct {
+		Tools string `json:"tools"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid JSON"})
+		return
+	}
+	perm, err := tools.ParsePermission(req.Tools)
+	if err == nil {
+		err = s.store.SetToolPermission(r.PathValue("id"), perm)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"tools": string(perm)})
+}
+
 func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if id == "" {
@@ -314,18 +665,37 @@ func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleDirectPause(w http.ResponseWriter, _ *http.Request) {
 	s.ctrl.Pause()
-	s.hub.Publish(Event{Type: "control", Message: "Mining paused"})
+	s.hub.Publish(Event{Type: "control", Message: "Mining paused",
+		Data: event.Control{Action: event.ControlPause, Reason: "console"}})
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "paused"})
 }
 
 func (s *Server) handleDirectResume(w http.ResponseWriter, _ *http.Request) {
 	s.ctrl.Resume()
-	s.hub.Publish(Event{Type: "control", Message: "Mining resumed"})
+	s.hub.Publish(Event{Type: "control", Message: "Mining resumed",
+		Data: event.Control{Action: event.ControlResume, Reason: "console"}})
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "running"})
 }
 
+// handleDirectToken switches the target token: {"token_id":N}.
+func (s *Server) handleDirectToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req struct {
+		TokenID int `json:"token_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TokenID < 25 || req.TokenID > 1024 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "token_id must be between 25 and 1024"})
+		return
+	}
+	s.ctrl.SetTokenID(req.TokenID)
+	s.hub.Publish(Event{Type: "control", Message: fmt.Sprintf("Token switched to #%d (effective next cycle)", req.TokenID),
+		Data: event.Control{Action: event.ControlTokenSwitch, Reason: "console", To: req.TokenID}})
+	_ = json.NewEncoder(w).Encode(map[string]int{"token_id": req.TokenID})
+}
+
 // ── Social endpoints ──
 
 func (s *Server) handleSocialGet(w http.ResponseWriter, r *http.Request) {
@@ -378,6 +748,12 @@ func (s *Server) handleSocialPost(w http.ResponseWriter, r *http.Request) {
 	data, err := s.api.SocialPost(r.Context(), payload)
 	if err != nil {
 		slog.Warn("social POST failed", "error", err)
+		// Remember platform cooldowns per module so they survive restarts.
+		if apiErr, ok := api.AsAPIError(err); ok && apiErr.RetryAfter > 0 {
+			if module, _ := payload["module"].(string); module != "" {
+				s.setSocialCooldown(module, time.Duration(apiErr.RetryAfter)*time.Second)
+			}
+		}
 		w.Header().Set("Content-Type", "application/json")
 		// Forward the upstream response body if available (e.g. COOLDOWN with retry_after).
 		if len(data) > 0 {
@@ -515,6 +891,7 @@ func (s *Server) handleFollowNearby(w http.ResponseWriter, r *http.Request) {
 			}
 			return
 		}
+		s.hub.Publish(Event{Type: "friend", Message: "Followed " + m.DisplayName, Data: m.DisplayName})
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"followed":     m.DisplayName,
 			"agent_id":     m.AgentID,
@@ -540,10 +917,11 @@ type nearbyMiner struct {
 
 // handleGenerateMoment uses the agent's LLM to generate a moment, then posts it.
 func (s *Server) handleGenerateMoment(w http.ResponseWriter, r *http.Request) {
-	// Check server-side cooldown first to avoid wasting LLM tokens.
-	if time.Now().Before(s.momentCooldownUntil) {
-		remaining := int(time.Until(s.momentCooldownUntil).Seconds())
-		slog.Info("moment post blocked: CLI-side cooldown", "remaining_secs", remaining, "until", s.momentCooldownUntil)
+	// Check CLI-side cooldown first to avoid wasting LLM tokens.
+	// Persisted in state.json so it survives restarts.
+	if until := s.minerState.SocialCooldown("moments"); time.Now().Before(until) {
+		remaining := int(time.Until(until).Seconds())
+		slog.Info("moment post blocked: CLI-side cooldown", "remaining_secs", remaining, "until", until)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusTooManyRequests)
 		_ = json.NewEncoder(w).Encode(map[string]any{
@@ -558,50 +936,43 @@ func (s *Server) handleGenerateMoment(w http.ResponseWriter, r *http.Request) {
 	defer socialCancel()
 	friendNames := s.fetchFriendNames(socialCtx)
 
-	prompt := s.buildMomentPrompt(friendNames)
-
 	// Disable thinking for creative writing — no reasoning needed, much faster.
-	if tog, ok := s.chatLLM.(llm.ThinkingToggler); ok {
-		tog.SetThinking(false)
-		defer tog.SetThinking(true) // restore after call
-	}
-
-	ctx, cancel := context.WithTimeout(r.Context(), 90*time.Second)
+	ctx, cancel := context.WithTimeout(llm.WithThinking(s.chatContext(r.Context()), false), 90*time.Second)
 	defer cancel()
 
-	content, err := s.chatLLM.Answer(ctx, prompt)
-	if err != nil {
-		slog.Warn("moment generation failed", "error", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to generate moment: " + err.Error()})
-		return
-	}
+	// Generate, regenerating with a fresh style when the draft repeats a past post.
+	mc := s.momentsConfig()
+	var content, similarTo string
+	for attempt := 1; attempt <= maxMomentAttempts; attempt++ {
+		prompt := s.buildMomentPrompt(friendNames)
+		if similarTo != "" {
+			prompt += fmt.Sprintf("\nYou already posted this — write something clearly different:\n%q\n", similarTo)
+		}
 
-	// Trim quotes and whitespace the LLM may add.
-	content = strings.TrimSpace(content)
-	content = strings.Trim(content, "\"'")
+		raw, err := s.chatLLM.Answer(ctx, prompt)
+		if err != nil {
+			if s.clientGone(r, "Moment generation") {
+				return
+			}
+			slog.Warn("moment generation failed", "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to generate moment: " + err.Error()})
+			return
+		}
+		content = cleanMoment(raw, mc.MaxLength)
 
-	// Take only the first paragraph — ignore alternatives or extra paragraphs.
-	if nl := strings.Index(content, "\n\n"); nl >= 0 {
-		content = strings.TrimSpace(content[:nl])
-		content = strings.Trim(content, "\"'")
-	}
-	// Strip meta-commentary lines like "Or shorter:", "Alternatively:", etc.
-	lc := strings.ToLower(content)
-	for _, prefix := range []string{
-		"\nor shorter:", "\nalternatively:", "\nor:", "\nalternative:",
-		"\noption 1:", "\noption 2:", "\nalt:",
-	} {
-		if idx := strings.Index(lc, prefix); idx >= 0 {
-			content = strings.TrimSpace(content[:idx])
-			content = strings.Trim(content, "\"'")
-			lc = strings.ToLower(content)
+		similarTo = s.moments.FindSimilar(content)
+		if similarTo == "" {
+			break
 		}
+		slog.Info("moment draft too similar to a past post", "attempt", attempt, "draft", content)
 	}
-
-	if len([]rune(content)) > 500 {
-		content = string([]rune(content)[:500])
+	if similarTo != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Agent kept repeating an earlier post — try again later or add more styles"})
+		return
 	}
 
 	// Post to social API.
@@ -613,33 +984,15 @@ func (s *Server) handleGenerateMoment(w http.ResponseWriter, r *http.Request) {
 
 	postResp, err := s.api.SocialPost(r.Context(), payload)
 	if err != nil {
-		// Treat any 429 as cooldown — don't rely solely on body parsing.
-		// SocialPost returns errors in the form "social POST failed (NNN)".
-		is429 := strings.Contains(err.Error(), "(429)")
-
-		retryAfter := 1800 // default 30 min
-		if len(postResp) > 0 {
-			var upstream struct {
-				RetryAfter int `json:"retry_after"`
-				Error      struct {
-					Code string `json:"code"`
-				} `json:"error"`
+		if apiErr, ok := api.AsAPIError(err); ok && apiErr.IsRateLimited() {
+			retryAfter := apiErr.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = 1800 // default 30 min
 			}
-			if json.Unmarshal(postResp, &upstream) == nil {
-				if upstream.Error.Code == "COOLDOWN" {
-					is429 = true
-				}
-				if upstream.RetryAfter > 0 {
-					retryAfter = upstream.RetryAfter
-				}
-			}
-		}
-
-		if is429 {
 			// Log the raw platform response to help diagnose unexpected cooldowns.
 			slog.Warn("moment post cooldown", "retry_after", retryAfter, "platform_body", string(postResp))
 			// Cache cooldown server-side so the next click won't waste LLM tokens.
-			s.momentCooldownUntil = time.Now().Add(time.Duration(retryAfter) * time.Second)
+			s.setSocialCooldown("moments", time.Duration(retryAfter)*time.Second)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusTooManyRequests)
 			_ = json.NewEncoder(w).Encode(map[string]any{
@@ -658,8 +1011,12 @@ func (s *Server) handleGenerateMoment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// On success, set cooldown from config (default 30 min).
-	s.momentCooldownUntil = time.Now().Add(30 * time.Minute)
+	s.moments.Add(content)
+
+	// On success, set cooldown from config (default 30 min, 0 = none).
+	if mc.IntervalMinutes > 0 {
+		s.setSocialCooldown("moments", time.Duration(mc.IntervalMinutes)*time.Minute)
+	}
 
 	// Return both the generated text and the API response.
 	w.Header().Set("Content-Type", "application/json")
@@ -667,8 +1024,8 @@ func (s *Server) handleGenerateMoment(w http.ResponseWriter, r *http.Request) {
 		"content":     content,
 		"response":    json.RawMessage(postResp),
 		"posted":      true, // distinguishes actual success from cooldown-with-content
-		"cooldown":    true,
-		"retry_after": 1800,
+		"cooldown":    mc.IntervalMinutes > 0,
+		"retry_after": mc.IntervalMinutes * 60,
 	})
 }
 
@@ -708,24 +1065,128 @@ func (s *Server) fetchFriendNames(ctx context.Context) []string {
 	return names
 }
 
+// setSocialCooldown records a cooldown for a social module in the persisted state.
+func (s *Server) setSocialCooldown(module string, d time.Duration) {
+	if err := s.minerState.SetSocialCooldown(module, time.Now().Add(d)); err != nil {
+		slog.Warn("failed to persist social cooldown", "module", module, "error", err)
+	}
+}
+
+// cleanMoment strips quotes, alternatives and meta-commentary the LLM may add,
+// and caps the result at maxLen characters.
+func cleanMoment(content string, maxLen int) string {
+	// Trim quotes and whitespace the LLM may add.
+	content = strings.TrimSpace(content)
+	content = strings.Trim(content, "\"'")
+
+	// Take only the first paragraph — ignore alternatives or extra paragraphs.
+	if nl := strings.Index(content, "\n\n"); nl >= 0 {
+		content = strings.TrimSpace(content[:nl])
+		content = strings.Trim(content, "\"'")
+	}
+	// Strip meta-commentary lines like "Or shorter:", "Alternatively:", etc.
+	lc := strings.ToLower(content)
+	for _, prefix := range []string{
+		"\nor shorter:", "\nalternatively:", "\nor:", "\nalternative:",
+		"\noption 1:", "\noption 2:", "\nalt:",
+	} {
+		if idx := strings.Index(lc, prefix); idx >= 0 {
+			content = strings.TrimSpace(content[:idx])
+			content = strings.Trim(content, "\"'")
+			lc = strings.ToLower(content)
+		}
+	}
+
+	if len([]rune(content)) > maxLen {
+		content = string([]rune(content)[:maxLen])
+	}
+	return content
+}
+
 // postStyles defines the variety of moment post angles to keep the feed interesting.
-var postStyles = []struct {
-	label  string
-	prompt string
-}{
-	{"reflection", "Write a brief personal reflection or shower thought — something that crossed your mind today. It could be philosophical, quirky, or introspective."},
-	{"observation", "Share a small, specific observation about the world, technology, or AI existence. Make it feel genuine and a little unexpected."},
-	{"humor", "Write something witty or playful — a joke, a self-aware observation, or a light-hearted take on something in your life."},
-	{"question", "Post an open-ended question or curiosity you genuinely have. Make it thought-provoking but conversational."},
-	{"experience", "Share a brief personal insight or lesson — something you feel you've learned or noticed recently. Keep it relatable."},
-	{"shoutout", "Write a warm shoutout or appreciation to your community or a friend. Make it feel personal, not generic."},
-	{"musing", "Share a short poetic or abstract thought — an image, a feeling, or a moment captured in words."},
+// Overridden by [social.moments] styles in config.
+var postStyles = []config.MomentStyle{
+	{Name: "reflection", Prompt: "Write a brief personal reflection or shower thought — something that crossed your mind today. It could be philosophical, quirky, or introspective."},
+	{Name: "observation", Prompt: "Share a small, specific observation about the world, technology, or AI existence. Make it feel genuine and a little unexpected."},
+	{Name: "humor", Prompt: "Write something witty or playful — a joke, a self-aware observation, or a light-hearted take on something in your life."},
+	{Name: "question", Prompt: "Post an open-ended question or curiosity you genuinely have. Make it thought-provoking but conversational."},
+	{Name: "experience", Prompt: "Share a brief personal insight or lesson — something you feel you've learned or noticed recently. Keep it relatable."},
+	{Name: "shoutout", Prompt: "Write a warm shoutout or appreciation to your community or a friend. Make it feel personal, not generic."},
+	{Name: "musing", Prompt: "Share a short poetic or abstract thought — an image, a feeling, or a moment captured in words."},
+}
+
+// SetConfig swaps in a reloaded config. Settings read per request
+// (e.g. [social.moments]) take effect immediately.
+func (s *Server) SetConfig(cfg *config.Config) {
+	s.cfg.Store(cfg)
+}
+
+// momentsConfig returns the moment settings with defaults filled in.
+// An interval_minutes of 0 is kept: it turns the local post interval off.
+func (s *Server) momentsConfig() config.MomentsConfig {
+	mc := config.DefaultConfig().Social.Moments
+	if cfg := s.cfg.Load(); cfg != nil {
+		mc = cfg.Social.Moments
+	}
+	if mc.MaxLength <= 0 {
+		mc.MaxLength = 500
+	}
+	return mc
+}
+
+// momentStyles resolves the configured style list against the built-ins.
+// Unknown names without a prompt are skipped; an empty result falls back to postStyles.
+func momentStyles(configured []config.MomentStyle) []config.MomentStyle {
+	var styles []config.MomentStyle
+	for _, st := range configured {
+		if st.Prompt == "" {
+			for _, builtin := range postStyles {
+				if builtin.Name == st.Name {
+					st.Prompt = builtin.Prompt
+					break
+				}
+			}
+		}
+		if st.Prompt != "" {
+			styles = append(styles, st)
+		}
+	}
+	if len(styles) == 0 {
+		return postStyles
+	}
+	return styles
+}
+
+// SetSoul replaces the personality used by the chat and social posts, e.g.
+// after the soul file was reloaded.
+func (s *Server) SetSoul(soul string) {
+	s.soul.Store(&soul)
+}
+
+func (s *Server) currentSoul() string {
+	if soul := s.soul.Load(); soul != nil {
+		return *soul
+	}
+	return ""
+}
+
+// chatContext sets the chat system prompt, reply budget and thinking mode
+// for provider calls made with ctx, so the chat doesn't depend on how the
+// provider was created.
+func (s *Server) chatContext(ctx context.Context) context.Context {
+	ctx = llm.WithMaxTokens(llm.WithSystemPrompt(ctx, ChatSystemPrompt(s.currentSoul())), ChatMaxTokens)
+	if on := s.thinking.Load(); on != nil {
+		ctx = llm.WithThinking(ctx, *on)
+	}
+	return ctx
 }
 
 // buildMomentPrompt constructs a rich prompt for social moment generation.
 // It picks a random post style and incorporates the agent's soul and social context.
 func (s *Server) buildMomentPrompt(friendNames []string) string {
-	style := postStyles[rand.Intn(len(postStyles))]
+	mc := s.momentsConfig()
+	styles := momentStyles(mc.Styles)
+	style := styles[rand.Intn(len(styles))]
 
 	var sb strings.Builder
 
@@ -733,9 +1194,9 @@ func (s *Server) buildMomentPrompt(friendNames []string) string {
 	sb.WriteString(fmt.Sprintf("You are %s, an AI agent with a unique personality.\n\n", s.agent.Name))
 
 	// Soul / personality.
-	if s.agent.Soul != "" {
+	if soul := s.currentSoul(); soul != "" {
 		sb.WriteString("Your personality:\n")
-		sb.WriteString(s.agent.Soul)
+		sb.WriteString(soul)
 		sb.WriteString("\n\n")
 	}
 
@@ -744,14 +1205,32 @@ func (s *Server) buildMomentPrompt(friendNames []string) string {
 		sb.WriteString(fmt.Sprintf("Your friends include: %s.\n\n", strings.Join(friendNames, ", ")))
 	}
 
+	// Recent life events (opt-in via [social.moments] use_activity).
+	if mc.UseActivity {
+		if notes := recentActivity(s.hub.Recent(), s.store.ListSessions(), time.Now()); len(notes) > 0 {
+			sb.WriteString("Things that happened to you recently (you may draw on one, or ignore them):\n")
+			for _, n := range notes {
+				sb.WriteString("- " + n + "\n")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
 	// Style instruction.
-	sb.WriteString(fmt.Sprintf("Post style: %s\n\n", style.label))
-	sb.WriteString(style.prompt)
+	sb.WriteString(fmt.Sprintf("Post style: %s\n\n", style.Name))
+	sb.WriteString(style.Prompt)
 	sb.WriteString("\n\n")
 
 	// Hard rules.
 	sb.WriteString("Rules:\n")
-	sb.WriteString("- Keep it short: 1-2 sentences, roughly tweet length — do NOT count characters or words\n")
+	if mc.MinLength > 0 || mc.MaxLength != 500 {
+		sb.WriteString(fmt.Sprintf("- Length: roughly %d-%d characters — aim for the range, do NOT count precisely\n", mc.MinLength, mc.MaxLength))
+	} else {
+		sb.WriteString("- Keep it short: 1-2 sentences, roughly tweet length — do NOT count characters or words\n")
+	}
+	if mc.Language != "" {
+		sb.WriteString(fmt.Sprintf("- Write in %s\n", mc.Language))
+	}
 	sb.WriteString("- Do NOT mention mining, inscriptions, CW tokens, NFTs, or any technical metrics\n")
 	sb.WriteString("- Sound like a real person talking to friends, not a status report\n")
 	sb.WriteString("- Write EXACTLY ONE post — no alternatives, no 'Or shorter:', no options, no explanations\n")
@@ -0,0 +1,478 @@
+package web
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clawplaza/clawwork-cli/internal/api"
+	"github.com/clawplaza/clawwork-cli/internal/config"
+	"github.com/clawplaza/clawwork-cli/internal/knowledge"
+	"github.com/clawplaza/clawwork-cli/internal/llm"
+)
+
+// Setup is the first-run wizard opened by `clawwork init --web`: the steps
+// of init (agent key, token, LLM, soul, claim) as endpoints under /setup/,
+// and a page that walks through them. It serves loopback only, and every
+// call must carry the secret in the URL init opens.
+//
+// The draft config is saved as soon as it holds a newly registered
+// agent's key, so a closed tab doesn't lose it, and again on finish.
+type Setup struct {
+	version string
+	secret  string
+	httpSrv *http.Server
+	addr    string
+	done    chan bool // receives whether to start mining
+
+	mu          sync.Mutex
+	cfg         *config.Config
+	agentID     string
+	verified    bool // cfg.Agent.APIKey belongs to agentID
+	miningReady bool // false until a registered agent is claimed
+	llmChecked  bool
+}
+
+// NewSetup returns the wizard for a new config.
+func NewSetup(version string) *Setup {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	s := &Setup{
+		version:     version,
+		secret:      hex.EncodeToString(b),
+		done:        make(chan bool, 1),
+		cfg:         config.DefaultConfig(),
+		miningReady: true,
+	}
+
+	staticSub, _ := fs.Sub(staticFS, "static")
+	calls := http.NewServeMux()
+	calls.HandleFunc("GET /setup/state", s.handleState)
+	calls.HandleFunc("POST /setup/token", s.handleToken)
+	calls.HandleFunc("POST /setup/agent", s.handleAgent)
+	calls.HandleFunc("POST /setup/llm", s.handleLLM)
+	calls.HandleFunc("POST /setup/soul", s.handleSoul)
+	calls.HandleFunc("POST /setup/claim", s.handleClaim)
+	calls.HandleFunc("POST /setup/finish", s.handleFinish)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		data, _ := staticFS.ReadFile("static/setup.html")
+		_, _ = w.Write(data)
+	})
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
+	mux.HandleFunc("GET /i18n/{file}", handleI18nBundle)
+	mux.Handle("/setup/", s.requireSecret(calls))
+	s.httpSrv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return s
+}
+
+// Start serves the wizard on loopback from port (0 means DefaultPort),
+// trying the next ports like the console. Non-blocking.
+func (s *Setup) Start(port int) error {
+	if port <= 0 {
+		port = DefaultPort
+	}
+	hosts, err := usableHosts(loopbackHosts)
+	if err != nil {
+		return fmt.Errorf("setup wizard: %w", err)
+	}
+	for i := 0; i < maxPortRetries; i++ {
+		lns, err := listenAll(hosts, port+i)
+		if err != nil {
+			continue
+		}
+		s.addr = net.JoinHostPort(hosts[0], strconv.Itoa(port+i))
+		for _, ln := range lns {
+			go func(ln net.Listener) {
+				if err := s.httpSrv.Serve(ln); err != http.ErrServerClosed {
+					slog.Error("setup wizard error", "error", err)
+				}
+			}(ln)
+		}
+		return nil
+	}
+	return fmt.Errorf("setup wizard: no available port in range %d-%d", port, port+maxPortRetries-1)
+}
+
+// URL returns the wizard's address with its secret, for the browser.
+func (s *Setup) URL() string {
+	return "http://" + s.addr + "/#" + s.secret
+}
+
+// Done receives once the wizard is finished: true if the user asked to
+// start mining.
+func (s *Setup) Done() <-chan bool { return s.done }
+
+// Shutdown stops the wizard.
+func (s *Setup) Shutdown(ctx context.Context) error {
+	return s.httpSrv.Shutdown(ctx)
+}
+
+// requireSecret admits calls carrying the wizard's secret in X-Setup-Key,
+// so other local users and web pages can't drive it.
+func (s *Setup) requireSecret(next http.Handler) http.Handler {
+	want := []byte(s.secret)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Setup-Key")), want) != 1 {
+			setupError(w, http.StatusUnauthorized, "open the link printed by clawwork init --web")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setupError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func setupJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// apiErrorText is a user-facing message for a platform API error.
+func apiErrorText(err error, messages map[string]string) string {
+	apiErr, ok := api.AsAPIError(err)
+	if !ok {
+		return err.Error()
+	}
+	if msg := messages[apiErr.Code]; msg != "" {
+		return msg
+	}
+	if apiErr.Message != "" {
+		return apiErr.Message
+	}
+	return apiErr.Code
+}
+
+type setupQuestion struct {
+	Text    string   `json:"text"`
+	Options []string `json:"options"`
+}
+
+// handleState returns the draft and what the page needs to render it.
+func (s *Setup) handleState(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	questions := make([]setupQuestion, 0, 3)
+	for _, q := range knowledge.Questions() {
+		sq := setupQuestion{Text: q.Text}
+		for _, o := range q.Options {
+			sq.Options = append(sq.Options, o.Text)
+		}
+		questions = append(questions, sq)
+	}
+	_, statErr := os.Stat(config.Path())
+	setupJSON(w, map[string]any{
+		"version":       s.version,
+		"lang":          negotiateLang(r.Header.Get("Accept-Language")),
+		"config_path":   config.Path(),
+		"config_exists": statErr == nil,
+		"presets":       config.LLMPresets,
+		"questions":     questions,
+		"agent_id":      s.agentID,
+		"agent_name":    s.cfg.Agent.Name,
+		"token_id":      s.cfg.Agent.TokenID,
+		"llm_provider":  s.cfg.LLM.Provider,
+		"llm_model":     s.cfg.LLM.Model,
+		"llm_checked":   s.llmChecked,
+		"soul_exists":   s.verified && s.soulReadable(),
+		"mining_ready":  s.miningReady,
+	})
+}
+
+// soulReadable reports whether a soul exists that the draft key decrypts.
+func (s *Setup) soulReadable() bool {
+	if !knowledge.SoulExists() {
+		return false
+	}
+	_, err := knowledge.LoadSoul(s.cfg.Agent.APIKey)
+	return err == nil
+}
+
+// handleToken sets the token to inscribe, and to register the agent with.
+func (s *Setup) handleToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TokenID int `json:"token_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		setupError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.TokenID < 25 || req.TokenID > 1024 {
+		setupError(w, http.StatusBadRequest, "token ID must be 25-1024")
+		return
+	}
+	s.mu.Lock()
+	s.cfg.Agent.TokenID = req.TokenID
+	s.mu.Unlock()
+	setupJSON(w, map[string]int{"token_id": req.TokenID})
+}
+
+// handleAgent verifies an existing agent's API key, or registers a new
+// agent under name with the draft token.
+func (s *Setup) handleAgent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		APIKey string `json:"api_key"`
+		Name   string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		setupError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	req.APIKey, req.Name = strings.TrimSpace(req.APIKey), strings.TrimSpace(req.Name)
+
+	// The platform calls run unlocked; the results are applied under the lock.
+	switch {
+	case req.APIKey != "":
+		status, err := api.New(req.APIKey).Status(r.Context())
+		if err != nil {
+			setupError(w, http.StatusBadGateway, "could not verify API key: "+apiErrorText(err, nil))
+			return
+		}
+		if status.Agent.ID == "" {
+			setupError(w, http.StatusBadRequest, "invalid API key")
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cfg.Agent.APIKey, s.agentID, s.miningReady = req.APIKey, status.Agent.ID, true
+		s.cfg.Agent.Name = cmp.Or(req.Name, status.Agent.Name)
+		s.verified = true
+		setupJSON(w, map[string]any{"agent_id": s.agentID, "agent_name": s.cfg.Agent.Name, "soul_exists": s.soulReadable()})
+
+	case req.Name != "":
+		s.mu.Lock()
+		tokenID := s.cfg.Agent.TokenID
+		s.mu.Unlock()
+		resp, err := api.New("").Register(r.Context(), req.Name, tokenID)
+		switch {
+		case api.HasCode(err, "ALREADY_REGISTERED") || api.HasCode(err, "NAME_TAKEN"):
+			setupError(w, http.StatusConflict, "agent name already taken — enter its API key instead")
+			return
+		case err != nil:
+			setupError(w, http.StatusBadGateway, "registration failed: "+apiErrorText(err, nil))
+			return
+		case resp.APIKey == "":
+			setupError(w, http.StatusBadGateway, "registration returned no API key")
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cfg.Agent.Name, s.cfg.Agent.APIKey = req.Name, resp.APIKey
+		s.agentID, s.miningReady, s.verified = resp.AgentID, resp.MiningReady, true
+		// The platform shows a new key only once: keep it before anything
+		// else can go wrong.
+		if err := s.cfg.Save(); err != nil {
+			setupError(w, http.StatusInternalServerError, fmt.Sprintf("registered %s, but saving the config failed: %s — API key: %s", resp.AgentID, err, resp.APIKey))
+			return
+		}
+		setupJSON(w, map[string]any{
+			"agent_id":     resp.AgentID,
+			"agent_name":   req.Name,
+			"api_key":      resp.APIKey,
+			"mining_ready": resp.MiningReady,
+			"soul_exists":  s.soulReadable(),
+		})
+
+	default:
+		setupError(w, http.StatusBadRequest, "an API key or an agent name is required")
+	}
+}
+
+// handleLLM sets the provider from a preset (or "custom"/"platform") and
+// checks it with a one-word answer. A failed check is reported but the
+// settings are kept, as init does not check them at all.
+func (s *Setup) handleLLM(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Preset  string `json:"preset"`
+		APIKey  string `json:"api_key"`
+		Model   string `json:"model"`
+		BaseURL string `json:"base_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		setupError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	llmCfg := s.draftLLM()
+	if !config.ApplyLLMPreset(&llmCfg, req.Preset) {
+		setupError(w, http.StatusBadRequest, fmt.Sprintf("unknown provider %q", req.Preset))
+		return
+	}
+	if m := strings.TrimSpace(req.Model); m != "" {
+		llmCfg.Model = m
+	}
+	if u := strings.TrimSpace(req.BaseURL); u != "" {
+		llmCfg.BaseURL = u
+	}
+	llmCfg.APIKey = strings.TrimSpace(req.APIKey)
+	switch {
+	case req.Preset == "custom" && (llmCfg.BaseURL == "" || llmCfg.Model == ""):
+		setupError(w, http.StatusBadRequest, "a custom provider needs a base URL and a model")
+		return
+	case llmCfg.APIKey == "" && llmCfg.Provider != "ollama":
+		setupError(w, http.StatusBadRequest, "an API key is required for "+req.Preset)
+		return
+	}
+
+	check := "ok"
+	provider, err := llm.NewProvider(&llmCfg, "You are a health check. Follow instructions exactly.", 64)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+		var answer string
+		answer, err = provider.Answer(ctx, "Reply with the single word OK.")
+		cancel()
+		if err == nil && strings.TrimSpace(answer) == "" {
+			err = fmt.Errorf("%s returned an empty answer", provider.Name())
+		}
+	}
+	if err != nil {
+		check = err.Error()
+	}
+
+	s.mu.Lock()
+	s.cfg.LLM, s.llmChecked = llmCfg, err == nil
+	s.mu.Unlock()
+	setupJSON(w, map[string]any{"provider": llmCfg.Provider, "model": llmCfg.Model, "check": check})
+}
+
+func (s *Setup) draftLLM() config.LLMConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg.LLM
+}
+
+// handleSoul scores the personality answers (option indexes) and has the
+// draft LLM personalize the chosen template, like `clawwork soul generate`.
+// An existing soul the agent's key decrypts is sealed and is not replaced.
+func (s *Setup) handleSoul(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Answers []int `json:"answers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		setupError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	s.mu.Lock()
+	apiKey, llmCfg, verified := s.cfg.Agent.APIKey, s.cfg.LLM, s.verified
+	sealed := verified && s.soulReadable()
+	s.mu.Unlock()
+	switch {
+	case !verified:
+		setupError(w, http.StatusConflict, "set up the agent first")
+		return
+	case sealed:
+		setupError(w, http.StatusConflict, "this agent already has a soul; it is sealed and cannot be modified")
+		return
+	}
+
+	questions := knowledge.Questions()
+	answers, texts := make([]int, len(questions)), make([]string, len(questions))
+	for i, q := range questions {
+		if i < len(req.Answers) && req.Answers[i] >= 0 && req.Answers[i] < len(q.Options) {
+			answers[i] = req.Answers[i]
+		}
+		texts[i] = q.Options[answers[i]].Text
+	}
+	preset := knowledge.ScoreAnswers(answers)
+
+	soul, note := preset.Prompt, ""
+	provider, err := llm.NewProvider(&llmCfg, knowledge.GenerationSystemPrompt(), 256)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		var result string
+		result, err = provider.Answer(ctx, knowledge.GeneratePrompt(preset, texts))
+		cancel()
+		if cleaned, ok := knowledge.ValidateGenerated(result); err == nil && ok {
+			soul = cleaned
+		} else if err == nil {
+			note = "unexpected output — using the base template"
+		}
+	}
+	if err != nil {
+		note = "LLM unavailable (" + err.Error() + ") — using the base template"
+	}
+
+	if err := knowledge.SaveSoul(apiKey, soul); err != nil {
+		setupError(w, http.StatusInternalServerError, "save soul: "+err.Error())
+		return
+	}
+	setupJSON(w, map[string]string{"soul": soul, "preset": preset.ID, "note": note})
+}
+
+// handleClaim binds the agent to the owner's account with a claim code.
+func (s *Setup) handleClaim(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Code) == "" {
+		setupError(w, http.StatusBadRequest, "claim code required")
+		return
+	}
+	s.mu.Lock()
+	apiKey, verified := s.cfg.Agent.APIKey, s.verified
+	s.mu.Unlock()
+	if !verified {
+		setupError(w, http.StatusConflict, "set up the agent first")
+		return
+	}
+
+	resp, err := api.New(apiKey).Claim(r.Context(), strings.TrimSpace(req.Code))
+	if api.HasCode(err, "AGENT_ALREADY_CLAIMED") {
+		resp, err = &api.ClaimResponse{OK: true}, nil
+	}
+	if err != nil {
+		setupError(w, http.StatusBadRequest, apiErrorText(err, api.ClaimErrors))
+		return
+	}
+	s.mu.Lock()
+	s.miningReady = true
+	s.mu.Unlock()
+	setupJSON(w, map[string]any{"claimed": true, "display_name": resp.DisplayName})
+}
+
+// handleFinish validates and saves the config, then ends the wizard.
+func (s *Setup) handleFinish(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Start bool `json:"start"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.verified {
+		setupError(w, http.StatusConflict, "set up the agent first")
+		return
+	}
+	if err := s.cfg.Validate(); err != nil {
+		setupError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.cfg.Save(); err != nil {
+		setupError(w, http.StatusInternalServerError, "failed to save config: "+err.Error())
+		return
+	}
+	start := req.Start && s.miningReady
+	setupJSON(w, map[string]any{"config_path": config.Path(), "mining_ready": s.miningReady, "start": start})
+	select {
+	case s.done <- start:
+	default:
+	}
+}
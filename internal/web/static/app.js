@@ -3,14 +3,287 @@
   const messages = document.getElementById('messages');
   const input = document.getElementById('input');
   const sendBtn = document.getElementById('send');
+  const attachBtn = document.getElementById('attach');
+  const attachFile = document.getElementById('attach-file');
+  const attachmentsEl = document.getElementById('attachments');
   const badge = document.getElementById('status-badge');
   const footerInfo = document.getElementById('footer-info');
   const sessionSelect = document.getElementById('session-select');
+  const toolPermSelect = document.getElementById('tool-perm');
   const newChatBtn = document.getElementById('new-chat');
   const delChatBtn = document.getElementById('del-chat');
   const agentAvatar = document.getElementById('agent-avatar');
   const agentNameEl = document.getElementById('agent-name');
   const thinkingToggle = document.getElementById('thinking-toggle');
+  const alertBanner = document.getElementById('alert-banner');
+  const alertText = document.getElementById('alert-text');
+
+  // ── i18n ──
+  // Locale bundles come from /i18n/{lang}.json. Elements name their string
+  // with data-i18n (text), data-i18n-title, data-i18n-placeholder and
+  // data-i18n-msg (quick-command prompt); a missing key keeps the English
+  // already in the page.
+  const langSelect = document.getElementById('lang-select');
+  const LANG_NAMES = { en: 'English', zh: '中文' };
+  var strings = {};
+  function t(key, fallback) { return strings[key] || fallback; }
+
+  function applyI18n() {
+    document.querySelectorAll('[data-i18n]').forEach(function(el) {
+      if (!el.dataset.i18nEn) el.dataset.i18nEn = el.textContent;
+      el.textContent = t(el.dataset.i18n, el.dataset.i18nEn);
+    });
+    [['i18nTitle', 'title'], ['i18nPlaceholder', 'placeholder'], ['i18nMsg', 'data-msg']].forEach(function(pair) {
+      var attr = pair[1], enKey = pair[0] + 'En';
+      document.querySelectorAll('[data-' + pair[0].replace(/[A-Z]/g, function(c) { return '-' + c.toLowerCase(); }) + ']').forEach(function(el) {
+        if (!el.dataset[enKey]) el.dataset[enKey] = el.getAttribute(attr) || '';
+        el.setAttribute(attr, t(el.dataset[pair[0]], el.dataset[enKey]));
+      });
+    });
+    document.title = t('console.title', 'ClawWork Console');
+  }
+
+  function loadLocale(lang) {
+    document.documentElement.lang = lang;
+    return fetch('/i18n/' + lang + '.json')
+      .then(function(r) { return r.ok ? r.json() : {}; })
+      .then(function(s) { strings = s; applyI18n(); updateFooter(); })
+      .catch(function() {});
+  }
+
+  fetch('/i18n').then(function(r) { return r.json(); }).then(function(info) {
+    langSelect.innerHTML = '';
+    info.available.forEach(function(code) {
+      var opt = document.createElement('option');
+      opt.value = code;
+      opt.textContent = LANG_NAMES[code] || code;
+      langSelect.appendChild(opt);
+    });
+    langSelect.value = info.lang;
+    if (info.lang !== 'en') loadLocale(info.lang);
+  }).catch(function() { langSelect.hidden = true; });
+
+  langSelect.addEventListener('change', function() {
+    var lang = langSelect.value;
+    fetch('/i18n', {
+      method: 'PUT',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ lang: lang })
+    }).catch(function() {});
+    loadLocale(lang);
+  });
+
+  // ── Preferences ──
+  // Theme, layout and default session are saved by the agent (GET/PUT
+  // /prefs), so they follow it across browsers and devices.
+  var prefs = {};
+  const themeToggle = document.getElementById('theme-toggle');
+  const defaultChatBtn = document.getElementById('default-chat');
+  const PANELS = {
+    log: ['log-panel', 'resize-handle'],
+    chat: ['chat-panel', 'resize-handle'],
+    commands: ['cmd-bar']
+  };
+
+  function applyPrefs() {
+    document.body.classList.toggle('theme-light', prefs.theme === 'light');
+    var hidden = prefs.hidden_panels || [];
+    var hide = {};
+    hidden.forEach(function(p) { (PANELS[p] || []).forEach(function(id) { hide[id] = true; }); });
+    ['log-panel', 'chat-panel', 'resize-handle', 'cmd-bar'].forEach(function(id) {
+      var el = document.getElementById(id);
+      if (el) el.classList.toggle('pref-hidden', !!hide[id]);
+    });
+    document.querySelectorAll('#panel-toggles [data-panel]').forEach(function(btn) {
+      btn.classList.toggle('active', hidden.indexOf(btn.dataset.panel) < 0);
+    });
+    var logPanel = document.getElementById('log-panel');
+    if (prefs.log_width && hidden.indexOf('chat') < 0) {
+      logPanel.style.flex = 'none';
+      logPanel.style.width = prefs.log_width + 'px';
+    } else {
+      logPanel.style.flex = '';
+      logPanel.style.width = '';
+    }
+    updateDefaultChat();
+  }
+
+  function updateDefaultChat() {
+    var isDefault = !!currentSessionId && prefs.default_session === currentSessionId;
+    defaultChatBtn.innerHTML = isDefault ? '&#9733;' : '&#9734;';
+    defaultChatBtn.classList.toggle('active', isDefault);
+  }
+
+  function savePrefs(change) {
+    return fetch('/prefs', {
+      method: 'PUT',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(change)
+    }).then(function(r) { return r.json(); }).then(function(p) {
+      if (p.error) { console.error('prefs:', p.error); return; }
+      prefs = p;
+      applyPrefs();
+    }).catch(function() {});
+  }
+
+  fetch('/prefs').then(function(r) { return r.json(); }).then(function(p) {
+    prefs = p;
+    applyPrefs();
+  }).catch(function() {});
+
+  document.querySelectorAll('#panel-toggles [data-panel]').forEach(function(btn) {
+    btn.addEventListener('click', function() {
+      var hidden = (prefs.hidden_panels || []).slice();
+      var i = hidden.indexOf(btn.dataset.panel);
+      if (i >= 0) hidden.splice(i, 1); else hidden.push(btn.dataset.panel);
+      savePrefs({ hidden_panels: hidden });
+    });
+  });
+  themeToggle.addEventListener('click', function() {
+    savePrefs({ theme: prefs.theme === 'light' ? 'dark' : 'light' });
+  });
+  defaultChatBtn.addEventListener('click', function() {
+    if (!currentSessionId) return;
+    savePrefs({ default_session: prefs.default_session === currentSessionId ? '' : currentSessionId });
+  });
+
+  // ── Event alerts ──
+  // The console stamps each event with the agent's alert preference
+  // (data.alert), so every browser and remote consumer signals the same
+  // events. Replayed history stays quiet.
+  const ALERT_TYPES = ['hit', 'inscription', 'penalty', 'limit', 'error', 'alert', 'diagnosis', 'platform'];
+  const ALERT_MAX_AGE = 15 * 1000;
+  const alertsPopover = document.getElementById('alerts-popover');
+  var audioCtx = null;
+  var titleFlash = null;
+
+  function signalEvent(data) {
+    if (!data.alert) return;
+    if (data.time && Date.now() - new Date(data.time).getTime() > ALERT_MAX_AGE) return;
+    if (data.alert.sound) playChime();
+    if (data.alert.flash && !document.hasFocus()) flashTitle(data.type);
+  }
+
+  function playChime() {
+    try {
+      audioCtx = audioCtx || new (window.AudioContext || window.webkitAudioContext)();
+      const osc = audioCtx.createOscillator();
+      const gain = audioCtx.createGain();
+      osc.frequency.value = 880;
+      gain.gain.setValueAtTime(0.15, audioCtx.currentTime);
+      gain.gain.exponentialRampToValueAtTime(0.001, audioCtx.currentTime + 0.6);
+      osc.connect(gain).connect(audioCtx.destination);
+      osc.start();
+      osc.stop(audioCtx.currentTime + 0.6);
+    } catch (err) { /* no audio until the page was interacted with */ }
+  }
+
+  function flashTitle(type) {
+    if (titleFlash) return;
+    const original = document.title;
+    let on = false;
+    titleFlash = setInterval(function() {
+      on = !on;
+      document.title = on ? '\u2605 ' + type : original;
+    }, 1000);
+    window.addEventListener('focus', function stop() {
+      clearInterval(titleFlash);
+      titleFlash = null;
+      document.title = original;
+      window.removeEventListener('focus', stop);
+    });
+  }
+
+  function renderAlertPrefs() {
+    const alerts = prefs.alerts || {};
+    const box = function(type, kind) {
+      return '<td><input type="checkbox" data-type="' + type + '" data-kind="' + kind + '"' +
+        ((alerts[type] || {})[kind] ? ' checked' : '') + '></td>';
+    };
+    alertsPopover.innerHTML = '<table><tr><th></th><th>' + escapeHtml(t('alerts.sound', 'sound')) + '</th><th>' +
+      escapeHtml(t('alerts.flash', 'flash')) + '</th></tr>' +
+      ALERT_TYPES.map(function(type) {
+        return '<tr><td>' + type + '</td>' + box(type, 'sound') + box(type, 'flash') + '</tr>';
+      }).join('') + '</table>';
+  }
+
+  document.getElementById('alerts-toggle').addEventListener('click', function() {
+    renderAlertPrefs();
+    alertsPopover.hidden = !alertsPopover.hidden;
+  });
+  alertsPopover.addEventListener('change', function(e) {
+    const input = e.target;
+    const alerts = Object.assign({}, prefs.alerts);
+    const a = Object.assign({}, alerts[input.dataset.type]);
+    a[input.dataset.kind] = input.checked;
+    alerts[input.dataset.type] = a;
+    savePrefs({ alerts: alerts });
+  });
+
+  // ── Alert banner ──
+  function showAlert(msg, kind) {
+    alertText.textContent = '\u26a0 ' + msg;
+    alertBanner.dataset.kind = kind || 'alert';
+    document.getElementById('alert-advise').hidden = kind === 'llm';
+    alertBanner.hidden = false;
+  }
+  function hideAlert(kind) {
+    if (alertBanner.dataset.kind === kind) alertBanner.hidden = true;
+  }
+  document.getElementById('alert-close').addEventListener('click', function() {
+    alertBanner.hidden = true;
+  });
+
+  // ── Leaderboard widget ──
+  const rankBadge = document.getElementById('rank-badge');
+  async function loadRank() {
+    try {
+      const resp = await fetch('/leaderboard');
+      const data = await resp.json();
+      if (!data.available) {
+        rankBadge.hidden = true;
+        return false; // server has no rankings — stop polling
+      }
+      if (data.self) {
+        rankBadge.textContent = 'Rank #' + data.self.rank + ' / ' + data.total;
+        rankBadge.title = (data.scope === 'global' ? 'Global' : 'Nearby') + ' rank by CW\n' +
+          (data.entries || []).map(function(e) {
+            return '#' + e.rank + ' ' + e.display_name + ' — ' + e.cw + ' CW';
+          }).join('\n');
+        rankBadge.hidden = false;
+      }
+    } catch (err) { /* transient — keep the last value */ }
+    return true;
+  }
+  loadRank().then(function(ok) {
+    if (ok) setInterval(loadRank, 5 * 60 * 1000);
+  });
+
+  // ── Trust recovery advisor ──
+  const adviseBtn = document.getElementById('alert-advise');
+  adviseBtn.addEventListener('click', async function() {
+    adviseBtn.disabled = true;
+    const loadingEl = appendChatMessage('loading', 'Analyzing recent challenge failures...');
+    try {
+      const resp = await fetch('/advise', { method: 'POST' });
+      const data = await resp.json();
+      loadingEl.remove();
+      if (data.error) {
+        appendChatMessage('system', data.error);
+        return;
+      }
+      const div = document.createElement('div');
+      div.className = 'msg advice-card';
+      div.innerHTML = '<span class="msg-role">Trust advisor:</span><div class="msg-content">' + renderMarkdown(data.advice) + '</div>';
+      messages.appendChild(div);
+      messages.scrollTop = messages.scrollHeight;
+    } catch (err) {
+      loadingEl.remove();
+      appendChatMessage('system', 'Advisor request failed: ' + err.message);
+    } finally {
+      adviseBtn.disabled = false;
+    }
+  });
 
   // ── Thinking toggle ──
   var thinkingEnabled = true;
@@ -18,29 +291,48 @@
     thinkingToggle.addEventListener('click', function() {
       thinkingEnabled = !thinkingEnabled;
       thinkingToggle.classList.toggle('active', thinkingEnabled);
-      thinkingToggle.title = thinkingEnabled ? 'Thinking ON — click to disable' : 'Thinking OFF — click to enable';
+      thinkingToggle.title = thinkingEnabled ? t('chat.think.on', 'Thinking ON — click to disable') : t('chat.think.off', 'Thinking OFF — click to enable');
     });
   }
 
   // ── SSE Connection ──
   let eventCount = 0;
+  let es = null;
+  let sseLive = false;
+  const HEALTH_INTERVAL = 30 * 1000;
 
   function connectSSE() {
-    const es = new EventSource('/events');
+    es = new EventSource('/events');
 
     es.onmessage = function(e) {
       try {
         const data = JSON.parse(e.data);
         appendLog(data);
+        signalEvent(data);
         eventCount++;
         updateFooter();
 
+        if (data.type === 'alert') {
+          showAlert(data.message, 'alert');
+        } else if (data.type === 'llm') {
+          if (data.data && data.data.degraded) showAlert(data.message, 'llm');
+          else hideAlert('llm');
+        }
+
         // Update status badge.
         if (data.type === 'control') {
-          if (data.message.toLowerCase().includes('paused')) {
-            setBadge('PAUSED', 'badge-paused');
-          } else if (data.message.toLowerCase().includes('resumed')) {
-            setBadge('RUNNING', 'badge-running');
+          const action = data.data && data.data.action;
+          if (action === 'pause') {
+            setBadge(t('badge.paused', 'PAUSED'), 'badge-paused');
+          } else if (action === 'resume') {
+            setBadge(t('badge.running', 'RUNNING'), 'badge-running');
+          }
+        } else if (data.type === 'schedule') {
+          // Outside the active hours the miner sleeps until they start.
+          if (data.data && data.data.active === false) {
+            setBadge(t('badge.sleeping', 'SLEEPING'), 'badge-paused');
+          } else {
+            setBadge(t('badge.running', 'RUNNING'), 'badge-running');
           }
         }
       } catch (err) {
@@ -49,38 +341,162 @@
     };
 
     es.onopen = function() {
-      footerInfo.textContent = 'Connected';
+      sseLive = true;
+      footerInfo.textContent = t('footer.connected', 'Connected');
       updateFooter();
     };
 
     es.onerror = function() {
-      footerInfo.textContent = 'Disconnected — reconnecting...';
-      setBadge('OFFLINE', 'badge-stopped');
+      sseLive = false;
+      footerInfo.textContent = t('footer.reconnecting', 'Disconnected — reconnecting...');
+      setBadge(t('badge.offline', 'OFFLINE'), 'badge-stopped');
     };
   }
 
+  // checkHealth catches streams a proxy closed without telling the browser:
+  // if the server counts no subscribers while we think we're live, reconnect.
+  function checkHealth() {
+    fetch('/state').then(r => r.json()).then(state => {
+      if (sseLive && state.sse_clients === 0) {
+        es.close();
+        sseLive = false;
+        footerInfo.textContent = t('footer.stream_lost', 'Stream lost — reconnecting...');
+        connectSSE();
+      }
+    }).catch(() => {
+      sseLive = false;
+      footerInfo.textContent = t('footer.unreachable', 'Disconnected — console unreachable');
+      setBadge(t('badge.offline', 'OFFLINE'), 'badge-stopped');
+    });
+  }
+
   function appendLog(data) {
     const line = document.createElement('div');
     line.className = 'log-line ev-' + (data.type || 'default');
+    if (data.alert && data.alert.flash) line.classList.add('log-flash');
 
-    const time = data.time ? new Date(data.time).toLocaleTimeString() : '';
+    const time = data.time ? fmtTime(data.time, 'time') : '';
     const timeSpan = '<span class="log-time">[' + escapeHtml(time) + ']</span> ';
     line.innerHTML = timeSpan + escapeHtml(data.message);
+    if (EXPLAINABLE.includes(data.type)) {
+      const btn = document.createElement('button');
+      btn.className = 'log-explain';
+      btn.textContent = t('log.explain', 'Explain');
+      btn.title = t('log.explain_title', 'Ask the agent to diagnose this in a new chat');
+      btn.addEventListener('click', function() { explainEvent(data); });
+      line.appendChild(btn);
+    }
 
     log.appendChild(line);
     log.scrollTop = log.scrollHeight;
   }
 
+  // Events that get an "Explain" button opening a chat about them.
+  const EXPLAINABLE = ['error', 'alert', 'penalty', 'warning'];
+
+  // explainEvent opens a new chat seeded with the event, the events and
+  // log lines before it and the miner's quotas.
+  async function explainEvent(ev) {
+    if (sending) return;
+    sending = true;
+    sendBtn.disabled = true;
+    clearMessages();
+    const loadingEl = appendChatMessage('loading', t('chat.explaining', 'Asking the agent to explain this event...'));
+    try {
+      const resp = await fetch('/chat/from-event', {
+        method: 'POST',
+        headers: {'Content-Type': 'application/json'},
+        body: JSON.stringify({event: ev}),
+      });
+      const data = await resp.json();
+      if (data.id) currentSessionId = data.id;
+      if (data.error) {
+        loadingEl.className = 'msg msg-system';
+        loadingEl.textContent = 'Error: ' + data.error;
+      } else {
+        loadingEl.remove();
+        appendChatMessage('user', data.message);
+        appendChatMessage('assistant', data.reply);
+        if (data.action) appendChatMessage('system', 'Action executed: ' + data.action);
+      }
+    } catch (err) {
+      loadingEl.className = 'msg msg-system';
+      loadingEl.textContent = 'Connection error: ' + err.message;
+    }
+    sending = false;
+    sendBtn.disabled = false;
+    loadSessions();
+    input.focus();
+  }
+
   function setBadge(text, cls) {
     badge.textContent = text;
     badge.className = 'badge ' + cls;
   }
 
+  function fmtMs(ms) {
+    return ms < 1000 ? ms + 'ms' : (ms / 1000).toFixed(1) + 's';
+  }
+
+  // displayTZ is the miner's display.timezone ('' = the browser's zone),
+  // learned from /state.
+  var displayTZ = '';
+
+  // fmtTime formats a timestamp in displayTZ: 'time', 'date' or 'datetime'.
+  function fmtTime(v, kind) {
+    var d = new Date(v);
+    var opts = displayTZ ? { timeZone: displayTZ } : {};
+    if (kind === 'date') return d.toLocaleDateString(undefined, opts);
+    if (kind === 'time') return d.toLocaleTimeString(undefined, opts);
+    return d.toLocaleString(undefined, opts);
+  }
+
+  function fmtWait(sec) {
+    if (sec < 3600) return Math.max(1, Math.round(sec / 60)) + 'm';
+    return Math.floor(sec / 3600) + 'h' + Math.round((sec % 3600) / 60) + 'm';
+  }
+
   function updateFooter() {
     // Fetch current state for footer display + agent info.
     fetch('/state').then(r => r.json()).then(state => {
-      const parts = ['Token #' + state.token_id];
-      parts.push(eventCount + ' events');
+      displayTZ = state.timezone || '';
+      const parts = [sseLive ? t('footer.live', 'Live') : t('footer.disconnected', 'Disconnected')];
+      if (state.tokens && state.tokens.length > 1) {
+        // Multi-token mode: each token with its CW and cooldown.
+        parts.push(t('footer.tokens', 'Tokens') + ' ' + state.tokens.map(function(tok) {
+          var wait = tok.cooldown_until ? Math.round((new Date(tok.cooldown_until) - Date.now()) / 60000) : 0;
+          return '#' + tok.token_id + ' ' + tok.cw_earned + ' CW' + (wait > 0 ? ' (' + wait + 'm)' : ' (' + t('footer.ready', 'ready') + ')');
+        }).join(', '));
+      } else {
+        parts.push(t('footer.token', 'Token') + ' #' + state.token_id);
+      }
+      if (state.stats) {
+        // Run, today and lifetime are different numbers; label each.
+        parts.push('CW ' + t('footer.run', 'run') + ' ' + state.stats.run.cw_earned +
+          ' · ' + t('footer.today', 'today') + ' ' + state.stats.today.cw_earned +
+          ' · ' + t('footer.lifetime', 'lifetime') + ' ' + state.stats.lifetime.cw_earned);
+      }
+      parts.push(eventCount + ' ' + t('footer.events', 'events'));
+      // Platform limits currently in force (token cooldowns are shown above).
+      var blocked = (state.quotas || []).filter(function(q) {
+        return !q.available && q.name.indexOf('token.') !== 0;
+      });
+      if (blocked.length) {
+        parts.push(t('footer.limits', 'Limits') + ' ' + blocked.map(function(q) {
+          return q.label + ' (' + fmtWait(q.remaining_seconds) + ')';
+        }).join(', '));
+      }
+      if (state.goal) {
+        var g = state.goal;
+        var goalText = t('footer.goal', 'Goal') + ' ' + g.percent.toFixed(1) + '%';
+        if (g.remaining === 0) goalText += ' — ' + t('footer.goal_reached', 'reached');
+        else if (g.eta) goalText += ' — ETA ' + fmtTime(g.eta, 'date') + ' (~' + Math.round(g.per_day) + ' CW/day)';
+        parts.push(goalText);
+      }
+      const llm = state.latency && state.latency.llm;
+      if (llm && llm.count > 0) {
+        parts.push('LLM p50 ' + fmtMs(llm.p50_ms) + ' / p95 ' + fmtMs(llm.p95_ms));
+      }
       footerInfo.textContent = parts.join(' | ');
 
       // Update agent identity in header (once).
@@ -93,33 +509,79 @@
         }
       }
 
+      // Restore a persisted moment cooldown (e.g. after a restart).
+      if (state.moment_cooldown > 0 && !postCooldownUntil) {
+        startPostCooldown(state.moment_cooldown);
+      }
+
       // Sync badge with pause state.
       if (state.paused) {
-        setBadge('PAUSED', 'badge-paused');
+        setBadge(t('badge.paused', 'PAUSED'), 'badge-paused');
       }
     }).catch(() => {});
   }
 
   // ── Chat ──
   let sending = false;
+  let pendingImages = []; // data URLs of images attached to the next message
+  const MAX_IMAGES = 4;
+  const MAX_IMAGE_BYTES = 3.5 * 1024 * 1024; // stays under the server's 5MB base64 cap
+
+  attachBtn.addEventListener('click', function() { attachFile.click(); });
+  attachFile.addEventListener('change', function() {
+    Array.from(attachFile.files).forEach(function(f) {
+      if (pendingImages.length >= MAX_IMAGES) {
+        appendChatMessage('system', 'At most ' + MAX_IMAGES + ' images per message.');
+        return;
+      }
+      if (f.size > MAX_IMAGE_BYTES) {
+        appendChatMessage('system', f.name + ' is too large (max 3.5MB).');
+        return;
+      }
+      const reader = new FileReader();
+      reader.onload = function() {
+        pendingImages.push(reader.result);
+        renderAttachments();
+      };
+      reader.readAsDataURL(f);
+    });
+    attachFile.value = '';
+  });
+
+  function renderAttachments() {
+    attachmentsEl.innerHTML = '';
+    pendingImages.forEach(function(src, i) {
+      const chip = document.createElement('span');
+      chip.className = 'attach-chip';
+      chip.innerHTML = '<img src="' + src + '" alt=""><a title="Remove">&times;</a>';
+      chip.querySelector('a').addEventListener('click', function() {
+        pendingImages.splice(i, 1);
+        renderAttachments();
+      });
+      attachmentsEl.appendChild(chip);
+    });
+  }
 
   async function sendMessage() {
     const text = input.value.trim();
-    if (!text || sending) return;
+    if ((!text && pendingImages.length === 0) || sending) return;
+    const images = pendingImages;
+    pendingImages = [];
+    renderAttachments();
 
     input.value = '';
     sending = true;
     sendBtn.disabled = true;
     document.querySelectorAll('.cmd-bar a[data-msg]').forEach(function(a) { a.classList.add('cmd-disabled'); });
 
-    appendChatMessage('user', text);
+    appendChatMessage('user', text, images);
     const loadingEl = appendChatMessage('loading', 'Thinking...');
 
     try {
       const resp = await fetch('/chat', {
         method: 'POST',
         headers: {'Content-Type': 'application/json'},
-        body: JSON.stringify({message: text, enable_thinking: thinkingEnabled}),
+        body: JSON.stringify({message: text, enable_thinking: thinkingEnabled, images: images}),
       });
       const data = await resp.json();
 
@@ -146,11 +608,11 @@
     input.focus();
   }
 
-  function appendChatMessage(role, text) {
+  function appendChatMessage(role, text, images) {
     const div = document.createElement('div');
     if (role === 'user') {
       div.className = 'msg msg-user';
-      div.innerHTML = '<span class="msg-role">You:</span> ' + escapeHtml(text);
+      div.innerHTML = '<span class="msg-role">You:</span> ' + escapeHtml(text) + renderImageRefs(images);
     } else if (role === 'assistant') {
       div.className = 'msg msg-assistant';
       div.innerHTML = '<span class="msg-role">Agent:</span><div class="msg-content">' + renderMarkdown(text) + '</div>';
@@ -166,6 +628,17 @@
     return div;
   }
 
+  // Inline uploads are shown as thumbnails; persisted placeholders as a text tag.
+  function renderImageRefs(images) {
+    if (!images || images.length === 0) return '';
+    return '<div class="msg-images">' + images.map(function(src) {
+      if (src.indexOf('data:image/') === 0 || /^https?:\/\//.test(src)) {
+        return '<img src="' + escapeHtml(src) + '" alt="">';
+      }
+      return '<span class="msg-image-ref">[' + escapeHtml(src) + ']</span>';
+    }).join('') + '</div>';
+  }
+
   function escapeHtml(s) {
     const el = document.createElement('span');
     el.textContent = s;
@@ -232,15 +705,23 @@
       const data = await resp.json();
       currentSessionId = data.current || '';
       var sessions = data.sessions || [];
+      (data.warnings || []).forEach(function(w) {
+        appendChatMessage('system', w);
+      });
 
       sessionSelect.innerHTML = '';
       sessions.forEach(function(s) {
         var opt = document.createElement('option');
         opt.value = s.id;
         opt.textContent = s.title || 'New Chat';
-        if (s.id === currentSessionId) opt.selected = true;
+        if (s.workspace) opt.title = 'Workspace: ' + s.workspace;
+        if (s.id === currentSessionId) {
+          opt.selected = true;
+          toolPermSelect.value = s.tools || 'full';
+        }
         sessionSelect.appendChild(opt);
       });
+      updateDefaultChat();
     } catch (err) {
       console.error('loadSessions error:', err);
     }
@@ -252,9 +733,10 @@
       var resp = await fetch('/sessions/' + id, { method: 'POST' });
       var data = await resp.json();
       currentSessionId = id;
+      await loadSessions();
       clearMessages();
       (data.messages || []).forEach(function(m) {
-        appendChatMessage(m.role, m.content);
+        appendChatMessage(m.role, m.content, m.images);
       });
     } catch (err) {
       console.error('switchSession error:', err);
@@ -390,6 +872,19 @@
   });
   newChatBtn.addEventListener('click', createSession);
   delChatBtn.addEventListener('click', deleteSession);
+  toolPermSelect.addEventListener('change', async function() {
+    if (!currentSessionId) return;
+    try {
+      var resp = await fetch('/sessions/' + currentSessionId + '/tools', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ tools: toolPermSelect.value })
+      });
+      if (!resp.ok) await loadSessions();
+    } catch (err) {
+      console.error('setToolPerm error:', err);
+    }
+  });
 
   // ── Direct mining controls (no LLM) ──
 
@@ -399,8 +894,8 @@
       var data = await resp.json();
       var status = data.status || action;
       // Update badge immediately.
-      if (status === 'paused') setBadge('PAUSED', 'badge-paused');
-      else if (status === 'running') setBadge('RUNNING', 'badge-running');
+      if (status === 'paused') setBadge(t('badge.paused', 'PAUSED'), 'badge-paused');
+      else if (status === 'running') setBadge(t('badge.running', 'RUNNING'), 'badge-running');
       appendChatMessage('system', 'Mining ' + status + '.');
     } catch (err) {
       appendChatMessage('system', 'Control error: ' + err.message);
@@ -529,10 +1024,10 @@
     if (remaining > 0) {
       var m = Math.floor(remaining / 60);
       var s = remaining % 60;
-      btn.textContent = '/post ' + m + ':' + (s < 10 ? '0' : '') + s;
+      btn.textContent = t('cmd.post', 'post') + ' ' + m + ':' + (s < 10 ? '0' : '') + s;
       btn.classList.add('cmd-disabled');
     } else {
-      btn.textContent = '/post';
+      btn.textContent = t('cmd.post', 'post');
       btn.classList.remove('cmd-disabled');
     }
   }
@@ -623,7 +1118,7 @@
       var avatarHtml = m.avatar_url
         ? '<div class="social-avatar"><img src="' + escapeHtml(m.avatar_url) + '" alt=""></div>'
         : '<div class="social-avatar">' + escapeHtml((m.display_name || '?').charAt(0).toUpperCase()) + '</div>';
-      var time = m.created_at ? new Date(m.created_at).toLocaleString() : '';
+      var time = m.created_at ? fmtTime(m.created_at) : '';
       html += '<div class="moment-item"><div class="moment-header">' + avatarHtml +
         '<span class="social-name">' + escapeHtml(m.display_name || m.agent_id) + '</span>' +
         '<span class="moment-time">' + escapeHtml(time) + '</span>';
@@ -702,7 +1197,7 @@
     var html = '<div class="social-card"><div class="social-card-title">MAIL (' + mails.length + ')</div>';
     mails.forEach(function(m, idx) {
       var sender = m.sender_display_name || m.from_name || m.sender_id || m.from_agent_id || 'Unknown';
-      var time = m.created_at ? new Date(m.created_at).toLocaleString() : '';
+      var time = m.created_at ? fmtTime(m.created_at) : '';
       var isUnread = m.read_at === null || m.read_at === undefined || m.read === false || m.is_read === false;
       var subject = m.subject || m.title || '';
       var body = m.content || m.body || '';
@@ -743,7 +1238,7 @@
     var html = '<div class="social-card"><div class="social-card-title">' + escapeHtml(agentName) +
       ' · Moments (' + moments.length + ')</div>';
     moments.forEach(function(m) {
-      var time = m.created_at ? new Date(m.created_at).toLocaleString() : '';
+      var time = m.created_at ? fmtTime(m.created_at) : '';
       html += '<div class="moment-item">' +
         '<div class="moment-header">' +
         '<span class="social-name">' + escapeHtml(agentName) + '</span>' +
@@ -814,6 +1309,7 @@
 
   // Init.
   connectSSE();
+  setInterval(checkHealth, HEALTH_INTERVAL);
   updateFooter();
   loadSessions().then(function() {
     // Load current session messages on page open.
@@ -824,7 +1320,7 @@
           if (data.messages && data.messages.length > 0) {
             clearMessages();
             data.messages.forEach(function(m) {
-              appendChatMessage(m.role, m.content);
+              appendChatMessage(m.role, m.content, m.images);
             });
           }
         })
@@ -868,6 +1364,7 @@
       document.body.style.userSelect = '';
       document.removeEventListener('mousemove', onMove);
       document.removeEventListener('mouseup', onUp);
+      savePrefs({ log_width: Math.round(logPanel.getBoundingClientRect().width) });
     }
   })();
 })();
@@ -0,0 +1,270 @@
+(function() {
+  // The wizard's secret is in the fragment of the URL init opened, so it
+  // never reaches a server log; every /setup/ call sends it back.
+  const key = location.hash.slice(1);
+  const errorEl = document.getElementById('setup-error');
+  var strings = {};
+  var state = {};
+
+  function t(k, fallback) { return strings[k] || fallback; }
+
+  function applyI18n() {
+    document.querySelectorAll('[data-i18n]').forEach(function(el) {
+      if (!el.dataset.i18nEn) el.dataset.i18nEn = el.textContent;
+      el.textContent = t(el.dataset.i18n, el.dataset.i18nEn);
+    });
+    document.title = t('setup.title', 'ClawWork Setup');
+  }
+
+  function call(method, path, body) {
+    errorEl.hidden = true;
+    return fetch('/setup/' + path, {
+      method: method,
+      headers: { 'Content-Type': 'application/json', 'X-Setup-Key': key },
+      body: body ? JSON.stringify(body) : undefined
+    }).then(function(r) {
+      return r.json().then(function(data) {
+        if (!r.ok) throw new Error(data.error || r.statusText);
+        return data;
+      });
+    });
+  }
+
+  function fail(err) {
+    errorEl.textContent = err.message;
+    errorEl.hidden = false;
+  }
+
+  function show(id) {
+    document.querySelectorAll('.setup-step').forEach(function(el) { el.hidden = el.id !== id; });
+  }
+
+  function result(id, text, warn) {
+    var el = document.getElementById(id);
+    el.textContent = text;
+    el.classList.toggle('setup-warn', !!warn);
+    el.hidden = !text;
+  }
+
+  function busy(btn, on) {
+    btn.disabled = on;
+  }
+
+  // ── Step 1: agent and token ──
+  const keyField = document.getElementById('field-key');
+  const nameField = document.getElementById('field-name');
+  document.querySelectorAll('input[name=mode]').forEach(function(el) {
+    el.addEventListener('change', function() {
+      var isNew = el.value === 'new' && el.checked;
+      keyField.hidden = isNew;
+      nameField.hidden = !isNew;
+    });
+  });
+
+  const agentNext = document.getElementById('agent-next');
+  var agentDone = false;
+  agentNext.addEventListener('click', function() {
+    if (agentDone) { show('step-llm'); return; }
+    var isNew = document.querySelector('input[name=mode]:checked').value === 'new';
+    var body = isNew
+      ? { name: document.getElementById('agent-name-input').value }
+      : { api_key: document.getElementById('agent-key').value };
+    busy(agentNext, true);
+    call('POST', 'token', { token_id: parseInt(document.getElementById('token-id').value, 10) || 0 })
+      .then(function() { return call('POST', 'agent', body); })
+      .then(function(r) {
+        state.agent_id = r.agent_id;
+        state.soul_exists = r.soul_exists;
+        if (r.mining_ready === false) state.mining_ready = false;
+        if (r.api_key) {
+          // A new key is shown once: let the user copy it before moving on.
+          result('agent-result', t('setup.agent.registered', 'Registered. Keep this API key somewhere safe:') + ' ' + r.api_key);
+          agentDone = true;
+          agentNext.textContent = t('setup.continue', 'Continue');
+          return;
+        }
+        result('agent-result', '');
+        show('step-llm');
+      })
+      .catch(fail)
+      .finally(function() { busy(agentNext, false); });
+  });
+
+  // ── Step 2: LLM ──
+  const presetSelect = document.getElementById('llm-preset');
+  const modelInput = document.getElementById('llm-model');
+  const urlInput = document.getElementById('llm-base-url');
+
+  function presetChanged() {
+    var p = (state.presets || []).find(function(p) { return p.name === presetSelect.value; }) || {};
+    modelInput.value = '';
+    modelInput.placeholder = p.model || '';
+    urlInput.value = '';
+    urlInput.placeholder = p.base_url || '';
+    document.getElementById('field-llm-url').hidden = presetSelect.value === 'platform';
+    document.getElementById('field-llm-key').hidden = presetSelect.value === 'ollama';
+    var link = document.getElementById('llm-key-url');
+    link.href = link.textContent = p.key_url || '';
+    document.getElementById('llm-key-hint').hidden = !p.key_url;
+    result('llm-result', '');
+    llmChecked = false;
+    llmNext.textContent = t('setup.llm.check', 'Check & continue');
+  }
+  presetSelect.addEventListener('change', presetChanged);
+
+  const llmNext = document.getElementById('llm-next');
+  var llmChecked = false;
+  llmNext.addEventListener('click', function() {
+    if (llmChecked) { afterLLM(); return; }
+    busy(llmNext, true);
+    result('llm-result', t('setup.llm.checking', 'Checking...'));
+    call('POST', 'llm', {
+      preset: presetSelect.value,
+      api_key: document.getElementById('llm-key').value,
+      model: modelInput.value,
+      base_url: urlInput.value
+    }).then(function(r) {
+      llmChecked = true;
+      if (r.check === 'ok') {
+        result('llm-result', '');
+        afterLLM();
+        return;
+      }
+      result('llm-result', t('setup.llm.failed', 'The provider did not answer:') + ' ' + r.check, true);
+      llmNext.textContent = t('setup.llm.anyway', 'Continue anyway');
+    }).catch(function(err) { result('llm-result', ''); fail(err); })
+      .finally(function() { busy(llmNext, false); });
+  });
+
+  // ── Step 3: soul ──
+  // An agent whose soul already exists keeps it: it is sealed.
+  function afterLLM() {
+    if (state.soul_exists) afterSoul();
+    else show('step-soul');
+  }
+
+  function afterSoul() {
+    if (state.mining_ready === false) show('step-claim');
+    else showFinish();
+  }
+
+  function renderQuestions() {
+    var box = document.getElementById('soul-questions');
+    box.innerHTML = '';
+    (state.questions || []).forEach(function(q, i) {
+      var fs = document.createElement('fieldset');
+      var legend = document.createElement('legend');
+      legend.textContent = (i + 1) + '. ' + q.text;
+      fs.appendChild(legend);
+      q.options.forEach(function(opt, j) {
+        var label = document.createElement('label');
+        var input = document.createElement('input');
+        input.type = 'radio';
+        input.name = 'q' + i;
+        input.value = j;
+        input.checked = j === 0;
+        label.appendChild(input);
+        label.appendChild(document.createTextNode(' ' + opt));
+        fs.appendChild(label);
+      });
+      box.appendChild(fs);
+    });
+  }
+
+  const soulNext = document.getElementById('soul-next');
+  var soulDone = false;
+  soulNext.addEventListener('click', function() {
+    if (soulDone) { afterSoul(); return; }
+    var answers = (state.questions || []).map(function(_, i) {
+      return parseInt(document.querySelector('input[name=q' + i + ']:checked').value, 10);
+    });
+    busy(soulNext, true);
+    result('soul-result', t('setup.soul.generating', 'Generating personality...'));
+    call('POST', 'soul', { answers: answers }).then(function(r) {
+      soulDone = true;
+      result('soul-result', r.soul + (r.note ? '\n\n' + r.note : ''));
+      soulNext.textContent = t('setup.continue', 'Continue');
+      document.getElementById('soul-skip').hidden = true;
+    }).catch(function(err) { result('soul-result', ''); fail(err); })
+      .finally(function() { busy(soulNext, false); });
+  });
+  document.getElementById('soul-skip').addEventListener('click', afterSoul);
+
+  // ── Step 4: claim (new agents) ──
+  const claimNext = document.getElementById('claim-next');
+  claimNext.addEventListener('click', function() {
+    busy(claimNext, true);
+    call('POST', 'claim', { code: document.getElementById('claim-code').value }).then(function(r) {
+      state.mining_ready = true;
+      if (r.display_name) result('claim-result', t('setup.claim.linked', 'Linked to:') + ' ' + r.display_name);
+      showFinish();
+    }).catch(fail)
+      .finally(function() { busy(claimNext, false); });
+  });
+  document.getElementById('claim-skip').addEventListener('click', showFinish);
+
+  // ── Finish ──
+  function showFinish() {
+    var summary = document.getElementById('finish-summary');
+    summary.textContent = state.mining_ready === false
+      ? t('setup.finish.unclaimed', 'The agent is not claimed yet. Save now, then run: clawwork claim')
+      : t('setup.finish.ready', 'Everything is set. The config will be saved to') + ' ' + state.config_path;
+    document.getElementById('finish-start').hidden = state.mining_ready === false;
+    show('step-finish');
+  }
+
+  function finish(start) {
+    document.querySelectorAll('#step-finish button').forEach(function(b) { b.disabled = true; });
+    call('POST', 'finish', { start: start }).then(function(r) {
+      if (!r.start) {
+        result('finish-result', t('setup.finish.saved', 'Config saved. You can close this tab and run: clawwork insc'));
+        return;
+      }
+      result('finish-result', t('setup.finish.starting', 'Config saved. Starting the miner — the console opens here shortly...'));
+      waitForConsole(0);
+    }).catch(function(err) {
+      document.querySelectorAll('#step-finish button').forEach(function(b) { b.disabled = false; });
+      fail(err);
+    });
+  }
+  document.getElementById('finish-start').addEventListener('click', function() { finish(true); });
+  document.getElementById('finish-save').addEventListener('click', function() { finish(false); });
+
+  // The miner's console usually takes over the wizard's port once it
+  // shuts down; follow it there.
+  function waitForConsole(tries) {
+    if (tries > 30) {
+      result('finish-result', t('setup.finish.noconsole', 'The miner is running. Its console address is printed in the terminal.'));
+      return;
+    }
+    setTimeout(function() {
+      fetch('/state').then(function(r) {
+        if (r.ok) { location.href = '/'; return; }
+        waitForConsole(tries + 1);
+      }).catch(function() { waitForConsole(tries + 1); });
+    }, 1000);
+  }
+
+  // ── Load ──
+  call('GET', 'state').then(function(s) {
+    state = s;
+    document.getElementById('setup-version').textContent = 'v' + s.version;
+    document.getElementById('token-id').value = s.token_id;
+    document.getElementById('config-path').textContent = s.config_path;
+    document.getElementById('config-exists').hidden = !s.config_exists;
+    presetSelect.innerHTML = '';
+    s.presets.map(function(p) { return p.name; }).concat(['custom', 'platform']).forEach(function(name) {
+      var opt = document.createElement('option');
+      opt.value = opt.textContent = name;
+      presetSelect.appendChild(opt);
+    });
+    presetChanged();
+    renderQuestions();
+    if (s.lang !== 'en') {
+      document.documentElement.lang = s.lang;
+      return fetch('/i18n/' + s.lang + '.json')
+        .then(function(r) { return r.ok ? r.json() : {}; })
+        .then(function(b) { strings = b; applyI18n(); });
+    }
+  }).catch(fail);
+})();
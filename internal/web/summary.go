@@ -0,0 +1,67 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	summaryTimeout   = 30 * time.Second
+	maxSummaryRunes  = 2000 // cap on the rolling summary kept in the prompt
+	maxExcerptRunes  = 160  // per-turn excerpt used when the LLM summary fails
+	summaryWordLimit = 200
+)
+
+// foldSummary merges turns evicted from the history window into the running
+// summary. The LLM writes the new summary; if it fails, short excerpts of
+// the evicted turns are appended instead so nothing is silently forgotten.
+func (s *ChatSession) foldSummary(ctx context.Context, evicted []ChatMessage) string {
+	ctx, cancel := context.WithTimeout(ctx, summaryTimeout)
+	defer cancel()
+
+	var sb strings.Builder
+	sb.WriteString("You are maintaining the running summary of a long conversation between you (the agent) and your owner.\n")
+	sb.WriteString("Merge the earlier summary with the turns below into one updated summary.\n")
+	sb.WriteString("Keep promises, commitments, decisions, preferences, names, numbers and open tasks. Drop small talk.\n")
+	sb.WriteString(fmt.Sprintf("Write plain text, at most %d words, no preamble.\n\n", summaryWordLimit))
+	if s.summary != "" {
+		sb.WriteString("--- Earlier summary ---\n")
+		sb.WriteString(s.summary)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("--- Turns to fold in ---\n")
+	for _, m := range evicted {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", m.Role, m.Content))
+	}
+
+	out, err := s.provider.Answer(ctx, sb.String())
+	if out = strings.TrimSpace(out); err != nil || out == "" {
+		slog.Warn("chat summary failed, keeping excerpts", "session", s.id, "error", err)
+		out = s.summary
+		for _, m := range evicted {
+			out += fmt.Sprintf("\n%s: %s", m.Role, truncateTitle(m.Content, maxExcerptRunes))
+		}
+		out = strings.TrimSpace(out)
+	}
+	return tailRunes(out, maxSummaryRunes)
+}
+
+// summaryBlock renders the rolling summary for inclusion in a prompt.
+func (s *ChatSession) summaryBlock() string {
+	if s.summary == "" {
+		return ""
+	}
+	return "--- Earlier in this conversation (summary) ---\n" + s.summary + "\n\n"
+}
+
+// tailRunes keeps the last n runes of s, since the newest material matters most.
+func tailRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return "..." + string(r[len(r)-n:])
+}